@@ -0,0 +1,126 @@
+// Command storage-sync copies the datasets, KV namespaces, queues and buckets
+// of one storage to another, such as the ./storage of a development run to the
+// cloud, or a cloud run down to ./storage for debugging.
+//
+// Usage:
+//
+//	storage-sync -from local -to cloud [-only datasets,kv] [-names a,b]
+//	             [-conflict skip|merge|overwrite] [-dry-run] [-page-size 100]
+//
+// The storages are local (the JSON files of ./storage), bolt (./storage/storage.db),
+// redis (KV and queues in the Redis of -redis-url, the rest local) and cloud.
+// The credentials and URLs come from the environment and the .env file, as
+// for an actor.
+//
+// The cloud storage can't list the messages of a queue without leasing them,
+// so queues synced from it are created without their messages; the SKIPPED
+// column of the report says so.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/scrapeless-ai/sdk-go/env"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/services/storage"
+)
+
+func main() {
+	from := flag.String("from", "local", "storage to copy from: local, bolt, redis or cloud (cloud queues are copied without their messages)")
+	to := flag.String("to", "cloud", "storage to copy to: local, bolt, redis or cloud")
+	only := flag.String("only", "", "comma-separated categories to copy: datasets, kv, queues, buckets (default all)")
+	names := flag.String("names", "", "comma-separated names of the resources to copy (default all)")
+	conflict := flag.String("conflict", string(storage.ConflictSkip), "what to do with resources already in the destination: skip, merge or overwrite")
+	dryRun := flag.Bool("dry-run", false, "report what would be copied without writing")
+	pageSize := flag.Int64("page-size", 100, "entries read per request")
+	redisUrl := flag.String("redis-url", "", "Redis URL of the redis storage (default SCRAPELESS_REDIS_URL)")
+	flag.Parse()
+
+	if err := run(*from, *to, *redisUrl, storage.SyncOptions{
+		Categories: split(*only),
+		Names:      split(*names),
+		Conflict:   storage.ConflictPolicy(*conflict),
+		DryRun:     *dryRun,
+		PageSize:   *pageSize,
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, "storage-sync:", err)
+		os.Exit(1)
+	}
+}
+
+func run(from string, to string, redisUrl string, opts storage.SyncOptions) error {
+	if from == to {
+		return fmt.Errorf("-from and -to are both %s", from)
+	}
+	base, _ := env.Load()
+	if redisUrl != "" {
+		base.RedisUrl = redisUrl
+	}
+	src, err := open(base, from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := open(base, to)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	results, err := storage.Sync(ctx, src, dst, opts)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CATEGORY\tNAME\tACTION\tCOPIED\tDROPPED\tSOURCE\tTARGET\tSKIPPED")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\t%s\n", r.Category, r.Name, r.Action, r.Copied, r.Dropped, r.SourceId, r.TargetId, r.Skipped)
+	}
+	_ = w.Flush()
+	if opts.DryRun {
+		fmt.Println("dry run: nothing was written")
+	}
+	return err
+}
+
+// open returns the storage kind, configured from base.
+func open(base *env.Config, kind string) (*storage.Storage, error) {
+	cfg := base.Clone()
+	cfg.IsOnline = false
+	cfg.LocalStorage = "dev"
+	switch kind {
+	case "local":
+		cfg.RedisUrl = ""
+	case "bolt":
+		cfg.LocalStorage = "bolt"
+		cfg.RedisUrl = ""
+	case "redis":
+		if cfg.RedisUrl == "" {
+			return nil, fmt.Errorf("the redis storage needs -redis-url or SCRAPELESS_REDIS_URL")
+		}
+	case "cloud":
+		if cfg.Actor.ApiKey == "" {
+			return nil, fmt.Errorf("the cloud storage needs SCRAPELESS_API_KEY")
+		}
+		cfg.IsOnline = true
+		cfg.RedisUrl = ""
+	default:
+		return nil, fmt.Errorf("unknown storage %q", kind)
+	}
+	return storage.NewStorage("http", cfg), nil
+}
+
+func split(s string) []string {
+	var parts []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
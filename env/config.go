@@ -1,10 +1,20 @@
 package env
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+	"time"
+)
 
-var Env config
+// Env is the process-wide configuration. It is populated by Default on first use.
+//
+// Deprecated: use Default, or build a *Config with Load or NewConfig and pass it
+// to scrapeless.WithConfig so several clients can run with different credentials.
+var Env Config
 
-type config struct {
+// Config holds everything a client needs to talk to Scrapeless: credentials,
+// service base URLs, proxy defaults and the actor's storage identifiers.
+type Config struct {
 	HTTPHeader              string `mapstructure:"SCRAPELESS_HTTP_HEADER"`
 	ProxyCountry            string `mapstructure:"SCRAPELESS_PROXY_COUNTRY"`
 	ProxySessionDurationMax int64  `mapstructure:"SCRAPELESS_PROXY_SESSION_DURATION_MAX"`
@@ -21,13 +31,33 @@ type config struct {
 	//ScrapelessApiHost     string `mapstructure:"SCRAPELESS_API_HOST"`
 	//ScrapelessCaptchaHost string `mapstructure:"SCRAPELESS_CAPTCHA_HOST"`
 
-	Actor actorEnv `mapstructure:",squash"`
-	Log   logEnv   `mapstructure:",squash"`
+	Actor ActorEnv `mapstructure:",squash"`
+	Log   LogEnv   `mapstructure:",squash"`
+	Retry RetryEnv `mapstructure:",squash"`
 
 	IsOnline bool `mapstructure:"SCRAPELESS_IS_ONLINE"`
+	// LocalStorage selects the storage used while offline: "dev", JSON files
+	// under storage/ (the default), or "bolt", a single embedded database at
+	// storage/storage.db that scales to large local runs.
+	LocalStorage string `mapstructure:"SCRAPELESS_LOCAL_STORAGE"`
+	// StorageDir is the directory of the storage used while offline,
+	// storage/ under the working directory when empty. The "dev" storage
+	// can use a single directory at a time in a process.
+	StorageDir string `mapstructure:"SCRAPELESS_STORAGE_DIR"`
+	// RedisUrl, such as redis://localhost:6379/0, serves the KV and queue
+	// storage from a self-hosted Redis, online or offline, or from a Redis
+	// Cluster with the cluster=true parameter. The other
+	// storage is unchanged. When Redis can't be reached as the storage is
+	// created, the error is logged and it isn't used.
+	RedisUrl string `mapstructure:"SCRAPELESS_REDIS_URL"`
+
+	// HTTPClient is used for every request made with this config. It can carry
+	// timeouts, proxies, TLS settings or a test transport. A nil HTTPClient makes
+	// each service create its own client.
+	HTTPClient *http.Client `mapstructure:"-"`
 }
 
-type actorEnv struct {
+type ActorEnv struct {
 	TeamId  string `mapstructure:"SCRAPELESS_TEAM_ID"`
 	ActorId string `mapstructure:"SCRAPELESS_ACTOR_ID"`
 	RunId   string `mapstructure:"SCRAPELESS_RUN_ID"`
@@ -42,14 +72,35 @@ type actorEnv struct {
 	HttpPort string `mapstructure:"SCRAPELESS_HTTP_PORT"`
 }
 
-type logEnv struct {
+type LogEnv struct {
 	MaxSize    int    `mapstructure:"SCRAPELESS_LOG_MAX_SIZE"`
 	MaxBackups int    `mapstructure:"SCRAPELESS_LOG_MAX_BACKUPS"`
 	MaxAge     int    `mapstructure:"SCRAPELESS_LOG_MAX_AGE"`
 	LogRootDir string `mapstructure:"SCRAPELESS_LOG_ROOT_DIR"`
 }
 
-func (c *config) Validate() error {
+// RetryEnv controls how failed requests to the Scrapeless API are retried.
+type RetryEnv struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int `mapstructure:"SCRAPELESS_RETRY_MAX_ATTEMPTS"`
+	// MinBackoff is the delay before the first retry; it doubles on every
+	// further retry up to MaxBackoff.
+	MinBackoff time.Duration `mapstructure:"SCRAPELESS_RETRY_MIN_BACKOFF"`
+	MaxBackoff time.Duration `mapstructure:"SCRAPELESS_RETRY_MAX_BACKOFF"`
+	// Jitter is the fraction (0-1) of each delay that is randomized.
+	Jitter float64 `mapstructure:"SCRAPELESS_RETRY_JITTER"`
+	// StatusCodes lists the response status codes that are retried.
+	StatusCodes []int `mapstructure:"SCRAPELESS_RETRY_STATUS_CODES"`
+	// NonIdempotent allows retrying POST and PATCH requests that carry no
+	// Idempotency-Key header. The SDK sets no such header, so by default
+	// POST and PATCH requests, such as those creating resources, are only
+	// retried when they failed before reaching the server, such as when the
+	// connection couldn't be established; never on a response status.
+	NonIdempotent bool `mapstructure:"SCRAPELESS_RETRY_NON_IDEMPOTENT"`
+}
+
+func (c *Config) Validate() error {
 	defaultID := "default"
 	if !c.IsOnline {
 		c.Actor.TeamId = defaultID
@@ -77,10 +128,27 @@ func (c *config) Validate() error {
 	return nil
 }
 
-func GetLogEnv() *logEnv {
-	return &Env.Log
+// Clone returns a copy of c that can be modified without affecting the original.
+func (c *Config) Clone() *Config {
+	cp := *c
+	cp.Retry.StatusCodes = append([]int(nil), c.Retry.StatusCodes...)
+	return &cp
+}
+
+// Or returns the first non-nil config in cfg, falling back to Default.
+func Or(cfg ...*Config) *Config {
+	for _, c := range cfg {
+		if c != nil {
+			return c
+		}
+	}
+	return Default()
+}
+
+func GetLogEnv() *LogEnv {
+	return &Default().Log
 }
 
-func GetActorEnv() *actorEnv {
-	return &Env.Actor
+func GetActorEnv() *ActorEnv {
+	return &Default().Actor
 }
@@ -6,44 +6,72 @@ import (
 	"path"
 	"reflect"
 	"runtime"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
-func init() {
-	initLog()
+var loadOnce sync.Once
+
+// Default returns the process-wide configuration. It is loaded from the
+// environment and an optional .env file the first time it is requested.
+func Default() *Config {
+	loadOnce.Do(func() {
+		initLog()
+		cfg, err := Load()
+		if err != nil {
+			log.Errorf("scrapeless: validate config err: %v", err)
+		}
+		Env = *cfg
+		log.Infof("scrapeless: conf: %+v", Env)
+	})
+	return &Env
+}
+
+// NewConfig returns a Config populated with the SDK defaults only, for callers
+// that set credentials and IDs in code instead of the environment.
+func NewConfig() *Config {
+	v := viper.New()
+	setDefaults(v)
+	var cfg Config
+	_ = v.Unmarshal(&cfg)
+	return &cfg
+}
+
+// Load reads a new Config from the environment and the optional .env file in
+// the working directory. The returned config is usable even when validation
+// fails, in which case the validation error is returned alongside it.
+func Load() (*Config, error) {
+	var cfg Config
+	v := viper.New()
 	// Set default values
-	setDefaults()
+	setDefaults(v)
 
 	// Enable automatic environment lookup
-	viper.AutomaticEnv()
+	v.AutomaticEnv()
 
 	// Bind env vars (required when there's no .env file)
-	if err := bindEnvs(viper.GetViper(), &Env); err != nil {
-		log.Fatalf("failed to bind environment variables: %v", err)
+	if err := bindEnvs(v, &cfg); err != nil {
+		return &cfg, fmt.Errorf("failed to bind environment variables: %v", err)
 	}
 
 	// Optionally read .env file (non-fatal)
-	viper.SetConfigFile(".env")
-	err := viper.ReadInConfig()
+	v.SetConfigFile(".env")
+	err := v.ReadInConfig()
 	if err != nil {
 		log.Warnf("scrapeless: warn reading config file: %v", err)
 	}
 
 	// Unmarshal all config into struct
-	err = viper.Unmarshal(&Env)
+	err = v.Unmarshal(&cfg)
 	if err != nil {
-		panic(err)
+		return &cfg, err
 	}
 
 	// Validate required fields
-	err = Env.Validate()
-	if err != nil {
-		log.Errorf("scrapeless: validate config err: %v", err)
-	}
-	log.Infof("scrapeless: conf: %+v", Env)
+	return &cfg, cfg.Validate()
 }
 
 func initLog() {
@@ -63,17 +91,22 @@ func initLog() {
 	log.SetLevel(log.TraceLevel)
 }
 
-func setDefaults() {
-	viper.SetDefault("SCRAPELESS_PROXY_COUNTRY", "ANY")
-	//viper.SetDefault("SCRAPELESS_BROWSER_API_HOST", "https://api.scrapeless.com")
-	viper.SetDefault("SCRAPELESS_PROXY_SESSION_DURATION_MAX", 120)
-	viper.SetDefault("SCRAPELESS_PROXY_GATEWAY_HOST", "gw-us.scrapeless.io:8789")
-	viper.SetDefault("SCRAPELESS_HTTP_HEADER", "x-api-token")
-	viper.SetDefault("SCRAPELESS_BASE_API_URL", "https://api.scrapeless.com")
-	viper.SetDefault("SCRAPELESS_ACTOR_API_URL", "https://actor.scrapeless.com")
-	viper.SetDefault("SCRAPELESS_STORAGE_API_URL", "https://storage.scrapeless.com")
-	viper.SetDefault("SCRAPELESS_BROWSER_API_URL", "https://browser.scrapeless.com")
-	viper.SetDefault("SCRAPELESS_CRAWL_API_URL", "https://api.scrapeless.com")
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("SCRAPELESS_PROXY_COUNTRY", "ANY")
+	//v.SetDefault("SCRAPELESS_BROWSER_API_HOST", "https://api.scrapeless.com")
+	v.SetDefault("SCRAPELESS_PROXY_SESSION_DURATION_MAX", 120)
+	v.SetDefault("SCRAPELESS_PROXY_GATEWAY_HOST", "gw-us.scrapeless.io:8789")
+	v.SetDefault("SCRAPELESS_HTTP_HEADER", "x-api-token")
+	v.SetDefault("SCRAPELESS_BASE_API_URL", "https://api.scrapeless.com")
+	v.SetDefault("SCRAPELESS_ACTOR_API_URL", "https://actor.scrapeless.com")
+	v.SetDefault("SCRAPELESS_STORAGE_API_URL", "https://storage.scrapeless.com")
+	v.SetDefault("SCRAPELESS_BROWSER_API_URL", "https://browser.scrapeless.com")
+	v.SetDefault("SCRAPELESS_CRAWL_API_URL", "https://api.scrapeless.com")
+	v.SetDefault("SCRAPELESS_RETRY_MAX_ATTEMPTS", 3)
+	v.SetDefault("SCRAPELESS_RETRY_MIN_BACKOFF", "200ms")
+	v.SetDefault("SCRAPELESS_RETRY_MAX_BACKOFF", "10s")
+	v.SetDefault("SCRAPELESS_RETRY_JITTER", 0.2)
+	v.SetDefault("SCRAPELESS_RETRY_STATUS_CODES", []int{408, 429, 500, 502, 503, 504})
 }
 
 func bindEnvs(v *viper.Viper, iface any) error {
@@ -87,7 +120,7 @@ func bindEnvs(v *viper.Viper, iface any) error {
 		field := typ.Field(i)
 
 		tag := field.Tag.Get("mapstructure")
-		if tag == "" {
+		if tag == "" || tag == "-" {
 			continue
 		}
 		if tag == ",squash" {
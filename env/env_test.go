@@ -11,3 +11,16 @@ func TestGetActorEnv(t *testing.T) {
 	actorEnv := GetActorEnv()
 	t.Logf("%+v", actorEnv)
 }
+
+func TestConfigClone(t *testing.T) {
+	a := NewConfig()
+	a.Actor.ApiKey = "key-a"
+	b := a.Clone()
+	b.Actor.ApiKey = "key-b"
+	if a.Actor.ApiKey != "key-a" || b.Actor.ApiKey != "key-b" {
+		t.Fatalf("clone shares state: %q %q", a.Actor.ApiKey, b.Actor.ApiKey)
+	}
+	if Or(nil, b) != b {
+		t.Fatal("Or should return the first non-nil config")
+	}
+}
@@ -4,13 +4,15 @@ import (
 	"context"
 	"github.com/scrapeless-ai/sdk-go/scrapeless"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"io"
+	"os"
 )
 
 func main() {
 	client := scrapeless.New(scrapeless.WithStorage())
 	defer client.Close()
 
-	// Put object The supported types include JSON、html、png
+	// Put object The supported types include JSON、html、png、jpg、pdf、har
 	objectId, err := client.Storage.Object.PutObject(context.Background(), "bucketId", "object.json", []byte("data"))
 	if err != nil {
 		log.Error(err.Error())
@@ -24,4 +26,33 @@ func main() {
 		}
 		log.Info(string(resp))
 	}
+
+	// Stream large files instead of loading them in memory
+	file, err := os.Open("report.pdf")
+	if err != nil {
+		log.Error(err.Error())
+		return
+	}
+	defer file.Close()
+	info, _ := file.Stat()
+	objectId, err = client.Storage.Object.PutObjectStream(context.Background(), "bucketId", "report.pdf", file, info.Size())
+	if err != nil {
+		log.Error(err.Error())
+		return
+	}
+	body, err := client.Storage.Object.GetObjectStream(context.Background(), "bucketId", objectId, 0)
+	if err != nil {
+		log.Error(err.Error())
+		return
+	}
+	defer body.Close()
+	out, err := os.Create("report-copy.pdf")
+	if err != nil {
+		log.Error(err.Error())
+		return
+	}
+	defer out.Close()
+	if _, err = io.Copy(out, body); err != nil {
+		log.Error(err.Error())
+	}
 }
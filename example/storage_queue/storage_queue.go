@@ -5,6 +5,9 @@ import (
 	"github.com/scrapeless-ai/sdk-go/scrapeless"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/services/storage"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/services/storage/queue"
+	"os"
+	"os/signal"
 )
 
 func main() {
@@ -36,4 +39,14 @@ func main() {
 		}
 	}
 
+	// consume messages with 8 concurrent handlers until interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	consumer := queue.NewConsumer(client.Storage.Queue, "queueId", func(ctx context.Context, msg *storage.Msg) error {
+		log.Infof("handle %s: %s", msg.ID, msg.Payload)
+		return nil
+	}, queue.WithConcurrency(8))
+	if err = consumer.Run(ctx); err != nil {
+		log.Error(err.Error())
+	}
 }
@@ -2,18 +2,18 @@ package code
 
 import (
 	"errors"
-	"fmt"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 const (
-	ErrCodeSystem          codes.Code = 500000
-	ErrCodeUnavailable                = 500014
-	ErrCodeUnauthorized               = 500401
-	ErrCodeInvalidArgument            = 400403
-	ErrCodeNotFound                   = 200005
-	ErrCodeAlreadyExists              = 200006
+	ErrCodeSystem          codes.Code = errs.CodeSystem
+	ErrCodeUnavailable                = errs.CodeUnavailable
+	ErrCodeUnauthorized               = errs.CodeUnauthorized
+	ErrCodeInvalidArgument            = errs.CodeInvalidArgument
+	ErrCodeNotFound                   = errs.CodeNotFound
+	ErrCodeAlreadyExists              = errs.CodeAlreadyExists
 )
 
 const (
@@ -69,15 +69,22 @@ func ErrParamInvalidMsg(msg string) error {
 	return status.Error(ErrCodeInvalidArgument, msg)
 }
 
+// Format converts err into an *errs.APIError. Errors that already are an
+// *errs.APIError are returned unchanged; other errors are wrapped so that
+// errors.Is and errors.As still reach them.
 func Format(err error) error {
 	if err == nil {
 		return nil
 	}
+	var apiErr *errs.APIError
+	if errors.As(err, &apiErr) {
+		return err
+	}
 
 	s, ok := status.FromError(err)
 	if ok && s != nil {
-		return errors.New(fmt.Sprintf("%d | %s", s.Proto().GetCode(), s.Proto().GetMessage()))
+		return errs.New(int(s.Proto().GetCode()), s.Proto().GetMessage(), err)
 	}
 
-	return errors.New(fmt.Sprintf("%d | %s", ErrCodeDefault, err.Error()))
+	return errs.New(errs.CodeOf(err), err.Error(), err)
 }
@@ -0,0 +1,63 @@
+package helper
+
+import (
+	"context"
+	"iter"
+)
+
+// PageFunc fetches a page (starting from 1) and reports whether more pages follow.
+type PageFunc[T any] func(ctx context.Context, page int64) (items []T, more bool, err error)
+
+// Paginate returns an iterator over the items of every page returned by
+// fetch. Pages are fetched lazily: the next page is fetched in the background
+// while the items of the current one are consumed. An error ends the
+// iteration after being yielded.
+func Paginate[T any](ctx context.Context, fetch PageFunc[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		// Cancels the prefetch when the loop stops early.
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		type result struct {
+			items []T
+			more  bool
+			err   error
+		}
+		start := func(page int64) <-chan result {
+			ch := make(chan result, 1)
+			go func() {
+				items, more, err := fetch(ctx, page)
+				ch <- result{items, more, err}
+			}()
+			return ch
+		}
+
+		next := start(1)
+		for page := int64(1); next != nil; page++ {
+			r := <-next
+			if r.err != nil {
+				var zero T
+				yield(zero, r.err)
+				return
+			}
+			next = nil
+			if r.more && len(r.items) > 0 {
+				next = start(page + 1)
+			}
+			for _, item := range r.items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// HasMore reports whether pages follow page, given the number of items it
+// held and the total number of items, when known (total > 0).
+func HasMore(page, pageSize int64, items int, total int64) bool {
+	if int64(items) < pageSize {
+		return false
+	}
+	return total <= 0 || page*pageSize < total
+}
@@ -0,0 +1,103 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func pages(n, pageSize int64, fetched *atomic.Int64) PageFunc[int64] {
+	return func(ctx context.Context, page int64) ([]int64, bool, error) {
+		fetched.Add(1)
+		var items []int64
+		for i := (page - 1) * pageSize; i < min(page*pageSize, n); i++ {
+			items = append(items, i)
+		}
+		return items, HasMore(page, pageSize, len(items), n), nil
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	var fetched atomic.Int64
+	var got []int64
+	for item, err := range Paginate(context.Background(), pages(25, 10, &fetched)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, item)
+	}
+	if len(got) != 25 || got[24] != 24 {
+		t.Fatalf("got %v", got)
+	}
+	if n := fetched.Load(); n != 3 {
+		t.Errorf("fetched %d pages, want 3", n)
+	}
+}
+
+func TestPaginateExactPages(t *testing.T) {
+	var fetched atomic.Int64
+	var got []int64
+	for item := range Paginate(context.Background(), pages(20, 10, &fetched)) {
+		got = append(got, item)
+	}
+	if len(got) != 20 {
+		t.Fatalf("got %d items", len(got))
+	}
+	if n := fetched.Load(); n != 2 {
+		t.Errorf("fetched %d pages, want 2", n)
+	}
+}
+
+func TestPaginatePrefetch(t *testing.T) {
+	started := make(chan int64, 10)
+	cancelled := make(chan struct{})
+	fetch := func(ctx context.Context, page int64) ([]int64, bool, error) {
+		started <- page
+		if page == 1 {
+			return []int64{1}, true, nil
+		}
+		<-ctx.Done()
+		close(cancelled)
+		return nil, false, ctx.Err()
+	}
+	for range Paginate(context.Background(), fetch) {
+		if page := <-started; page != 1 {
+			t.Fatalf("fetched page %d, want 1", page)
+		}
+		// The second page is fetched while the first one is consumed.
+		if page := <-started; page != 2 {
+			t.Fatalf("prefetched page %d, want 2", page)
+		}
+		break
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("prefetch not cancelled after break")
+	}
+}
+
+func TestPaginateError(t *testing.T) {
+	boom := errors.New("boom")
+	fetch := func(ctx context.Context, page int64) ([]string, bool, error) {
+		if page == 2 {
+			return nil, false, boom
+		}
+		return []string{"a", "b"}, true, nil
+	}
+	var items []string
+	var errs []error
+	for item, err := range Paginate(context.Background(), fetch) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		items = append(items, item)
+	}
+	if !reflect.DeepEqual(items, []string{"a", "b"}) || len(errs) != 1 || !errors.Is(errs[0], boom) {
+		t.Errorf("items = %v, errs = %v", items, errs)
+	}
+}
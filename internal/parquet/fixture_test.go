@@ -0,0 +1,63 @@
+package parquet
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/rows.parquet")
+
+// fixtureColumns and fixtureRows are the content of testdata/rows.parquet.
+var fixtureColumns = []Column{{"name", String}, {"price.amount", Double}, {"stock", Int64}, {"sale", Boolean}}
+
+func fixtureRows() [][]any {
+	rows := make([][]any, 20)
+	for i := range rows {
+		rows[i] = []any{fmt.Sprint("item ", i), nil, int64(i - 5), i%5 < 2}
+		if i%3 == 0 {
+			rows[i][1] = float64(i) / 4
+		}
+		if i%7 == 0 {
+			rows[i][3] = nil
+		}
+	}
+	rows[4][0] = nil
+	return rows
+}
+
+// TestWriterFixture checks that Writer still writes testdata/rows.parquet,
+// a file read back with another Parquet implementation, parquet-go, when it
+// was checked in. Only rewrite it with -update after reading the new file
+// back the same way.
+func TestWriterFixture(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, fixtureColumns)
+	for _, row := range fixtureRows() {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := os.WriteFile("testdata/rows.parquet", buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile("testdata/rows.parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Error("Writer output differs from testdata/rows.parquet")
+	}
+	columns, rows := readFile(t, want)
+	if !reflect.DeepEqual(columns, fixtureColumns) || !reflect.DeepEqual(rows, fixtureRows()) {
+		t.Errorf("testdata/rows.parquet = %v %v", columns, rows)
+	}
+}
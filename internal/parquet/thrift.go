@@ -0,0 +1,89 @@
+package parquet
+
+import "encoding/binary"
+
+// Thrift compact protocol types.
+const (
+	typeI32    = 5
+	typeI64    = 6
+	typeBinary = 8
+	typeList   = 9
+	typeStruct = 12
+)
+
+// tfield is a field of a thrift struct. Its value is an int32, int64,
+// string, tstruct or tlist.
+type tfield struct {
+	id    int16
+	value any
+}
+
+type tstruct []tfield
+
+type tlist struct {
+	elem  byte
+	items []any
+}
+
+// appendStruct encodes s with the thrift compact protocol.
+func appendStruct(b []byte, s tstruct) []byte {
+	var last int16
+	for _, f := range s {
+		typ := thriftType(f.value)
+		if delta := f.id - last; delta > 0 && delta <= 15 {
+			b = append(b, byte(delta)<<4|typ)
+		} else {
+			b = append(b, typ)
+			b = binary.AppendUvarint(b, zigzag(int64(f.id)))
+		}
+		last = f.id
+		b = appendValue(b, f.value)
+	}
+	return append(b, 0)
+}
+
+func appendValue(b []byte, v any) []byte {
+	switch v := v.(type) {
+	case int32:
+		return binary.AppendUvarint(b, zigzag(int64(v)))
+	case int64:
+		return binary.AppendUvarint(b, zigzag(v))
+	case string:
+		b = binary.AppendUvarint(b, uint64(len(v)))
+		return append(b, v...)
+	case tstruct:
+		return appendStruct(b, v)
+	case tlist:
+		if n := len(v.items); n < 15 {
+			b = append(b, byte(n)<<4|v.elem)
+		} else {
+			b = append(b, 0xf0|v.elem)
+			b = binary.AppendUvarint(b, uint64(n))
+		}
+		for _, item := range v.items {
+			b = appendValue(b, item)
+		}
+		return b
+	}
+	panic("parquet: unsupported thrift value")
+}
+
+func thriftType(v any) byte {
+	switch v.(type) {
+	case int32:
+		return typeI32
+	case int64:
+		return typeI64
+	case string:
+		return typeBinary
+	case tlist:
+		return typeList
+	case tstruct:
+		return typeStruct
+	}
+	panic("parquet: unsupported thrift value")
+}
+
+func zigzag(n int64) uint64 {
+	return uint64(n<<1) ^ uint64(n>>63)
+}
@@ -0,0 +1,282 @@
+// Package parquet writes Parquet files of optional UTF-8 string, 64-bit
+// integer, double and boolean columns, stored uncompressed with the PLAIN
+// encoding. That is all dataset exports need, without pulling in a full
+// Parquet implementation.
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const magic = "PAR1"
+
+// rowGroupRows is the number of rows buffered before a row group is written.
+const rowGroupRows = 10000
+
+// Parquet enums, see parquet.thrift.
+const (
+	physicalBoolean    = int32(0)
+	physicalInt64      = int32(2)
+	physicalDouble     = int32(5)
+	physicalByteArray  = int32(6)
+	repetitionOptional = int32(1)
+	convertedUTF8      = int32(0)
+	encodingPlain      = int32(0)
+	encodingRLE        = int32(3)
+	codecUncompressed  = int32(0)
+	pageData           = int32(0)
+)
+
+// Type is the type of the values of a column.
+type Type int
+
+const (
+	String Type = iota
+	Int64
+	Double
+	Boolean
+)
+
+func (t Type) String() string {
+	switch t {
+	case Int64:
+		return "int64"
+	case Double:
+		return "double"
+	case Boolean:
+		return "boolean"
+	}
+	return "string"
+}
+
+func (t Type) physical() int32 {
+	switch t {
+	case Int64:
+		return physicalInt64
+	case Double:
+		return physicalDouble
+	case Boolean:
+		return physicalBoolean
+	}
+	return physicalByteArray
+}
+
+// holds reports whether v is a value of a column of type t.
+func (t Type) holds(v any) bool {
+	switch v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == String
+	case int64:
+		return t == Int64
+	case float64:
+		return t == Double
+	case bool:
+		return t == Boolean
+	}
+	return false
+}
+
+// Column is a column of a file.
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Writer writes rows to a Parquet file. Rows are buffered and written one
+// row group at a time; the file is complete once Close returns.
+type Writer struct {
+	w       io.Writer
+	offset  int64
+	columns []Column
+
+	values    [][]any
+	rows      int
+	numRows   int64
+	rowGroups []any
+	err       error
+}
+
+// NewWriter returns a Writer of a file with the given columns to w.
+func NewWriter(w io.Writer, columns []Column) *Writer {
+	return &Writer{
+		w:       w,
+		columns: columns,
+		values:  make([][]any, len(columns)),
+	}
+}
+
+// Write adds a row, holding one value per column: a string, int64, float64
+// or bool matching the type of the column, or nil for a null.
+func (w *Writer) Write(row []any) error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(row) != len(w.columns) {
+		return errors.New("parquet: row length doesn't match the columns")
+	}
+	for i, v := range row {
+		if !w.columns[i].Type.holds(v) {
+			return fmt.Errorf("parquet: column %s holds %s values, not %T", w.columns[i].Name, w.columns[i].Type, v)
+		}
+	}
+	for i, v := range row {
+		w.values[i] = append(w.values[i], v)
+	}
+	w.rows++
+	if w.rows >= rowGroupRows {
+		w.flush()
+	}
+	return w.err
+}
+
+// Close writes the buffered rows and the file footer. It doesn't close the
+// underlying writer.
+func (w *Writer) Close() error {
+	w.flush()
+	if w.err != nil {
+		return w.err
+	}
+	schema := []any{tstruct{
+		{4, "schema"},
+		{5, int32(len(w.columns))},
+	}}
+	for _, column := range w.columns {
+		element := tstruct{
+			{1, column.Type.physical()},
+			{3, repetitionOptional},
+			{4, column.Name},
+		}
+		if column.Type == String {
+			element = append(element, tfield{6, convertedUTF8})
+		}
+		schema = append(schema, element)
+	}
+	footer := appendStruct(nil, tstruct{
+		{1, int32(1)},
+		{2, tlist{typeStruct, schema}},
+		{3, w.numRows},
+		{4, tlist{typeStruct, w.rowGroups}},
+		{6, "scrapeless sdk-go"},
+	})
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	w.write(append(footer, magic...))
+	return w.err
+}
+
+// flush writes the buffered rows as a row group, with one data page per column.
+func (w *Writer) flush() {
+	if w.err != nil {
+		return
+	}
+	if w.offset == 0 {
+		w.write([]byte(magic))
+	}
+	if w.rows == 0 {
+		return
+	}
+	var chunks []any
+	var groupSize int64
+	for i, values := range w.values {
+		column := w.columns[i]
+		page := encodePage(column.Type, values)
+		header := appendStruct(nil, tstruct{
+			{1, pageData},
+			{2, int32(len(page))},
+			{3, int32(len(page))},
+			{5, tstruct{
+				{1, int32(len(values))},
+				{2, encodingPlain},
+				{3, encodingRLE},
+				{4, encodingRLE},
+			}},
+		})
+		pageOffset := w.offset
+		size := int64(len(header) + len(page))
+		w.write(header)
+		w.write(page)
+		groupSize += size
+		chunks = append(chunks, tstruct{
+			{2, pageOffset},
+			{3, tstruct{
+				{1, column.Type.physical()},
+				{2, tlist{typeI32, []any{encodingPlain, encodingRLE}}},
+				{3, tlist{typeBinary, []any{column.Name}}},
+				{4, codecUncompressed},
+				{5, int64(len(values))},
+				{6, size},
+				{7, size},
+				{9, pageOffset},
+			}},
+		})
+		w.values[i] = values[:0]
+	}
+	w.rowGroups = append(w.rowGroups, tstruct{
+		{1, tlist{typeStruct, chunks}},
+		{2, groupSize},
+		{3, int64(w.rows)},
+	})
+	w.numRows += int64(w.rows)
+	w.rows = 0
+}
+
+func (w *Writer) write(b []byte) {
+	if w.err != nil {
+		return
+	}
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	w.err = err
+}
+
+// encodePage encodes the definition levels of values, as RLE runs, followed
+// by the non-null values of type t.
+func encodePage(t Type, values []any) []byte {
+	var levels []byte
+	for i := 0; i < len(values); {
+		defined := values[i] != nil
+		j := i + 1
+		for j < len(values) && (values[j] != nil) == defined {
+			j++
+		}
+		levels = binary.AppendUvarint(levels, uint64(j-i)<<1)
+		if defined {
+			levels = append(levels, 1)
+		} else {
+			levels = append(levels, 0)
+		}
+		i = j
+	}
+	page := binary.LittleEndian.AppendUint32(nil, uint32(len(levels)))
+	page = append(page, levels...)
+	// Booleans are bit-packed, the first value in the least significant bit.
+	var bits, n int
+	for _, v := range values {
+		switch v := v.(type) {
+		case string:
+			page = binary.LittleEndian.AppendUint32(page, uint32(len(v)))
+			page = append(page, v...)
+		case int64:
+			page = binary.LittleEndian.AppendUint64(page, uint64(v))
+		case float64:
+			page = binary.LittleEndian.AppendUint64(page, math.Float64bits(v))
+		case bool:
+			if v {
+				bits |= 1 << n
+			}
+			if n++; n == 8 {
+				page = append(page, byte(bits))
+				bits, n = 0, 0
+			}
+		}
+	}
+	if t == Boolean && n > 0 {
+		page = append(page, byte(bits))
+	}
+	return page
+}
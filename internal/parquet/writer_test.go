@@ -0,0 +1,209 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// reader decodes thrift compact structs into maps of field id to value.
+type reader struct {
+	b   []byte
+	pos int
+}
+
+func (r *reader) byte() byte {
+	c := r.b[r.pos]
+	r.pos++
+	return c
+}
+
+func (r *reader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *reader) varint() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *reader) structure() map[int16]any {
+	s := map[int16]any{}
+	var last int16
+	for {
+		h := r.byte()
+		if h == 0 {
+			return s
+		}
+		id := last + int16(h>>4)
+		if h>>4 == 0 {
+			id = int16(r.varint())
+		}
+		last = id
+		s[id] = r.value(h & 0x0f)
+	}
+}
+
+func (r *reader) value(typ byte) any {
+	switch typ {
+	case typeI32, typeI64:
+		return r.varint()
+	case typeBinary:
+		n := int(r.uvarint())
+		r.pos += n
+		return string(r.b[r.pos-n : r.pos])
+	case typeStruct:
+		return r.structure()
+	case typeList:
+		h := r.byte()
+		n := int(h >> 4)
+		if n == 15 {
+			n = int(r.uvarint())
+		}
+		items := make([]any, n)
+		for i := range items {
+			items[i] = r.value(h & 0x0f)
+		}
+		return items
+	}
+	panic(fmt.Sprintf("unexpected thrift type %d", typ))
+}
+
+// readFile decodes a file written by Writer into its columns and rows.
+func readFile(t *testing.T, data []byte) ([]Column, [][]any) {
+	t.Helper()
+	if string(data[:4]) != magic || string(data[len(data)-4:]) != magic {
+		t.Fatal("missing magic")
+	}
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := (&reader{b: data[:len(data)-8], pos: len(data) - 8 - size}).structure()
+
+	types := map[int64]Type{
+		int64(physicalByteArray): String,
+		int64(physicalInt64):     Int64,
+		int64(physicalDouble):    Double,
+		int64(physicalBoolean):   Boolean,
+	}
+	var columns []Column
+	for _, el := range footer[2].([]any)[1:] {
+		el := el.(map[int16]any)
+		columns = append(columns, Column{Name: el[4].(string), Type: types[el[1].(int64)]})
+	}
+	var rows [][]any
+	for _, rg := range footer[4].([]any) {
+		numRows := int(rg.(map[int16]any)[3].(int64))
+		group := make([][]any, numRows)
+		for i := range group {
+			group[i] = make([]any, len(columns))
+		}
+		for c, chunk := range rg.(map[int16]any)[1].([]any) {
+			meta := chunk.(map[int16]any)[3].(map[int16]any)
+			r := &reader{b: data, pos: int(meta[9].(int64))}
+			r.structure()
+			levelsLen := int(binary.LittleEndian.Uint32(data[r.pos:]))
+			r.pos += 4
+			levels := &reader{b: data[:r.pos+levelsLen], pos: r.pos}
+			r.pos += levelsLen
+			bit := 0
+			for row := 0; row < numRows; {
+				run := int(levels.uvarint() >> 1)
+				defined := levels.byte() == 1
+				for ; run > 0; run-- {
+					if defined {
+						switch columns[c].Type {
+						case String:
+							n := int(binary.LittleEndian.Uint32(data[r.pos:]))
+							group[row][c] = string(data[r.pos+4 : r.pos+4+n])
+							r.pos += 4 + n
+						case Int64:
+							group[row][c] = int64(binary.LittleEndian.Uint64(data[r.pos:]))
+							r.pos += 8
+						case Double:
+							group[row][c] = math.Float64frombits(binary.LittleEndian.Uint64(data[r.pos:]))
+							r.pos += 8
+						case Boolean:
+							group[row][c] = data[r.pos+bit/8]>>(bit%8)&1 == 1
+							bit++
+						}
+					}
+					row++
+				}
+			}
+		}
+		rows = append(rows, group...)
+	}
+	if n := footer[3].(int64); int(n) != len(rows) {
+		t.Fatalf("footer has %d rows, row groups %d", n, len(rows))
+	}
+	return columns, rows
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	columns := []Column{{"name", String}, {"price.amount", Double}, {"stock", Int64}, {"sale", Boolean}}
+	w := NewWriter(&buf, columns)
+	want := [][]any{
+		{"apple", 1.5, int64(3), true},
+		{"pear", nil, int64(-1), false},
+		{nil, nil, nil, nil},
+		{"", 3.0, int64(1) << 40, true},
+	}
+	for i := range 9 {
+		want = append(want, []any{nil, nil, nil, i%3 == 0})
+	}
+	for _, row := range want {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, rows := readFile(t, buf.Bytes())
+	if !reflect.DeepEqual(got, columns) {
+		t.Errorf("columns = %v", got)
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+	if err := NewWriter(&buf, columns).Write([]any{"x", "1.5", nil, nil}); err == nil {
+		t.Error("Write(string in a double column) succeeded")
+	}
+}
+
+func TestWriterRowGroups(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, []Column{{"n", String}})
+	total := rowGroupRows*2 + 5
+	for i := 0; i < total; i++ {
+		if err := w.Write([]any{fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_, rows := readFile(t, buf.Bytes())
+	if len(rows) != total {
+		t.Fatalf("got %d rows, want %d", len(rows), total)
+	}
+	if got := rows[total-1][0]; got != fmt.Sprint(total-1) {
+		t.Errorf("last row = %s", got)
+	}
+}
+
+func TestWriterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewWriter(&buf, []Column{{"a", String}}).Close(); err != nil {
+		t.Fatal(err)
+	}
+	columns, rows := readFile(t, buf.Bytes())
+	if len(columns) != 1 || len(rows) != 0 {
+		t.Errorf("columns = %v, rows = %d", columns, len(rows))
+	}
+}
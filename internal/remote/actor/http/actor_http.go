@@ -15,7 +15,7 @@ import (
 
 func (c *Client) Run(ctx context.Context, req *models.IRunActorData) (string, error) {
 	reqBody, _ := json.Marshal(req)
-	body, err := request2.RequestData(ctx, request2.ReqInfo{
+	body, err := c.req.RequestData(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/api/v1/actors/%s/runs", c.BaseUrl, req.ActorId),
 		Body:    string(reqBody),
@@ -29,7 +29,7 @@ func (c *Client) Run(ctx context.Context, req *models.IRunActorData) (string, er
 }
 
 func (c *Client) GetRunInfo(ctx context.Context, runId string) (*models.RunInfo, error) {
-	body, err := request2.RequestData(ctx, request2.ReqInfo{
+	body, err := c.req.RequestData(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/actors/runs/%s", c.BaseUrl, runId),
 		Body:    "",
@@ -49,7 +49,7 @@ func (c *Client) GetRunInfo(ctx context.Context, runId string) (*models.RunInfo,
 }
 
 func (c *Client) AbortRun(ctx context.Context, actorId, runId string) (bool, error) {
-	_, err := request2.RequestData(ctx, request2.ReqInfo{
+	_, err := c.req.RequestData(ctx, request2.ReqInfo{
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/actors/%s/runs/%s", c.BaseUrl, actorId, runId),
 		Headers: map[string]string{},
@@ -63,7 +63,7 @@ func (c *Client) AbortRun(ctx context.Context, actorId, runId string) (bool, err
 
 func (c *Client) Build(ctx context.Context, actorId string, version string) (string, error) {
 	fmt.Println(fmt.Sprintf("%s/api/v1/actors/%s/builds", c.BaseUrl, actorId))
-	body, err := request2.RequestData(ctx, request2.ReqInfo{
+	body, err := c.req.RequestData(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/api/v1/actors/%s/builds", c.BaseUrl, actorId),
 		Body:    fmt.Sprintf(`{"version": "%s"}`, version),
@@ -79,7 +79,7 @@ func (c *Client) Build(ctx context.Context, actorId string, version string) (str
 
 func (c *Client) GetBuildStatus(ctx context.Context, actorId string, buildId string) (*models.BuildInfo, error) {
 	fmt.Println(fmt.Sprintf("%s/api/v1/actors/%s/builds/%s", c.BaseUrl, actorId, buildId))
-	body, err := request2.RequestData(ctx, request2.ReqInfo{
+	body, err := c.req.RequestData(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/actors/%s/builds/%s", c.BaseUrl, actorId, buildId),
 		Headers: map[string]string{},
@@ -94,7 +94,7 @@ func (c *Client) GetBuildStatus(ctx context.Context, actorId string, buildId str
 }
 
 func (c *Client) AbortBuild(ctx context.Context, actorId string, buildId string) (bool, error) {
-	body, err := request2.RequestData(ctx, request2.ReqInfo{
+	body, err := c.req.RequestData(ctx, request2.ReqInfo{
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/actors/%s/builds/%s", c.BaseUrl, actorId, buildId),
 		Headers: map[string]string{},
@@ -118,7 +118,7 @@ func (c *Client) GetRunList(ctx context.Context, paginationParams *models.IPagin
 	val.Set("pageSize", fmt.Sprintf("%d", paginationParams.PageSize))
 	val.Set("desc", strconv.FormatBool(paginationParams.Desc))
 	parse.RawQuery = val.Encode()
-	body, err := request2.RequestData(ctx, request2.ReqInfo{
+	body, err := c.req.RequestData(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     parse.String(),
 		Headers: map[string]string{},
@@ -2,44 +2,21 @@ package http
 
 import (
 	"github.com/scrapeless-ai/sdk-go/env"
-	log "github.com/sirupsen/logrus"
-	"net/http"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
 )
 
-var (
-	defaultGatewayClient *Client
-)
-
-func Init(baseUrl ...string) {
-	log.Info("actor init")
-	var err error
-	u := env.Env.ScrapelessActorUrl
-	if len(baseUrl) > 0 {
-		u = baseUrl[0]
-	}
-	defaultGatewayClient, err = New(u)
-	if err != nil {
-		panic(err)
-	}
-}
-
 type Client struct {
-	client  *http.Client
+	req     *request.Client
 	BaseUrl string
 }
 
-func Default() *Client {
-	return defaultGatewayClient
-}
-
-func New(baseUrl string) (*Client, error) {
+func New(cfg *env.Config, baseUrl string) (*Client, error) {
 	return &Client{
-		client:  &http.Client{},
+		req:     request.New(cfg),
 		BaseUrl: baseUrl,
 	}, nil
 }
 
 func (c *Client) Close() error {
-	c.client.CloseIdleConnections()
-	return nil
+	return c.req.Close()
 }
@@ -2,6 +2,7 @@ package actor
 
 import (
 	"context"
+	"github.com/scrapeless-ai/sdk-go/env"
 	actor_http "github.com/scrapeless-ai/sdk-go/internal/remote/actor/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/actor/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
@@ -15,18 +16,21 @@ type Actor interface {
 	GetBuildStatus(ctx context.Context, actorId string, buildId string) (*models.BuildInfo, error)
 	AbortBuild(ctx context.Context, actorId string, buildId string) (bool, error)
 	GetRunList(ctx context.Context, paginationParams *models.IPaginationParams) ([]models.Payload, error)
+	Close() error
 }
 
-var ClientInterface Actor
-
-func NewClient(serverMode, baseUrl string) {
+func NewClient(serverMode string, cfg *env.Config, baseUrl string) Actor {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		actor_http.Init(baseUrl)
-		ClientInterface = actor_http.Default()
+		c, err := actor_http.New(cfg, baseUrl)
+		if err != nil {
+			panic(err)
+		}
+		return c
 	}
+	return nil
 }
@@ -25,7 +25,7 @@ func (c *Client) ScrapingBrowserCreate(ctx context.Context, req *models.CreateBr
 	value.Set("extension_ids", req.ExtensionIds)
 	parse, _ := url.Parse(fmt.Sprintf("%s/browser", c.BaseUrl))
 	parse.RawQuery = value.Encode()
-	request, err := request2.Request(ctx, request2.ReqInfo{
+	request, err := c.req.Request(ctx, request2.ReqInfo{
 		Method: http.MethodGet,
 		Url:    parse.String(),
 	})
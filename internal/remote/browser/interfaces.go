@@ -2,6 +2,7 @@ package browser
 
 import (
 	"context"
+	"github.com/scrapeless-ai/sdk-go/env"
 	browser_http "github.com/scrapeless-ai/sdk-go/internal/remote/browser/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/browser/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
@@ -9,18 +10,21 @@ import (
 
 type Browser interface {
 	ScrapingBrowserCreate(ctx context.Context, req *models.CreateBrowserRequest) (*models.CreateBrowserResponse, error)
+	Close() error
 }
 
-var ClientInterface Browser
-
-func NewClient(serverMode, baseUrl string) {
+func NewClient(serverMode string, cfg *env.Config, baseUrl string) Browser {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		browser_http.Init(baseUrl)
-		ClientInterface = browser_http.Default()
+		c, err := browser_http.New(cfg, baseUrl)
+		if err != nil {
+			panic(err)
+		}
+		return c
 	}
+	return nil
 }
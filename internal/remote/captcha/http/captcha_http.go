@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/captcha/models"
 	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"github.com/tidwall/gjson"
 	"google.golang.org/grpc/codes"
@@ -25,7 +26,7 @@ func (c *Client) CaptchaSolverCreateTask(ctx context.Context, req *models.Create
 		return "", err
 	}
 	fmt.Println(fmt.Sprintf("%s/api/v1/createTask", c.BaseUrl))
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method: http.MethodPost,
 		Url:    fmt.Sprintf("%s/api/v1/createTask", c.BaseUrl),
 		Body:   string(reqBody),
@@ -40,14 +41,14 @@ func (c *Client) CaptchaSolverCreateTask(ctx context.Context, req *models.Create
 	taskId := gjson.Parse(body).Get("taskId").String()
 	if taskId == "" {
 		msg := gjson.Parse(body).Get("message").String()
-		return "", fmt.Errorf("create task err:%s", msg)
+		return "", &errs.APIError{Code: errs.CodeSystem, Message: fmt.Sprintf("create task err:%s", msg)}
 	}
 	return taskId, nil
 
 }
 
 func (c *Client) CaptchaSolverGetTaskResult(ctx context.Context, req *models.GetTaskResultRequest) (map[string]any, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method: http.MethodGet,
 		Url:    fmt.Sprintf("%s/api/v1/getTaskResult/%s", c.BaseUrl, req.TaskId),
 		Headers: map[string]string{
@@ -60,7 +61,7 @@ func (c *Client) CaptchaSolverGetTaskResult(ctx context.Context, req *models.Get
 	}
 	if ok := gjson.Parse(body).Get("success").Bool(); !ok {
 		log.Error(body)
-		return nil, fmt.Errorf("get task result err")
+		return nil, &errs.APIError{Code: errs.CodeSystem, Message: "get task result err"}
 	}
 	var solution map[string]any
 	solutionStr := gjson.Parse(body).Get("solution").String()
@@ -78,11 +79,11 @@ func (c *Client) CaptchaSolverSolverTask(ctx context.Context, req *models.Create
 	for {
 		select {
 		case <-ctx.Done():
-			return nil, status.Errorf(codes.DeadlineExceeded, ctx.Err().Error())
+			return nil, status.Error(codes.DeadlineExceeded, ctx.Err().Error())
 		case <-time.After(time.Second):
 			result, err := c.CaptchaSolverGetTaskResult(ctx, &models.GetTaskResultRequest{TaskId: task, ApiKey: req.ApiKey})
 			if err != nil {
-				return nil, status.Errorf(codes.Aborted, err.Error())
+				return nil, status.Error(codes.Aborted, err.Error())
 			}
 			return result, nil
 		}
@@ -2,6 +2,7 @@ package captcha
 
 import (
 	"context"
+	"github.com/scrapeless-ai/sdk-go/env"
 	captcha_http "github.com/scrapeless-ai/sdk-go/internal/remote/captcha/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/captcha/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
@@ -11,18 +12,21 @@ type Captcha interface {
 	CaptchaSolverCreateTask(ctx context.Context, req *models.CreateTaskRequest) (string, error)
 	CaptchaSolverGetTaskResult(ctx context.Context, req *models.GetTaskResultRequest) (map[string]any, error)
 	CaptchaSolverSolverTask(ctx context.Context, req *models.CreateTaskRequest) (map[string]any, error)
+	Close() error
 }
 
-var ClientInterface Captcha
-
-func NewClient(serverMode, baseUrl string) {
+func NewClient(serverMode string, cfg *env.Config, baseUrl string) Captcha {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		captcha_http.Init(baseUrl)
-		ClientInterface = captcha_http.Default()
+		c, err := captcha_http.New(cfg, baseUrl)
+		if err != nil {
+			panic(err)
+		}
+		return c
 	}
+	return nil
 }
@@ -3,10 +3,10 @@ package http
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/crawl/models"
 	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
 	"github.com/tidwall/gjson"
 	"net/http"
 )
@@ -15,7 +15,7 @@ import (
 func (c *Client) ScrapeUrl(ctx context.Context, req *models.ScrapeOptions) (id string, err error) {
 	body, _ := json.Marshal(req)
 	fmt.Println(string(body))
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/api/v1/crawler/scrape", c.BaseUrl),
 		Body:    string(body),
@@ -28,14 +28,14 @@ func (c *Client) ScrapeUrl(ctx context.Context, req *models.ScrapeOptions) (id s
 	data := gjson.Parse(response)
 	success := data.Get("success").Bool()
 	if !success {
-		return "", errors.New("server error")
+		return "", serverError(data)
 	}
 	return data.Get("id").String(), nil
 }
 
 func (c *Client) BatchScrapeUrls(ctx context.Context, req *models.ScrapeOptionsMultiple) (scrapeResponse *models.ScrapeResponse, err error) {
 	body, _ := json.Marshal(req)
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/api/v1/crawler/scrape/batch", c.BaseUrl),
 		Body:    string(body),
@@ -47,7 +47,7 @@ func (c *Client) BatchScrapeUrls(ctx context.Context, req *models.ScrapeOptionsM
 	data := gjson.Parse(response)
 	success := data.Get("success").Bool()
 	if !success {
-		return nil, errors.New("server error")
+		return nil, serverError(data)
 	}
 	var invalidURLs = make([]string, 0)
 	id := data.Get("id").String()
@@ -62,7 +62,7 @@ func (c *Client) BatchScrapeUrls(ctx context.Context, req *models.ScrapeOptionsM
 }
 
 func (c *Client) CheckScrapeStatus(ctx context.Context, id string) (scrapeStatusResponse *models.ScrapeStatusResponse, err error) {
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/crawler/scrape/%s", c.BaseUrl, id),
 		Headers: map[string]string{},
@@ -73,7 +73,7 @@ func (c *Client) CheckScrapeStatus(ctx context.Context, id string) (scrapeStatus
 	data := gjson.Parse(response)
 	success := data.Get("success").Bool()
 	if !success {
-		return nil, errors.New("server error")
+		return nil, serverError(data)
 	}
 	if err = json.Unmarshal([]byte(response), &scrapeStatusResponse); err != nil {
 		return nil, err
@@ -81,11 +81,11 @@ func (c *Client) CheckScrapeStatus(ctx context.Context, id string) (scrapeStatus
 	if scrapeStatusResponse.Success {
 		return scrapeStatusResponse, nil
 	}
-	return nil, errors.New(scrapeStatusResponse.Error)
+	return nil, &errs.APIError{Code: errs.CodeSystem, Message: scrapeStatusResponse.Error}
 }
 
 func (c *Client) CheckBatchScrapeStatus(ctx context.Context, id string) (scrapeStatusResponseMultiple *models.ScrapeStatusResponseMultiple, err error) {
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/crawler/scrape/batch/%s", c.BaseUrl, id),
 		Headers: map[string]string{},
@@ -96,7 +96,7 @@ func (c *Client) CheckBatchScrapeStatus(ctx context.Context, id string) (scrapeS
 	data := gjson.Parse(response)
 	success := data.Get("success").Bool()
 	if !success {
-		return nil, errors.New("server error")
+		return nil, serverError(data)
 	}
 	if err = json.Unmarshal([]byte(response), &scrapeStatusResponseMultiple); err != nil {
 		return nil, err
@@ -107,7 +107,7 @@ func (c *Client) CheckBatchScrapeStatus(ctx context.Context, id string) (scrapeS
 func (c *Client) CrawlUrl(ctx context.Context, req *models.CrawlParams) (id string, err error) {
 	body, _ := json.Marshal(req)
 	fmt.Println(string(body))
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/api/v1/crawler/crawl", c.BaseUrl),
 		Body:    string(body),
@@ -119,13 +119,13 @@ func (c *Client) CrawlUrl(ctx context.Context, req *models.CrawlParams) (id stri
 	}
 	success := gjson.Parse(response).Get("success").Bool()
 	if !success {
-		return "", errors.New("server error")
+		return "", serverError(gjson.Parse(response))
 	}
 	return gjson.Parse(response).Get("id").String(), nil
 }
 
 func (c *Client) CheckCrawlStatus(ctx context.Context, id string) (crawlStatusResponse *models.CrawlStatusResponse, err error) {
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/crawler/crawl/%s", c.BaseUrl, id),
 		Headers: map[string]string{},
@@ -141,7 +141,7 @@ func (c *Client) CheckCrawlStatus(ctx context.Context, id string) (crawlStatusRe
 }
 
 func (c *Client) CheckCrawlErrors(ctx context.Context, id string) (crawlErrorsResponse *models.CrawlErrorsResponse, err error) {
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/crawler/crawl/%s/errors", c.BaseUrl, id),
 		Headers: map[string]string{},
@@ -156,7 +156,7 @@ func (c *Client) CheckCrawlErrors(ctx context.Context, id string) (crawlErrorsRe
 }
 
 func (c *Client) CancelCrawl(ctx context.Context, id string) (errorResponse *models.ErrorResponse, err error) {
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/crawler/crawl/%s", c.BaseUrl, id),
 		Headers: map[string]string{},
@@ -168,7 +168,16 @@ func (c *Client) CancelCrawl(ctx context.Context, id string) (errorResponse *mod
 		return nil, err
 	}
 	if errorResponse.Error != "" {
-		return nil, errors.New(errorResponse.Error)
+		return nil, &errs.APIError{Code: errs.CodeSystem, Message: errorResponse.Error}
 	}
 	return errorResponse, nil
 }
+
+// serverError returns the error reported by a response with success=false.
+func serverError(data gjson.Result) error {
+	msg := data.Get("error").String()
+	if msg == "" {
+		msg = "server error"
+	}
+	return &errs.APIError{Code: errs.CodeSystem, Message: msg}
+}
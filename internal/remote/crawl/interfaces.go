@@ -2,6 +2,7 @@ package crawl
 
 import (
 	"context"
+	"github.com/scrapeless-ai/sdk-go/env"
 	crawl_http "github.com/scrapeless-ai/sdk-go/internal/remote/crawl/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/crawl/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
@@ -16,18 +17,21 @@ type Captcha interface {
 	CheckCrawlStatus(ctx context.Context, id string) (crawlStatusResponse *models.CrawlStatusResponse, err error)
 	CheckCrawlErrors(ctx context.Context, id string) (crawlErrorsResponse *models.CrawlErrorsResponse, err error)
 	CancelCrawl(ctx context.Context, id string) (errorResponse *models.ErrorResponse, err error)
+	Close() error
 }
 
-var ClientInterface Captcha
-
-func NewClient(serverMode, baseUrl string) {
+func NewClient(serverMode string, cfg *env.Config, baseUrl string) Captcha {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		crawl_http.Init(baseUrl)
-		ClientInterface = crawl_http.Default()
+		c, err := crawl_http.New(cfg, baseUrl)
+		if err != nil {
+			panic(err)
+		}
+		return c
 	}
+	return nil
 }
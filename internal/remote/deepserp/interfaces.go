@@ -2,6 +2,7 @@ package deepserp
 
 import (
 	"context"
+	"github.com/scrapeless-ai/sdk-go/env"
 	deepserp_http "github.com/scrapeless-ai/sdk-go/internal/remote/deepserp/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/deepserp/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
@@ -10,18 +11,21 @@ import (
 type DeepSerp interface {
 	CreateTask(ctx context.Context, req *models.DeepserpTaskRequest) ([]byte, error)
 	GetTaskResult(ctx context.Context, taskIKd string) ([]byte, error)
+	Close() error
 }
 
-var ClientInterface DeepSerp
-
-func NewClient(serverMode, baseUrl string) {
+func NewClient(serverMode string, cfg *env.Config, baseUrl string) DeepSerp {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		deepserp_http.Init(baseUrl)
-		ClientInterface = deepserp_http.Default()
+		c, err := deepserp_http.New(cfg, baseUrl)
+		if err != nil {
+			panic(err)
+		}
+		return c
 	}
+	return nil
 }
@@ -5,9 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/extension/models"
 	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"io"
 	"mime/multipart"
@@ -57,9 +57,8 @@ func (c *Client) Upload(ctx context.Context, filePath, pluginName string) (exten
 		return nil, err
 	}
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set(env.Env.HTTPHeader, env.GetActorEnv().ApiKey)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.req.Do(req)
 	if err != nil {
 		log.Errorf("request error: %v", err)
 		return nil, err
@@ -88,7 +87,7 @@ func getFileName(filePath string) (string, error) {
 	}
 
 	if !isValid {
-		return "", fmt.Errorf("invalid file suffix: %s. Supported suffixes: %s", fileSuffix, strings.Join(validSuffixes, ", "))
+		return "", fmt.Errorf("%w: invalid file suffix: %s. Supported suffixes: %s", errs.ErrInvalidArgument, fileSuffix, strings.Join(validSuffixes, ", "))
 	}
 
 	fileName := filepath.Base(filePath)
@@ -130,9 +129,8 @@ func (c *Client) Update(ctx context.Context, extensionId, filePath, pluginName s
 		return false, err
 	}
 	request.Header.Set("Content-Type", writer.FormDataContentType())
-	request.Header.Set(env.Env.HTTPHeader, env.GetActorEnv().ApiKey)
 
-	resp, err := c.client.Do(request)
+	resp, err := c.req.Do(request)
 	if err != nil {
 		log.Errorf("request error: %v", err)
 		return false, err
@@ -146,11 +144,11 @@ func (c *Client) Update(ctx context.Context, extensionId, filePath, pluginName s
 		return true, nil
 	}
 
-	return false, fmt.Errorf("update failed with status %d: %s", resp.StatusCode, string(all))
+	return false, request2.ResponseError(resp, all)
 }
 
 func (c *Client) Get(ctx context.Context, extensionId string) (extensionDetail *models.ExtensionDetail, err error) {
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/browser/extensions/%s", c.BaseUrl, extensionId),
 		Headers: map[string]string{},
@@ -169,7 +167,7 @@ func (c *Client) Get(ctx context.Context, extensionId string) (extensionDetail *
 }
 
 func (c *Client) List(ctx context.Context) (extensionList []models.ExtensionListItem, err error) {
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/browser/extensions/list", c.BaseUrl),
 		Headers: map[string]string{},
@@ -186,7 +184,7 @@ func (c *Client) List(ctx context.Context) (extensionList []models.ExtensionList
 }
 
 func (c *Client) Delete(ctx context.Context, extensionId string) (success bool, err error) {
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/browser/extensions/%s", c.BaseUrl, extensionId),
 		Headers: map[string]string{},
@@ -2,6 +2,7 @@ package extension
 
 import (
 	"context"
+	"github.com/scrapeless-ai/sdk-go/env"
 	extension_http "github.com/scrapeless-ai/sdk-go/internal/remote/extension/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/extension/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
@@ -13,18 +14,21 @@ type Extension interface {
 	Get(ctx context.Context, extensionId string) (extensionDetail *models.ExtensionDetail, err error)
 	List(ctx context.Context) (extensionList []models.ExtensionListItem, err error)
 	Delete(ctx context.Context, extensionId string) (success bool, err error)
+	Close() error
 }
 
-var ClientInterface Extension
-
-func NewClient(serverMode, baseUrl string) {
+func NewClient(serverMode string, cfg *env.Config, baseUrl string) Extension {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		extension_http.Init(baseUrl)
-		ClientInterface = extension_http.Default()
+		c, err := extension_http.New(cfg, baseUrl)
+		if err != nil {
+			panic(err)
+		}
+		return c
 	}
+	return nil
 }
@@ -3,10 +3,10 @@ package http
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/profile/models"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
 	"net/http"
 	"net/url"
 )
@@ -16,7 +16,7 @@ func (c *Client) Create(ctx context.Context, name string) (profile *models.Profi
 	req := make(map[string]string)
 	req["name"] = name
 	body, _ := json.Marshal(req)
-	response, err := request.Request(ctx, request.ReqInfo{
+	response, err := c.req.Request(ctx, request.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/browser/profiles", c.BaseUrl),
 		Body:    string(body),
@@ -33,7 +33,7 @@ func (c *Client) Create(ctx context.Context, name string) (profile *models.Profi
 
 func (c *Client) Get(ctx context.Context, profileId string) (profile *models.ProfileInfo, err error) {
 	profile = new(models.ProfileInfo)
-	response, err := request.Request(ctx, request.ReqInfo{
+	response, err := c.req.Request(ctx, request.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/browser/profiles/%s", c.BaseUrl, profileId),
 		Headers: map[string]string{},
@@ -42,7 +42,7 @@ func (c *Client) Get(ctx context.Context, profileId string) (profile *models.Pro
 		return nil, err
 	}
 	if response == "" {
-		return nil, errors.New("profile not found")
+		return nil, fmt.Errorf("profile %s %w", profileId, errs.ErrNotFound)
 	}
 	if err = json.Unmarshal([]byte(response), profile); err != nil {
 		return nil, err
@@ -66,7 +66,7 @@ func (c *Client) List(ctx context.Context, req *models.ListProfileRequest) (resp
 	q.Add("pageSize", fmt.Sprintf("%d", req.PageSize))
 	u.RawQuery = q.Encode()
 
-	response, err := request.Request(ctx, request.ReqInfo{
+	response, err := c.req.Request(ctx, request.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     u.String(),
 		Headers: map[string]string{},
@@ -83,7 +83,7 @@ func (c *Client) List(ctx context.Context, req *models.ListProfileRequest) (resp
 
 func (c *Client) Delete(ctx context.Context, profileId string) (resp *models.DeleteProfileResponse, err error) {
 	resp = new(models.DeleteProfileResponse)
-	response, err := request.Request(ctx, request.ReqInfo{
+	response, err := c.req.Request(ctx, request.ReqInfo{
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/browser/profiles/%s", c.BaseUrl, profileId),
 		Body:    "",
@@ -104,7 +104,7 @@ func (c *Client) Update(ctx context.Context, profileId string, name string) (res
 	body, _ := json.Marshal(map[string]string{
 		"name": name,
 	})
-	response, err := request.Request(ctx, request.ReqInfo{
+	response, err := c.req.Request(ctx, request.ReqInfo{
 		Method:  http.MethodPut,
 		Url:     fmt.Sprintf("%s/browser/profiles/%s", c.BaseUrl, profileId),
 		Body:    string(body),
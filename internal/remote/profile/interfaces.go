@@ -2,6 +2,7 @@ package profile
 
 import (
 	"context"
+	"github.com/scrapeless-ai/sdk-go/env"
 	profile_http "github.com/scrapeless-ai/sdk-go/internal/remote/profile/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/profile/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
@@ -13,18 +14,21 @@ type Profile interface {
 	List(ctx context.Context, req *models.ListProfileRequest) (resp *models.ListProfileResponse, err error)
 	Update(ctx context.Context, profileId string, name string) (resp *models.UpdateProfileRequest, err error)
 	Delete(ctx context.Context, profileId string) (resp *models.DeleteProfileResponse, err error)
+	Close() error
 }
 
-var ClientInterface Profile
-
-func NewClient(serverMode, baseUrl string) {
+func NewClient(serverMode string, cfg *env.Config, baseUrl string) Profile {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		profile_http.Init(baseUrl)
-		ClientInterface = profile_http.Default()
+		c, err := profile_http.New(cfg, baseUrl)
+		if err != nil {
+			panic(err)
+		}
+		return c
 	}
+	return nil
 }
@@ -1,36 +1,20 @@
 package http
 
 import (
-	"net/http"
+	"github.com/scrapeless-ai/sdk-go/env"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
 )
 
-var (
-	defaultGatewayClient *Client
-)
-
-func Init() {
-	var err error
-	defaultGatewayClient, err = New()
-	if err != nil {
-		panic(err)
-	}
-}
-
 type Client struct {
-	client *http.Client
-}
-
-func Default() *Client {
-	return defaultGatewayClient
+	req *request.Client
 }
 
-func New() (*Client, error) {
+func New(cfg *env.Config) (*Client, error) {
 	return &Client{
-		client: &http.Client{},
+		req: request.New(cfg),
 	}, nil
 }
 
 func (c *Client) Close() error {
-	c.client.CloseIdleConnections()
-	return nil
+	return c.req.Close()
 }
@@ -3,7 +3,6 @@ package http
 import (
 	"context"
 	"fmt"
-	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/proxy/models"
 	"github.com/thoas/go-funk"
 	"google.golang.org/grpc/codes"
@@ -14,18 +13,19 @@ func (c *Client) ProxyGetProxy(ctx context.Context, req *models.GetProxyRequest)
 	if req.ApiKey == "" {
 		return "", status.Errorf(codes.InvalidArgument, "api key is required")
 	}
+	cfg := c.req.Config()
 	if req.Country == "" {
-		req.Country = env.Env.ProxyCountry
+		req.Country = cfg.ProxyCountry
 	}
-	if int64(req.SessionDuration) > env.Env.ProxySessionDurationMax {
-		req.SessionDuration = uint64(env.Env.ProxySessionDurationMax)
+	if int64(req.SessionDuration) > cfg.ProxySessionDurationMax {
+		req.SessionDuration = uint64(cfg.ProxySessionDurationMax)
 	}
 	if req.SessionId == "" {
 
 		req.SessionId = funk.RandomString(10)
 	}
 	if req.Gateway == "" {
-		req.Gateway = env.Env.ProxyGatewayHost
+		req.Gateway = cfg.ProxyGatewayHost
 	}
 
 	proxyURL := fmt.Sprintf(
@@ -2,6 +2,7 @@ package proxy
 
 import (
 	"context"
+	"github.com/scrapeless-ai/sdk-go/env"
 	proxy_http "github.com/scrapeless-ai/sdk-go/internal/remote/proxy/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/proxy/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
@@ -9,18 +10,21 @@ import (
 
 type Proxy interface {
 	ProxyGetProxy(ctx context.Context, req *models.GetProxyRequest) (string, error)
+	Close() error
 }
 
-var ClientInterface Proxy
-
-func NewClient(serverMode string) {
+func NewClient(serverMode string, cfg *env.Config) Proxy {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		proxy_http.Init()
-		ClientInterface = proxy_http.Default()
+		c, err := proxy_http.New(cfg)
+		if err != nil {
+			panic(err)
+		}
+		return c
 	}
+	return nil
 }
@@ -0,0 +1,74 @@
+package request
+
+import (
+	"net/http"
+
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+	"github.com/tidwall/gjson"
+)
+
+// requestIDHeaders are the response headers that may carry the request ID.
+var requestIDHeaders = []string{"X-Request-Id", "X-Trace-Id"}
+
+// AsError returns the *errs.APIError described by an error response.
+func (resp RespInfo) AsError() error {
+	e := &errs.APIError{Code: resp.Code, Message: resp.Msg}
+	if resp.Code >= 100 && resp.Code < 600 {
+		e.HTTPStatus = resp.Code
+	}
+	if e.Code == 0 {
+		e.Code = errs.CodeDefault
+	}
+	return e
+}
+
+// ResponseError returns the *errs.APIError for a response with an error
+// status, taking the code and message from its body when it is JSON.
+func ResponseError(resp *http.Response, body []byte) error {
+	e := &errs.APIError{
+		Code:       int(gjson.GetBytes(body, "code").Int()),
+		HTTPStatus: resp.StatusCode,
+	}
+	for _, field := range []string{"msg", "message", "error"} {
+		if v := gjson.GetBytes(body, field); v.Type == gjson.String && v.String() != "" {
+			e.Message = v.String()
+			break
+		}
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(resp.StatusCode)
+		if len(body) > 0 && !gjson.ValidBytes(body) {
+			e.Message = string(body)
+		}
+	}
+	if e.Code == 0 {
+		e.Code = statusCode(resp.StatusCode)
+	}
+	for _, h := range requestIDHeaders {
+		if id := resp.Header.Get(h); id != "" {
+			e.RequestID = id
+			break
+		}
+	}
+	return e
+}
+
+// statusCode maps an HTTP status to the matching Scrapeless error code.
+func statusCode(status int) int {
+	switch status {
+	case http.StatusNotFound:
+		return errs.CodeNotFound
+	case http.StatusConflict:
+		return errs.CodeAlreadyExists
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return errs.CodeInvalidArgument
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errs.CodeUnauthorized
+	case http.StatusServiceUnavailable:
+		return errs.CodeUnavailable
+	}
+	if status >= 500 {
+		return errs.CodeSystem
+	}
+	return errs.CodeDefault
+}
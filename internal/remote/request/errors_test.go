@@ -0,0 +1,30 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+func TestRequestReturnsAPIError(t *testing.T) {
+	c, srv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"err":true,"msg":"dataset not found"}`))
+	})
+
+	_, err := c.Request(context.Background(), ReqInfo{Method: http.MethodGet, Url: srv.URL})
+	var apiErr *errs.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *errs.APIError, got %T: %v", err, err)
+	}
+	if !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if apiErr.HTTPStatus != http.StatusNotFound || apiErr.Message != "dataset not found" || apiErr.RequestID != "req-1" {
+		t.Errorf("unexpected error %+v", apiErr)
+	}
+}
@@ -3,7 +3,6 @@ package request
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"io"
@@ -11,12 +10,27 @@ import (
 	"strings"
 )
 
-var (
-	c *http.Client
-)
+// Client sends authenticated requests to the Scrapeless API with the
+// credentials of a single env.Config.
+type Client struct {
+	cfg    *env.Config
+	client *http.Client
+	// owned reports whether client was created by New rather than supplied
+	// through cfg.HTTPClient, in which case Close must leave it alone.
+	owned bool
+}
 
-func init() {
-	c = &http.Client{}
+// New creates a Client bound to cfg. A nil cfg falls back to env.Default.
+// cfg.HTTPClient is used to send requests when it is set.
+func New(cfg *env.Config) *Client {
+	c := &Client{cfg: env.Or(cfg)}
+	if c.cfg.HTTPClient != nil {
+		c.client = c.cfg.HTTPClient
+	} else {
+		c.client = &http.Client{}
+		c.owned = true
+	}
+	return c
 }
 
 type ReqInfo struct {
@@ -45,7 +59,28 @@ func (resp RespInfo) GetData() any {
 	return resp.Data
 }
 
-func Request(ctx context.Context, reqInfo ReqInfo) (string, error) {
+// Config returns the configuration the client was created with.
+func (c *Client) Config() *env.Config {
+	return c.cfg
+}
+
+// Do sets the API key header on request and sends it. Network errors and
+// retryable status codes are retried according to the config's retry policy.
+func (c *Client) Do(request *http.Request) (*http.Response, error) {
+	request.Header.Set(c.cfg.HTTPHeader, c.cfg.Actor.ApiKey)
+	return c.do(request)
+}
+
+// Close releases idle connections of a client created by New. A client
+// supplied through env.Config.HTTPClient is shared and is not touched.
+func (c *Client) Close() error {
+	if c.owned {
+		c.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+func (c *Client) Request(ctx context.Context, reqInfo ReqInfo) (string, error) {
 	request, err := http.NewRequestWithContext(ctx, reqInfo.Method, reqInfo.Url, strings.NewReader(reqInfo.Body))
 	if err != nil {
 		log.Error(err.Error())
@@ -54,7 +89,6 @@ func Request(ctx context.Context, reqInfo ReqInfo) (string, error) {
 	for k, v := range reqInfo.Headers {
 		request.Header.Set(k, v)
 	}
-	request.Header.Set(env.Env.HTTPHeader, env.GetActorEnv().ApiKey)
 	if reqInfo.Body != "" {
 		if reqInfo.Body[0] == '[' || reqInfo.Body[0] == '{' {
 			request.Header.Set("Content-Type", "application/json")
@@ -73,11 +107,14 @@ func Request(ctx context.Context, reqInfo ReqInfo) (string, error) {
 		return "", err
 	}
 	defer do.Body.Close()
+	if do.StatusCode >= http.StatusBadRequest {
+		return string(all), ResponseError(do, all)
+	}
 	return string(all), nil
 }
 
 // RequestData return request data
-func RequestData(ctx context.Context, reqInfo ReqInfo) ([]byte, error) {
+func (c *Client) RequestData(ctx context.Context, reqInfo ReqInfo) ([]byte, error) {
 	request, err := http.NewRequestWithContext(ctx, reqInfo.Method, reqInfo.Url, strings.NewReader(reqInfo.Body))
 	if err != nil {
 		log.Error(err.Error())
@@ -86,7 +123,6 @@ func RequestData(ctx context.Context, reqInfo ReqInfo) ([]byte, error) {
 	for k, v := range reqInfo.Headers {
 		request.Header.Set(k, v)
 	}
-	request.Header.Set(env.Env.HTTPHeader, env.GetActorEnv().ApiKey)
 	if reqInfo.Body != "" {
 		if reqInfo.Body[0] == '[' || reqInfo.Body[0] == '{' {
 			request.Header.Set("Content-Type", "application/json")
@@ -106,6 +142,9 @@ func RequestData(ctx context.Context, reqInfo ReqInfo) ([]byte, error) {
 	}
 	defer do.Body.Close()
 	log.Infof("request data :%s", string(all))
+	if do.StatusCode >= http.StatusBadRequest {
+		return nil, ResponseError(do, all)
+	}
 	var resp RespInfo
 	err = json.Unmarshal(all, &resp)
 	if err != nil {
@@ -113,7 +152,7 @@ func RequestData(ctx context.Context, reqInfo ReqInfo) ([]byte, error) {
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("resp err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	return json.Marshal(resp.Data)
 }
@@ -0,0 +1,155 @@
+package request
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptrace"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/env"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+)
+
+// idempotencyKeyHeader marks a POST or PATCH request as safe to retry.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// do sends request, retrying it according to the retry policy of the config.
+// A request that can't be retried, see canRetry, is still sent again when it
+// failed before any of it reached the server, such as when the connection
+// couldn't be established. The response of the last attempt is returned as
+// is.
+func (c *Client) do(request *http.Request) (*http.Response, error) {
+	policy := c.cfg.Retry
+	attempts := policy.MaxAttempts
+	if attempts < 1 || !canRewind(request) {
+		attempts = 1
+	}
+	retryable := canRetry(request, policy)
+	for attempt := 1; ; attempt++ {
+		req := request
+		if attempt > 1 {
+			var err error
+			if req, err = rewind(request); err != nil {
+				return nil, err
+			}
+		}
+		var sent atomic.Bool
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+			WroteHeaders: func() { sent.Store(true) },
+		}))
+		resp, err := c.client.Do(req)
+		if attempt >= attempts || !shouldRetry(request.Context(), resp, err, policy) ||
+			!retryable && (resp != nil || sent.Load()) {
+			return resp, err
+		}
+
+		delay := backoff(policy, attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok && after > delay {
+				delay = after
+			}
+			// Drain the body so the connection can be reused.
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+			log.Warnf("%s %s returned %d, retrying in %s (attempt %d/%d)", request.Method, request.URL, resp.StatusCode, delay, attempt+1, attempts)
+		} else {
+			log.Warnf("%s %s failed: %v, retrying in %s (attempt %d/%d)", request.Method, request.URL, err, delay, attempt+1, attempts)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-request.Context().Done():
+			timer.Stop()
+			return nil, request.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// canRewind reports whether the body of request can be sent again.
+func canRewind(request *http.Request) bool {
+	return request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+}
+
+// canRetry reports whether request may be sent again after it reached the
+// server: POST and PATCH requests only with an Idempotency-Key header, or when
+// the policy allows retrying non-idempotent requests.
+func canRetry(request *http.Request, policy env.RetryEnv) bool {
+	switch request.Method {
+	case http.MethodPost, http.MethodPatch:
+		return policy.NonIdempotent || request.Header.Get(idempotencyKeyHeader) != ""
+	}
+	return true
+}
+
+func shouldRetry(ctx context.Context, resp *http.Response, err error, policy env.RetryEnv) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return slices.Contains(policy.StatusCodes, resp.StatusCode)
+}
+
+// rewind returns a copy of request with a fresh body for another attempt.
+func rewind(request *http.Request) (*http.Request, error) {
+	req := request.Clone(request.Context())
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+	return req, nil
+}
+
+// Backoff returns the delay to wait before retry number attempt (starting at 1)
+// of an operation the client can't retry by itself, such as resuming a
+// download.
+func (c *Client) Backoff(attempt int) time.Duration {
+	return backoff(c.cfg.Retry, attempt)
+}
+
+// backoff returns the delay before retry number attempt (starting at 1):
+// MinBackoff doubled on every retry, capped at MaxBackoff, with the Jitter
+// fraction of it randomized.
+func backoff(policy env.RetryEnv, attempt int) time.Duration {
+	delay := policy.MinBackoff
+	for i := 1; i < attempt && delay < policy.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+	if policy.Jitter > 0 && delay > 0 {
+		jitter := min(policy.Jitter, 1)
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+	return delay
+}
+
+// retryAfter parses the Retry-After header of 429 and 503 responses, given
+// either in seconds or as an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
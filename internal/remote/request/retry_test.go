@@ -0,0 +1,140 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/env"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *httptest.Server) {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	cfg := env.NewConfig()
+	cfg.Retry.MinBackoff = time.Millisecond
+	cfg.Retry.MaxBackoff = 5 * time.Millisecond
+	return New(cfg), srv
+}
+
+func TestRequestRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	c, srv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"a":1}` {
+			t.Errorf("body not replayed: %q", body)
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	resp, err := c.Request(context.Background(), ReqInfo{Method: http.MethodPut, Url: srv.URL, Body: `{"a":1}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp != "ok" || calls.Load() != 3 {
+		t.Fatalf("got %q after %d calls", resp, calls.Load())
+	}
+}
+
+func TestRequestDoesNotRetryPost(t *testing.T) {
+	var calls atomic.Int32
+	c, srv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	_, _ = c.Request(context.Background(), ReqInfo{Method: http.MethodPost, Url: srv.URL, Body: "{}"})
+	if calls.Load() != 1 {
+		t.Fatalf("POST without idempotency key sent %d times", calls.Load())
+	}
+
+	calls.Store(0)
+	_, _ = c.Request(context.Background(), ReqInfo{
+		Method:  http.MethodPost,
+		Url:     srv.URL,
+		Body:    "{}",
+		Headers: map[string]string{idempotencyKeyHeader: "key"},
+	})
+	if calls.Load() != 3 {
+		t.Fatalf("POST with idempotency key sent %d times", calls.Load())
+	}
+}
+
+func TestRequestHonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	c, srv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	start := time.Now()
+	resp, err := c.Request(context.Background(), ReqInfo{Method: http.MethodGet, Url: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp != "ok" || time.Since(start) < time.Second {
+		t.Fatalf("got %q after %s", resp, time.Since(start))
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := env.RetryEnv{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second}
+	for i, w := range want {
+		if got := backoff(policy, i+1); got != w {
+			t.Errorf("attempt %d: got %s, want %s", i+1, got, w)
+		}
+	}
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := backoff(policy, 1); got < 50*time.Millisecond || got > 100*time.Millisecond {
+			t.Fatalf("jittered delay out of range: %s", got)
+		}
+	}
+}
+
+func TestRequestRetriesPostNotSent(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+
+	// The first connection fails, before anything of the request is sent.
+	var dials atomic.Int32
+	dialer := &net.Dialer{}
+	cfg := env.NewConfig()
+	cfg.Retry.MinBackoff = time.Millisecond
+	cfg.HTTPClient = &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if dials.Add(1) == 1 {
+				return nil, errors.New("connection refused")
+			}
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}}
+	c := New(cfg)
+
+	resp, err := c.Request(context.Background(), ReqInfo{Method: http.MethodPost, Url: srv.URL, Body: "{}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp != "ok" || dials.Load() != 2 || calls.Load() != 1 {
+		t.Fatalf("got %q after %d dials and %d calls", resp, dials.Load(), calls.Load())
+	}
+}
@@ -2,7 +2,6 @@ package http
 
 import (
 	"fmt"
-	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"io"
 	"net/http"
@@ -21,8 +20,7 @@ func (c *Client) Request(keyword string, method string, path string, body io.Rea
 	for k, v := range headers {
 		request.Header.Set(k, v)
 	}
-	request.Header.Set(env.Env.HTTPHeader, env.GetActorEnv().ApiKey)
-	do, err := c.client.Do(request)
+	do, err := c.req.Do(request)
 	if err != nil {
 		log.Errorf("do request error :%v", err)
 		return nil, err
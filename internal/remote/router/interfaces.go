@@ -1,6 +1,7 @@
 package router
 
 import (
+	"github.com/scrapeless-ai/sdk-go/env"
 	router_http "github.com/scrapeless-ai/sdk-go/internal/remote/router/http"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"io"
@@ -8,18 +9,21 @@ import (
 
 type Router interface {
 	Request(keyword string, method string, path string, body io.Reader, headers map[string]string) (data []byte, err error)
+	Close() error
 }
 
-var ClientInterface Router
-
-func NewClient(serverMode string) {
+func NewClient(serverMode string, cfg *env.Config, baseUrl string) Router {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		router_http.Init()
-		ClientInterface = router_http.Default()
+		c, err := router_http.New(cfg, baseUrl)
+		if err != nil {
+			panic(err)
+		}
+		return c
 	}
+	return nil
 }
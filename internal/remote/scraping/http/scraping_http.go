@@ -12,7 +12,7 @@ import (
 
 func (c *Client) Scrape(ctx context.Context, req *models.ScrapingRequest) ([]byte, error) {
 	body, _ := json.Marshal(req)
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/scraping", c.BaseUrl),
 		Body:    string(body),
@@ -26,7 +26,7 @@ func (c *Client) Scrape(ctx context.Context, req *models.ScrapingRequest) ([]byt
 
 func (c *Client) CreateTask(ctx context.Context, req *models.ScrapingTaskRequest) ([]byte, error) {
 	body, _ := json.Marshal(req)
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/api/v1/scraper/request", c.BaseUrl),
 		Body:    string(body),
@@ -39,7 +39,7 @@ func (c *Client) CreateTask(ctx context.Context, req *models.ScrapingTaskRequest
 }
 
 func (c *Client) GetTaskResult(ctx context.Context, taskIKd string) ([]byte, error) {
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/result/%s", c.BaseUrl, taskIKd),
 		Headers: map[string]string{},
@@ -2,6 +2,7 @@ package scraping
 
 import (
 	"context"
+	"github.com/scrapeless-ai/sdk-go/env"
 	scraping_http "github.com/scrapeless-ai/sdk-go/internal/remote/scraping/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/scraping/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
@@ -11,18 +12,21 @@ type Scraping interface {
 	Scrape(ctx context.Context, req *models.ScrapingRequest) ([]byte, error)
 	CreateTask(ctx context.Context, req *models.ScrapingTaskRequest) ([]byte, error)
 	GetTaskResult(ctx context.Context, taskIKd string) ([]byte, error)
+	Close() error
 }
 
-var ClientInterface Scraping
-
-func NewClient(serverMode, baseUrl string) {
+func NewClient(serverMode string, cfg *env.Config, baseUrl string) Scraping {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		scraping_http.Init(baseUrl)
-		ClientInterface = scraping_http.Default()
+		c, err := scraping_http.New(cfg, baseUrl)
+		if err != nil {
+			panic(err)
+		}
+		return c
 	}
+	return nil
 }
@@ -2,11 +2,15 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/storage_bolt"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/storage_http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/storage_memory"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/storage_redis"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"io"
 )
 
 type Dataset interface {
@@ -15,7 +19,9 @@ type Dataset interface {
 	UpdateDataset(ctx context.Context, datasetID, name string) (bool, error)
 	DelDataset(ctx context.Context, datasetID string) (bool, error)
 	GetDataset(ctx context.Context, req *models.GetDataset) (*models.DatasetItem, error)
+	QueryDataset(ctx context.Context, req *models.QueryDatasetRequest) (*models.DatasetItem, error)
 	AddDatasetItem(ctx context.Context, datasetId string, data []map[string]any) (bool, error)
+	SetDatasetSchema(ctx context.Context, datasetId string, schema []byte) error
 	Close() error
 }
 
@@ -27,10 +33,19 @@ type KV interface {
 	RenameNamespace(ctx context.Context, namespaceId string, name string) (bool, error)
 	SetValue(ctx context.Context, req *models.SetValue) (bool, error)
 	ListKeys(ctx context.Context, req *models.ListKeyInfo) (*models.KvKeys, error)
+	ScanKeys(ctx context.Context, req *models.ScanKeysRequest) (*models.KvScan, error)
 	GetValue(ctx context.Context, namespaceId string, key string) (string, error)
 	DelValue(ctx context.Context, namespaceId string, key string) (bool, error)
 	BulkSetValue(ctx context.Context, req *models.BulkSet) (int64, error)
 	BulkDelValue(ctx context.Context, namespaceId string, keys []string) (bool, error)
+	// The atomic operations below are implemented by the local, bolt and
+	// Redis storage only: the KV API has no endpoint for them, so the HTTP
+	// storage returns errors.ErrUnsupported.
+	CompareAndSwap(ctx context.Context, req *models.CompareAndSwap) (bool, error)
+	SetIfNotExists(ctx context.Context, req *models.SetValue) (bool, error)
+	Increment(ctx context.Context, namespaceId string, key string, delta int64) (int64, error)
+	Touch(ctx context.Context, namespaceId string, key string, expiration uint) (bool, error)
+	GetWithMetadata(ctx context.Context, namespaceId string, key string) (*models.KvValue, error)
 	Close() error
 }
 
@@ -43,6 +58,10 @@ type Queue interface {
 	CreateMsg(ctx context.Context, req *models.CreateMsgRequest) (*models.CreateMsgResponse, error)
 	GetMsg(ctx context.Context, req *models.GetMsgRequest) (*models.GetMsgResponse, error)
 	AckMsg(ctx context.Context, req *models.AckMsgRequest) error
+	RenewMsg(ctx context.Context, req *models.RenewMsgRequest) error
+	NackMsg(ctx context.Context, req *models.NackMsgRequest) error
+	// ListMsgs lists the messages not acked yet without leasing them.
+	ListMsgs(ctx context.Context, req *models.ListMsgsRequest) (*models.ListMsgsResponse, error)
 	Close() error
 }
 
@@ -55,6 +74,8 @@ type Object interface {
 	GetObject(ctx context.Context, req *models.ObjectRequest) ([]byte, error)
 	DeleteObject(ctx context.Context, req *models.ObjectRequest) (bool, error)
 	PutObject(ctx context.Context, req *models.PutObjectRequest) (string, error)
+	PutObjectStream(ctx context.Context, req *models.PutObjectStreamRequest) (string, error)
+	GetObjectStream(ctx context.Context, req *models.GetObjectStreamRequest) (io.ReadCloser, error)
 	Close() error
 }
 
@@ -79,23 +100,77 @@ type Storage interface {
 	Object
 	Queue
 	Vector
+	// Compact removes the expired and orphaned data of the storage.
+	Compact(ctx context.Context) (*models.CompactStats, error)
 }
 
-var ClientInterface Storage
+// NewClient returns the storage of serverMode, or the local storage while
+// offline. With cfg.RedisUrl set, its KV and Queue are served by Redis; when
+// Redis can't be reached, the error is logged and they are served by the
+// storage of serverMode instead.
+func NewClient(serverMode string, cfg *env.Config, baseUrl string) Storage {
+	s := newClient(serverMode, cfg, baseUrl)
+	if s == nil || cfg.RedisUrl == "" {
+		return s
+	}
+	log.Info("redis...")
+	c, err := storage_redis.Open(context.Background(), cfg.RedisUrl)
+	if err != nil {
+		log.Errorf("failed to open the redis storage, serving KV and Queue without it: %v", err)
+		return s
+	}
+	return &redisStorage{Dataset: s, Object: s, Vector: s, RedisClient: c, base: s}
+}
+
+// redisStorage serves the KV and Queue of a storage from Redis.
+type redisStorage struct {
+	Dataset
+	Object
+	Vector
+	*storage_redis.RedisClient
+	base Storage
+}
+
+func (s *redisStorage) Close() error {
+	return errors.Join(s.RedisClient.Close(), s.base.Close())
+}
+
+// Compact compacts the base storage; Redis expires keys and messages
+// itself.
+func (s *redisStorage) Compact(ctx context.Context) (*models.CompactStats, error) {
+	return s.base.Compact(ctx)
+}
 
-func NewClient(serverMode, baseUrl string) {
-	if !env.Env.IsOnline {
+func newClient(serverMode string, cfg *env.Config, baseUrl string) Storage {
+	if !cfg.IsOnline {
 		serverMode = "dev"
+		if cfg.LocalStorage == "bolt" {
+			serverMode = "bolt"
+		}
 	}
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
-		storage_memory.Init()
-		ClientInterface = storage_memory.Default()
+		c, err := storage_memory.Default(cfg.StorageDir)
+		if err != nil {
+			panic(err)
+		}
+		return c
+	case "bolt":
+		log.Info("bolt...")
+		c, err := storage_bolt.Default(cfg.StorageDir)
+		if err != nil {
+			panic(err)
+		}
+		return c
 	default:
-		storage_http.Init(baseUrl)
-		ClientInterface = storage_http.Default()
+		c, err := storage_http.New(cfg, baseUrl)
+		if err != nil {
+			panic(err)
+		}
+		return c
 	}
+	return nil
 }
@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/env"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/storage_bolt"
+)
+
+func TestNewClientRedisUnreachable(t *testing.T) {
+	cfg := &env.Config{
+		LocalStorage: "bolt",
+		StorageDir:   filepath.Join(t.TempDir(), "storage"),
+		RedisUrl:     "redis://127.0.0.1:1/0",
+	}
+	s := NewClient("dev", cfg, "")
+	defer s.Close()
+	if _, ok := s.(*storage_bolt.BoltClient); !ok {
+		t.Errorf("NewClient() = %T, want the bolt storage", s)
+	}
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"io"
 	"time"
 )
 
@@ -31,6 +33,8 @@ type Dataset struct {
 	CreatedAt string       `json:"createdAt,omitempty"`
 	UpdatedAt string       `json:"updatedAt,omitempty"`
 	Stats     DatasetStats `json:"stats,omitempty"`
+	// Schema is the JSON Schema items must match, if any.
+	Schema json.RawMessage `json:"schema,omitempty"`
 }
 
 type DatasetStats struct {
@@ -51,6 +55,39 @@ type GetDataset struct {
 	PageSize  int    `json:"pageSize"`
 }
 
+// QueryOp is the operator of a dataset query condition.
+type QueryOp string
+
+const (
+	QueryEq       QueryOp = "eq"
+	QueryNe       QueryOp = "ne"
+	QueryGt       QueryOp = "gt"
+	QueryGte      QueryOp = "gte"
+	QueryLt       QueryOp = "lt"
+	QueryLte      QueryOp = "lte"
+	QueryContains QueryOp = "contains"
+)
+
+type QueryCondition struct {
+	Field string  `json:"field"`
+	Op    QueryOp `json:"op"`
+	Value any     `json:"value"`
+}
+
+type QuerySort struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc,omitempty"`
+}
+
+type QueryDatasetRequest struct {
+	DatasetId string           `json:"datasetId"`
+	Where     []QueryCondition `json:"where,omitempty"`
+	Sort      []QuerySort      `json:"sort,omitempty"`
+	Fields    []string         `json:"fields,omitempty"`
+	Offset    int              `json:"offset,omitempty"`
+	Limit     int              `json:"limit,omitempty"`
+}
+
 type DatasetItem struct {
 	Items     []map[string]any `json:"items,omitempty"`
 	Total     int              `json:"total"`
@@ -83,6 +120,8 @@ type Queue struct {
 	CreatedAt   string     `json:"createdAt,omitempty"`
 	UpdatedAt   string     `json:"updatedAt,omitempty"`
 	Stats       QueueStats `json:"stats,omitempty"`
+	// DeadLetterQueueId is the queue that receives the messages failing for good.
+	DeadLetterQueueId string `json:"deadLetterQueueId,omitempty"`
 }
 
 type GetQueueResponse struct {
@@ -90,10 +129,11 @@ type GetQueueResponse struct {
 }
 
 type CreateQueueRequest struct {
-	ActorId     string `json:"actorId"`
-	Name        string `json:"name"`
-	RunId       string `json:"runId"`
-	Description string `json:"description"`
+	ActorId           string `json:"actorId"`
+	Name              string `json:"name"`
+	RunId             string `json:"runId"`
+	Description       string `json:"description"`
+	DeadLetterQueueId string `json:"deadLetterQueueId,omitempty"`
 }
 
 type CreateQueueResponse struct {
@@ -154,6 +194,9 @@ type Msg struct {
 	SuccessAt int64  `json:"successAt"`
 	FailedAt  int64  `json:"failedAt"`
 	Desc      string `json:"desc"`
+	// FailedReason and SourceQueueId are set on the messages of a dead-letter queue.
+	FailedReason  string `json:"failedReason,omitempty"`
+	SourceQueueId string `json:"sourceQueueId,omitempty"`
 }
 
 type MsgLocal struct {
@@ -164,11 +207,35 @@ type MsgLocal struct {
 
 type GetMsgResponse []*Msg
 
+type ListMsgsRequest struct {
+	QueueId  string `json:"queueId"`
+	Page     int64  `json:"page"`
+	PageSize int64  `json:"pageSize"`
+}
+
+type ListMsgsResponse struct {
+	// Items are the messages not acked yet, in the order they are due.
+	Items     []*Msg `json:"items"`
+	Total     int64  `json:"total"`
+	TotalPage int64  `json:"totalPage"`
+	Page      int64  `json:"page"`
+	PageSize  int64  `json:"pageSize"`
+}
+
 type AckMsgRequest struct {
 	QueueId string `json:"queueId"`
 	MsgId   string `json:"msgId"`
 }
 
+type RenewMsgRequest AckMsgRequest
+
+type NackMsgRequest struct {
+	QueueId string `json:"queueId"`
+	MsgId   string `json:"msgId"`
+	// Delay is the number of seconds before the message can be pulled again.
+	Delay int64 `json:"delay"`
+}
+
 // Bucket
 
 type Bucket struct {
@@ -238,6 +305,23 @@ type PutObjectRequest struct {
 	RunId    string `json:"runId,omitempty"`
 }
 
+type PutObjectStreamRequest struct {
+	BucketId string
+	Filename string
+	Reader   io.Reader
+	// Size is the number of bytes Reader yields, or -1 if unknown.
+	Size    int64
+	ActorId string
+	RunId   string
+}
+
+type GetObjectStreamRequest struct {
+	BucketId string
+	ObjectId string
+	// Offset is the number of leading bytes of the object to skip.
+	Offset int64
+}
+
 type KvNamespace struct {
 	Items     []KvNamespaceItem `json:"items"`
 	Total     int64             `json:"total"`
@@ -275,6 +359,42 @@ type SetValueLocal struct {
 	ExpireAt time.Time `json:"expireAt"`
 }
 
+type CompareAndSwap struct {
+	NamespaceId string `json:"namespaceId"`
+	Key         string `json:"key"`
+	OldValue    string `json:"oldValue"`
+	Value       string `json:"value"`
+	Expiration  uint   `json:"expiration"`
+}
+
+type KvValue struct {
+	Key      string    `json:"key"`
+	Value    string    `json:"value"`
+	Size     int       `json:"size"`
+	ExpireAt time.Time `json:"expireAt"`
+}
+
+type ScanKeysRequest struct {
+	NamespaceId string `json:"namespaceId"`
+	Prefix      string `json:"prefix"`
+	// After is the key the scan resumes after, or "" to start from the first key.
+	After string `json:"after"`
+	Limit int64  `json:"limit"`
+}
+
+type KvScan struct {
+	// Items are the keys in ascending order, like the items of KvKeys.
+	Items []map[string]any `json:"items"`
+	More  bool             `json:"more"`
+}
+
+type CompactStats struct {
+	ExpiredKeys     int   `json:"expiredKeys"`
+	ExpiredMessages int   `json:"expiredMessages"`
+	OrphanedFiles   int   `json:"orphanedFiles"`
+	ReclaimedBytes  int64 `json:"reclaimedBytes"`
+}
+
 type ListKeyInfo struct {
 	NamespaceId string `json:"namespaceId"`
 	Page        int64  `json:"page"`
@@ -347,6 +467,7 @@ type CreateCollectionRequest struct {
 	ActorId     string `json:"actorId"`
 	Description string `json:"description"`
 	Dimension   int    `json:"dimension"`
+	Metric      string `json:"metric,omitempty"`
 	Name        string `json:"name"`
 	RunId       string `json:"runId"`
 }
@@ -0,0 +1,250 @@
+// Package query evaluates dataset queries on the client side. Backends
+// without server-side queries evaluate them with this package, so that every
+// backend returns the same results.
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+// Validate checks the operators of req and normalizes the values of its
+// conditions to the types JSON decoding produces, so that they compare
+// equal to the values of items.
+func Validate(req *models.QueryDatasetRequest) error {
+	if req.Offset < 0 || req.Limit < 0 {
+		return fmt.Errorf("%w: offset and limit must not be negative", errs.ErrInvalidArgument)
+	}
+	for i, cond := range req.Where {
+		switch cond.Op {
+		case models.QueryEq, models.QueryNe, models.QueryGt, models.QueryGte, models.QueryLt, models.QueryLte, models.QueryContains:
+		default:
+			return fmt.Errorf("%w: unknown query operator %q", errs.ErrInvalidArgument, cond.Op)
+		}
+		if cond.Field == "" {
+			return fmt.Errorf("%w: query condition %d has no field", errs.ErrInvalidArgument, i)
+		}
+		data, err := json.Marshal(cond.Value)
+		if err != nil {
+			return fmt.Errorf("%w: value of field %s: %v", errs.ErrInvalidArgument, cond.Field, err)
+		}
+		if err = json.Unmarshal(data, &req.Where[i].Value); err != nil {
+			return fmt.Errorf("%w: value of field %s: %v", errs.ErrInvalidArgument, cond.Field, err)
+		}
+	}
+	for _, s := range req.Sort {
+		if s.Field == "" {
+			return fmt.Errorf("%w: sort field is empty", errs.ErrInvalidArgument)
+		}
+	}
+	return nil
+}
+
+// Match reports whether item satisfies every condition. A missing field
+// compares as null.
+func Match(item map[string]any, where []models.QueryCondition) bool {
+	for _, cond := range where {
+		value, _ := Lookup(item, cond.Field)
+		if !matches(value, cond.Op, cond.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func matches(value any, op models.QueryOp, want any) bool {
+	switch op {
+	case models.QueryEq:
+		return reflect.DeepEqual(value, want)
+	case models.QueryNe:
+		return !reflect.DeepEqual(value, want)
+	case models.QueryContains:
+		switch v := value.(type) {
+		case string:
+			s, ok := want.(string)
+			return ok && strings.Contains(v, s)
+		case []any:
+			for _, elem := range v {
+				if reflect.DeepEqual(elem, want) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	// Ranges only compare numbers with numbers and strings with strings.
+	c, ok := compareSame(value, want)
+	if !ok {
+		return false
+	}
+	switch op {
+	case models.QueryGt:
+		return c > 0
+	case models.QueryGte:
+		return c >= 0
+	case models.QueryLt:
+		return c < 0
+	default:
+		return c <= 0
+	}
+}
+
+func compareSame(a, b any) (int, bool) {
+	switch a := a.(type) {
+	case float64:
+		if b, ok := b.(float64); ok {
+			return compareOrdered(a, b), true
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), true
+		}
+	}
+	return 0, false
+}
+
+func compareOrdered[T float64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Apply sorts the items matching a query, keeps those between req.Offset
+// and req.Limit and projects them on req.Fields. Items are otherwise kept in
+// their dataset order.
+func Apply(matches []map[string]any, req *models.QueryDatasetRequest) []map[string]any {
+	if len(req.Sort) > 0 {
+		sort.SliceStable(matches, func(i, j int) bool {
+			for _, s := range req.Sort {
+				a, _ := Lookup(matches[i], s.Field)
+				b, _ := Lookup(matches[j], s.Field)
+				c := compareValues(a, b, s.Desc)
+				if c != 0 {
+					return c < 0
+				}
+			}
+			return false
+		})
+	}
+	start := min(req.Offset, len(matches))
+	end := len(matches)
+	if req.Limit > 0 {
+		end = min(start+req.Limit, end)
+	}
+	page := matches[start:end]
+	if len(req.Fields) == 0 {
+		return page
+	}
+	projected := make([]map[string]any, 0, len(page))
+	for _, item := range page {
+		projected = append(projected, Project(item, req.Fields))
+	}
+	return projected
+}
+
+// compareValues orders values for sorting: numbers, then strings, then
+// booleans, then other values; null and missing values come last whatever
+// the direction.
+func compareValues(a, b any, desc bool) int {
+	ra, rb := rank(a), rank(b)
+	if ra == rankNull || rb == rankNull || ra != rb {
+		return ra - rb
+	}
+	var c int
+	switch a := a.(type) {
+	case float64:
+		c = compareOrdered(a, b.(float64))
+	case string:
+		c = strings.Compare(a, b.(string))
+	case bool:
+		if a != b.(bool) {
+			c = 1
+			if !a {
+				c = -1
+			}
+		}
+	}
+	if desc {
+		return -c
+	}
+	return c
+}
+
+const (
+	rankNumber = iota
+	rankString
+	rankBool
+	rankOther
+	rankNull
+)
+
+func rank(v any) int {
+	switch v.(type) {
+	case nil:
+		return rankNull
+	case float64:
+		return rankNumber
+	case string:
+		return rankString
+	case bool:
+		return rankBool
+	}
+	return rankOther
+}
+
+// Project returns the given fields of item, keeping nested fields selected
+// with dotted paths nested.
+func Project(item map[string]any, fields []string) map[string]any {
+	projected := map[string]any{}
+	for _, field := range fields {
+		value, ok := Lookup(item, field)
+		if !ok {
+			continue
+		}
+		if _, literal := item[field]; literal {
+			projected[field] = value
+			continue
+		}
+		parts := strings.Split(field, ".")
+		m := projected
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := m[part].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				m[part] = next
+			}
+			m = next
+		}
+		m[parts[len(parts)-1]] = value
+	}
+	return projected
+}
+
+// Lookup returns the value of field in item, following dotted paths into
+// nested objects.
+func Lookup(item map[string]any, field string) (any, bool) {
+	if value, ok := item[field]; ok {
+		return value, true
+	}
+	for i := 0; i < len(field); i++ {
+		if field[i] != '.' {
+			continue
+		}
+		if nested, ok := item[field[:i]].(map[string]any); ok {
+			if value, ok := Lookup(nested, field[i+1:]); ok {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
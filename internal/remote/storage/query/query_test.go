@@ -0,0 +1,134 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+var items = []map[string]any{
+	{"name": "apple", "price": map[string]any{"amount": 1.5}, "tags": []any{"fruit", "red"}, "updated": "2025-01-02"},
+	{"name": "pear", "price": map[string]any{"amount": 3.0}, "tags": []any{"fruit"}, "updated": "2025-03-01"},
+	{"name": "carrot", "price": map[string]any{"amount": 0.5}, "tags": []any{"vegetable"}},
+	{"name": "pineapple", "price": map[string]any{"amount": 3.0}},
+}
+
+func run(t *testing.T, req *models.QueryDatasetRequest) ([]string, int) {
+	t.Helper()
+	if err := Validate(req); err != nil {
+		t.Fatal(err)
+	}
+	var matches []map[string]any
+	for _, item := range items {
+		if Match(item, req.Where) {
+			matches = append(matches, item)
+		}
+	}
+	var names []string
+	for _, item := range Apply(matches, req) {
+		names = append(names, item["name"].(string))
+	}
+	return names, len(matches)
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   models.QueryDatasetRequest
+		want  []string
+		total int
+	}{
+		{
+			name:  "equality of a nested field with an int",
+			req:   models.QueryDatasetRequest{Where: []models.QueryCondition{{Field: "price.amount", Op: models.QueryEq, Value: 3}}},
+			want:  []string{"pear", "pineapple"},
+			total: 2,
+		},
+		{
+			name: "range",
+			req: models.QueryDatasetRequest{Where: []models.QueryCondition{
+				{Field: "price.amount", Op: models.QueryGte, Value: 1},
+				{Field: "price.amount", Op: models.QueryLt, Value: 3},
+			}},
+			want:  []string{"apple"},
+			total: 1,
+		},
+		{
+			name:  "string range skips missing fields",
+			req:   models.QueryDatasetRequest{Where: []models.QueryCondition{{Field: "updated", Op: models.QueryGt, Value: "2025-02-01"}}},
+			want:  []string{"pear"},
+			total: 1,
+		},
+		{
+			name:  "contains substring",
+			req:   models.QueryDatasetRequest{Where: []models.QueryCondition{{Field: "name", Op: models.QueryContains, Value: "apple"}}},
+			want:  []string{"apple", "pineapple"},
+			total: 2,
+		},
+		{
+			name:  "contains element",
+			req:   models.QueryDatasetRequest{Where: []models.QueryCondition{{Field: "tags", Op: models.QueryContains, Value: "fruit"}}},
+			want:  []string{"apple", "pear"},
+			total: 2,
+		},
+		{
+			name:  "not equal matches missing fields",
+			req:   models.QueryDatasetRequest{Where: []models.QueryCondition{{Field: "updated", Op: models.QueryNe, Value: "2025-01-02"}}},
+			want:  []string{"pear", "carrot", "pineapple"},
+			total: 3,
+		},
+		{
+			name: "sort by several fields",
+			req: models.QueryDatasetRequest{Sort: []models.QuerySort{
+				{Field: "price.amount", Desc: true},
+				{Field: "name"},
+			}},
+			want:  []string{"pear", "pineapple", "apple", "carrot"},
+			total: 4,
+		},
+		{
+			name:  "missing fields sort last",
+			req:   models.QueryDatasetRequest{Sort: []models.QuerySort{{Field: "updated", Desc: true}}},
+			want:  []string{"pear", "apple", "carrot", "pineapple"},
+			total: 4,
+		},
+		{
+			name:  "offset and limit",
+			req:   models.QueryDatasetRequest{Offset: 1, Limit: 2},
+			want:  []string{"pear", "carrot"},
+			total: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, total := run(t, &tt.req)
+			if !reflect.DeepEqual(got, tt.want) || total != tt.total {
+				t.Errorf("got %v (total %d), want %v (total %d)", got, total, tt.want, tt.total)
+			}
+		})
+	}
+}
+
+func TestProject(t *testing.T) {
+	got := Project(items[0], []string{"name", "price.amount", "missing"})
+	want := map[string]any{"name": "apple", "price": map[string]any{"amount": 1.5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, req := range []*models.QueryDatasetRequest{
+		{Where: []models.QueryCondition{{Field: "a", Op: "like"}}},
+		{Where: []models.QueryCondition{{Op: models.QueryEq}}},
+		{Sort: []models.QuerySort{{}}},
+		{Limit: -1},
+	} {
+		if err := Validate(req); !errors.Is(err, errs.ErrInvalidArgument) {
+			t.Errorf("Validate(%+v) = %v, want ErrInvalidArgument", req, err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// FromType returns the JSON Schema of the JSON encoding of values of type t,
+// following the rules of encoding/json: struct fields are named after their
+// json tag, and fields without omitempty are required. Pointers also accept
+// null. Types with custom JSON marshaling accept any value.
+func FromType(t reflect.Type) map[string]any {
+	doc := typeSchema(t, map[reflect.Type]bool{})
+	doc["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	return doc
+}
+
+func typeSchema(t reflect.Type, visiting map[reflect.Type]bool) map[string]any {
+	if t.Kind() == reflect.Pointer {
+		s := typeSchema(t.Elem(), visiting)
+		if typ, ok := s["type"].(string); ok {
+			s["type"] = []string{typ, "null"}
+		}
+		return s
+	}
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == rawMessageType, t.Implements(jsonMarshalerType), reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return map[string]any{}
+	case t.Implements(textMarshalerType), reflect.PointerTo(t).Implements(textMarshalerType):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Encoded in base64.
+			return map[string]any{"type": "string"}
+		}
+		s := map[string]any{"type": "array", "items": typeSchema(t.Elem(), visiting)}
+		if t.Kind() == reflect.Slice {
+			s["type"] = []string{"array", "null"}
+		}
+		return s
+	case reflect.Map:
+		return map[string]any{"type": []string{"object", "null"}, "additionalProperties": typeSchema(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			// Recursive type: don't constrain deeper levels.
+			return map[string]any{}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		properties := map[string]any{}
+		required := []string{}
+		structFields(t, visiting, properties, &required)
+		return map[string]any{"type": "object", "properties": properties, "required": required}
+	}
+	// Interfaces, and types encoding/json can't encode.
+	return map[string]any{}
+}
+
+// structFields adds the fields of t to properties, inlining embedded structs
+// like encoding/json does.
+func structFields(t reflect.Type, visiting map[reflect.Type]bool, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				structFields(ft, visiting, properties, required)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s := typeSchema(ft, visiting)
+		if strings.Contains(","+opts+",", ",string,") {
+			s = map[string]any{"type": "string"}
+		}
+		properties[name] = s
+		if !strings.Contains(","+opts+",", ",omitempty,") && !strings.Contains(","+opts+",", ",omitzero,") {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Package schema validates dataset items against the JSON Schema of their
+// dataset. Every backend validates items with it before storing them.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+// Validator validates items against a compiled schema.
+type Validator struct {
+	schema *jsonschema.Schema
+}
+
+// Compile compiles a JSON Schema document. Schemas without "$schema" follow
+// draft 2020-12.
+func Compile(raw []byte) (*Validator, error) {
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("dataset.json", bytes.NewReader(raw)); err != nil {
+		return nil, fmt.Errorf("%w: invalid schema: %v", errs.ErrInvalidArgument, err)
+	}
+	s, err := c.Compile("dataset.json")
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid schema: %v", errs.ErrInvalidArgument, err)
+	}
+	return &Validator{schema: s}, nil
+}
+
+// ValidateItems validates every item and returns an *errs.ValidationError
+// listing the invalid ones, or nil if they are all valid.
+func (v *Validator) ValidateItems(items []map[string]any) error {
+	var invalid []errs.ItemError
+	for i, item := range items {
+		if reasons := v.validate(item); len(reasons) > 0 {
+			invalid = append(invalid, errs.ItemError{Index: i, Reasons: reasons})
+		}
+	}
+	if len(invalid) > 0 {
+		return &errs.ValidationError{Items: invalid}
+	}
+	return nil
+}
+
+func (v *Validator) validate(item map[string]any) []string {
+	// Items may hold any Go value: validate their JSON form.
+	data, err := json.Marshal(item)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var doc any
+	if err = d.Decode(&doc); err != nil {
+		return []string{err.Error()}
+	}
+
+	err = v.schema.Validate(doc)
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		if err != nil {
+			return []string{err.Error()}
+		}
+		return nil
+	}
+	var reasons []string
+	var leaves func(ve *jsonschema.ValidationError)
+	leaves = func(ve *jsonschema.ValidationError) {
+		if len(ve.Causes) == 0 {
+			location := ve.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+			reasons = append(reasons, location+": "+ve.Message)
+			return
+		}
+		for _, cause := range ve.Causes {
+			leaves(cause)
+		}
+	}
+	leaves(ve)
+	return reasons
+}
@@ -0,0 +1,109 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+func TestValidateItems(t *testing.T) {
+	v, err := Compile([]byte(`{
+		"type": "object",
+		"properties": {
+			"url": {"type": "string", "format": "uri"},
+			"price": {"type": "number", "minimum": 0}
+		},
+		"required": ["url"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = v.ValidateItems([]map[string]any{
+		{"url": "https://example.com", "price": 1},
+		{"price": -1},
+		{"url": "https://example.com", "price": "1"},
+	})
+	var ve *errs.ValidationError
+	if !errors.As(err, &ve) || !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Fatalf("err = %v, want a ValidationError", err)
+	}
+	if len(ve.Items) != 2 || ve.Items[0].Index != 1 || ve.Items[1].Index != 2 {
+		t.Fatalf("invalid items = %+v", ve.Items)
+	}
+	if reasons := strings.Join(ve.Items[0].Reasons, "\n"); !strings.Contains(reasons, "url") || !strings.Contains(reasons, "/price") {
+		t.Errorf("reasons of item 1 = %q", reasons)
+	}
+	if reasons := ve.Items[1].Reasons; len(reasons) != 1 || !strings.HasPrefix(reasons[0], "/price:") {
+		t.Errorf("reasons of item 2 = %q", reasons)
+	}
+
+	if err = v.ValidateItems([]map[string]any{{"url": "https://example.com"}}); err != nil {
+		t.Errorf("valid item: %v", err)
+	}
+}
+
+func TestCompileInvalid(t *testing.T) {
+	for _, doc := range []string{`{"type": 1}`, `not json`} {
+		if _, err := Compile([]byte(doc)); !errors.Is(err, errs.ErrInvalidArgument) {
+			t.Errorf("Compile(%s) = %v, want ErrInvalidArgument", doc, err)
+		}
+	}
+}
+
+type price struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency,omitempty"`
+}
+
+type product struct {
+	URL     string            `json:"url"`
+	Price   *price            `json:"price"`
+	Tags    []string          `json:"tags,omitempty"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+	Seen    time.Time         `json:"seen"`
+	Stock   int               `json:"stock,string"`
+	Related []*product        `json:"related,omitempty"`
+	Ignored string            `json:"-"`
+	secret  string
+}
+
+func TestFromType(t *testing.T) {
+	doc, err := json.Marshal(FromType(reflect.TypeFor[product]()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := Compile(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var item map[string]any
+	data, _ := json.Marshal(product{URL: "https://example.com", Price: &price{Amount: 1}, Seen: time.Now(), Related: []*product{{}}})
+	_ = json.Unmarshal(data, &item)
+	if err = v.ValidateItems([]map[string]any{item}); err != nil {
+		t.Errorf("encoded product: %v", err)
+	}
+
+	err = v.ValidateItems([]map[string]any{
+		{"url": "https://example.com", "price": nil, "seen": "2025-01-01T00:00:00Z", "stock": "1"},
+		{"url": 1, "price": nil, "seen": "2025-01-01T00:00:00Z", "stock": "1"},
+		{"url": "https://example.com", "price": map[string]any{"amount": "1"}, "seen": "2025-01-01T00:00:00Z", "stock": "1"},
+		{"url": "https://example.com", "seen": "2025-01-01T00:00:00Z", "stock": "1"},
+	})
+	var ve *errs.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("err = %v, want a ValidationError", err)
+	}
+	var indexes []int
+	for _, item := range ve.Items {
+		indexes = append(indexes, item.Index)
+	}
+	if !reflect.DeepEqual(indexes, []int{1, 2, 3}) {
+		t.Errorf("invalid items = %v, want [1 2 3]", ve.Items)
+	}
+}
@@ -0,0 +1,562 @@
+package storage_bolt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+	bolt "go.etcd.io/bbolt"
+)
+
+var ctx = context.Background()
+
+func openTemp(t *testing.T) *BoltClient {
+	t.Helper()
+	c, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	inputDir := filepath.Join(dir, "kv_stores", "default")
+	if err := os.MkdirAll(inputDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(inputDir, inputFile), []byte(`{"url":"a"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := c.GetValue(ctx, "default", "INPUT"); err != nil || v != `{"url":"a"}` {
+		t.Errorf("GetValue(INPUT) = %q, %v", v, err)
+	}
+	if ok, err := c.SetValue(ctx, &models.SetValue{NamespaceId: "default", Key: "INPUT", Value: "x"}); ok || err != nil {
+		t.Errorf("SetValue(INPUT) = %v, %v, want read-only", ok, err)
+	}
+	if _, err = c.AddDatasetItem(ctx, "", []map[string]any{{"a": 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The data survives reopening, and the input is reloaded.
+	if err = os.WriteFile(filepath.Join(inputDir, inputFile), []byte(`{"url":"b"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if c, err = Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	items, err := c.GetDataset(ctx, &models.GetDataset{DatasetId: "default", Page: 1, PageSize: 10})
+	if err != nil || items.Total != 1 {
+		t.Errorf("GetDataset() = %+v, %v", items, err)
+	}
+	if v, _ := c.GetValue(ctx, "default", "INPUT"); v != `{"url":"b"}` {
+		t.Errorf("GetValue(INPUT) = %q after reopening", v)
+	}
+}
+
+func TestDataset(t *testing.T) {
+	c := openTemp(t)
+	dataset, err := c.CreateDataset(ctx, &models.CreateDatasetRequest{Name: "products"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 3 {
+		if _, err = c.AddDatasetItem(ctx, dataset.Id, []map[string]any{{"n": i, "b": "x"}, {"n": i + 10, "a": true}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	items, err := c.GetDataset(ctx, &models.GetDataset{DatasetId: dataset.Id, Desc: true, Page: 2, PageSize: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items.Total != 6 || items.TotalPage != 2 || len(items.Items) != 2 || items.Items[0]["n"] != 10.0 || items.Items[1]["n"] != 0.0 {
+		t.Errorf("GetDataset() = %+v", items)
+	}
+	query, err := c.QueryDataset(ctx, &models.QueryDatasetRequest{
+		DatasetId: dataset.Id,
+		Where:     []models.QueryCondition{{Field: "n", Op: models.QueryGte, Value: 2}},
+		Sort:      []models.QuerySort{{Field: "n", Desc: true}},
+	})
+	if err != nil || query.Total != 4 || query.Items[0]["n"] != 12.0 {
+		t.Errorf("QueryDataset() = %+v, %v", query, err)
+	}
+
+	if ok, err := c.UpdateDataset(ctx, dataset.Id, "renamed"); !ok || err != nil {
+		t.Fatalf("UpdateDataset() = %v, %v", ok, err)
+	}
+	list, err := c.ListDatasets(ctx, &models.ListDatasetsRequest{Page: 1, PageSize: 10})
+	if err != nil || list.Total != 2 || list.Items[1].Name != "renamed" {
+		t.Fatalf("ListDatasets() = %+v, %v", list, err)
+	}
+	if got := list.Items[1].Fields; fmt.Sprint(got) != "[b n a]" {
+		t.Errorf("Fields = %v, want [b n a]", got)
+	}
+
+	err = c.SetDatasetSchema(ctx, dataset.Id, []byte(`{"type":"object","required":["n"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.AddDatasetItem(ctx, dataset.Id, []map[string]any{{"b": "no n"}}); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("AddDatasetItem(invalid) = %v, want ErrInvalidArgument", err)
+	}
+	if _, err = c.UpdateDataset(ctx, "missing", "x"); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("UpdateDataset(missing) = %v, want ErrNotFound", err)
+	}
+	if _, err = c.DelDataset(ctx, dataset.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.GetDataset(ctx, &models.GetDataset{DatasetId: dataset.Id, Page: 1, PageSize: 1}); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("GetDataset(deleted) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestKV(t *testing.T) {
+	c := openTemp(t)
+	ns, err := c.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{Name: "sessions"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{Name: "sessions"}); !errors.Is(err, errs.ErrAlreadyExists) {
+		t.Errorf("CreateNamespace(duplicate) = %v, want ErrAlreadyExists", err)
+	}
+
+	n, err := c.BulkSetValue(ctx, &models.BulkSet{NamespaceId: ns, Items: []models.BulkItem{
+		{Key: "user/1", Value: "a"}, {Key: "user/2", Value: "bb"}, {Key: "user/3", Value: "c"}, {Key: "site/1", Value: "d"},
+	}})
+	if err != nil || n != 4 {
+		t.Fatalf("BulkSetValue() = %d, %v", n, err)
+	}
+	if v, err := c.GetValue(ctx, ns, "user/2"); err != nil || v != "bb" {
+		t.Errorf("GetValue() = %q, %v", v, err)
+	}
+	if _, err = c.GetValue(ctx, ns, "missing"); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("GetValue(missing) = %v, want ErrNotFound", err)
+	}
+
+	scan, err := c.ScanKeys(ctx, &models.ScanKeysRequest{NamespaceId: ns, Prefix: "user/", Limit: 2})
+	if err != nil || len(scan.Items) != 2 || !scan.More || scan.Items[1]["key"] != "user/2" {
+		t.Fatalf("ScanKeys() = %+v, %v", scan, err)
+	}
+	scan, err = c.ScanKeys(ctx, &models.ScanKeysRequest{NamespaceId: ns, Prefix: "user/", After: "user/2", Limit: 2})
+	if err != nil || len(scan.Items) != 1 || scan.More || scan.Items[0]["key"] != "user/3" {
+		t.Errorf("ScanKeys(after) = %+v, %v", scan, err)
+	}
+
+	if ok, _ := c.CompareAndSwap(ctx, &models.CompareAndSwap{NamespaceId: ns, Key: "user/1", OldValue: "x", Value: "b"}); ok {
+		t.Error("CompareAndSwap() swapped a different value")
+	}
+	if ok, _ := c.CompareAndSwap(ctx, &models.CompareAndSwap{NamespaceId: ns, Key: "user/1", OldValue: "a", Value: "b"}); !ok {
+		t.Error("CompareAndSwap() didn't swap")
+	}
+	if ok, _ := c.SetIfNotExists(ctx, &models.SetValue{NamespaceId: ns, Key: "user/1", Value: "c"}); ok {
+		t.Error("SetIfNotExists() overwrote a key")
+	}
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Increment(ctx, ns, "counter", 2); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if v, _ := c.GetValue(ctx, ns, "counter"); v != "40" {
+		t.Errorf("counter = %s, want 40", v)
+	}
+
+	// An expired key reads as empty and isn't listed.
+	expired := newLocalValue(&models.SetValue{NamespaceId: ns, Key: "user/4", Value: "old"})
+	expired.ExpireAt = time.Now().Add(-time.Second)
+	if err = c.updateKeys(ns, func(keys *bolt.Bucket) error { return putKey(keys, expired) }); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := c.GetValue(ctx, ns, "user/4"); err != nil || v != "" {
+		t.Errorf("GetValue(expired) = %q, %v", v, err)
+	}
+	keys, err := c.ListKeys(ctx, &models.ListKeyInfo{NamespaceId: ns, Page: 1, Size: 10})
+	if err != nil || keys.Total != 5 {
+		t.Errorf("ListKeys() = %+v, %v", keys, err)
+	}
+	if ok, _ := c.Touch(ctx, ns, "user/4", 60); ok {
+		t.Error("Touch() revived an expired key")
+	}
+
+	if _, err = c.BulkDelValue(ctx, ns, []string{"user/1", "user/2"}); err != nil {
+		t.Fatal(err)
+	}
+	namespace, err := c.GetNamespace(ctx, ns)
+	if err != nil || namespace.Stats.Count != 3 {
+		t.Errorf("GetNamespace() = %+v, %v", namespace, err)
+	}
+	if ok, err := c.RenameNamespace(ctx, ns, "default"); ok || !errors.Is(err, errs.ErrAlreadyExists) {
+		t.Errorf("RenameNamespace(default) = %v, %v, want ErrAlreadyExists", ok, err)
+	}
+	if _, err = c.DelNamespace(ctx, ns); err != nil {
+		t.Fatal(err)
+	}
+	// The name is free again.
+	if _, err = c.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{Name: "sessions"}); err != nil {
+		t.Errorf("CreateNamespace() after delete = %v", err)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	c := openTemp(t)
+	dlq, err := c.CreateQueue(ctx, &models.CreateQueueRequest{Name: "dead"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	queue, err := c.CreateQueue(ctx, &models.CreateQueueRequest{Name: "jobs", DeadLetterQueueId: dlq.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Unix() + 3600
+	for i := range 50 {
+		_, err = c.CreateMsg(ctx, &models.CreateMsgRequest{QueueId: queue.Id, Name: fmt.Sprint(i), Retry: 1, Timeout: 60, Deadline: deadline})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := c.ListMsgs(ctx, &models.ListMsgsRequest{QueueId: queue.Id, Page: 3, PageSize: 20})
+	if err != nil || list.Total != 50 || list.TotalPage != 3 || len(list.Items) != 10 {
+		t.Errorf("ListMsgs(page 3) = %+v, %v", list, err)
+	}
+
+	// Concurrent pulls lease every message exactly once, oldest first.
+	var mu sync.Mutex
+	leased := make(map[string]int)
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			msgs, err := c.GetMsg(ctx, &models.GetMsgRequest{QueueId: queue.Id, Limit: 7})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, msg := range *msgs {
+				leased[msg.ID]++
+			}
+		}()
+	}
+	wg.Wait()
+	if len(leased) != 50 {
+		t.Errorf("leased %d messages, want 50", len(leased))
+	}
+	for id, n := range leased {
+		if n != 1 {
+			t.Errorf("msg %s leased %d times", id, n)
+		}
+	}
+	if msgs, _ := c.GetMsg(ctx, &models.GetMsgRequest{QueueId: queue.Id, Limit: 10}); len(*msgs) != 0 {
+		t.Errorf("GetMsg() = %d leased messages", len(*msgs))
+	}
+
+	ids := make([]string, 0, len(leased))
+	for id := range leased {
+		ids = append(ids, id)
+	}
+	if err = c.AckMsg(ctx, &models.AckMsgRequest{QueueId: queue.Id, MsgId: ids[0]}); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.AckMsg(ctx, &models.AckMsgRequest{QueueId: queue.Id, MsgId: ids[0]}); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("AckMsg(acked) = %v, want ErrNotFound", err)
+	}
+	// Leased messages are listed until acked.
+	if list, err = c.ListMsgs(ctx, &models.ListMsgsRequest{QueueId: queue.Id, Page: 1, PageSize: 100}); err != nil || list.Total != 49 || len(list.Items) != 49 {
+		t.Errorf("ListMsgs() = %d of %d messages, %v, want 49", len(list.Items), list.Total, err)
+	}
+	if err = c.RenewMsg(ctx, &models.RenewMsgRequest{QueueId: queue.Id, MsgId: ids[1]}); err != nil {
+		t.Fatal(err)
+	}
+	// Nacked without retries left, the message goes to the dead-letter queue
+	// when pulled again.
+	if err = c.NackMsg(ctx, &models.NackMsgRequest{QueueId: queue.Id, MsgId: ids[1]}); err != nil {
+		t.Fatal(err)
+	}
+	if msgs, _ := c.GetMsg(ctx, &models.GetMsgRequest{QueueId: queue.Id, Limit: 10}); len(*msgs) != 0 {
+		t.Errorf("GetMsg() = %d messages, want the nacked one dead-lettered", len(*msgs))
+	}
+	dead, err := c.GetMsg(ctx, &models.GetMsgRequest{QueueId: dlq.Id, Limit: 10})
+	if err != nil || len(*dead) != 1 || (*dead)[0].ID != ids[1] || (*dead)[0].FailedReason != "retries exhausted" {
+		t.Errorf("GetMsg(dlq) = %+v, %v", dead, err)
+	}
+
+	if _, err = c.CreateQueue(ctx, &models.CreateQueueRequest{Name: "jobs"}); !errors.Is(err, errs.ErrAlreadyExists) {
+		t.Errorf("CreateQueue(duplicate) = %v, want ErrAlreadyExists", err)
+	}
+	if _, err = c.CreateMsg(ctx, &models.CreateMsgRequest{QueueId: queue.Id, Deadline: time.Now().Unix()}); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("CreateMsg(past deadline) = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestObject(t *testing.T) {
+	c := openTemp(t)
+	bucketId, err := c.CreateBucket(ctx, &models.CreateBucketRequest{Name: "files"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := c.PutObject(ctx, &models.PutObjectRequest{BucketId: bucketId, Filename: "dir/a.txt", Data: []byte("hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	emptyId, err := c.PutObject(ctx, &models.PutObjectRequest{BucketId: bucketId, Filename: "empty.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := c.GetObject(ctx, &models.ObjectRequest{BucketId: bucketId, ObjectId: emptyId}); err != nil || len(data) != 0 {
+		t.Errorf("GetObject(empty) = %q, %v", data, err)
+	}
+	if data, err := c.GetObject(ctx, &models.ObjectRequest{BucketId: bucketId, ObjectId: id}); err != nil || string(data) != "hello" {
+		t.Errorf("GetObject() = %q, %v", data, err)
+	}
+	r, err := c.GetObjectStream(ctx, &models.GetObjectStreamRequest{BucketId: bucketId, ObjectId: id, Offset: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(r); string(data) != "llo" {
+		t.Errorf("GetObjectStream(offset 2) = %q", data)
+	}
+	list, err := c.ListObjects(ctx, &models.ListObjectsRequest{BucketId: bucketId, Search: "A.TXT"})
+	if err != nil || list.Total != 1 || list.Objects[0].Filename != "a.txt" || list.Objects[0].FileType != "txt" {
+		t.Errorf("ListObjects() = %+v, %v", list, err)
+	}
+	if bucket, err := c.GetBucket(ctx, bucketId); err != nil || bucket.Size != 5 {
+		t.Errorf("GetBucket() = %+v, %v", bucket, err)
+	}
+	_, err = c.PutObjectStream(ctx, &models.PutObjectStreamRequest{BucketId: bucketId, Filename: "b", Reader: io.LimitReader(zeros{}, 3), Size: 4})
+	if !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("PutObjectStream(short) = %v, want ErrInvalidArgument", err)
+	}
+	if _, err = c.DeleteObject(ctx, &models.ObjectRequest{BucketId: bucketId, ObjectId: id}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.GetObject(ctx, &models.ObjectRequest{BucketId: bucketId, ObjectId: id}); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("GetObject(deleted) = %v, want ErrNotFound", err)
+	}
+}
+
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestVector(t *testing.T) {
+	c := openTemp(t)
+	resp, err := c.CreateCollections(ctx, &models.CreateCollectionRequest{Name: "docs", Metric: "euclidean"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	collId := resp.Coll.Id
+	out, err := c.CreateDocs(ctx, &models.CreateDocsRequest{CollId: collId, Docs: []models.Doc{
+		{ID: "a", Vector: []float64{0, 0}, Content: "origin"},
+		{ID: "b", Vector: []float64{1, 1}},
+		{ID: "c", Vector: []float64{1, 2, 3}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Output[2].Code == 0 {
+		t.Error("CreateDocs() accepted a vector of another dimension")
+	}
+	if coll, err := c.GetCollection(ctx, collId); err != nil || coll.Dimension != 2 || coll.Stats.Count != 2 {
+		t.Errorf("GetCollection() = %+v, %v", coll, err)
+	}
+	docs, err := c.QueryDocs(ctx, &models.QueryVectorRequest{CollId: collId, Vector: []float64{0.9, 0.9}, Topk: 1, IncludeContent: true})
+	if err != nil || len(docs) != 1 || docs[0].ID != "b" {
+		t.Errorf("QueryDocs() = %+v, %v", docs, err)
+	}
+	if _, err = c.UpsertDocs(ctx, &models.UpsertVectorDocsParam{CollId: collId, Docs: []models.Doc{{ID: "a", Vector: []float64{1, 1.1}}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.DelDocs(ctx, &models.DeleteDocsRequest{CollId: collId, Ids: []string{"b"}}); err != nil {
+		t.Fatal(err)
+	}
+	byIds, err := c.QueryDocsByIds(ctx, &models.QueryDocsByIdsRequest{CollId: collId, Ids: []string{"a", "b"}})
+	if err != nil || len(byIds) != 1 || byIds["a"].Vector[1] != 1.1 {
+		t.Errorf("QueryDocsByIds() = %+v, %v", byIds, err)
+	}
+	if err = c.DelCollection(ctx, collId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.GetCollection(ctx, collId); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("GetCollection(deleted) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	c := openTemp(t)
+	ns, err := c.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{Name: "compact"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired := newLocalValue(&models.SetValue{NamespaceId: ns, Key: "old", Value: "v"})
+	expired.ExpireAt = time.Now().Add(-time.Minute)
+	if err = c.updateKeys(ns, func(keys *bolt.Bucket) error { return putKey(keys, expired) }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.SetValue(ctx, &models.SetValue{NamespaceId: ns, Key: "new", Value: "v"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A message past its deadline, next to a pending one.
+	queue, err := c.CreateQueue(ctx, &models.CreateQueueRequest{Name: "compact"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgIds []string
+	for range 2 {
+		resp, err := c.CreateMsg(ctx, &models.CreateMsgRequest{QueueId: queue.Id, Deadline: time.Now().Unix() + 3600})
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgIds = append(msgIds, resp.MsgId)
+	}
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		q, err := openQueue(tx, queue.Id)
+		if err != nil {
+			return err
+		}
+		var msg models.MsgLocal
+		if err = getJSON(q.msgs, []byte(msgIds[0]), &msg); err != nil {
+			return err
+		}
+		msg.Deadline = time.Now().Unix() - 1
+		return putJSON(q.msgs, []byte(msg.ID), &msg)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An object whose content is gone.
+	bucketId, err := c.CreateBucket(ctx, &models.CreateBucketRequest{Name: "compact"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	objectId, err := c.PutObject(ctx, &models.PutObjectRequest{BucketId: bucketId, Filename: "a.txt", Data: []byte("data")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		data, err := resourceBucket(tx, objectsBucket, bucketId, objectsDataBucket)
+		if err != nil {
+			return err
+		}
+		return data.Delete([]byte(objectId))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := c.Compact(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.ExpiredKeys != 1 || stats.ExpiredMessages != 1 || stats.OrphanedFiles != 1 || stats.ReclaimedBytes <= 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if v, err := c.GetValue(ctx, ns, "new"); err != nil || v != "v" {
+		t.Errorf("GetValue(new) = %q, %v", v, err)
+	}
+	if v, _ := c.GetValue(ctx, "default", "INPUT"); v != "" {
+		t.Errorf("GetValue(INPUT) = %q", v)
+	}
+	msgs, err := c.GetMsg(ctx, &models.GetMsgRequest{QueueId: queue.Id, Limit: 10})
+	if err != nil || len(*msgs) != 1 || (*msgs)[0].ID != msgIds[1] {
+		t.Errorf("GetMsg() = %+v, %v", msgs, err)
+	}
+
+	// Nothing is left to compact.
+	if stats, err = c.Compact(ctx); err != nil || *stats != (models.CompactStats{}) {
+		t.Errorf("second Compact() = %+v, %v", stats, err)
+	}
+}
+
+func TestJanitor(t *testing.T) {
+	c := openTemp(t)
+	c.startJanitor(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		_ = c.Close()
+		_ = c.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() didn't stop the janitor")
+	}
+}
+
+func TestDefaultShared(t *testing.T) {
+	t.Chdir(t.TempDir())
+	a, err := Default("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Default("storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Fatal("Default() returned two clients of the same dir")
+	}
+	other, err := Default(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if other == a {
+		t.Fatal("Default() returned the client of another dir")
+	}
+	if err = a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Still open for the other user.
+	if _, err = b.GetNamespace(ctx, defaultId); err != nil {
+		t.Fatalf("GetNamespace() after the first Close() = %v", err)
+	}
+	if err = b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = b.GetNamespace(ctx, defaultId); !errors.Is(err, bolt.ErrDatabaseNotOpen) {
+		t.Errorf("GetNamespace() after the last Close() = %v, want ErrDatabaseNotOpen", err)
+	}
+
+	// Reopened on the next use.
+	c, err := Default("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err = c.GetNamespace(ctx, defaultId); err != nil {
+		t.Errorf("GetNamespace() after reopening = %v", err)
+	}
+}
@@ -0,0 +1,412 @@
+// Package storage_bolt is a local storage backend keeping all resources in a
+// single embedded bbolt database, storage/storage.db in the working directory.
+// Unlike the JSON files of storage_memory, it stays fast with hundreds of
+// thousands of keys, items or messages: every operation runs in one
+// transaction and reads only what it needs through the ordering of keys.
+//
+// Each category of resources is a top-level bucket, holding a sub-bucket per
+// resource:
+//
+//	datasets/<id>/{meta, items/<seq>}
+//	kv/<namespaceId>/{meta, keys/<key>}
+//	queues/<id>/{meta, msgs/<msgId>, ready/<readyAt><msgId>}
+//	buckets/<id>/{meta, objects/<objectId>, data/<objectId>}
+//	collections/<id>/{meta, docs/<docId>}
+//
+// names/<category>/<name> indexes the resources whose names are unique.
+//
+// The database is locked by the process that opens it: other processes wait
+// for it to be closed.
+package storage_bolt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/vector"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	dbFile = "storage.db"
+	// inputFile holds the input of the actor, loaded into the INPUT key of
+	// the default namespace when the database is opened. INPUT is read-only.
+	inputFile = "INPUT.json"
+
+	datasetsBucket    = "datasets"
+	kvBucket          = "kv"
+	queuesBucket      = "queues"
+	objectsBucket     = "buckets"
+	collectionsBucket = "collections"
+	namesBucket       = "names"
+
+	defaultId = "default"
+	inputKey  = "INPUT"
+
+	// openTimeout is how long Open waits for another process to close the
+	// database.
+	openTimeout = 10 * time.Second
+)
+
+var (
+	ErrResourceNotFound = fmt.Errorf("resource %w", errs.ErrNotFound)
+	ErrResourceExists   = fmt.Errorf("resource %w", errs.ErrAlreadyExists)
+
+	metaKey = []byte("meta")
+
+	// nestedBuckets lists the buckets of each resource, by category.
+	nestedBuckets = map[string][]string{
+		datasetsBucket:    {itemsBucket},
+		kvBucket:          {keysBucket},
+		queuesBucket:      {msgsBucket, readyBucket},
+		objectsBucket:     {objectsMetaBucket, objectsDataBucket},
+		collectionsBucket: {docsBucket},
+	}
+)
+
+type BoltClient struct {
+	db  *bolt.DB
+	dir string
+
+	// vectorMu guards indexes.
+	vectorMu sync.Mutex
+	// indexes caches the HNSW index of large vector collections by id.
+	indexes vector.Indexes
+
+	// The janitor compacts the storage until the client is closed.
+	closeOnce   sync.Once
+	stopJanitor chan struct{}
+	janitorDone chan struct{}
+
+	// refs counts the users of a shared client that haven't closed it yet.
+	// It is guarded by defaultMu.
+	refs int
+}
+
+var (
+	defaultMu sync.Mutex
+	// defaultClients holds the shared clients by directory.
+	defaultClients = map[string]*BoltClient{}
+)
+
+// Default returns the client of the database in dir, storage/ under the
+// working directory when empty, opening it on first use or after it was
+// closed. The client is shared by the callers of Default with the same dir:
+// the database is closed when every one of them has closed it.
+func Default(dir string) (*BoltClient, error) {
+	if dir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("get working directory failed: %v", err)
+		}
+		dir = filepath.Join(cwd, "storage")
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve storage dir failed: %v", err)
+	}
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if c := defaultClients[dir]; c != nil {
+		c.refs++
+		return c, nil
+	}
+	c, err := Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	c.startJanitor(janitorInterval)
+	c.refs = 1
+	defaultClients[dir] = c
+	return c, nil
+}
+
+// Open opens the database in dir, creating it along with the default
+// resources if needed.
+func Open(dir string) (*BoltClient, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("create storage dir failed: %v", err)
+	}
+	db, err := bolt.Open(filepath.Join(dir, dbFile), 0644, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("open %s failed: %v", filepath.Join(dir, dbFile), err)
+	}
+	c := &BoltClient{db: db, dir: dir}
+	if err = c.init(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// init creates the top-level buckets and the default resources, and loads
+// the input of the actor.
+func (c *BoltClient) init() error {
+	// The input stays where the JSON-file storage keeps it, so both backends
+	// run an actor with the same input.
+	input, err := os.ReadFile(filepath.Join(c.dir, "kv_stores", defaultId, inputFile))
+	if err != nil && !os.IsNotExist(err) {
+		log.Warnf("load %s failed: %v", inputFile, err)
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range []string{datasetsBucket, kvBucket, queuesBucket, objectsBucket, collectionsBucket, namesBucket} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		for _, category := range []string{datasetsBucket, kvBucket, queuesBucket, objectsBucket, collectionsBucket} {
+			if tx.Bucket([]byte(category)).Bucket([]byte(defaultId)) != nil {
+				continue
+			}
+			// Dataset names needn't be unique.
+			name := defaultId
+			if category == datasetsBucket {
+				name = ""
+			}
+			if _, err := createResource(tx, category, defaultId, name, defaultMeta(category)); err != nil {
+				return err
+			}
+		}
+		keys, err := resourceBucket(tx, kvBucket, defaultId, keysBucket)
+		if err != nil {
+			return err
+		}
+		return putJSON(keys, []byte(inputKey), &models.SetValueLocal{
+			SetValue: models.SetValue{NamespaceId: defaultId, Key: inputKey, Value: string(input)},
+			Size:     len(input),
+		})
+	})
+}
+
+// defaultMeta returns the metadata of the default resource of category.
+func defaultMeta(category string) any {
+	def := defaultId
+	now := time.Now()
+	switch category {
+	case datasetsBucket:
+		return &models.Dataset{
+			Id:        def,
+			Name:      def,
+			ActorId:   def,
+			RunId:     def,
+			CreatedAt: now.Format(time.RFC3339),
+			UpdatedAt: now.Format(time.RFC3339),
+		}
+	case kvBucket:
+		return &models.KvNamespaceItem{
+			Id:        def,
+			Name:      def,
+			ActorId:   def,
+			RunId:     def,
+			CreatedAt: now.Format(time.RFC3339),
+			UpdatedAt: now.Format(time.RFC3339),
+		}
+	case queuesBucket:
+		return &models.Queue{
+			Id:          def,
+			Name:        def,
+			TeamId:      def,
+			ActorId:     def,
+			RunId:       def,
+			Description: def,
+			CreatedAt:   now.Format(time.RFC3339),
+			UpdatedAt:   now.Format(time.RFC3339),
+		}
+	case objectsBucket:
+		return &models.Bucket{
+			Id:          def,
+			Name:        def,
+			Description: def,
+			ActorId:     def,
+			RunId:       def,
+			CreatedAt:   now.Format(time.RFC3339),
+			UpdatedAt:   now.Format(time.RFC3339),
+		}
+	}
+	return &models.Collection{
+		Id:        def,
+		Name:      def,
+		ActorId:   def,
+		RunId:     def,
+		Metric:    vector.MetricCosine,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Close closes the database, once the other users of a shared client have
+// closed it too.
+func (c *BoltClient) Close() error {
+	defaultMu.Lock()
+	if c.refs > 0 {
+		c.refs--
+		if c.refs > 0 {
+			defaultMu.Unlock()
+			return nil
+		}
+		if defaultClients[c.dir] == c {
+			delete(defaultClients, c.dir)
+		}
+	}
+	defaultMu.Unlock()
+	var err error
+	c.closeOnce.Do(func() {
+		if c.stopJanitor != nil {
+			close(c.stopJanitor)
+			<-c.janitorDone
+		}
+		err = c.db.Close()
+	})
+	return err
+}
+
+// resource returns the bucket of the resource id of category.
+func resource(tx *bolt.Tx, category string, id string) (*bolt.Bucket, error) {
+	if id == "" {
+		return nil, ErrResourceNotFound
+	}
+	b := tx.Bucket([]byte(category)).Bucket([]byte(id))
+	if b == nil {
+		return nil, ErrResourceNotFound
+	}
+	return b, nil
+}
+
+// resourceBucket returns the nested bucket name of the resource id of
+// category.
+func resourceBucket(tx *bolt.Tx, category string, id string, name string) (*bolt.Bucket, error) {
+	b, err := resource(tx, category, id)
+	if err != nil {
+		return nil, err
+	}
+	return b.Bucket([]byte(name)), nil
+}
+
+// createResource creates the bucket of the resource id of category, holding
+// meta and the nested buckets of its category. A non-empty name must not be
+// used by another resource of the category.
+func createResource(tx *bolt.Tx, category string, id string, name string, meta any) (*bolt.Bucket, error) {
+	if name != "" {
+		if err := indexName(tx, category, id, "", name); err != nil {
+			return nil, err
+		}
+	}
+	b, err := tx.Bucket([]byte(category)).CreateBucket([]byte(id))
+	if errors.Is(err, bolt.ErrBucketExists) {
+		return nil, ErrResourceExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, nested := range nestedBuckets[category] {
+		if _, err = b.CreateBucket([]byte(nested)); err != nil {
+			return nil, err
+		}
+	}
+	return b, putJSON(b, metaKey, meta)
+}
+
+// deleteResource deletes the resource id of category and its name, and
+// reports whether it existed.
+func deleteResource(tx *bolt.Tx, category string, id string, name string) (bool, error) {
+	if id == "" {
+		return false, nil
+	}
+	err := tx.Bucket([]byte(category)).DeleteBucket([]byte(id))
+	if errors.Is(err, bolt.ErrBucketNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if name != "" {
+		if err = indexName(tx, category, id, name, ""); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// indexName moves the resource id of category from oldName to newName in
+// the name index. An empty name is not indexed.
+func indexName(tx *bolt.Tx, category string, id string, oldName string, newName string) error {
+	names, err := tx.Bucket([]byte(namesBucket)).CreateBucketIfNotExists([]byte(category))
+	if err != nil {
+		return err
+	}
+	if newName != "" {
+		if owner := names.Get([]byte(newName)); owner != nil && string(owner) != id {
+			return fmt.Errorf("%s %s %w", category, newName, errs.ErrAlreadyExists)
+		}
+	}
+	if oldName != "" && string(names.Get([]byte(oldName))) == id {
+		if err = names.Delete([]byte(oldName)); err != nil {
+			return err
+		}
+	}
+	if newName == "" {
+		return nil
+	}
+	return names.Put([]byte(newName), []byte(id))
+}
+
+// eachResource calls fn with the id and the bucket of every resource of
+// category.
+func eachResource(tx *bolt.Tx, category string, fn func(id string, b *bolt.Bucket) error) error {
+	return tx.Bucket([]byte(category)).ForEachBucket(func(id []byte) error {
+		return fn(string(id), tx.Bucket([]byte(category)).Bucket(id))
+	})
+}
+
+// readMeta decodes the metadata of the resource id of category into meta.
+func readMeta(tx *bolt.Tx, category string, id string, meta any) (*bolt.Bucket, error) {
+	b, err := resource(tx, category, id)
+	if err != nil {
+		return nil, err
+	}
+	return b, getJSON(b, metaKey, meta)
+}
+
+func getJSON(b *bolt.Bucket, key []byte, v any) error {
+	data := b.Get(key)
+	if data == nil {
+		return ErrResourceNotFound
+	}
+	return unmarshal(data, v)
+}
+
+func unmarshal(data []byte, v any) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("json unmarshal failed: %s", err)
+	}
+	return nil
+}
+
+func putJSON(b *bolt.Bucket, key []byte, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("json marshal failed: %s", err)
+	}
+	return b.Put(key, data)
+}
+
+// pageBounds returns the bounds of page (from 1) of size items out of total.
+func pageBounds(page int64, size int64, total int64) (start int64, end int64) {
+	start = min(max(page-1, 0)*max(size, 0), total)
+	return start, min(start+max(size, 0), total)
+}
+
+func totalPage(total, pageSize int64) int64 {
+	if pageSize <= 0 {
+		return 0
+	}
+	return (total + pageSize - 1) / pageSize
+}
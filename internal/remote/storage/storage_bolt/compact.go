@@ -0,0 +1,192 @@
+package storage_bolt
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	bolt "go.etcd.io/bbolt"
+)
+
+// janitorInterval is how often the database is compacted in the background.
+const janitorInterval = 10 * time.Minute
+
+// startJanitor compacts the database every interval until Close.
+func (c *BoltClient) startJanitor(interval time.Duration) {
+	c.stopJanitor = make(chan struct{})
+	c.janitorDone = make(chan struct{})
+	go func() {
+		defer close(c.janitorDone)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stopJanitor:
+				return
+			case <-ticker.C:
+			}
+			stats, err := c.Compact(context.Background())
+			if err != nil {
+				log.Warnf("compact local storage failed: %v", err)
+				continue
+			}
+			if stats.ExpiredKeys+stats.ExpiredMessages+stats.OrphanedFiles > 0 {
+				log.Infof("compacted local storage: %d expired keys, %d expired messages, %d orphaned entries, %d bytes reclaimed",
+					stats.ExpiredKeys, stats.ExpiredMessages, stats.OrphanedFiles, stats.ReclaimedBytes)
+			}
+		}
+	}()
+}
+
+// Compact removes the expired keys, the messages past their deadline, and
+// the entries left without the data they describe: index entries of missing
+// messages, and object metadata or content without the other. Each category
+// is compacted in its own transaction. The pages freed are reused by later
+// writes; the database file doesn't shrink.
+func (c *BoltClient) Compact(ctx context.Context) (*models.CompactStats, error) {
+	stats := &models.CompactStats{}
+	for _, compact := range []func(*bolt.Tx, *models.CompactStats) error{
+		compactKeys,
+		compactQueues,
+		compactObjects,
+	} {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		// Counted in a copy, as a failed transaction removes nothing.
+		txStats := *stats
+		if err := c.db.Update(func(tx *bolt.Tx) error { return compact(tx, &txStats) }); err != nil {
+			return stats, err
+		}
+		*stats = txStats
+	}
+	return stats, nil
+}
+
+func compactKeys(tx *bolt.Tx, stats *models.CompactStats) error {
+	now := time.Now()
+	return eachResource(tx, kvBucket, func(namespaceId string, b *bolt.Bucket) error {
+		keys := b.Bucket([]byte(keysBucket))
+		var expired [][]byte
+		err := keys.ForEach(func(k, v []byte) error {
+			kv, err := decodeKey(v, now)
+			if err != nil {
+				log.Warnf("compact key %s of namespace %s: %v", k, namespaceId, err)
+				return nil
+			}
+			if kv == nil {
+				expired = append(expired, bytes.Clone(k))
+				stats.ReclaimedBytes += int64(len(k) + len(v))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		stats.ExpiredKeys += len(expired)
+		return deleteKeys(keys, expired)
+	})
+}
+
+func compactQueues(tx *bolt.Tx, stats *models.CompactStats) error {
+	var queueIds []string
+	err := eachResource(tx, queuesBucket, func(id string, b *bolt.Bucket) error {
+		queueIds = append(queueIds, id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, queueId := range queueIds {
+		q, err := openQueue(tx, queueId)
+		if err != nil {
+			continue
+		}
+		var dead []*models.MsgLocal
+		indexed := make(map[string]bool)
+		var dangling [][]byte
+		err = q.ready.ForEach(func(k, v []byte) error {
+			var msg models.MsgLocal
+			if getJSON(q.msgs, v, &msg) != nil || !bytes.Equal(readyKey(&msg), k) {
+				dangling = append(dangling, bytes.Clone(k))
+				stats.ReclaimedBytes += int64(len(k) + len(v))
+				return nil
+			}
+			indexed[msg.ID] = true
+			// Like GetMsg would, hand the message to the dead-letter queue.
+			if msg.Deadline < now.Unix() {
+				dead = append(dead, &msg)
+				if q.meta.DeadLetterQueueId == "" {
+					stats.ReclaimedBytes += int64(len(q.msgs.Get([]byte(msg.ID))))
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// Messages missing from the index would never be pulled.
+		var unindexed [][]byte
+		err = q.msgs.ForEach(func(k, v []byte) error {
+			if !indexed[string(k)] {
+				unindexed = append(unindexed, bytes.Clone(k))
+				stats.ReclaimedBytes += int64(len(k) + len(v))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		stats.OrphanedFiles += len(dangling) + len(unindexed)
+		if err = deleteKeys(q.ready, dangling); err != nil {
+			return err
+		}
+		if err = deleteKeys(q.msgs, unindexed); err != nil {
+			return err
+		}
+		for _, msg := range dead {
+			if err = q.deadLetter(tx, msg, "deadline exceeded"); err != nil {
+				return err
+			}
+			stats.ExpiredMessages++
+		}
+	}
+	return nil
+}
+
+func compactObjects(tx *bolt.Tx, stats *models.CompactStats) error {
+	return eachResource(tx, objectsBucket, func(bucketId string, b *bolt.Bucket) error {
+		objects := b.Bucket([]byte(objectsMetaBucket))
+		data := b.Bucket([]byte(objectsDataBucket))
+		for _, pair := range [][2]*bolt.Bucket{{objects, data}, {data, objects}} {
+			var orphaned [][]byte
+			err := pair[0].ForEach(func(k, v []byte) error {
+				if pair[1].Get(k) == nil {
+					orphaned = append(orphaned, bytes.Clone(k))
+					stats.ReclaimedBytes += int64(len(k) + len(v))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			stats.OrphanedFiles += len(orphaned)
+			if err = deleteKeys(pair[0], orphaned); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func deleteKeys(b *bolt.Bucket, keys [][]byte) error {
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package storage_bolt
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/query"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/schema"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Items are stored in the items bucket of their dataset, keyed by their
+// big-endian sequence number so that they read back in insertion order.
+
+const itemsBucket = "items"
+
+func (c *BoltClient) ListDatasets(ctx context.Context, req *models.ListDatasetsRequest) (*models.ListDatasetsResponse, error) {
+	var datasets []models.Dataset
+	err := c.db.View(func(tx *bolt.Tx) error {
+		return eachResource(tx, datasetsBucket, func(id string, b *bolt.Bucket) error {
+			var meta models.Dataset
+			if getJSON(b, metaKey, &meta) == nil {
+				datasets = append(datasets, models.Dataset{
+					Id:      id,
+					Name:    meta.Name,
+					ActorId: meta.ActorId,
+					RunId:   meta.RunId,
+					Fields:  meta.Fields,
+					Schema:  meta.Schema,
+				})
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(datasets, func(i, j int) bool {
+		if req.Desc {
+			return datasets[i].Name > datasets[j].Name
+		}
+		return datasets[i].Name < datasets[j].Name
+	})
+
+	total := int64(len(datasets))
+	start, end := pageBounds(req.Page, req.PageSize, total)
+	return &models.ListDatasetsResponse{
+		Items:     datasets[start:end],
+		Total:     total,
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+		TotalPage: totalPage(total, req.PageSize),
+	}, nil
+}
+
+func (c *BoltClient) CreateDataset(ctx context.Context, req *models.CreateDatasetRequest) (*models.Dataset, error) {
+	now := time.Now().Format(time.RFC3339Nano)
+	dataset := &models.Dataset{
+		Id:        uuid.NewString(),
+		Name:      req.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if req.ActorId != nil {
+		dataset.ActorId = *req.ActorId
+	}
+	if req.RunId != nil {
+		dataset.RunId = *req.RunId
+	}
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		_, err := createResource(tx, datasetsBucket, dataset.Id, "", dataset)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create dataset failed, cause: %v", err)
+	}
+	return dataset, nil
+}
+
+func (c *BoltClient) UpdateDataset(ctx context.Context, datasetID string, name string) (bool, error) {
+	err := c.updateDataset(datasetID, func(b *bolt.Bucket, meta *models.Dataset) error {
+		meta.Name = name
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *BoltClient) DelDataset(ctx context.Context, datasetID string) (bool, error) {
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		_, err := deleteResource(tx, datasetsBucket, datasetID, "")
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("delete dataset failed, cause: %v", err)
+	}
+	return true, nil
+}
+
+func (c *BoltClient) GetDataset(ctx context.Context, req *models.GetDataset) (*models.DatasetItem, error) {
+	var items []map[string]any
+	var total int
+	err := c.db.View(func(tx *bolt.Tx) error {
+		var meta models.Dataset
+		b, err := readMeta(tx, datasetsBucket, req.DatasetId, &meta)
+		if err != nil {
+			return err
+		}
+		total = int(meta.Stats.Count)
+		start, end := pageBounds(int64(req.Page), int64(req.PageSize), int64(total))
+		// Skip to the page through the cursor, without decoding the items
+		// before it.
+		cursor := b.Bucket([]byte(itemsBucket)).Cursor()
+		first, next := cursor.First, cursor.Next
+		if req.Desc {
+			first, next = cursor.Last, cursor.Prev
+		}
+		i := int64(0)
+		for k, v := first(); k != nil && i < end; k, v = next() {
+			if i++; i <= start {
+				continue
+			}
+			var item map[string]any
+			if err := unmarshal(v, &item); err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.DatasetItem{
+		Items:     items,
+		Total:     total,
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+		TotalPage: int(totalPage(int64(total), int64(req.PageSize))),
+	}, nil
+}
+
+// QueryDataset evaluates the query on every item of the dataset, the same
+// way the client evaluates it for backends without queries.
+func (c *BoltClient) QueryDataset(ctx context.Context, req *models.QueryDatasetRequest) (*models.DatasetItem, error) {
+	if err := query.Validate(req); err != nil {
+		return nil, err
+	}
+	var matches []map[string]any
+	err := c.db.View(func(tx *bolt.Tx) error {
+		b, err := resourceBucket(tx, datasetsBucket, req.DatasetId, itemsBucket)
+		if err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var item map[string]any
+			if err := unmarshal(v, &item); err != nil {
+				return err
+			}
+			if query.Match(item, req.Where) {
+				matches = append(matches, item)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.DatasetItem{
+		Items: query.Apply(matches, req),
+		Total: len(matches),
+	}, nil
+}
+
+// AddDatasetItem appends the items to the dataset in one transaction, after
+// validating them against its schema.
+func (c *BoltClient) AddDatasetItem(ctx context.Context, datasetId string, items []map[string]any) (bool, error) {
+	if datasetId == "" {
+		datasetId = defaultId
+	}
+	err := c.updateDataset(datasetId, func(b *bolt.Bucket, meta *models.Dataset) error {
+		if len(meta.Schema) > 0 {
+			v, err := schema.Compile(meta.Schema)
+			if err != nil {
+				return err
+			}
+			if err = v.ValidateItems(items); err != nil {
+				return err
+			}
+		}
+		known := make(map[string]bool, len(meta.Fields))
+		for _, field := range meta.Fields {
+			known[field] = true
+		}
+		itemsB := b.Bucket([]byte(itemsBucket))
+		for i, item := range items {
+			// New fields are appended in name order, keeping the order stable.
+			var newFields []string
+			for key := range item {
+				if !known[key] {
+					known[key] = true
+					newFields = append(newFields, key)
+				}
+			}
+			sort.Strings(newFields)
+			meta.Fields = append(meta.Fields, newFields...)
+
+			data, err := json.Marshal(item)
+			if err != nil {
+				return fmt.Errorf("json marshal failed at index %d: %v", i, err)
+			}
+			seq, err := itemsB.NextSequence()
+			if err != nil {
+				return err
+			}
+			if err = itemsB.Put(binary.BigEndian.AppendUint64(nil, seq), data); err != nil {
+				return err
+			}
+			meta.Stats.Size += uint64(len(data))
+		}
+		meta.Stats.Count += uint64(len(items))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetDatasetSchema stores the schema in the dataset metadata, or removes it
+// if schema is empty.
+func (c *BoltClient) SetDatasetSchema(ctx context.Context, datasetId string, schemaDoc []byte) error {
+	if len(schemaDoc) > 0 {
+		if _, err := schema.Compile(schemaDoc); err != nil {
+			return err
+		}
+	}
+	return c.updateDataset(datasetId, func(b *bolt.Bucket, meta *models.Dataset) error {
+		meta.Schema = json.RawMessage(schemaDoc)
+		return nil
+	})
+}
+
+// updateDataset runs fn in a transaction on the dataset and its metadata,
+// storing the metadata back when fn succeeds.
+func (c *BoltClient) updateDataset(datasetId string, fn func(b *bolt.Bucket, meta *models.Dataset) error) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		var meta models.Dataset
+		b, err := readMeta(tx, datasetsBucket, datasetId, &meta)
+		if err != nil {
+			return err
+		}
+		if err = fn(b, &meta); err != nil {
+			return err
+		}
+		meta.UpdatedAt = time.Now().Format(time.RFC3339Nano)
+		return putJSON(b, metaKey, &meta)
+	})
+}
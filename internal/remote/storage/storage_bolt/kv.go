@@ -0,0 +1,501 @@
+package storage_bolt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Keys are stored in the keys bucket of their namespace, in key order, as
+// models.SetValueLocal values.
+
+const keysBucket = "keys"
+
+const MaxExpireTime = 24 * 60 * 60 * 7
+
+func (c *BoltClient) GetNamespace(ctx context.Context, namespaceId string) (*models.KvNamespaceItem, error) {
+	var namespace models.KvNamespaceItem
+	err := c.db.View(func(tx *bolt.Tx) error {
+		b, err := readMeta(tx, kvBucket, namespaceId, &namespace)
+		if err != nil {
+			return err
+		}
+		namespace.Stats = keysStats(b.Bucket([]byte(keysBucket)), time.Now())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &namespace, nil
+}
+
+func (c *BoltClient) ListNamespaces(ctx context.Context, page int64, pageSize int64, desc bool) (*models.KvNamespace, error) {
+	var namespaces []models.KvNamespaceItem
+	var total int64
+	err := c.db.View(func(tx *bolt.Tx) error {
+		err := eachResource(tx, kvBucket, func(id string, b *bolt.Bucket) error {
+			var meta models.KvNamespaceItem
+			if getJSON(b, metaKey, &meta) == nil {
+				namespaces = append(namespaces, meta)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		sort.Slice(namespaces, func(i, j int) bool {
+			if desc {
+				return namespaces[i].CreatedAt > namespaces[j].CreatedAt
+			}
+			return namespaces[i].CreatedAt < namespaces[j].CreatedAt
+		})
+		total = int64(len(namespaces))
+		start, end := pageBounds(page, pageSize, total)
+		namespaces = namespaces[start:end]
+		now := time.Now()
+		for i := range namespaces {
+			keys, err := resourceBucket(tx, kvBucket, namespaces[i].Id, keysBucket)
+			if err != nil {
+				return err
+			}
+			namespaces[i].Stats = keysStats(keys, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.KvNamespace{
+		Items:     namespaces,
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+		TotalPage: totalPage(total, pageSize),
+	}, nil
+}
+
+func (c *BoltClient) CreateNamespace(ctx context.Context, req *models.CreateKvNamespaceRequest) (string, error) {
+	id := uuid.NewString()
+	now := time.Now().Format(time.RFC3339)
+	namespace := &models.KvNamespaceItem{
+		Id:        id,
+		Name:      req.Name,
+		ActorId:   req.ActorId,
+		RunId:     req.RunId,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		_, err := createResource(tx, kvBucket, id, req.Name, namespace)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (c *BoltClient) DelNamespace(ctx context.Context, namespaceId string) (bool, error) {
+	var ok bool
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		var namespace models.KvNamespaceItem
+		if _, err := readMeta(tx, kvBucket, namespaceId, &namespace); err != nil {
+			return nil
+		}
+		var err error
+		ok, err = deleteResource(tx, kvBucket, namespaceId, namespace.Name)
+		return err
+	})
+	return ok, err
+}
+
+func (c *BoltClient) RenameNamespace(ctx context.Context, namespaceId string, name string) (bool, error) {
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		var namespace models.KvNamespaceItem
+		b, err := readMeta(tx, kvBucket, namespaceId, &namespace)
+		if err != nil {
+			return err
+		}
+		if err = indexName(tx, kvBucket, namespaceId, namespace.Name, name); err != nil {
+			return err
+		}
+		namespace.Name = name
+		namespace.UpdatedAt = time.Now().Format(time.RFC3339)
+		return putJSON(b, metaKey, &namespace)
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *BoltClient) SetValue(ctx context.Context, req *models.SetValue) (bool, error) {
+	if isInput(req.NamespaceId, req.Key) {
+		return false, nil
+	}
+	err := c.updateKeys(req.NamespaceId, func(keys *bolt.Bucket) error {
+		return putKey(keys, newLocalValue(req))
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CompareAndSwap sets the key to req.Value if it holds req.OldValue.
+func (c *BoltClient) CompareAndSwap(ctx context.Context, req *models.CompareAndSwap) (bool, error) {
+	if isInput(req.NamespaceId, req.Key) {
+		return false, nil
+	}
+	var ok bool
+	err := c.updateKeys(req.NamespaceId, func(keys *bolt.Bucket) error {
+		kv, err := getKey(keys, req.Key, time.Now())
+		if err != nil || kv == nil || kv.Value != req.OldValue {
+			return err
+		}
+		ok = true
+		return putKey(keys, newLocalValue(&models.SetValue{
+			NamespaceId: req.NamespaceId,
+			Key:         req.Key,
+			Value:       req.Value,
+			Expiration:  req.Expiration,
+		}))
+	})
+	return ok && err == nil, err
+}
+
+// SetIfNotExists sets the key unless it holds a value that hasn't expired.
+func (c *BoltClient) SetIfNotExists(ctx context.Context, req *models.SetValue) (bool, error) {
+	if isInput(req.NamespaceId, req.Key) {
+		return false, nil
+	}
+	var ok bool
+	err := c.updateKeys(req.NamespaceId, func(keys *bolt.Bucket) error {
+		kv, err := getKey(keys, req.Key, time.Now())
+		if err != nil || kv != nil {
+			return err
+		}
+		ok = true
+		return putKey(keys, newLocalValue(req))
+	})
+	return ok && err == nil, err
+}
+
+// Increment adds delta to the integer value of the key, keeping its expiry,
+// and returns the new value. A missing key counts as 0.
+func (c *BoltClient) Increment(ctx context.Context, namespaceId string, key string, delta int64) (int64, error) {
+	if isInput(namespaceId, key) {
+		return 0, fmt.Errorf("%w: key %s is read-only", errs.ErrInvalidArgument, key)
+	}
+	var n int64
+	err := c.updateKeys(namespaceId, func(keys *bolt.Bucket) error {
+		kv, err := getKey(keys, key, time.Now())
+		if err != nil {
+			return err
+		}
+		if kv == nil {
+			local := newLocalValue(&models.SetValue{NamespaceId: namespaceId, Key: key})
+			kv = &local
+		} else if n, err = strconv.ParseInt(kv.Value, 10, 64); err != nil {
+			return fmt.Errorf("%w: value of key %s is not an integer", errs.ErrInvalidArgument, key)
+		}
+		n += delta
+		kv.Value = strconv.FormatInt(n, 10)
+		kv.Size = len(kv.Value)
+		return putKey(keys, *kv)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Touch resets the expiry of the key to expiration seconds from now. It
+// returns false if the key doesn't exist.
+func (c *BoltClient) Touch(ctx context.Context, namespaceId string, key string, expiration uint) (bool, error) {
+	if isInput(namespaceId, key) {
+		return false, nil
+	}
+	var ok bool
+	err := c.updateKeys(namespaceId, func(keys *bolt.Bucket) error {
+		kv, err := getKey(keys, key, time.Now())
+		if err != nil || kv == nil {
+			return err
+		}
+		ok = true
+		kv.Expiration = expiration
+		return putKey(keys, newLocalValue(&kv.SetValue))
+	})
+	return ok && err == nil, err
+}
+
+// GetWithMetadata returns the value of the key with its size and expiry.
+func (c *BoltClient) GetWithMetadata(ctx context.Context, namespaceId string, key string) (*models.KvValue, error) {
+	var value *models.KvValue
+	err := c.db.View(func(tx *bolt.Tx) error {
+		keys, err := resourceBucket(tx, kvBucket, namespaceId, keysBucket)
+		if err != nil {
+			return err
+		}
+		kv, err := getKey(keys, key, time.Now())
+		if err != nil {
+			return err
+		}
+		if kv == nil {
+			return fmt.Errorf("key %s %w", key, errs.ErrNotFound)
+		}
+		value = &models.KvValue{
+			Key:      kv.Key,
+			Value:    kv.Value,
+			Size:     kv.Size,
+			ExpireAt: kv.ExpireAt,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// GetValue returns the value of the key, or "" if it has expired.
+func (c *BoltClient) GetValue(ctx context.Context, namespaceId string, key string) (string, error) {
+	var value string
+	err := c.db.View(func(tx *bolt.Tx) error {
+		keys, err := resourceBucket(tx, kvBucket, namespaceId, keysBucket)
+		if err != nil {
+			return err
+		}
+		if keys.Get([]byte(key)) == nil {
+			return fmt.Errorf("key %s %w", key, errs.ErrNotFound)
+		}
+		kv, err := getKey(keys, key, time.Now())
+		if err != nil || kv == nil {
+			return err
+		}
+		value = kv.Value
+		return nil
+	})
+	return value, err
+}
+
+func (c *BoltClient) DelValue(ctx context.Context, namespaceId string, key string) (bool, error) {
+	return c.BulkDelValue(ctx, namespaceId, []string{key})
+}
+
+// BulkSetValue sets all the items in one transaction.
+func (c *BoltClient) BulkSetValue(ctx context.Context, req *models.BulkSet) (int64, error) {
+	var success int64
+	err := c.updateKeys(req.NamespaceId, func(keys *bolt.Bucket) error {
+		for _, item := range req.Items {
+			if isInput(req.NamespaceId, item.Key) {
+				continue
+			}
+			err := putKey(keys, newLocalValue(&models.SetValue{
+				NamespaceId: req.NamespaceId,
+				Key:         item.Key,
+				Value:       item.Value,
+				Expiration:  item.Expiration,
+			}))
+			if err != nil {
+				return err
+			}
+			success++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return success, nil
+}
+
+// BulkDelValue deletes all the keys in one transaction.
+func (c *BoltClient) BulkDelValue(ctx context.Context, namespaceId string, keys []string) (bool, error) {
+	err := c.updateKeys(namespaceId, func(b *bolt.Bucket) error {
+		for _, key := range keys {
+			if isInput(namespaceId, key) {
+				continue
+			}
+			if err := b.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *BoltClient) ListKeys(ctx context.Context, req *models.ListKeyInfo) (*models.KvKeys, error) {
+	var items []map[string]any
+	err := c.db.View(func(tx *bolt.Tx) error {
+		keys, err := resourceBucket(tx, kvBucket, req.NamespaceId, keysBucket)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		return keys.ForEach(func(k, v []byte) error {
+			kv, err := decodeKey(v, now)
+			if err != nil || kv == nil {
+				return err
+			}
+			items = append(items, map[string]any{
+				"key":  kv.Key,
+				"size": kv.Size,
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	total := int64(len(items))
+	start, end := pageBounds(req.Page, req.Size, total)
+	kvKeys := &models.KvKeys{
+		Total:     total,
+		Page:      req.Page,
+		PageSize:  req.Size,
+		TotalPage: totalPage(total, req.Size),
+	}
+	if start < end {
+		kvKeys.Items = items[start:end]
+	}
+	return kvKeys, nil
+}
+
+// ScanKeys returns the keys of the namespace starting with req.Prefix, in
+// ascending order, after req.After. It seeks to the first of them rather than
+// reading the whole namespace.
+func (c *BoltClient) ScanKeys(ctx context.Context, req *models.ScanKeysRequest) (*models.KvScan, error) {
+	scan := &models.KvScan{}
+	err := c.db.View(func(tx *bolt.Tx) error {
+		keys, err := resourceBucket(tx, kvBucket, req.NamespaceId, keysBucket)
+		if err != nil {
+			return err
+		}
+		prefix := []byte(req.Prefix)
+		seek := prefix
+		if req.After > req.Prefix {
+			seek = []byte(req.After)
+		}
+		now := time.Now()
+		cursor := keys.Cursor()
+		for k, v := cursor.Seek(seek); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+			if string(k) <= req.After {
+				continue
+			}
+			kv, err := decodeKey(v, now)
+			if err != nil {
+				return err
+			}
+			if kv == nil {
+				continue
+			}
+			if int64(len(scan.Items)) == req.Limit {
+				scan.More = true
+				break
+			}
+			scan.Items = append(scan.Items, map[string]any{
+				"key":  kv.Key,
+				"size": kv.Size,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scan, nil
+}
+
+// updateKeys runs fn in a transaction on the keys of the namespace.
+func (c *BoltClient) updateKeys(namespaceId string, fn func(keys *bolt.Bucket) error) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		keys, err := resourceBucket(tx, kvBucket, namespaceId, keysBucket)
+		if err != nil {
+			return err
+		}
+		return fn(keys)
+	})
+}
+
+// isInput reports whether the key is the input of the actor, which can't be
+// set.
+func isInput(namespaceId string, key string) bool {
+	return key == inputKey && namespaceId == defaultId
+}
+
+func newLocalValue(req *models.SetValue) models.SetValueLocal {
+	expiration := req.Expiration
+	if expiration == 0 {
+		expiration = MaxExpireTime
+	}
+	return models.SetValueLocal{
+		SetValue: models.SetValue{
+			Expiration:  expiration,
+			Key:         req.Key,
+			Value:       req.Value,
+			NamespaceId: req.NamespaceId,
+		},
+		ExpireAt: time.Now().Add(time.Duration(expiration) * time.Second),
+		Size:     len([]byte(req.Value)),
+	}
+}
+
+// getKey reads the key, or returns nil if it doesn't exist or has expired.
+func getKey(keys *bolt.Bucket, key string, now time.Time) (*models.SetValueLocal, error) {
+	if key == "" {
+		return nil, fmt.Errorf("%w: key is required", errs.ErrInvalidArgument)
+	}
+	data := keys.Get([]byte(key))
+	if data == nil {
+		return nil, nil
+	}
+	return decodeKey(data, now)
+}
+
+// decodeKey decodes a stored key, or returns nil if it has expired.
+func decodeKey(data []byte, now time.Time) (*models.SetValueLocal, error) {
+	var kv models.SetValueLocal
+	if err := unmarshal(data, &kv); err != nil {
+		return nil, err
+	}
+	if isInput(kv.NamespaceId, kv.Key) {
+		return &kv, nil
+	}
+	if kv.ExpireAt.Before(now) {
+		return nil, nil
+	}
+	return &kv, nil
+}
+
+func putKey(keys *bolt.Bucket, kv models.SetValueLocal) error {
+	if kv.Key == "" {
+		return fmt.Errorf("%w: key is required", errs.ErrInvalidArgument)
+	}
+	return putJSON(keys, []byte(kv.Key), &kv)
+}
+
+// keysStats counts the live keys and their size.
+func keysStats(keys *bolt.Bucket, now time.Time) models.Stats {
+	var stats models.Stats
+	_ = keys.ForEach(func(k, v []byte) error {
+		if kv, err := decodeKey(v, now); err == nil && kv != nil {
+			stats.Count++
+			stats.Size += uint64(kv.Size)
+		}
+		return nil
+	})
+	return stats
+}
@@ -0,0 +1,333 @@
+package storage_bolt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+	bolt "go.etcd.io/bbolt"
+)
+
+// The metadata of the objects of a bucket is stored in its objects bucket,
+// and their content in its data bucket, both by object id.
+
+const (
+	objectsMetaBucket = "objects"
+	objectsDataBucket = "data"
+)
+
+func (c *BoltClient) ListBuckets(ctx context.Context, page, size int) (*models.Object, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+	var buckets []models.Bucket
+	var total int64
+	err := c.db.View(func(tx *bolt.Tx) error {
+		err := eachResource(tx, objectsBucket, func(id string, b *bolt.Bucket) error {
+			var bucket models.Bucket
+			if getJSON(b, metaKey, &bucket) == nil {
+				buckets = append(buckets, bucket)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(buckets, func(i, j int) bool {
+			return buckets[i].CreatedAt < buckets[j].CreatedAt
+		})
+		total = int64(len(buckets))
+		start, end := pageBounds(int64(page), int64(size), total)
+		buckets = buckets[start:end]
+		for i := range buckets {
+			objects, err := resourceBucket(tx, objectsBucket, buckets[i].Id, objectsMetaBucket)
+			if err != nil {
+				return err
+			}
+			if buckets[i].Size, err = objectsSize(objects); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.Object{
+		Buckets:   buckets,
+		Total:     total,
+		TotalPage: totalPage(total, int64(size)),
+		Page:      int64(page),
+		PageSize:  int64(size),
+	}, nil
+}
+
+func (c *BoltClient) CreateBucket(ctx context.Context, req *models.CreateBucketRequest) (string, error) {
+	id := uuid.NewString()
+	now := time.Now().Format(time.RFC3339)
+	bucket := &models.Bucket{
+		Id:          id,
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		ActorId:     req.ActorId,
+		RunId:       req.RunId,
+	}
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		_, err := createResource(tx, objectsBucket, id, req.Name, bucket)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (c *BoltClient) DeleteBucket(ctx context.Context, bucketId string) (bool, error) {
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		var bucket models.Bucket
+		if _, err := readMeta(tx, objectsBucket, bucketId, &bucket); err != nil {
+			return err
+		}
+		_, err := deleteResource(tx, objectsBucket, bucketId, bucket.Name)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *BoltClient) GetBucket(ctx context.Context, bucketId string) (*models.Bucket, error) {
+	var bucket models.Bucket
+	err := c.db.View(func(tx *bolt.Tx) error {
+		b, err := readMeta(tx, objectsBucket, bucketId, &bucket)
+		if err != nil {
+			return err
+		}
+		bucket.Size, err = objectsSize(b.Bucket([]byte(objectsMetaBucket)))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &bucket, nil
+}
+
+// ListObjects lists the objects of a bucket whose filename contains req.Search,
+// ignoring case.
+func (c *BoltClient) ListObjects(ctx context.Context, req *models.ListObjectsRequest) (*models.ObjectList, error) {
+	page, pageSize := req.Page, req.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	search := strings.ToLower(req.Search)
+	matched := make([]models.BucketObject, 0)
+	err := c.db.View(func(tx *bolt.Tx) error {
+		objects, err := resourceBucket(tx, objectsBucket, req.BucketId, objectsMetaBucket)
+		if err != nil {
+			return err
+		}
+		return objects.ForEach(func(k, v []byte) error {
+			var object models.BucketObject
+			if err := unmarshal(v, &object); err != nil {
+				return err
+			}
+			if strings.Contains(strings.ToLower(object.Filename), search) {
+				matched = append(matched, object)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].CreatedAt < matched[j].CreatedAt
+	})
+
+	total := int64(len(matched))
+	start, end := pageBounds(page, pageSize, total)
+	return &models.ObjectList{
+		Objects:   matched[start:end],
+		Total:     total,
+		TotalPage: totalPage(total, pageSize),
+		Page:      page,
+		PageSize:  pageSize,
+	}, nil
+}
+
+func (c *BoltClient) GetObject(ctx context.Context, req *models.ObjectRequest) ([]byte, error) {
+	var data []byte
+	err := c.db.View(func(tx *bolt.Tx) error {
+		content, err := objectData(tx, req.BucketId, req.ObjectId)
+		if err != nil {
+			return err
+		}
+		// The content is only valid during the transaction.
+		data = bytes.Clone(content)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (c *BoltClient) DeleteObject(ctx context.Context, req *models.ObjectRequest) (bool, error) {
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		b, err := resource(tx, objectsBucket, req.BucketId)
+		if err != nil {
+			return err
+		}
+		objects := b.Bucket([]byte(objectsMetaBucket))
+		if req.ObjectId == "" || objects.Get([]byte(req.ObjectId)) == nil {
+			return ErrResourceNotFound
+		}
+		if err = objects.Delete([]byte(req.ObjectId)); err != nil {
+			return err
+		}
+		return b.Bucket([]byte(objectsDataBucket)).Delete([]byte(req.ObjectId))
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *BoltClient) PutObject(ctx context.Context, req *models.PutObjectRequest) (string, error) {
+	return c.PutObjectStream(ctx, &models.PutObjectStreamRequest{
+		BucketId: req.BucketId,
+		Filename: req.Filename,
+		Reader:   bytes.NewReader(req.Data),
+		Size:     int64(len(req.Data)),
+		ActorId:  req.ActorId,
+		RunId:    req.RunId,
+	})
+}
+
+// PutObjectStream reads req.Reader into the object. When req.Size is known,
+// an object of another size is rejected. The content is read before the
+// transaction starts, so slow readers don't hold up the other writes.
+func (c *BoltClient) PutObjectStream(ctx context.Context, req *models.PutObjectStreamRequest) (string, error) {
+	filename := filepath.Base(req.Filename)
+	if filename == "." || filename == string(filepath.Separator) {
+		return "", fmt.Errorf("%w: filename is required", errs.ErrInvalidArgument)
+	}
+	if _, err := c.GetBucket(ctx, req.BucketId); err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(&ctxReader{ctx: ctx, r: req.Reader})
+	if err != nil {
+		return "", fmt.Errorf("read object failed: %w", err)
+	}
+	if req.Size >= 0 && int64(len(data)) != req.Size {
+		return "", fmt.Errorf("%w: read %d bytes, expected %d", errs.ErrInvalidArgument, len(data), req.Size)
+	}
+
+	id := uuid.NewString()
+	now := time.Now().Format(time.RFC3339)
+	object := &models.BucketObject{
+		Id:        id,
+		Path:      filepath.Join(objectsBucket, req.BucketId, id, filename),
+		Size:      len(data),
+		Filename:  filename,
+		BucketId:  req.BucketId,
+		ActorId:   req.ActorId,
+		RunId:     req.RunId,
+		FileType:  strings.TrimPrefix(filepath.Ext(filename), "."),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		b, err := resource(tx, objectsBucket, req.BucketId)
+		if err != nil {
+			return err
+		}
+		if err = b.Bucket([]byte(objectsDataBucket)).Put([]byte(id), data); err != nil {
+			return err
+		}
+		return putJSON(b.Bucket([]byte(objectsMetaBucket)), []byte(id), object)
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// GetObjectStream returns a reader of the object content from req.Offset.
+func (c *BoltClient) GetObjectStream(ctx context.Context, req *models.GetObjectStreamRequest) (io.ReadCloser, error) {
+	if req.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", errs.ErrInvalidArgument)
+	}
+	var data []byte
+	err := c.db.View(func(tx *bolt.Tx) error {
+		content, err := objectData(tx, req.BucketId, req.ObjectId)
+		if err != nil {
+			return err
+		}
+		data = bytes.Clone(content[min(req.Offset, int64(len(content))):])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// objectData returns the content of an object, valid during tx.
+func objectData(tx *bolt.Tx, bucketId, objectId string) ([]byte, error) {
+	b, err := resource(tx, objectsBucket, bucketId)
+	if err != nil {
+		return nil, err
+	}
+	if objectId == "" || b.Bucket([]byte(objectsMetaBucket)).Get([]byte(objectId)) == nil {
+		return nil, ErrResourceNotFound
+	}
+	data := b.Bucket([]byte(objectsDataBucket)).Get([]byte(objectId))
+	if data == nil {
+		return nil, fmt.Errorf("content of object %s %w", objectId, errs.ErrNotFound)
+	}
+	return data, nil
+}
+
+func objectsSize(objects *bolt.Bucket) (int, error) {
+	size := 0
+	err := objects.ForEach(func(k, v []byte) error {
+		var object models.BucketObject
+		if err := unmarshal(v, &object); err != nil {
+			return err
+		}
+		size += object.Size
+		return nil
+	})
+	return size, err
+}
+
+// ctxReader stops reading from r once ctx is done.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,396 @@
+package storage_bolt
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Messages are stored in the msgs bucket of their queue by id. The ready
+// bucket indexes them by the time they can be pulled, the big-endian unix
+// nanoseconds followed by the id, so GetMsg only reads the messages due.
+
+const (
+	msgsBucket  = "msgs"
+	readyBucket = "ready"
+)
+
+func (c *BoltClient) CreateQueue(ctx context.Context, req *models.CreateQueueRequest) (*models.CreateQueueResponse, error) {
+	id := uuid.NewString()
+	now := time.Now().Format(time.RFC3339Nano)
+	queue := &models.Queue{
+		Id:          id,
+		Name:        req.Name,
+		ActorId:     req.ActorId,
+		RunId:       req.RunId,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+
+		DeadLetterQueueId: req.DeadLetterQueueId,
+	}
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		if req.DeadLetterQueueId != "" {
+			if _, err := resource(tx, queuesBucket, req.DeadLetterQueueId); err != nil {
+				return fmt.Errorf("%w: dead-letter queue %s not found", errs.ErrInvalidArgument, req.DeadLetterQueueId)
+			}
+		}
+		_, err := createResource(tx, queuesBucket, id, req.Name, queue)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.CreateQueueResponse{Id: id}, nil
+}
+
+func (c *BoltClient) GetQueue(ctx context.Context, req *models.GetQueueRequest) (*models.GetQueueResponse, error) {
+	var queue models.Queue
+	err := c.db.View(func(tx *bolt.Tx) error {
+		_, err := readMeta(tx, queuesBucket, req.Id, &queue)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.GetQueueResponse{Queue: queue}, nil
+}
+
+func (c *BoltClient) GetQueues(ctx context.Context, req *models.GetQueuesRequest) (*models.ListQueuesResponse, error) {
+	var queues []*models.Queue
+	err := c.db.View(func(tx *bolt.Tx) error {
+		return eachResource(tx, queuesBucket, func(id string, b *bolt.Bucket) error {
+			var meta models.Queue
+			if getJSON(b, metaKey, &meta) == nil {
+				queues = append(queues, &meta)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(queues, func(i, j int) bool {
+		if req.Desc {
+			return queues[i].CreatedAt > queues[j].CreatedAt
+		}
+		return queues[i].CreatedAt < queues[j].CreatedAt
+	})
+
+	total := int64(len(queues))
+	start, end := pageBounds(req.Page, req.PageSize, total)
+	return &models.ListQueuesResponse{
+		Items:     queues[start:end],
+		Total:     total,
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+		TotalPage: totalPage(total, req.PageSize),
+	}, nil
+}
+
+func (c *BoltClient) UpdateQueue(ctx context.Context, req *models.UpdateQueueRequest) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		var queue models.Queue
+		b, err := readMeta(tx, queuesBucket, req.QueueId, &queue)
+		if errors.Is(err, ErrResourceNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = indexName(tx, queuesBucket, req.QueueId, queue.Name, req.Name); err != nil {
+			return err
+		}
+		queue.Name = req.Name
+		queue.Description = req.Description
+		queue.UpdatedAt = time.Now().Format(time.RFC3339Nano)
+		return putJSON(b, metaKey, &queue)
+	})
+}
+
+func (c *BoltClient) DelQueue(ctx context.Context, req *models.DelQueueRequest) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		var queue models.Queue
+		if _, err := readMeta(tx, queuesBucket, req.QueueId, &queue); err != nil {
+			return nil
+		}
+		_, err := deleteResource(tx, queuesBucket, req.QueueId, queue.Name)
+		return err
+	})
+}
+
+func (c *BoltClient) CreateMsg(ctx context.Context, req *models.CreateMsgRequest) (*models.CreateMsgResponse, error) {
+	if req.Deadline < time.Now().Unix()+300 {
+		return nil, fmt.Errorf("%w: deadline must after now + 300s", errs.ErrInvalidArgument)
+	}
+	msg := &models.MsgLocal{
+		Msg: models.Msg{
+			ID:       uuid.NewString(),
+			QueueID:  req.QueueId,
+			Name:     req.Name,
+			Payload:  req.PayLoad,
+			Deadline: req.Deadline,
+			Retry:    req.Retry,
+			Timeout:  req.Timeout,
+		},
+		UpdateTime: time.Now(),
+	}
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		q, err := openQueue(tx, req.QueueId)
+		if err != nil {
+			return err
+		}
+		return q.put(msg, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.CreateMsgResponse{MsgId: msg.ID}, nil
+}
+
+// GetMsg leases up to req.Limit messages due, oldest first. Being a single
+// transaction, concurrent pulls never lease the same message.
+func (c *BoltClient) GetMsg(ctx context.Context, req *models.GetMsgRequest) (*models.GetMsgResponse, error) {
+	resp := make(models.GetMsgResponse, 0)
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		q, err := openQueue(tx, req.QueueId)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		// The index can't be changed while iterated: collect the messages
+		// due first.
+		var due, dead []*models.MsgLocal
+		cursor := q.ready.Cursor()
+		for k, v := cursor.First(); k != nil && len(due) < int(req.Limit); k, v = cursor.Next() {
+			if int64(binary.BigEndian.Uint64(k)) > now.UnixNano() {
+				break
+			}
+			var msg models.MsgLocal
+			if err := getJSON(q.msgs, v, &msg); err != nil {
+				return fmt.Errorf("read msg %s: %w", v, err)
+			}
+			// msg failed for good; a msg is always delivered at least once
+			if msg.Deadline < now.Unix() || (msg.Retried > 0 && msg.Retried >= msg.Retry) {
+				dead = append(dead, &msg)
+				continue
+			}
+			due = append(due, &msg)
+		}
+		for _, msg := range dead {
+			reason := "retries exhausted"
+			if msg.Deadline < now.Unix() {
+				reason = "deadline exceeded"
+			}
+			if err := q.deadLetter(tx, msg, reason); err != nil {
+				return err
+			}
+		}
+		for _, msg := range due {
+			old := readyKey(msg)
+			msg.ReenterTime = now.Add(time.Duration(msg.Timeout) * time.Second)
+			msg.Retried++
+			if err := q.put(msg, old); err != nil {
+				return err
+			}
+			resp = append(resp, &models.Msg{
+				ID:        msg.ID,
+				QueueID:   msg.QueueID,
+				Name:      msg.Name,
+				Payload:   msg.Payload,
+				Timeout:   msg.Timeout,
+				Deadline:  msg.Deadline,
+				Retry:     msg.Retry,
+				Retried:   msg.Retried,
+				SuccessAt: msg.SuccessAt,
+				FailedAt:  msg.FailedAt,
+				Desc:      msg.Desc,
+
+				FailedReason:  msg.FailedReason,
+				SourceQueueId: msg.SourceQueueId,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *BoltClient) AckMsg(ctx context.Context, req *models.AckMsgRequest) error {
+	return c.updateLeased(req.QueueId, req.MsgId, "ack", func(q *queueTx, msg *models.MsgLocal) error {
+		return q.delete(msg)
+	})
+}
+
+// NackMsg returns a pulled message to the queue, to be pulled again after
+// req.Delay seconds. A message without retries left is dead-lettered when
+// pulled again.
+func (c *BoltClient) NackMsg(ctx context.Context, req *models.NackMsgRequest) error {
+	if req.Delay < 0 {
+		return fmt.Errorf("%w: delay must not be negative", errs.ErrInvalidArgument)
+	}
+	return c.updateLeased(req.QueueId, req.MsgId, "nack", func(q *queueTx, msg *models.MsgLocal) error {
+		old := readyKey(msg)
+		now := time.Now()
+		msg.ReenterTime = now.Add(time.Duration(req.Delay) * time.Second)
+		msg.UpdateTime = now
+		return q.put(msg, old)
+	})
+}
+
+// RenewMsg extends the lease of a pulled message by its timeout.
+func (c *BoltClient) RenewMsg(ctx context.Context, req *models.RenewMsgRequest) error {
+	return c.updateLeased(req.QueueId, req.MsgId, "renew", func(q *queueTx, msg *models.MsgLocal) error {
+		old := readyKey(msg)
+		msg.ReenterTime = time.Now().Add(time.Duration(msg.Timeout) * time.Second)
+		return q.put(msg, old)
+	})
+}
+
+// ListMsgs lists the messages of the queue not acked yet, leased or not,
+// through the ready index.
+func (c *BoltClient) ListMsgs(ctx context.Context, req *models.ListMsgsRequest) (*models.ListMsgsResponse, error) {
+	items := make([]*models.Msg, 0)
+	var total int64
+	err := c.db.View(func(tx *bolt.Tx) error {
+		q, err := openQueue(tx, req.QueueId)
+		if err != nil {
+			return err
+		}
+		total = int64(q.ready.Stats().KeyN)
+		start, end := pageBounds(req.Page, req.PageSize, total)
+		cursor := q.ready.Cursor()
+		i := int64(0)
+		for k, v := cursor.First(); k != nil && i < end; k, v = cursor.Next() {
+			if i++; i <= start {
+				continue
+			}
+			var msg models.MsgLocal
+			if err := getJSON(q.msgs, v, &msg); err != nil {
+				return fmt.Errorf("read msg %s: %w", v, err)
+			}
+			items = append(items, &msg.Msg)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListMsgsResponse{
+		Items:     items,
+		Total:     total,
+		TotalPage: totalPage(total, req.PageSize),
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+	}, nil
+}
+
+// updateLeased runs fn in a transaction on a message whose lease is still
+// running. op names the operation in errors.
+func (c *BoltClient) updateLeased(queueId string, msgId string, op string, fn func(q *queueTx, msg *models.MsgLocal) error) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		q, err := openQueue(tx, queueId)
+		if err != nil {
+			return err
+		}
+		var msg models.MsgLocal
+		if err = getJSON(q.msgs, []byte(msgId), &msg); err != nil {
+			return err
+		}
+		if msg.ReenterTime.Equal(time.Time{}) {
+			return ErrResourceNotFound
+		}
+		if msg.ReenterTime.Before(time.Now()) {
+			return fmt.Errorf("msg is timeout, you must %s within the timeout period", op)
+		}
+		return fn(q, &msg)
+	})
+}
+
+// queueTx is a queue open in a transaction.
+type queueTx struct {
+	meta  models.Queue
+	msgs  *bolt.Bucket
+	ready *bolt.Bucket
+}
+
+func openQueue(tx *bolt.Tx, queueId string) (*queueTx, error) {
+	q := &queueTx{}
+	b, err := readMeta(tx, queuesBucket, queueId, &q.meta)
+	if err != nil {
+		return nil, err
+	}
+	q.msgs = b.Bucket([]byte(msgsBucket))
+	q.ready = b.Bucket([]byte(readyBucket))
+	return q, nil
+}
+
+// put stores msg and indexes it by the time it is due, replacing its index
+// entry old if any.
+func (q *queueTx) put(msg *models.MsgLocal, old []byte) error {
+	if old != nil {
+		if err := q.ready.Delete(old); err != nil {
+			return err
+		}
+	}
+	if err := putJSON(q.msgs, []byte(msg.ID), msg); err != nil {
+		return err
+	}
+	return q.ready.Put(readyKey(msg), []byte(msg.ID))
+}
+
+func (q *queueTx) delete(msg *models.MsgLocal) error {
+	if err := q.ready.Delete(readyKey(msg)); err != nil {
+		return err
+	}
+	return q.msgs.Delete([]byte(msg.ID))
+}
+
+// deadLetter moves the failed msg to the dead-letter queue of q, or deletes
+// it when q has none.
+func (q *queueTx) deadLetter(tx *bolt.Tx, msg *models.MsgLocal, reason string) error {
+	if err := q.delete(msg); err != nil {
+		return err
+	}
+	dlq, err := openQueue(tx, q.meta.DeadLetterQueueId)
+	if err != nil {
+		return nil
+	}
+	now := time.Now()
+	return dlq.put(&models.MsgLocal{
+		Msg: models.Msg{
+			ID:            msg.ID,
+			QueueID:       q.meta.DeadLetterQueueId,
+			Name:          msg.Name,
+			Payload:       msg.Payload,
+			Timeout:       msg.Timeout,
+			Deadline:      max(msg.Deadline, now.Unix()+86400),
+			Retry:         msg.Retry,
+			Desc:          msg.Desc,
+			FailedReason:  reason,
+			SourceQueueId: msg.QueueID,
+		},
+		UpdateTime: now,
+	}, nil)
+}
+
+// readyKey returns the key indexing msg in the ready bucket: pending
+// messages are due from their last update, leased ones when their lease ends.
+func readyKey(msg *models.MsgLocal) []byte {
+	due := msg.UpdateTime
+	if !msg.ReenterTime.Equal(time.Time{}) {
+		due = msg.ReenterTime
+	}
+	key := binary.BigEndian.AppendUint64(nil, uint64(max(due.UnixNano(), 0)))
+	return append(key, msg.ID...)
+}
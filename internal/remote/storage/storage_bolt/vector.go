@@ -0,0 +1,354 @@
+package storage_bolt
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/vector"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+	bolt "go.etcd.io/bbolt"
+)
+
+// The documents of a collection are stored in its docs bucket by id. They are
+// searched with the vector package.
+
+const docsBucket = "docs"
+
+func (c *BoltClient) ListCollections(ctx context.Context, req *models.ListCollectionsRequest) (*models.ListCollectionsResponse, error) {
+	page, pageSize := req.Page, req.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	var collections []models.Collection
+	var total int64
+	err := c.db.View(func(tx *bolt.Tx) error {
+		err := eachResource(tx, collectionsBucket, func(id string, b *bolt.Bucket) error {
+			var coll models.Collection
+			if getJSON(b, metaKey, &coll) == nil {
+				collections = append(collections, coll)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(collections, func(i, j int) bool {
+			if req.Desc {
+				return collections[i].CreatedAt.After(collections[j].CreatedAt)
+			}
+			return collections[i].CreatedAt.Before(collections[j].CreatedAt)
+		})
+		total = int64(len(collections))
+		start, end := pageBounds(page, pageSize, total)
+		collections = collections[start:end]
+		for i := range collections {
+			docs, err := resourceBucket(tx, collectionsBucket, collections[i].Id, docsBucket)
+			if err != nil {
+				return err
+			}
+			collections[i].Stats = docsStats(docs)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListCollectionsResponse{
+		Items:     collections,
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+		TotalPage: totalPage(total, pageSize),
+	}, nil
+}
+
+func (c *BoltClient) CreateCollections(ctx context.Context, req *models.CreateCollectionRequest) (*models.CreateCollectionResponse, error) {
+	metric, err := vector.NormalizeMetric(req.Metric)
+	if err != nil {
+		return nil, err
+	}
+	if req.Dimension < 0 {
+		return nil, fmt.Errorf("%w: dimension must not be negative", errs.ErrInvalidArgument)
+	}
+	now := time.Now()
+	coll := models.Collection{
+		Id:          uuid.NewString(),
+		Name:        req.Name,
+		ActorId:     req.ActorId,
+		RunId:       req.RunId,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Dimension:   uint32(req.Dimension),
+		Metric:      metric,
+	}
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		_, err := createResource(tx, collectionsBucket, coll.Id, req.Name, &coll)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.CreateCollectionResponse{Coll: coll}, nil
+}
+
+func (c *BoltClient) UpdateCollection(ctx context.Context, req *models.UpdateCollectionRequest) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		var coll models.Collection
+		b, err := readMeta(tx, collectionsBucket, req.CollId, &coll)
+		if err != nil {
+			return err
+		}
+		if req.Name != "" {
+			if err = indexName(tx, collectionsBucket, req.CollId, coll.Name, req.Name); err != nil {
+				return err
+			}
+			coll.Name = req.Name
+		}
+		coll.Description = req.Description
+		coll.UpdatedAt = time.Now()
+		return putJSON(b, metaKey, &coll)
+	})
+}
+
+func (c *BoltClient) DelCollection(ctx context.Context, collId string) error {
+	c.vectorMu.Lock()
+	defer c.vectorMu.Unlock()
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		var coll models.Collection
+		if _, err := readMeta(tx, collectionsBucket, collId, &coll); err != nil {
+			return err
+		}
+		_, err := deleteResource(tx, collectionsBucket, collId, coll.Name)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	delete(c.indexes, collId)
+	return nil
+}
+
+func (c *BoltClient) GetCollection(ctx context.Context, collId string) (*models.Collection, error) {
+	var coll models.Collection
+	err := c.db.View(func(tx *bolt.Tx) error {
+		b, err := readMeta(tx, collectionsBucket, collId, &coll)
+		if err != nil {
+			return err
+		}
+		coll.Stats = docsStats(b.Bucket([]byte(docsBucket)))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &coll, nil
+}
+
+func (c *BoltClient) CreateDocs(ctx context.Context, req *models.CreateDocsRequest) (*models.DocOpResponse, error) {
+	return c.writeDocs(req.CollId, "insert", req.Docs)
+}
+
+func (c *BoltClient) UpdateDocs(ctx context.Context, req *models.UpdateDocsRequest) (*models.DocOpResponse, error) {
+	return c.writeDocs(req.CollId, "update", req.Docs)
+}
+
+func (c *BoltClient) UpsertDocs(ctx context.Context, req *models.UpsertVectorDocsParam) (*models.DocOpResponse, error) {
+	return c.writeDocs(req.CollId, "upsert", req.Docs)
+}
+
+func (c *BoltClient) DelDocs(ctx context.Context, req *models.DeleteDocsRequest) (*models.DocOpResponse, error) {
+	c.vectorMu.Lock()
+	defer c.vectorMu.Unlock()
+	resp := &models.DocOpResponse{Output: make([]models.DocOpResult, 0, len(req.Ids))}
+	var removed []string
+	var before, after string
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		docs, err := resourceBucket(tx, collectionsBucket, req.CollId, docsBucket)
+		if err != nil {
+			return err
+		}
+		if before, after, err = bumpVersion(docs); err != nil {
+			return err
+		}
+		for _, id := range req.Ids {
+			if id == "" || docs.Get([]byte(id)) == nil {
+				resp.Output = append(resp.Output, vector.OpFailed("delete", id, "doc not found"))
+				continue
+			}
+			if err = docs.Delete([]byte(id)); err != nil {
+				return err
+			}
+			removed = append(removed, id)
+			resp.Output = append(resp.Output, vector.OpSucceeded("delete", id))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.indexes.Written(req.CollId, before, after, nil, removed)
+	return resp, nil
+}
+
+func (c *BoltClient) QueryDocs(ctx context.Context, req *models.QueryVectorRequest) ([]*models.Doc, error) {
+	c.vectorMu.Lock()
+	defer c.vectorMu.Unlock()
+	var coll models.Collection
+	var docs map[string]models.Doc
+	var version string
+	err := c.db.View(func(tx *bolt.Tx) error {
+		b, err := readMeta(tx, collectionsBucket, req.CollId, &coll)
+		if err != nil {
+			return err
+		}
+		if err = vector.CheckQuery(&coll, req); err != nil {
+			return err
+		}
+		bucket := b.Bucket([]byte(docsBucket))
+		version = docsVersion(bucket)
+		docs, err = readDocs(bucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]models.Doc, 0, len(docs))
+	if index := c.indexes.For(&coll, docs, version, req); index != nil {
+		for _, id := range index.Search(req.Vector, vector.Topk(req)) {
+			candidates = append(candidates, docs[id])
+		}
+	} else {
+		for _, doc := range docs {
+			candidates = append(candidates, doc)
+		}
+	}
+	return vector.Rank(coll.Metric, candidates, req), nil
+}
+
+func (c *BoltClient) QueryDocsByIds(ctx context.Context, req *models.QueryDocsByIdsRequest) (map[string]*models.Doc, error) {
+	result := make(map[string]*models.Doc, len(req.Ids))
+	err := c.db.View(func(tx *bolt.Tx) error {
+		docs, err := resourceBucket(tx, collectionsBucket, req.CollId, docsBucket)
+		if err != nil {
+			return err
+		}
+		for _, id := range req.Ids {
+			var doc models.Doc
+			if id == "" || docs.Get([]byte(id)) == nil {
+				continue
+			}
+			if err = getJSON(docs, []byte(id), &doc); err != nil {
+				return err
+			}
+			result[id] = &doc
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// writeDocs applies an insert, update or upsert of docs to a collection.
+// Documents are validated one by one and reported in the response; only the
+// valid ones are stored.
+func (c *BoltClient) writeDocs(collId string, op string, in []models.Doc) (*models.DocOpResponse, error) {
+	c.vectorMu.Lock()
+	defer c.vectorMu.Unlock()
+	resp := &models.DocOpResponse{Output: make([]models.DocOpResult, 0, len(in))}
+	var written []models.Doc
+	var before, after string
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		var coll models.Collection
+		b, err := readMeta(tx, collectionsBucket, collId, &coll)
+		if err != nil {
+			return err
+		}
+		docs := b.Bucket([]byte(docsBucket))
+		if before, after, err = bumpVersion(docs); err != nil {
+			return err
+		}
+		dimension := coll.Dimension
+		for _, doc := range in {
+			if doc.ID == "" && op != "update" {
+				doc.ID = uuid.NewString()
+			}
+			exists := doc.ID != "" && docs.Get([]byte(doc.ID)) != nil
+			if reason := vector.CheckDoc(op, doc, exists, dimension); reason != "" {
+				resp.Output = append(resp.Output, vector.OpFailed(op, doc.ID, reason))
+				continue
+			}
+			// A collection created without a dimension adopts the one of its first vector.
+			if dimension == 0 && len(doc.Vector) > 0 {
+				dimension = uint32(len(doc.Vector))
+			}
+			doc.Score = 0
+			if err = putJSON(docs, []byte(doc.ID), &doc); err != nil {
+				return err
+			}
+			written = append(written, doc)
+			resp.Output = append(resp.Output, vector.OpSucceeded(op, doc.ID))
+		}
+		if dimension == coll.Dimension {
+			return nil
+		}
+		coll.Dimension = dimension
+		coll.UpdatedAt = time.Now()
+		return putJSON(b, metaKey, &coll)
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.indexes.Written(collId, before, after, written, nil)
+	return resp, nil
+}
+
+func readDocs(b *bolt.Bucket) (map[string]models.Doc, error) {
+	docs := make(map[string]models.Doc)
+	err := b.ForEach(func(k, v []byte) error {
+		var doc models.Doc
+		if err := unmarshal(v, &doc); err != nil {
+			return err
+		}
+		docs[string(k)] = doc
+		return nil
+	})
+	return docs, err
+}
+
+// docsVersion identifies the stored docs of a collection by the sequence of
+// its docs bucket, which every write bumps.
+func docsVersion(docs *bolt.Bucket) string {
+	return strconv.FormatUint(docs.Sequence(), 10)
+}
+
+// bumpVersion bumps the version of the docs bucket and returns it before and
+// after.
+func bumpVersion(docs *bolt.Bucket) (before, after string, err error) {
+	before = docsVersion(docs)
+	if _, err = docs.NextSequence(); err != nil {
+		return "", "", err
+	}
+	return before, docsVersion(docs), nil
+}
+
+func docsStats(docs *bolt.Bucket) models.Stats {
+	var stats models.Stats
+	_ = docs.ForEach(func(k, v []byte) error {
+		stats.Count++
+		stats.Size += uint64(len(v))
+		return nil
+	})
+	return stats
+}
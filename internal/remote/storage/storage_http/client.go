@@ -1,46 +1,38 @@
 package storage_http
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/scrapeless-ai/sdk-go/env"
-	"google.golang.org/grpc"
-	"net/http"
-)
-
-var (
-	defaultStorageClient *Client
+	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"sync"
 )
 
 type Client struct {
-	client  *http.Client
-	BaseUrl string
+	req         *request2.Client
+	BaseUrl     string
+	queueHandel map[HandleFuncName]*HttpHandle[request2.RespInfo]
+	// schemas holds the *schema.Validator of datasets by id.
+	schemas sync.Map
 }
 
-func Init(baseUrl ...string) {
-	var (
-		err error
-	)
-	u := env.Env.ScrapelessStorageUrl
-	if len(baseUrl) > 0 {
-		u = baseUrl[0]
-	}
-	defaultStorageClient, err = New(u)
-	defaultStorageClient.regisHttpHandleFunc()
-	if err != nil {
-		panic(err)
+func New(cfg *env.Config, baseUrl string) (*Client, error) {
+	c := &Client{
+		req:     request2.New(cfg),
+		BaseUrl: baseUrl,
 	}
+	c.regisHttpHandleFunc()
+	return c, nil
 }
 
-func Default() *Client {
-	return defaultStorageClient
-}
-
-func New(baseUrl string, opts ...grpc.DialOption) (*Client, error) {
-	return &Client{
-		client:  &http.Client{},
-		BaseUrl: baseUrl,
-	}, nil
+func (c *Client) Close() error {
+	return c.req.Close()
 }
 
-func (c *Client) Close() error {
-	return nil
+// Compact is not supported by the storage API, which expires and cleans up
+// its data itself.
+func (c *Client) Compact(ctx context.Context) (*models.CompactStats, error) {
+	return nil, fmt.Errorf("compact %w", errors.ErrUnsupported)
 }
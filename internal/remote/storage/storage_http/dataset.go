@@ -3,17 +3,32 @@ package storage_http
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/schema"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"net/http"
+	"net/url"
 )
 
 func (c *Client) ListDatasets(ctx context.Context, req *models.ListDatasetsRequest) (*models.ListDatasetsResponse, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	u, err := url.Parse(fmt.Sprintf("%s/api/v1/dataset?desc=%v&page=%d&pageSize=%d", c.BaseUrl, req.Desc, req.Page, req.PageSize))
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	if req.ActorId != nil && *req.ActorId != "" {
+		q.Add("actorId", *req.ActorId)
+	}
+	if req.RunId != nil && *req.RunId != "" {
+		q.Add("runId", *req.RunId)
+	}
+	u.RawQuery = q.Encode()
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
-		Url:     fmt.Sprintf("%s/api/v1/dataset?actorId=%s&desc=%v&page=%d&pageSize=%d&runId=%s", c.BaseUrl, *req.ActorId, req.Desc, req.Page, req.PageSize, *req.RunId),
+		Url:     u.String(),
 		Body:    "",
 		Headers: map[string]string{},
 	})
@@ -29,7 +44,7 @@ func (c *Client) ListDatasets(ctx context.Context, req *models.ListDatasetsReque
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get dataset list err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.ListDatasetsResponse
@@ -47,7 +62,7 @@ func (c *Client) CreateDataset(ctx context.Context, req *models.CreateDatasetReq
 		return nil, err
 	}
 
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/api/v1/dataset", c.BaseUrl),
 		Body:    string(reqBody),
@@ -65,7 +80,7 @@ func (c *Client) CreateDataset(ctx context.Context, req *models.CreateDatasetReq
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("creat dataset err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.Dataset
@@ -78,7 +93,7 @@ func (c *Client) CreateDataset(ctx context.Context, req *models.CreateDatasetReq
 }
 
 func (c *Client) UpdateDataset(ctx context.Context, datasetID, name string) (bool, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPut,
 		Url:     fmt.Sprintf("%s/api/v1/dataset/%s", c.BaseUrl, datasetID),
 		Body:    fmt.Sprintf(`{"name":"%s"}`, name),
@@ -96,12 +111,12 @@ func (c *Client) UpdateDataset(ctx context.Context, datasetID, name string) (boo
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("edit dataset err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
 func (c *Client) DelDataset(ctx context.Context, datasetID string) (bool, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/dataset/%s", c.BaseUrl, datasetID),
 		Headers: map[string]string{},
@@ -118,13 +133,13 @@ func (c *Client) DelDataset(ctx context.Context, datasetID string) (bool, error)
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("del dataset err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
 
 func (c *Client) GetDataset(ctx context.Context, req *models.GetDataset) (*models.DatasetItem, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/dataset/%s/items?page=%d&pageSize=%d&desc=%v", c.BaseUrl, req.DatasetId, req.Page, req.PageSize, req.Desc),
 		Headers: map[string]string{},
@@ -141,7 +156,7 @@ func (c *Client) GetDataset(ctx context.Context, req *models.GetDataset) (*model
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get dataset item err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.DatasetItem
@@ -153,7 +168,18 @@ func (c *Client) GetDataset(ctx context.Context, req *models.GetDataset) (*model
 	return &respData, nil
 }
 
+// QueryDataset is not supported by the dataset API: queries are evaluated on
+// the client.
+func (c *Client) QueryDataset(ctx context.Context, req *models.QueryDatasetRequest) (*models.DatasetItem, error) {
+	return nil, fmt.Errorf("query dataset %w", errors.ErrUnsupported)
+}
+
 func (c *Client) AddDatasetItem(ctx context.Context, datasetId string, data []map[string]any) (bool, error) {
+	if v, ok := c.schemas.Load(datasetId); ok {
+		if err := v.(*schema.Validator).ValidateItems(data); err != nil {
+			return false, err
+		}
+	}
 	reqBody, err := json.Marshal(map[string]any{
 		"items": data,
 	})
@@ -161,7 +187,7 @@ func (c *Client) AddDatasetItem(ctx context.Context, datasetId string, data []ma
 		log.Errorf("marshal dataset item err:%v", err)
 		return false, err
 	}
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/api/v1/dataset/%s/items", c.BaseUrl, datasetId),
 		Body:    string(reqBody),
@@ -180,7 +206,23 @@ func (c *Client) AddDatasetItem(ctx context.Context, datasetId string, data []ma
 	}
 	if resp.Err {
 		log.Errorf("add dataset item err:%s", resp.Msg)
-		return false, fmt.Errorf("add dataset item err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
+
+// SetDatasetSchema sets the schema items added to the dataset must match, or
+// removes it if schema is empty. The dataset API doesn't store schemas: items
+// are validated by this client before they are sent.
+func (c *Client) SetDatasetSchema(ctx context.Context, datasetId string, schemaDoc []byte) error {
+	if len(schemaDoc) == 0 {
+		c.schemas.Delete(datasetId)
+		return nil
+	}
+	v, err := schema.Compile(schemaDoc)
+	if err != nil {
+		return err
+	}
+	c.schemas.Store(datasetId, v)
+	return nil
+}
@@ -0,0 +1,42 @@
+package storage_http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+func TestAddDatasetItemValidatesSchema(t *testing.T) {
+	var requests atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"data":{}}`))
+	})
+	ctx := context.Background()
+	if err := c.SetDatasetSchema(ctx, "ds", []byte(`{"required":["url"]}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := c.AddDatasetItem(ctx, "ds", []map[string]any{{"url": "https://example.com"}, {"title": "no url"}})
+	var ve *errs.ValidationError
+	if !errors.As(err, &ve) || len(ve.Items) != 1 || ve.Items[0].Index != 1 {
+		t.Fatalf("err = %v, want a ValidationError of item 1", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Fatalf("sent %d requests for an invalid batch", n)
+	}
+
+	if _, err = c.AddDatasetItem(ctx, "ds", []map[string]any{{"url": "https://example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.AddDatasetItem(ctx, "other", []map[string]any{{"title": "no url"}}); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("sent %d requests, want 2", n)
+	}
+}
@@ -14,6 +14,7 @@ import (
 type IResponse interface {
 	IsErr() bool
 	Error() string
+	AsError() error
 	GetData() any
 }
 
@@ -24,10 +25,9 @@ type HttpHandle[T IResponse] struct {
 	NeedMarshalReq bool
 	FormatURL      func(h *HttpHandle[T]) (string, error)
 	respInfo       T // Compatible with other HTTP interfaces with different response structures
+	client         *request2.Client
 }
 
-var queueHandel map[HandleFuncName]*HttpHandle[request2.RespInfo]
-
 type HandleFuncName string
 
 const (
@@ -45,7 +45,7 @@ const (
 
 func (c *Client) regisHttpHandleFunc() {
 
-	queueHandel = map[HandleFuncName]*HttpHandle[request2.RespInfo]{
+	c.queueHandel = map[HandleFuncName]*HttpHandle[request2.RespInfo]{
 		createQueue: {
 			Method:         http.MethodPost,
 			Url:            fmt.Sprintf("%s/api/v1/queue", c.BaseUrl),
@@ -134,10 +134,17 @@ func (c *Client) regisHttpHandleFunc() {
 			},
 		},
 	}
+	for _, h := range c.queueHandel {
+		h.client = c.req
+	}
 }
+
+// setReq returns a copy of h bound to req, so that concurrent calls don't
+// share their request and response.
 func (h *HttpHandle[T]) setReq(req any) *HttpHandle[T] {
-	h.Req = req
-	return h
+	c := *h
+	c.Req = req
+	return &c
 }
 
 func (h *HttpHandle[T]) setRespInfo(info T) *HttpHandle[T] {
@@ -147,7 +154,7 @@ func (h *HttpHandle[T]) setRespInfo(info T) *HttpHandle[T] {
 
 func (h *HttpHandle[T]) Unmarshal(resp any) error {
 	if h.respInfo.IsErr() {
-		return errors.New(h.respInfo.Error())
+		return h.respInfo.AsError()
 	}
 	marshal, err := json.Marshal(h.respInfo.GetData())
 	if err != nil {
@@ -161,8 +168,6 @@ func (h *HttpHandle[T]) Unmarshal(resp any) error {
 }
 
 func (h *HttpHandle[T]) sendRequest(ctx context.Context) (*HttpHandle[T], error) {
-	defer h.setReq(nil)
-
 	reqBody := ""
 	if h.NeedMarshalReq {
 		if h.Req == nil {
@@ -184,7 +189,7 @@ func (h *HttpHandle[T]) sendRequest(ctx context.Context) (*HttpHandle[T], error)
 		url = u
 	}
 
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := h.client.Request(ctx, request2.ReqInfo{
 		Method:  h.Method,
 		Url:     url,
 		Body:    reqBody,
@@ -202,7 +207,7 @@ func (h *HttpHandle[T]) sendRequest(ctx context.Context) (*HttpHandle[T], error)
 	}
 	h.setRespInfo(resp)
 	if resp.IsErr() {
-		return h, errors.New(resp.Error())
+		return h, resp.AsError()
 	}
 	return h, nil
 }
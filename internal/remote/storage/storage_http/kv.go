@@ -3,6 +3,7 @@ package storage_http
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
@@ -12,7 +13,7 @@ import (
 )
 
 func (c *Client) ListNamespaces(ctx context.Context, page int64, pageSize int64, desc bool) (*models.KvNamespace, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/kv/namespaces?desc=%v&page=%d&pageSize=%d", c.BaseUrl, desc, page, pageSize),
 		Body:    "",
@@ -30,7 +31,7 @@ func (c *Client) ListNamespaces(ctx context.Context, page int64, pageSize int64,
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get dataset list err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.KvNamespace
@@ -47,7 +48,7 @@ func (c *Client) CreateNamespace(ctx context.Context, req *models.CreateKvNamesp
 	if err != nil {
 		return "", err
 	}
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/api/v1/kv/namespaces", c.BaseUrl),
 		Body:    string(reqBody),
@@ -65,13 +66,13 @@ func (c *Client) CreateNamespace(ctx context.Context, req *models.CreateKvNamesp
 		return "", err
 	}
 	if resp.Err {
-		return "", fmt.Errorf("create namespace err:%s", resp.Msg)
+		return "", resp.AsError()
 	}
 	id := gjson.Parse(body).Get("data.id").String()
 	return id, nil
 }
 func (c *Client) GetNamespace(ctx context.Context, namespaceId string) (*models.KvNamespaceItem, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s", c.BaseUrl, namespaceId),
 		Headers: map[string]string{},
@@ -88,7 +89,7 @@ func (c *Client) GetNamespace(ctx context.Context, namespaceId string) (*models.
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get namespace err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	data := gjson.Parse(body).Get("data").String()
 	var kvi models.KvNamespaceItem
@@ -100,7 +101,7 @@ func (c *Client) GetNamespace(ctx context.Context, namespaceId string) (*models.
 }
 
 func (c *Client) DelNamespace(ctx context.Context, namespaceId string) (bool, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s", c.BaseUrl, namespaceId),
 		Headers: map[string]string{},
@@ -116,13 +117,13 @@ func (c *Client) DelNamespace(ctx context.Context, namespaceId string) (bool, er
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("get namespace err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
 
 func (c *Client) RenameNamespace(ctx context.Context, namespaceId string, name string) (bool, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPut,
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s/rename", c.BaseUrl, namespaceId),
 		Body:    fmt.Sprintf(`{"name":"%s"}`, name),
@@ -140,7 +141,7 @@ func (c *Client) RenameNamespace(ctx context.Context, namespaceId string, name s
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("get namespace err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
@@ -156,7 +157,7 @@ func (c *Client) SetValue(ctx context.Context, req *models.SetValue) (bool, erro
 		log.Infof("marshal reqBody error :%v", err)
 		return false, err
 	}
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPut,
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s/key", c.BaseUrl, req.NamespaceId),
 		Body:    string(reqBodyStr),
@@ -175,13 +176,13 @@ func (c *Client) SetValue(ctx context.Context, req *models.SetValue) (bool, erro
 	}
 	if resp.Err {
 		log.Errorf("set value err :%v", resp.Msg)
-		return false, fmt.Errorf("set value err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
 
 func (c *Client) ListKeys(ctx context.Context, req *models.ListKeyInfo) (*models.KvKeys, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s/keys?page=%d&pageSize=%d", c.BaseUrl, req.NamespaceId, req.Page, req.Size),
 		Headers: map[string]string{},
@@ -198,7 +199,7 @@ func (c *Client) ListKeys(ctx context.Context, req *models.ListKeyInfo) (*models
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("list Keys err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.KvKeys
@@ -210,8 +211,13 @@ func (c *Client) ListKeys(ctx context.Context, req *models.ListKeyInfo) (*models
 	return &respData, nil
 }
 
+// ScanKeys is not supported by the KV API: scans are evaluated on the client.
+func (c *Client) ScanKeys(ctx context.Context, req *models.ScanKeysRequest) (*models.KvScan, error) {
+	return nil, fmt.Errorf("scan keys %w", errors.ErrUnsupported)
+}
+
 func (c *Client) GetValue(ctx context.Context, namespaceId string, key string) (string, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s/%s", c.BaseUrl, namespaceId, key),
 		Headers: map[string]string{},
@@ -228,14 +234,14 @@ func (c *Client) GetValue(ctx context.Context, namespaceId string, key string) (
 		return "", err
 	}
 	if resp.Err {
-		return "", fmt.Errorf("get value err:%s", resp.Msg)
+		return "", resp.AsError()
 	}
 	data := gjson.Parse(body).Get("data").String()
 	return data, nil
 }
 
 func (c *Client) DelValue(ctx context.Context, namespaceId string, key string) (bool, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s/%s", c.BaseUrl, namespaceId, key),
 		Headers: map[string]string{},
@@ -252,7 +258,7 @@ func (c *Client) DelValue(ctx context.Context, namespaceId string, key string) (
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("list Keys err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
@@ -262,7 +268,7 @@ func (c *Client) BulkSetValue(ctx context.Context, req *models.BulkSet) (int64,
 	if err != nil {
 		return 0, err
 	}
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s/bulk", c.BaseUrl, req.NamespaceId),
 		Body:    fmt.Sprintf(`{"Items":%s}`, reqBody),
@@ -279,7 +285,7 @@ func (c *Client) BulkSetValue(ctx context.Context, req *models.BulkSet) (int64,
 		return 0, err
 	}
 	if resp.Err {
-		return 0, fmt.Errorf("bulk set value err:%s", resp.Msg)
+		return 0, resp.AsError()
 	}
 	successfulKeyCount := gjson.Parse(body).Get("data.successfulKeyCount").Int()
 	return successfulKeyCount, nil
@@ -290,7 +296,7 @@ func (c *Client) BulkDelValue(ctx context.Context, namespaceId string, keys []st
 	if err != nil {
 		return false, err
 	}
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s/bulk", c.BaseUrl, namespaceId),
 		Body:    fmt.Sprintf(`{"keys":%s}`, reqBody),
@@ -308,7 +314,32 @@ func (c *Client) BulkDelValue(ctx context.Context, namespaceId string, keys []st
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("bulk set value err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
+
+// CompareAndSwap is not supported by the KV API.
+func (c *Client) CompareAndSwap(ctx context.Context, req *models.CompareAndSwap) (bool, error) {
+	return false, fmt.Errorf("compare and swap %w", errors.ErrUnsupported)
+}
+
+// SetIfNotExists is not supported by the KV API.
+func (c *Client) SetIfNotExists(ctx context.Context, req *models.SetValue) (bool, error) {
+	return false, fmt.Errorf("set if not exists %w", errors.ErrUnsupported)
+}
+
+// Increment is not supported by the KV API.
+func (c *Client) Increment(ctx context.Context, namespaceId string, key string, delta int64) (int64, error) {
+	return 0, fmt.Errorf("increment %w", errors.ErrUnsupported)
+}
+
+// Touch is not supported by the KV API.
+func (c *Client) Touch(ctx context.Context, namespaceId string, key string, expiration uint) (bool, error) {
+	return false, fmt.Errorf("touch %w", errors.ErrUnsupported)
+}
+
+// GetWithMetadata is not supported by the KV API.
+func (c *Client) GetWithMetadata(ctx context.Context, namespaceId string, key string) (*models.KvValue, error) {
+	return nil, fmt.Errorf("get with metadata %w", errors.ErrUnsupported)
+}
@@ -0,0 +1,33 @@
+package storage_http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+)
+
+func TestAtomicKVUnsupported(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+	ctx := context.Background()
+	_, casErr := c.CompareAndSwap(ctx, &models.CompareAndSwap{NamespaceId: "ns", Key: "state", OldValue: "a", Value: "b"})
+	_, nxErr := c.SetIfNotExists(ctx, &models.SetValue{NamespaceId: "ns", Key: "state", Value: "c"})
+	_, incrErr := c.Increment(ctx, "ns", "count", 3)
+	_, touchErr := c.Touch(ctx, "ns", "count", 60)
+	_, metaErr := c.GetWithMetadata(ctx, "ns", "count")
+	for name, err := range map[string]error{
+		"CompareAndSwap":  casErr,
+		"SetIfNotExists":  nxErr,
+		"Increment":       incrErr,
+		"Touch":           touchErr,
+		"GetWithMetadata": metaErr,
+	} {
+		if !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("%s = %v, want ErrUnsupported", name, err)
+		}
+	}
+}
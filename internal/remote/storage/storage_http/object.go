@@ -4,19 +4,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/scrapeless-ai/sdk-go/env"
 	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"github.com/tidwall/gjson"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 func (c *Client) ListBuckets(ctx context.Context, page, size int) (*models.Object, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/object/buckets?page=%d&pageSize=%d", c.BaseUrl, page, size),
 		Headers: map[string]string{},
@@ -33,7 +37,7 @@ func (c *Client) ListBuckets(ctx context.Context, page, size int) (*models.Objec
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get dataset list err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.Object
@@ -50,7 +54,7 @@ func (c *Client) CreateBucket(ctx context.Context, req *models.CreateBucketReque
 	if err != nil {
 		return "", err
 	}
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/api/v1/object/buckets", c.BaseUrl),
 		Body:    string(reqBody),
@@ -68,17 +72,17 @@ func (c *Client) CreateBucket(ctx context.Context, req *models.CreateBucketReque
 		return "", err
 	}
 	if resp.Err {
-		return "", fmt.Errorf("create bucket err:%s", resp.Msg)
+		return "", resp.AsError()
 	}
 	id := gjson.Parse(body).Get("data.id").String()
 	if id != "" {
 		return id, nil
 	}
-	return "", fmt.Errorf("create bucket err:%s", resp.Msg)
+	return "", resp.AsError()
 }
 
 func (c *Client) DeleteBucket(ctx context.Context, bucketId string) (bool, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/object/buckets/%s", c.BaseUrl, bucketId),
 		Headers: map[string]string{},
@@ -95,12 +99,12 @@ func (c *Client) DeleteBucket(ctx context.Context, bucketId string) (bool, error
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("get bucket err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
 func (c *Client) GetBucket(ctx context.Context, bucketId string) (*models.Bucket, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/object/buckets/%s", c.BaseUrl, bucketId),
 		Headers: map[string]string{},
@@ -117,7 +121,7 @@ func (c *Client) GetBucket(ctx context.Context, bucketId string) (*models.Bucket
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get bucket err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.Bucket
@@ -130,9 +134,9 @@ func (c *Client) GetBucket(ctx context.Context, bucketId string) (*models.Bucket
 }
 
 func (c *Client) ListObjects(ctx context.Context, req *models.ListObjectsRequest) (*models.ObjectList, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
-		Url:     fmt.Sprintf("%s/api/v1/object/buckets/%s/objects", c.BaseUrl, req.BucketId),
+		Url:     fmt.Sprintf("%s/api/v1/object/buckets/%s/objects?page=%d&pageSize=%d&search=%s", c.BaseUrl, req.BucketId, req.Page, req.PageSize, url.QueryEscape(req.Search)),
 		Headers: map[string]string{},
 	})
 	log.Infof("list objects body :%s", body)
@@ -147,7 +151,7 @@ func (c *Client) ListObjects(ctx context.Context, req *models.ListObjectsRequest
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get bucket err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.ObjectList
@@ -160,7 +164,7 @@ func (c *Client) ListObjects(ctx context.Context, req *models.ListObjectsRequest
 }
 
 func (c *Client) GetObject(ctx context.Context, req *models.ObjectRequest) ([]byte, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/object/buckets/%s/%s", c.BaseUrl, req.BucketId, req.ObjectId),
 		Headers: map[string]string{},
@@ -177,13 +181,13 @@ func (c *Client) GetObject(ctx context.Context, req *models.ObjectRequest) ([]by
 		return []byte(body), nil
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get object err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	return []byte(body), nil
 }
 
 func (c *Client) DeleteObject(ctx context.Context, req *models.ObjectRequest) (bool, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/object/buckets/%s/%s", c.BaseUrl, req.BucketId, req.ObjectId),
 		Headers: map[string]string{},
@@ -200,7 +204,7 @@ func (c *Client) DeleteObject(ctx context.Context, req *models.ObjectRequest) (b
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("delete object err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
@@ -217,27 +221,268 @@ func (c *Client) PutObject(ctx context.Context, req *models.PutObjectRequest) (s
 	url := fmt.Sprintf("%s/api/v1/object/buckets/%s/object", c.BaseUrl, req.BucketId)
 	request, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
 	request.Header.Set("Content-Type", writer.FormDataContentType())
-	request.Header.Set(env.Env.HTTPHeader, env.GetActorEnv().ApiKey)
-	resp, err := c.client.Do(request)
+	resp, err := c.req.Do(request)
+	if err != nil {
+		log.Errorf("request error :%v", err)
+		return "", err
+	}
 	defer resp.Body.Close()
 	all, _ := io.ReadAll(resp.Body)
 	log.Infof("put object body :%s", string(all))
+	var respInfo request2.RespInfo
+	err = json.Unmarshal(all, &respInfo)
+	if err != nil {
+		log.Errorf("unmarshal resp error :%v", err)
+		return "", err
+	}
+	if respInfo.Err {
+		return "", respInfo.AsError()
+	}
+	objectId := gjson.Parse(string(all)).Get("data.objectId").String()
+	if objectId == "" {
+		return "", respInfo.AsError()
+	}
+	return objectId, nil
+}
+
+// PutObjectStream uploads the content of req.Reader without buffering it. The
+// multipart body is sent with a Content-Length when req.Size is known, and
+// with chunked transfer encoding otherwise. Like other POST requests, the
+// upload is only sent again when it failed before reaching the server, or
+// when the retry policy allows retrying non-idempotent requests; either way,
+// only when req.Reader is an io.Seeker.
+func (c *Client) PutObjectStream(ctx context.Context, req *models.PutObjectStreamRequest) (string, error) {
+	// Render the multipart envelope around the file content once, so the
+	// content itself can be streamed in between.
+	envelope := &bytes.Buffer{}
+	writer := multipart.NewWriter(envelope)
+	if _, err := writer.CreateFormFile("file", req.Filename); err != nil {
+		return "", err
+	}
+	prefix := bytes.Clone(envelope.Bytes())
+	envelope.Reset()
+	writer.WriteField("actorId", req.ActorId)
+	writer.WriteField("runId", req.RunId)
+	writer.Close()
+	suffix := bytes.Clone(envelope.Bytes())
+
+	newBody := func() io.Reader {
+		return io.MultiReader(bytes.NewReader(prefix), req.Reader, bytes.NewReader(suffix))
+	}
+	url := fmt.Sprintf("%s/api/v1/object/buckets/%s/object", c.BaseUrl, req.BucketId)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, newBody())
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	request.ContentLength = -1
+	if req.Size >= 0 {
+		request.ContentLength = int64(len(prefix)) + req.Size + int64(len(suffix))
+	}
+	if seeker, ok := req.Reader.(io.Seeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			request.GetBody = func() (io.ReadCloser, error) {
+				if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+					return nil, err
+				}
+				return io.NopCloser(newBody()), nil
+			}
+		}
+	}
+
+	resp, err := c.req.Do(request)
 	if err != nil {
 		log.Errorf("request error :%v", err)
 		return "", err
 	}
+	defer resp.Body.Close()
+	all, _ := io.ReadAll(resp.Body)
+	log.Infof("put object stream body :%s", string(all))
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", request2.ResponseError(resp, all)
+	}
 	var respInfo request2.RespInfo
-	err = json.Unmarshal(all, &respInfo)
-	if err != nil {
+	if err = json.Unmarshal(all, &respInfo); err != nil {
 		log.Errorf("unmarshal resp error :%v", err)
 		return "", err
 	}
 	if respInfo.Err {
-		return "", fmt.Errorf("put object err:%s", respInfo.Msg)
+		return "", respInfo.AsError()
 	}
 	objectId := gjson.Parse(string(all)).Get("data.objectId").String()
 	if objectId == "" {
-		return "", fmt.Errorf("put object err:%s", respInfo.Msg)
+		return "", respInfo.AsError()
 	}
 	return objectId, nil
 }
+
+// GetObjectStream downloads an object as a stream. When the connection breaks
+// mid-way, the download resumes where it stopped with a Range request, up to
+// the number of attempts of the retry policy. Resuming fails with
+// errObjectChanged when the object was replaced meanwhile.
+func (c *Client) GetObjectStream(ctx context.Context, req *models.GetObjectStreamRequest) (io.ReadCloser, error) {
+	r := &objectReader{
+		ctx:    ctx,
+		c:      c,
+		url:    fmt.Sprintf("%s/api/v1/object/buckets/%s/%s", c.BaseUrl, req.BucketId, req.ObjectId),
+		offset: req.Offset,
+		size:   -1,
+	}
+	if err := r.open(); err != nil {
+		log.Errorf("get object stream err:%v", err)
+		return nil, err
+	}
+	return r, nil
+}
+
+// objectReader reads an object body, reopening it from the current offset
+// when reading fails.
+type objectReader struct {
+	ctx    context.Context
+	c      *Client
+	url    string
+	body   io.ReadCloser
+	offset int64
+	// size is the total object size, or -1 until known.
+	size     int64
+	attempts int
+	// etag and lastModified identify the version of the object being
+	// downloaded, so that only this one is resumed.
+	etag         string
+	lastModified string
+}
+
+// errObjectChanged reports an object replaced while it was being downloaded.
+var errObjectChanged = errors.New("object changed during the download")
+
+func (r *objectReader) Read(p []byte) (int, error) {
+	for {
+		if r.body == nil {
+			return 0, io.EOF
+		}
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if err == nil || (err == io.EOF && (r.size < 0 || r.offset >= r.size)) {
+			return n, err
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if resumeErr := r.resume(err); resumeErr != nil {
+			return n, resumeErr
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// resume reopens the body after the read error cause, or returns cause when
+// the download can't be resumed.
+func (r *objectReader) resume(cause error) error {
+	r.body.Close()
+	r.body = nil
+	for {
+		r.attempts++
+		if r.ctx.Err() != nil || r.attempts >= r.c.req.Config().Retry.MaxAttempts {
+			return cause
+		}
+		delay := r.c.req.Backoff(r.attempts)
+		log.Warnf("get object stream failed at byte %d: %v, resuming in %s", r.offset, cause, delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-r.ctx.Done():
+			timer.Stop()
+			return cause
+		case <-timer.C:
+		}
+		err := r.open()
+		if err == nil {
+			return nil
+		}
+		var apiErr *errs.APIError
+		if errors.As(err, &apiErr) || errors.Is(err, errObjectChanged) {
+			return err
+		}
+		cause = err
+	}
+}
+
+// open requests the object from the current offset.
+func (r *objectReader) open() error {
+	request, err := http.NewRequestWithContext(r.ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return err
+	}
+	if r.offset > 0 {
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.offset))
+		// Without a strong ETag, If-Range compares the modification date.
+		if r.etag != "" && !strings.HasPrefix(r.etag, "W/") {
+			request.Header.Set("If-Range", r.etag)
+		} else if r.lastModified != "" {
+			request.Header.Set("If-Range", r.lastModified)
+		}
+	}
+	resp, err := r.c.req.Do(request)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < http.StatusBadRequest {
+		if err = r.checkVersion(resp); err != nil {
+			resp.Body.Close()
+			return err
+		}
+	}
+	switch {
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && r.offset > 0:
+		// Nothing is left past offset.
+		resp.Body.Close()
+		return nil
+	case resp.StatusCode >= http.StatusBadRequest:
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return request2.ResponseError(resp, body)
+	case resp.StatusCode == http.StatusPartialContent:
+		if resp.ContentLength >= 0 {
+			r.size = r.offset + resp.ContentLength
+		}
+	default:
+		// The server ignored the Range header and sent the whole object.
+		if resp.ContentLength >= 0 {
+			r.size = resp.ContentLength
+		}
+		if r.offset > 0 {
+			if _, err = io.CopyN(io.Discard, resp.Body, r.offset); err != nil {
+				resp.Body.Close()
+				return err
+			}
+		}
+	}
+	r.body = resp.Body
+	return nil
+}
+
+// checkVersion records the version of the object of the first response, and
+// fails with errObjectChanged when a later one serves another version. The
+// ETag is compared when known, the modification date otherwise.
+func (r *objectReader) checkVersion(resp *http.Response) error {
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if r.etag == "" && r.lastModified == "" {
+		r.etag, r.lastModified = etag, lastModified
+		return nil
+	}
+	if r.etag != "" && etag != "" && etag != r.etag || r.etag == "" && lastModified != "" && lastModified != r.lastModified {
+		return fmt.Errorf("%w: %s", errObjectChanged, r.url)
+	}
+	return nil
+}
+
+func (r *objectReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
@@ -0,0 +1,155 @@
+package storage_http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/env"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	cfg := env.NewConfig()
+	cfg.Retry.MinBackoff = time.Millisecond
+	cfg.Retry.MaxBackoff = 5 * time.Millisecond
+	c, err := New(cfg, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestPutObjectStream(t *testing.T) {
+	content := strings.Repeat("har", 1000)
+	for _, size := range []int64{int64(len(content)), -1} {
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if size >= 0 && r.ContentLength < size {
+				t.Errorf("unexpected content length %d", r.ContentLength)
+			}
+			if size < 0 && r.ContentLength != -1 {
+				t.Errorf("expected chunked upload, got content length %d", r.ContentLength)
+			}
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			data, _ := io.ReadAll(file)
+			if header.Filename != "trace.har" || string(data) != content || r.FormValue("runId") != "run" {
+				t.Errorf("unexpected upload %s %q %q", header.Filename, data, r.FormValue("runId"))
+			}
+			_, _ = w.Write([]byte(`{"data":{"objectId":"obj"}}`))
+		})
+
+		id, err := c.PutObjectStream(context.Background(), &models.PutObjectStreamRequest{
+			BucketId: "bucket",
+			Filename: "trace.har",
+			Reader:   strings.NewReader(content),
+			Size:     size,
+			RunId:    "run",
+		})
+		if err != nil || id != "obj" {
+			t.Fatalf("got %q, %v", id, err)
+		}
+	}
+}
+
+func TestGetObjectStreamResumes(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 1000))
+	var ranges []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range")+" "+r.Header.Get("If-Range"))
+		w.Header().Set("ETag", `"v1"`)
+		offset := 0
+		if rng := r.Header.Get("Range"); rng != "" {
+			offset, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(content)-1, len(content)))
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)-offset))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		}
+		if len(ranges) == 1 {
+			// Break the connection half way through the first response.
+			_, _ = w.Write(content[:len(content)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		_, _ = w.Write(content[offset:])
+	})
+
+	body, err := c.GetObjectStream(context.Background(), &models.GetObjectStreamRequest{BucketId: "bucket", ObjectId: "obj"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Fatalf("got %d bytes, want %d", len(data), len(content))
+	}
+	if len(ranges) != 2 || ranges[0] != " " || !strings.HasPrefix(ranges[1], "bytes=") || !strings.HasSuffix(ranges[1], ` "v1"`) {
+		t.Fatalf("unexpected ranges %q", ranges)
+	}
+}
+
+func TestGetObjectStreamChanged(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 1000))
+	var calls int
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		if calls == 1 {
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = w.Write(content[:len(content)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		// Replaced meanwhile: If-Range doesn't match, so the whole new
+		// version is sent.
+		w.Header().Set("ETag", `"v2"`)
+		_, _ = w.Write(content)
+	})
+
+	body, err := c.GetObjectStream(context.Background(), &models.GetObjectStreamRequest{BucketId: "bucket", ObjectId: "obj"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	if _, err = io.ReadAll(body); !errors.Is(err, errObjectChanged) {
+		t.Fatalf("ReadAll() = %v, want errObjectChanged", err)
+	}
+	if calls != 2 {
+		t.Errorf("object requested %d times, want 2", calls)
+	}
+}
+
+func TestListObjects(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/object/buckets/bucket/objects" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("page") != "2" || query.Get("pageSize") != "50" || query.Get("search") != "trace & har" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"data":{"objects":[{"id":"obj"}],"total":51,"totalPage":2}}`))
+	})
+	list, err := c.ListObjects(context.Background(), &models.ListObjectsRequest{BucketId: "bucket", Search: "trace & har", Page: 2, PageSize: 50})
+	if err != nil || len(list.Objects) != 1 || list.Total != 51 {
+		t.Fatalf("ListObjects() = %+v, %v", list, err)
+	}
+}
@@ -2,13 +2,19 @@ package storage_http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
+// CreateQueue creates a queue. The queue API has no dead-letter queues, so a
+// queue with one is rejected rather than created without it.
 func (c *Client) CreateQueue(ctx context.Context, req *models.CreateQueueRequest) (*models.CreateQueueResponse, error) {
-	handel, ok := queueHandel[createQueue]
+	if req.DeadLetterQueueId != "" {
+		return nil, fmt.Errorf("dead-letter queue %w", errors.ErrUnsupported)
+	}
+	handel, ok := c.queueHandel[createQueue]
 	if !ok {
 		return nil, fmt.Errorf("not found handle func")
 	}
@@ -26,7 +32,7 @@ func (c *Client) CreateQueue(ctx context.Context, req *models.CreateQueueRequest
 }
 
 func (c *Client) GetQueue(ctx context.Context, req *models.GetQueueRequest) (*models.GetQueueResponse, error) {
-	handel, ok := queueHandel[getQueue]
+	handel, ok := c.queueHandel[getQueue]
 	if !ok {
 		return nil, fmt.Errorf("not found handle func")
 	}
@@ -44,7 +50,7 @@ func (c *Client) GetQueue(ctx context.Context, req *models.GetQueueRequest) (*mo
 }
 
 func (c *Client) GetQueues(ctx context.Context, req *models.GetQueuesRequest) (*models.ListQueuesResponse, error) {
-	handel, ok := queueHandel[getQueues]
+	handel, ok := c.queueHandel[getQueues]
 	if !ok {
 		return nil, fmt.Errorf("not found handle func")
 	}
@@ -62,7 +68,7 @@ func (c *Client) GetQueues(ctx context.Context, req *models.GetQueuesRequest) (*
 }
 
 func (c *Client) UpdateQueue(ctx context.Context, req *models.UpdateQueueRequest) error {
-	handel, ok := queueHandel[updateQueue]
+	handel, ok := c.queueHandel[updateQueue]
 	if !ok {
 		return fmt.Errorf("not found handle func")
 	}
@@ -76,7 +82,7 @@ func (c *Client) UpdateQueue(ctx context.Context, req *models.UpdateQueueRequest
 }
 
 func (c *Client) DelQueue(ctx context.Context, req *models.DelQueueRequest) error {
-	handel, ok := queueHandel[delQueue]
+	handel, ok := c.queueHandel[delQueue]
 	if !ok {
 		return fmt.Errorf("not found handle func")
 	}
@@ -89,7 +95,7 @@ func (c *Client) DelQueue(ctx context.Context, req *models.DelQueueRequest) erro
 }
 
 func (c *Client) CreateMsg(ctx context.Context, req *models.CreateMsgRequest) (*models.CreateMsgResponse, error) {
-	handel, ok := queueHandel[createMsg]
+	handel, ok := c.queueHandel[createMsg]
 	if !ok {
 		return nil, fmt.Errorf("not found handle func")
 	}
@@ -107,7 +113,7 @@ func (c *Client) CreateMsg(ctx context.Context, req *models.CreateMsgRequest) (*
 }
 
 func (c *Client) GetMsg(ctx context.Context, req *models.GetMsgRequest) (*models.GetMsgResponse, error) {
-	handel, ok := queueHandel[getMsg]
+	handel, ok := c.queueHandel[getMsg]
 	if !ok {
 		return nil, fmt.Errorf("not found handle func")
 	}
@@ -124,7 +130,7 @@ func (c *Client) GetMsg(ctx context.Context, req *models.GetMsgRequest) (*models
 }
 
 func (c *Client) AckMsg(ctx context.Context, req *models.AckMsgRequest) error {
-	handel, ok := queueHandel[ackMsg]
+	handel, ok := c.queueHandel[ackMsg]
 	if !ok {
 		return fmt.Errorf("not found handle func")
 	}
@@ -135,3 +141,20 @@ func (c *Client) AckMsg(ctx context.Context, req *models.AckMsgRequest) error {
 
 	return nil
 }
+
+// NackMsg is not supported by the queue API: messages return to the queue
+// once their timeout elapses.
+func (c *Client) NackMsg(ctx context.Context, req *models.NackMsgRequest) error {
+	return fmt.Errorf("nack msg %w", errors.ErrUnsupported)
+}
+
+// RenewMsg is not supported by the queue API: messages return to the queue
+// once their timeout elapses.
+func (c *Client) RenewMsg(ctx context.Context, req *models.RenewMsgRequest) error {
+	return fmt.Errorf("renew msg %w", errors.ErrUnsupported)
+}
+
+// ListMsgs is not supported by the queue API: messages can only be pulled.
+func (c *Client) ListMsgs(ctx context.Context, req *models.ListMsgsRequest) (*models.ListMsgsResponse, error) {
+	return nil, fmt.Errorf("list msgs %w", errors.ErrUnsupported)
+}
@@ -0,0 +1,25 @@
+package storage_http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+)
+
+func TestDeadLetterQueueUnsupported(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+	ctx := context.Background()
+	_, err := c.CreateQueue(ctx, &models.CreateQueueRequest{Name: "jobs", DeadLetterQueueId: "dlq"})
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("CreateQueue(dead-letter queue) = %v, want ErrUnsupported", err)
+	}
+	err = c.NackMsg(ctx, &models.NackMsgRequest{QueueId: "jobs", MsgId: "msg"})
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("NackMsg = %v, want ErrUnsupported", err)
+	}
+}
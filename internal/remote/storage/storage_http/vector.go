@@ -24,7 +24,7 @@ func (c *Client) ListCollections(ctx context.Context, req *models.ListCollection
 		q.Add("actorId", *req.ActorId)
 	}
 	u.RawQuery = q.Encode()
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     u.String(),
 		Headers: map[string]string{},
@@ -41,7 +41,7 @@ func (c *Client) ListCollections(ctx context.Context, req *models.ListCollection
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get collections list err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.ListCollectionsResponse
@@ -59,7 +59,7 @@ func (c *Client) CreateCollections(ctx context.Context, req *models.CreateCollec
 		return nil, err
 	}
 
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/api/v1/vector", c.BaseUrl),
 		Body:    string(reqBody),
@@ -76,7 +76,7 @@ func (c *Client) CreateCollections(ctx context.Context, req *models.CreateCollec
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("create collection err: %s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var coll models.Collection
@@ -96,7 +96,7 @@ func (c *Client) UpdateCollection(ctx context.Context, req *models.UpdateCollect
 	if err != nil {
 		return err
 	}
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPut,
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s", c.BaseUrl, req.CollId),
 		Body:    string(reqBody),
@@ -114,13 +114,13 @@ func (c *Client) UpdateCollection(ctx context.Context, req *models.UpdateCollect
 		return err
 	}
 	if resp.Err {
-		return fmt.Errorf("update collection err:%s", resp.Msg)
+		return resp.AsError()
 	}
 	return nil
 }
 
 func (c *Client) DelCollection(ctx context.Context, collId string) error {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s", c.BaseUrl, collId),
 		Headers: map[string]string{},
@@ -137,13 +137,13 @@ func (c *Client) DelCollection(ctx context.Context, collId string) error {
 		return err
 	}
 	if resp.Err {
-		return fmt.Errorf("delete collection err:%s", resp.Msg)
+		return resp.AsError()
 	}
 	return nil
 }
 
 func (c *Client) GetCollection(ctx context.Context, collId string) (*models.Collection, error) {
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s", c.BaseUrl, collId),
 		Headers: map[string]string{},
@@ -160,7 +160,7 @@ func (c *Client) GetCollection(ctx context.Context, collId string) (*models.Coll
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get collection err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.Collection
@@ -177,7 +177,7 @@ func (c *Client) CreateDocs(ctx context.Context, req *models.CreateDocsRequest)
 	if err != nil {
 		return nil, err
 	}
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s/docs", c.BaseUrl, req.CollId),
 		Body:    string(reqBody),
@@ -195,7 +195,7 @@ func (c *Client) CreateDocs(ctx context.Context, req *models.CreateDocsRequest)
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("create docs err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.DocOpResponse
@@ -212,7 +212,7 @@ func (c *Client) UpdateDocs(ctx context.Context, req *models.UpdateDocsRequest)
 	if err != nil {
 		return nil, err
 	}
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPut,
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s/docs", c.BaseUrl, req.CollId),
 		Body:    string(reqBody),
@@ -230,7 +230,7 @@ func (c *Client) UpdateDocs(ctx context.Context, req *models.UpdateDocsRequest)
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("update docs err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.DocOpResponse
@@ -247,7 +247,7 @@ func (c *Client) UpsertDocs(ctx context.Context, req *models.UpsertVectorDocsPar
 	if err != nil {
 		return nil, err
 	}
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s/docs/upsert", c.BaseUrl, req.CollId),
 		Body:    string(reqBody),
@@ -265,7 +265,7 @@ func (c *Client) UpsertDocs(ctx context.Context, req *models.UpsertVectorDocsPar
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("upsert docs err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.DocOpResponse
@@ -282,7 +282,7 @@ func (c *Client) DelDocs(ctx context.Context, req *models.DeleteDocsRequest) (*m
 	if err != nil {
 		return nil, err
 	}
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s/docs", c.BaseUrl, req.CollId),
 		Body:    string(reqBody),
@@ -300,7 +300,7 @@ func (c *Client) DelDocs(ctx context.Context, req *models.DeleteDocsRequest) (*m
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("del docs err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.DocOpResponse
@@ -317,7 +317,7 @@ func (c *Client) QueryDocs(ctx context.Context, req *models.QueryVectorRequest)
 	if err != nil {
 		return nil, err
 	}
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodPost,
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s/docs/query", c.BaseUrl, req.CollId),
 		Body:    string(reqBody),
@@ -335,7 +335,7 @@ func (c *Client) QueryDocs(ctx context.Context, req *models.QueryVectorRequest)
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("query docs err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	respData := make([]*models.Doc, 0)
@@ -359,7 +359,7 @@ func (c *Client) QueryDocsByIds(ctx context.Context, req *models.QueryDocsByIdsR
 	}
 	u.RawQuery = query.Encode()
 
-	body, err := request2.Request(ctx, request2.ReqInfo{
+	body, err := c.req.Request(ctx, request2.ReqInfo{
 		Method: http.MethodGet,
 		Url:    u.String(),
 	})
@@ -375,7 +375,7 @@ func (c *Client) QueryDocsByIds(ctx context.Context, req *models.QueryDocsByIdsR
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("query docs byIds err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	respData := make(map[string]*models.Doc)
@@ -2,10 +2,14 @@ package storage_memory
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/vector"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 )
 
@@ -16,16 +20,36 @@ const (
 	keyValueDir = "kv_stores"
 	queueDir    = "queues_stores"
 	objectDir   = "objects_stores"
+	vectorDir   = "vectors_stores"
 
 	metadataFile = "metadata.json"
 	inputJson    = "INPUT.json"
 	defaultDir   = "default"
 )
 
-var defaultLocalClient *LocalClient
+var (
+	defaultMu          sync.Mutex
+	defaultLocalClient *LocalClient
+)
+
+type LocalClient struct {
+	// vectorMu guards the vector documents and indexes.
+	vectorMu sync.Mutex
+	// indexes caches the HNSW index of large vector collections by id.
+	indexes vector.Indexes
 
-type LocalClient struct{}
+	// The janitor compacts the storage until the client is closed.
+	closeOnce   sync.Once
+	stopJanitor chan struct{}
+	janitorDone chan struct{}
 
+	// dir is the storage directory of the default client, and refs counts
+	// its users that haven't closed it yet. They are guarded by defaultMu.
+	dir  string
+	refs int
+}
+
+// Init sets the storage directory to storage/ under the working directory.
 func Init() {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -36,11 +60,38 @@ func Init() {
 	if err != nil {
 		log.Warnf("warn create storage dir err: %v", err)
 	}
-	defaultLocalClient = &LocalClient{}
 }
 
-func Default() *LocalClient {
-	return defaultLocalClient
+// Default returns the client of the storage in dir, storage/ under the
+// working directory when empty, initializing it on first use or after it was
+// closed. The client is shared by the callers of Default: its janitor stops
+// when every one of them has closed it. The storage directory is global to
+// the package, so a single one can be used at a time.
+func Default(dir string) (*LocalClient, error) {
+	if dir == "" {
+		dir = "storage"
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve storage dir failed: %v", err)
+	}
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if c := defaultLocalClient; c != nil {
+		if c.dir != dir {
+			return nil, fmt.Errorf("%w: local storage is already used in %s", errs.ErrInvalidArgument, c.dir)
+		}
+		c.refs++
+		return c, nil
+	}
+	storageDir = dir
+	if err = EnsureDir(storageDir); err != nil {
+		log.Warnf("warn create storage dir err: %v", err)
+	}
+	c := &LocalClient{dir: dir, refs: 1}
+	c.startJanitor(janitorInterval)
+	defaultLocalClient = c
+	return c, nil
 }
 
 // EnsureDir Ensure that the directory exists (create if it does not exist)
@@ -55,6 +106,8 @@ func EnsureDir(storageDir string) error {
 	createMetadata(path, datasetDir)
 	path, err = createDir(absPath, objectDir)
 	createMetadata(path, objectDir)
+	path, err = createDir(absPath, vectorDir)
+	createMetadata(path, vectorDir)
 	createInput(absPath)
 	return err
 }
@@ -111,10 +164,21 @@ func createMetadata(path, category string) {
 			Size:        0,
 		}
 		meta, _ = json.MarshalIndent(bucket, "", "  ")
+	case vectorDir:
+		coll := models.Collection{
+			Id:        def,
+			Name:      def,
+			ActorId:   def,
+			RunId:     def,
+			Metric:    vector.MetricCosine,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		meta, _ = json.MarshalIndent(coll, "", "  ")
 	}
 	exists := isFileExists(metaPath)
 	if !exists {
-		err := os.WriteFile(metaPath, meta, os.ModePerm)
+		err := writeFile(metaPath, meta)
 		if err != nil {
 			log.Warnf("warn create metadata.json failed: %v", err)
 		}
@@ -149,6 +213,26 @@ func createDir(absPath string, dirName string) (string, error) {
 	return path, err
 }
 
+// Close stops the janitor, once the other users of the default client have
+// closed it too.
 func (c *LocalClient) Close() error {
+	defaultMu.Lock()
+	if c.refs > 0 {
+		c.refs--
+		if c.refs > 0 {
+			defaultMu.Unlock()
+			return nil
+		}
+		if defaultLocalClient == c {
+			defaultLocalClient = nil
+		}
+	}
+	defaultMu.Unlock()
+	c.closeOnce.Do(func() {
+		if c.stopJanitor != nil {
+			close(c.stopJanitor)
+			<-c.janitorDone
+		}
+	})
 	return nil
 }
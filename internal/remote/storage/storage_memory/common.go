@@ -4,17 +4,49 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
 	"io/fs"
 	"os"
 	"path/filepath"
 )
 
 var (
-	ErrResourceNotFound          = errors.New("resource not found")
-	ErrResourceExists            = errors.New("resource exists")
+	ErrResourceNotFound          = fmt.Errorf("resource %w", errs.ErrNotFound)
+	ErrResourceExists            = fmt.Errorf("resource %w", errs.ErrAlreadyExists)
 	ErrLocalStorageUnimplemented = errors.New("local storage unimplemented")
 )
 
+// tmpDir holds the files being written, renamed into place once complete.
+const tmpDir = ".tmp"
+
+// writeFile writes data to the file at path atomically: its readers see
+// either the previous content or data, never a partial write.
+func writeFile(path string, data []byte) error {
+	dir := filepath.Join(storageDir, tmpDir)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("create temp dir failed: %v", err)
+	}
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("create temp file failed: %v", err)
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("write file %s failed: %v", path, err)
+	}
+	return nil
+}
+
 func isDirExists(path string) bool {
 	info, err := os.Stat(path)
 	if os.IsNotExist(err) {
@@ -41,7 +73,7 @@ func isNameExists(path string, name string) (bool, error) {
 			return nil
 		}
 		if d.Name() == queueDir || d.Name() == datasetDir || d.Name() == keyValueDir ||
-			d.Name() == objectDir || d.Name() == metadataFile {
+			d.Name() == objectDir || d.Name() == vectorDir || d.Name() == metadataFile {
 			return nil
 		}
 		metaDataPath := filepath.Join(path, metadataFile)
@@ -0,0 +1,276 @@
+package storage_memory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+)
+
+// janitorInterval is how often the local storage is compacted in the background.
+const janitorInterval = 10 * time.Minute
+
+// startJanitor compacts the storage every interval until Close.
+func (c *LocalClient) startJanitor(interval time.Duration) {
+	c.stopJanitor = make(chan struct{})
+	c.janitorDone = make(chan struct{})
+	go func() {
+		defer close(c.janitorDone)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stopJanitor:
+				return
+			case <-ticker.C:
+			}
+			stats, err := c.Compact(context.Background())
+			if err != nil {
+				log.Warnf("compact local storage failed: %v", err)
+				continue
+			}
+			if stats.ExpiredKeys+stats.ExpiredMessages+stats.OrphanedFiles > 0 {
+				log.Infof("compacted local storage: %d expired keys, %d expired messages, %d orphaned files, %d bytes reclaimed",
+					stats.ExpiredKeys, stats.ExpiredMessages, stats.OrphanedFiles, stats.ReclaimedBytes)
+			}
+		}
+	}()
+}
+
+// Compact removes from the storage directory the expired keys, the finished
+// and expired queue messages, and the files left without the data they
+// describe: objects whose file is missing, locks of deleted resources and
+// temp files of interrupted writes.
+func (c *LocalClient) Compact(ctx context.Context) (*models.CompactStats, error) {
+	stats := &models.CompactStats{}
+	for _, compact := range []func(context.Context, *models.CompactStats) error{
+		compactKeys,
+		c.compactQueues,
+		compactObjects,
+		compactLocks,
+		compactTemp,
+	} {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		if err := compact(ctx, stats); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+func compactKeys(ctx context.Context, stats *models.CompactStats) error {
+	for _, namespaceId := range subDirs(filepath.Join(storageDir, keyValueDir)) {
+		if err := compactNamespace(namespaceId, stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func compactNamespace(namespaceId string, stats *models.CompactStats) error {
+	nsPath := filepath.Join(storageDir, keyValueDir, namespaceId)
+	entries, err := os.ReadDir(nsPath)
+	if err != nil {
+		return nil
+	}
+	unlock, err := lockNamespace(namespaceId)
+	if err != nil {
+		// Deleted meanwhile.
+		return nil
+	}
+	defer unlock()
+	now := time.Now()
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok || entry.Name() == metadataFile || isInput(namespaceId, name) {
+			continue
+		}
+		var kv models.SetValueLocal
+		path := filepath.Join(nsPath, entry.Name())
+		if err = readJSON(path, &kv); err != nil {
+			log.Warnf("compact key %s: %v", path, err)
+			continue
+		}
+		if kv.ExpireAt.Before(now) {
+			removeFile(path, &stats.ExpiredKeys, stats)
+		}
+	}
+	return nil
+}
+
+func (c *LocalClient) compactQueues(ctx context.Context, stats *models.CompactStats) error {
+	for _, queueId := range subDirs(filepath.Join(storageDir, queueDir)) {
+		if err := c.compactQueue(queueId, stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *LocalClient) compactQueue(queueId string, stats *models.CompactStats) error {
+	unlock, err := lockResource(queueDir, queueId)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	queuePath := filepath.Join(storageDir, queueDir, queueId)
+	var queue models.Queue
+	if err = readJSON(filepath.Join(queuePath, metadataFile), &queue); err != nil {
+		return nil
+	}
+	entries, err := os.ReadDir(queuePath)
+	if err != nil {
+		return nil
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == metadataFile {
+			continue
+		}
+		msgPath := filepath.Join(queuePath, entry.Name())
+		var msg models.MsgLocal
+		if err = readJSON(msgPath, &msg); err != nil {
+			continue
+		}
+		switch {
+		case msg.SuccessAt > 0 || msg.FailedAt > 0:
+			removeFile(msgPath, &stats.ExpiredMessages, stats)
+		case msg.Deadline < now.Unix():
+			// Like GetMsg would, hand the message to the dead-letter queue.
+			size := fileSize(msgPath)
+			if err = c.deadLetter(&queue, msgPath, &msg, "deadline exceeded"); err != nil {
+				return fmt.Errorf("dead-letter msg %s: %w", msg.ID, err)
+			}
+			stats.ExpiredMessages++
+			if queue.DeadLetterQueueId == "" {
+				stats.ReclaimedBytes += size
+			}
+		}
+	}
+	return nil
+}
+
+func compactObjects(ctx context.Context, stats *models.CompactStats) error {
+	for _, bucketId := range subDirs(filepath.Join(storageDir, objectDir)) {
+		bucketPath := filepath.Join(storageDir, objectDir, bucketId)
+		for _, objectId := range subDirs(bucketPath) {
+			objectPath := filepath.Join(bucketPath, objectId)
+			var object models.BucketObject
+			// Objects without metadata may be being uploaded.
+			if readJSON(filepath.Join(objectPath, metadataFile), &object) != nil || object.Filename == "" {
+				continue
+			}
+			if isFileExists(filepath.Join(objectPath, object.Filename)) {
+				continue
+			}
+			size := dirSize(objectPath)
+			if err := os.RemoveAll(objectPath); err != nil {
+				log.Warnf("compact object %s: %v", objectPath, err)
+				continue
+			}
+			stats.OrphanedFiles++
+			stats.ReclaimedBytes += size
+		}
+	}
+	return nil
+}
+
+func compactLocks(ctx context.Context, stats *models.CompactStats) error {
+	entries, err := os.ReadDir(filepath.Join(storageDir, lockDir))
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".lock")
+		category, id, found := strings.Cut(name, "-")
+		if !ok || !found || isDirExists(filepath.Join(storageDir, category, id)) {
+			continue
+		}
+		// Holding the lock, no one else is using the file. Those who opened
+		// it meanwhile notice it was removed once they lock it, and lock a
+		// new one.
+		unlock, err := lockFile(name)
+		if err != nil {
+			continue
+		}
+		if !isDirExists(filepath.Join(storageDir, category, id)) {
+			removeFile(filepath.Join(storageDir, lockDir, entry.Name()), &stats.OrphanedFiles, stats)
+		}
+		unlock()
+	}
+	return nil
+}
+
+// tempMaxAge is the age after which a temp file is considered left behind by
+// a crashed write.
+const tempMaxAge = time.Hour
+
+func compactTemp(ctx context.Context, stats *models.CompactStats) error {
+	entries, err := os.ReadDir(filepath.Join(storageDir, tmpDir))
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < tempMaxAge {
+			continue
+		}
+		removeFile(filepath.Join(storageDir, tmpDir, entry.Name()), &stats.OrphanedFiles, stats)
+	}
+	return nil
+}
+
+// removeFile removes the file at path, counting it in count and its size in
+// the reclaimed bytes.
+func removeFile(path string, count *int, stats *models.CompactStats) {
+	size := fileSize(path)
+	if err := os.Remove(path); err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("compact %s: %v", path, err)
+		}
+		return
+	}
+	*count++
+	stats.ReclaimedBytes += size
+}
+
+// subDirs returns the names of the directories in dir.
+func subDirs(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			size += fileSize(path)
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,205 @@
+package storage_memory
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+// useTempStorage points the storage to an empty directory for the test.
+func useTempStorage(t *testing.T) {
+	t.Helper()
+	dir := storageDir
+	storageDir = t.TempDir()
+	t.Cleanup(func() { storageDir = dir })
+	if err := EnsureDir(storageDir); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	useTempStorage(t)
+
+	// An expired key, next to a live one.
+	ns := newNamespace(t)
+	expired := newLocalValue(&models.SetValue{NamespaceId: ns, Key: "old", Value: "v"})
+	expired.ExpireAt = time.Now().Add(-time.Minute)
+	if err := writeKey(expired); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := local.SetValue(ctx, &models.SetValue{NamespaceId: ns, Key: "new", Value: "v", Expiration: 60}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A finished message and one past its deadline, next to a pending one.
+	queue, err := local.CreateQueue(ctx, &models.CreateQueueRequest{Name: "compact"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgIds []string
+	for range 3 {
+		resp, err := local.CreateMsg(ctx, &models.CreateMsgRequest{QueueId: queue.Id, Deadline: time.Now().Unix() + 3600})
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgIds = append(msgIds, resp.MsgId)
+	}
+	updateMsg := func(id string, update func(*models.MsgLocal)) {
+		path := filepath.Join(storageDir, queueDir, queue.Id, id+".json")
+		var msg models.MsgLocal
+		if err := readJSON(path, &msg); err != nil {
+			t.Fatal(err)
+		}
+		update(&msg)
+		if err := writeJSON(path, &msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	updateMsg(msgIds[0], func(msg *models.MsgLocal) { msg.SuccessAt = time.Now().Unix() })
+	updateMsg(msgIds[1], func(msg *models.MsgLocal) { msg.Deadline = time.Now().Unix() - 1 })
+
+	// An object whose file is gone, next to an intact one.
+	bucketId, err := local.CreateBucket(ctx, &models.CreateBucketRequest{Name: "compact"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var objectIds []string
+	for range 2 {
+		id, err := local.PutObject(ctx, &models.PutObjectRequest{BucketId: bucketId, Filename: "a.txt", Data: []byte("data")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		objectIds = append(objectIds, id)
+	}
+	if err = os.Remove(filepath.Join(storageDir, objectDir, bucketId, objectIds[0], "a.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The lock of a deleted namespace.
+	gone := newNamespace(t)
+	unlock, err := lockNamespace(gone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+	if _, err = local.DelNamespace(ctx, gone); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := local.Compact(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.ExpiredKeys != 1 || stats.ExpiredMessages != 2 || stats.OrphanedFiles != 2 || stats.ReclaimedBytes <= 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if v, err := local.GetValue(ctx, ns, "new"); err != nil || v != "v" {
+		t.Errorf("GetValue(new) = %q, %v", v, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(storageDir, queueDir, queue.Id)); len(entries) != 2 {
+		t.Errorf("queue holds %d files, want the metadata and one message", len(entries))
+	}
+	if _, err = local.GetObject(ctx, &models.ObjectRequest{BucketId: bucketId, ObjectId: objectIds[1]}); err != nil {
+		t.Errorf("GetObject = %v", err)
+	}
+	if isDirExists(filepath.Join(storageDir, objectDir, bucketId, objectIds[0])) {
+		t.Error("orphaned object not removed")
+	}
+
+	// Nothing is left to compact.
+	if stats, err = local.Compact(ctx); err != nil || *stats != (models.CompactStats{}) {
+		t.Errorf("second Compact() = %+v, %v", stats, err)
+	}
+}
+
+func TestJanitor(t *testing.T) {
+	useTempStorage(t)
+
+	c := &LocalClient{}
+	c.startJanitor(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		_ = c.Close()
+		_ = c.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() didn't stop the janitor")
+	}
+}
+
+func TestDefaultShared(t *testing.T) {
+	useTempStorage(t)
+	dir := t.TempDir()
+	a, err := Default(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Default(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b || storageDir != dir || !isDirExists(filepath.Join(dir, keyValueDir, defaultDir)) {
+		t.Fatalf("Default() = %p, %p in %s, want one client in %s", a, b, storageDir, dir)
+	}
+	if _, err = Default(t.TempDir()); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("Default(other dir) = %v, want ErrInvalidArgument", err)
+	}
+
+	if err = a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-b.janitorDone:
+		t.Fatal("janitor stopped while the client is still used")
+	default:
+	}
+	if err = b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	<-b.janitorDone
+
+	// Once closed, another directory can be used.
+	other := t.TempDir()
+	c, err := Default(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if c == a || storageDir != other {
+		t.Errorf("Default(other dir) = %p in %s", c, storageDir)
+	}
+}
+
+func TestLockFileRemoved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queues_stores-gone.lock")
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if !isLockedFile(f, path) {
+		t.Fatal("isLockedFile() = false for the file at path")
+	}
+	// Removed by the janitor after f was opened, then created by another lock.
+	if err = os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if isLockedFile(f, path) {
+		t.Error("isLockedFile() = true for a removed file")
+	}
+	if err = os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if isLockedFile(f, path) {
+		t.Error("isLockedFile() = true for a replaced file")
+	}
+}
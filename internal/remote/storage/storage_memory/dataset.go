@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/query"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/schema"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"os"
 	"path/filepath"
@@ -44,9 +46,12 @@ func (c *LocalClient) ListDatasets(ctx context.Context, req *models.ListDatasets
 		}
 
 		allDatasets = append(allDatasets, models.Dataset{
-			Id:     name,
-			Name:   meta.Name,
-			Fields: meta.Fields,
+			Id:      name,
+			Name:    meta.Name,
+			ActorId: meta.ActorId,
+			RunId:   meta.RunId,
+			Fields:  meta.Fields,
+			Schema:  meta.Schema,
 		})
 	}
 
@@ -77,7 +82,7 @@ func (c *LocalClient) ListDatasets(ctx context.Context, req *models.ListDatasets
 		Total:     total,
 		Page:      req.Page,
 		PageSize:  req.PageSize,
-		TotalPage: total/req.PageSize + 1,
+		TotalPage: totalPage(total, req.PageSize),
 	}, nil
 }
 
@@ -102,6 +107,11 @@ func (c *LocalClient) CreateDataset(ctx context.Context, req *models.CreateDatas
 }
 
 func (c *LocalClient) UpdateDataset(ctx context.Context, datasetID string, name string) (ok bool, err error) {
+	unlock, err := lockResource(datasetDir, datasetID)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
 	_, err = updateMetadata(datasetID, name)
 	if err != nil {
 		return false, fmt.Errorf("dataset update failed, cause: %v", err)
@@ -120,31 +130,11 @@ func (c *LocalClient) DelDataset(ctx context.Context, datasetID string) (bool, e
 
 func (c *LocalClient) GetDataset(ctx context.Context, req *models.GetDataset) (*models.DatasetItem, error) {
 	dirPath := filepath.Join(storageDir, datasetDir, req.DatasetId)
-	if isDirExists(dirPath) {
-		return nil, ErrResourceNotFound
-	}
-	entries, err := os.ReadDir(dirPath)
+	files, err := itemFiles(dirPath, req.Desc)
 	if err != nil {
-		return nil, fmt.Errorf("read dir failed: %v", err)
-	}
-
-	var files []os.DirEntry
-
-	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
-			continue
-		}
-		files = append(files, entry)
+		return nil, err
 	}
 
-	// sort
-	sort.Slice(files, func(i, j int) bool {
-		if req.Desc {
-			return files[i].Name() > files[j].Name()
-		}
-		return files[i].Name() < files[j].Name()
-	})
-
 	total := len(files)
 
 	// page
@@ -157,23 +147,13 @@ func (c *LocalClient) GetDataset(ctx context.Context, req *models.GetDataset) (*
 		end = total
 	}
 
-	pagedFiles := files[start:end]
-
 	var result []map[string]any
 
-	for _, entry := range pagedFiles {
-		fullPath := filepath.Join(dirPath, entry.Name())
-
-		data, err := os.ReadFile(fullPath)
+	for _, entry := range files[start:end] {
+		item, err := readItem(dirPath, entry)
 		if err != nil {
-			return nil, fmt.Errorf("read %s failed: %v", entry.Name(), err)
-		}
-
-		var item map[string]any
-		if err := json.Unmarshal(data, &item); err != nil {
-			return nil, fmt.Errorf("parse JSON %s failed: %v", entry.Name(), err)
+			return nil, err
 		}
-
 		result = append(result, item)
 	}
 
@@ -182,10 +162,80 @@ func (c *LocalClient) GetDataset(ctx context.Context, req *models.GetDataset) (*
 		Total:     total,
 		Page:      req.Page,
 		PageSize:  req.PageSize,
-		TotalPage: total/req.PageSize + 1,
+		TotalPage: int(totalPage(int64(total), int64(req.PageSize))),
+	}, nil
+}
+
+// QueryDataset evaluates the query on every item of the dataset, the same
+// way the client evaluates it for backends without queries.
+func (c *LocalClient) QueryDataset(ctx context.Context, req *models.QueryDatasetRequest) (*models.DatasetItem, error) {
+	if err := query.Validate(req); err != nil {
+		return nil, err
+	}
+	dirPath := filepath.Join(storageDir, datasetDir, req.DatasetId)
+	files, err := itemFiles(dirPath, false)
+	if err != nil {
+		return nil, err
+	}
+	var matches []map[string]any
+	for _, entry := range files {
+		item, err := readItem(dirPath, entry)
+		if err != nil {
+			return nil, err
+		}
+		if query.Match(item, req.Where) {
+			matches = append(matches, item)
+		}
+	}
+	return &models.DatasetItem{
+		Items: query.Apply(matches, req),
+		Total: len(matches),
 	}, nil
 }
 
+// itemFiles returns the item files of a dataset in insertion order, or in
+// reverse order if desc is set.
+func itemFiles(dirPath string, desc bool) ([]os.DirEntry, error) {
+	if !isDirExists(dirPath) {
+		return nil, ErrResourceNotFound
+	}
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("read dir failed: %v", err)
+	}
+
+	var files []os.DirEntry
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" || entry.Name() == metadataFile {
+			continue
+		}
+		files = append(files, entry)
+	}
+
+	// sort
+	sort.Slice(files, func(i, j int) bool {
+		if desc {
+			return files[i].Name() > files[j].Name()
+		}
+		return files[i].Name() < files[j].Name()
+	})
+	return files, nil
+}
+
+func readItem(dirPath string, entry os.DirEntry) (map[string]any, error) {
+	data, err := os.ReadFile(filepath.Join(dirPath, entry.Name()))
+	if err != nil {
+		return nil, fmt.Errorf("read %s failed: %v", entry.Name(), err)
+	}
+
+	var item map[string]any
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("parse JSON %s failed: %v", entry.Name(), err)
+	}
+	return item, nil
+}
+
 func (c *LocalClient) AddDatasetItem(ctx context.Context, datasetId string, items []map[string]any) (bool, error) {
 	if datasetId == "" {
 		datasetId = "default"
@@ -194,6 +244,15 @@ func (c *LocalClient) AddDatasetItem(ctx context.Context, datasetId string, item
 	if !isDirExists(dirPath) {
 		return false, ErrResourceNotFound
 	}
+	// The items are numbered after the last one, and counted in the metadata.
+	unlock, err := lockResource(datasetDir, datasetId)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	if err := validateItems(datasetId, items); err != nil {
+		return false, err
+	}
 	meta, err := updateMetadata(datasetId, "default")
 	if err != nil {
 		return false, err
@@ -229,7 +288,11 @@ func (c *LocalClient) AddDatasetItem(ctx context.Context, datasetId string, item
 			maxIndex = idx
 		}
 	}
-	var fields []string
+	fields := meta.Fields
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field] = true
+	}
 	var newSize uint64 = 0
 
 	for i, item := range items {
@@ -237,11 +300,16 @@ func (c *LocalClient) AddDatasetItem(ctx context.Context, datasetId string, item
 		fileName := fmt.Sprintf("%08d.json", index)
 		filePath := filepath.Join(dirPath, fileName)
 
-		if len(fields) == 0 {
-			for key := range item {
-				fields = append(fields, key)
+		// New fields are appended in name order, keeping the order stable.
+		var newFields []string
+		for key := range item {
+			if !known[key] {
+				known[key] = true
+				newFields = append(newFields, key)
 			}
 		}
+		sort.Strings(newFields)
+		fields = append(fields, newFields...)
 
 		data, err := json.MarshalIndent(item, "", "  ")
 		if err != nil {
@@ -250,8 +318,8 @@ func (c *LocalClient) AddDatasetItem(ctx context.Context, datasetId string, item
 
 		newSize += uint64(len(data))
 
-		if err := os.WriteFile(filePath, data, 0644); err != nil {
-			return false, fmt.Errorf("write file %s failed: %v", fileName, err)
+		if err := writeFile(filePath, data); err != nil {
+			return false, err
 		}
 	}
 
@@ -266,12 +334,61 @@ func (c *LocalClient) AddDatasetItem(ctx context.Context, datasetId string, item
 	if err != nil {
 		log.Warnf("warn json marshal err: %v", err)
 	}
-	if err := os.WriteFile(metaFile, data, 0644); err != nil {
-		return false, fmt.Errorf("write file %s failed: %v", metaFile, err)
+	if err := writeFile(metaFile, data); err != nil {
+		return false, err
 	}
 	return true, nil
 }
 
+// SetDatasetSchema stores the schema in the dataset metadata, or removes it
+// if schema is empty.
+func (c *LocalClient) SetDatasetSchema(ctx context.Context, datasetId string, schemaDoc []byte) error {
+	dirPath := filepath.Join(storageDir, datasetDir, datasetId)
+	if !isDirExists(dirPath) {
+		return ErrResourceNotFound
+	}
+	if len(schemaDoc) > 0 {
+		if _, err := schema.Compile(schemaDoc); err != nil {
+			return err
+		}
+	}
+	unlock, err := lockResource(datasetDir, datasetId)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	metaPath := filepath.Join(dirPath, metadataFile)
+	meta := &models.Dataset{Id: datasetId}
+	if isFileExists(metaPath) {
+		if err := readJSON(metaPath, meta); err != nil {
+			return err
+		}
+	}
+	meta.Schema = json.RawMessage(schemaDoc)
+	meta.UpdatedAt = time.Now().Format(time.RFC3339Nano)
+	return writeJSON(metaPath, meta)
+}
+
+// validateItems validates items against the schema of the dataset, if any.
+func validateItems(datasetId string, items []map[string]any) error {
+	metaPath := filepath.Join(storageDir, datasetDir, datasetId, metadataFile)
+	if !isFileExists(metaPath) {
+		return nil
+	}
+	var meta models.Dataset
+	if err := readJSON(metaPath, &meta); err != nil {
+		return err
+	}
+	if len(meta.Schema) == 0 {
+		return nil
+	}
+	v, err := schema.Compile(meta.Schema)
+	if err != nil {
+		return err
+	}
+	return v.ValidateItems(items)
+}
+
 func updateMetadata(datasetId string, name string) (*models.Dataset, error) {
 	path := filepath.Join(storageDir, datasetDir, datasetId, metadataFile)
 	file, err := os.ReadFile(path)
@@ -294,8 +411,8 @@ func updateMetadata(datasetId string, name string) (*models.Dataset, error) {
 	if err != nil {
 		log.Warnf("warn json marshal err: %v", err)
 	}
-	if err := os.WriteFile(path, indent, 0644); err != nil {
-		return nil, fmt.Errorf("write file %s failed: %v", path, err)
+	if err := writeFile(path, indent); err != nil {
+		return nil, err
 	}
 	return meta, nil
 }
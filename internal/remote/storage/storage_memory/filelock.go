@@ -0,0 +1,81 @@
+package storage_memory
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// lockDir holds the lock files, outside the directories of the resources so
+// they are never listed.
+const lockDir = ".locks"
+
+// lockMus holds a *sync.Mutex per lock name. The goroutines of the process
+// take it before the lock file, so they don't each block a thread in the
+// file lock, and stay serialized where file locks aren't available.
+var lockMus sync.Map
+
+// lockFile takes the exclusive lock named name, shared by the goroutines and
+// the processes using the same storage directory, and returns the function
+// releasing it.
+func lockFile(name string) (unlock func(), err error) {
+	v, _ := lockMus.LoadOrStore(name, new(sync.Mutex))
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	defer func() {
+		if err != nil {
+			mu.Unlock()
+		}
+	}()
+
+	dir := filepath.Join(storageDir, lockDir)
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("create lock dir failed: %v", err)
+	}
+	path := filepath.Join(dir, name+".lock")
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("open lock file %s failed: %v", path, err)
+		}
+		if err = lockHandle(f); err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("lock file %s failed: %v", path, err)
+		}
+		// The janitor of another process may have removed the file between
+		// opening and locking it, leaving this lock on a file no one else
+		// opens: lock the current file instead.
+		if isLockedFile(f, path) {
+			return func() {
+				_ = unlockHandle(f)
+				_ = f.Close()
+				mu.Unlock()
+			}, nil
+		}
+		_ = unlockHandle(f)
+		_ = f.Close()
+	}
+}
+
+// isLockedFile reports whether f is still the file at path.
+func isLockedFile(f *os.File, path string) bool {
+	opened, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	return err == nil && os.SameFile(opened, current)
+}
+
+// lockResource takes the lock serializing the updates of the resource id of
+// category, e.g. the messages of a queue.
+func lockResource(category string, id string) (func(), error) {
+	return lockFile(category + "-" + id)
+}
+
+// lockCategory takes the lock serializing the creation of the resources of
+// category, so that their names stay unique.
+func lockCategory(category string) (func(), error) {
+	return lockFile(category)
+}
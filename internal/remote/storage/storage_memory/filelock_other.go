@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package storage_memory
+
+import "os"
+
+// Without file locks, the mutexes of lockFile only hold within the process.
+
+func lockHandle(f *os.File) error {
+	return nil
+}
+
+func unlockHandle(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build unix
+
+package storage_memory
+
+import (
+	"os"
+	"syscall"
+)
+
+func lockHandle(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+func unlockHandle(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package storage_memory
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+func lockHandle(f *os.File) error {
+	return windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, new(windows.Overlapped))
+}
+
+func unlockHandle(f *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, new(windows.Overlapped))
+}
@@ -6,11 +6,15 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"io/fs"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -172,12 +176,17 @@ func (c *LocalClient) CreateNamespace(ctx context.Context, req *models.CreateKvN
 	id := uuid.NewString()
 	path := filepath.Join(storageDir, keyValueDir, id)
 
+	unlock, err := lockCategory(keyValueDir)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
 	exists, err := isNameExists(filepath.Join(storageDir, keyValueDir), req.Name)
 	if err != nil {
 		return "", err
 	}
 	if exists {
-		return "", fmt.Errorf("namespace %s already exists", req.Name)
+		return "", fmt.Errorf("namespace %s %w", req.Name, errs.ErrAlreadyExists)
 	}
 
 	err = os.MkdirAll(path, os.ModePerm)
@@ -199,9 +208,8 @@ func (c *LocalClient) CreateNamespace(ctx context.Context, req *models.CreateKvN
 	}
 	metaFile := filepath.Join(path, metadataFile)
 
-	err = os.WriteFile(metaFile, marshal, os.ModePerm)
-	if err != nil {
-		return "", fmt.Errorf("write file %s failed, cause: %v", metaFile, err)
+	if err = writeFile(metaFile, marshal); err != nil {
+		return "", err
 	}
 	return id, nil
 }
@@ -217,10 +225,11 @@ func (c *LocalClient) DelNamespace(ctx context.Context, namespaceId string) (boo
 
 func (c *LocalClient) RenameNamespace(ctx context.Context, namespaceId string, name string) (ok bool, err error) {
 	nsPath := filepath.Join(storageDir, keyValueDir, namespaceId)
-	exists := isDirExists(nsPath)
-	if !exists {
-		return false, ErrResourceNotFound
+	unlock, err := lockNamespace(namespaceId)
+	if err != nil {
+		return false, err
 	}
+	defer unlock()
 	filePath := filepath.Join(nsPath, metadataFile)
 	file, err := os.ReadFile(filePath)
 	if err != nil {
@@ -238,44 +247,254 @@ func (c *LocalClient) RenameNamespace(ctx context.Context, namespaceId string, n
 		return false, fmt.Errorf("json marshal failed: %s", err)
 	}
 
-	err = os.WriteFile(filePath, marshal, 0644)
-	if err != nil {
-		return false, fmt.Errorf("write file %s failed: %v", filePath, err)
+	if err = writeFile(filePath, marshal); err != nil {
+		return false, err
 	}
 	return true, nil
 }
 
 func (c *LocalClient) SetValue(ctx context.Context, req *models.SetValue) (bool, error) {
-	if req.Key == "INPUT" && req.NamespaceId == "default" {
+	if isInput(req.NamespaceId, req.Key) {
+		return false, nil
+	}
+	if _, err := keyPath(req.NamespaceId, req.Key); err != nil {
+		return false, err
+	}
+	unlock, err := lockNamespace(req.NamespaceId)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	if err = writeKey(newLocalValue(req)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CompareAndSwap sets the key to req.Value if it holds req.OldValue.
+func (c *LocalClient) CompareAndSwap(ctx context.Context, req *models.CompareAndSwap) (bool, error) {
+	if isInput(req.NamespaceId, req.Key) {
+		return false, nil
+	}
+	if _, err := keyPath(req.NamespaceId, req.Key); err != nil {
+		return false, err
+	}
+	unlock, err := lockNamespace(req.NamespaceId)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	kv, err := readKey(req.NamespaceId, req.Key)
+	if err != nil || kv == nil || kv.Value != req.OldValue {
+		return false, err
+	}
+	err = writeKey(newLocalValue(&models.SetValue{
+		NamespaceId: req.NamespaceId,
+		Key:         req.Key,
+		Value:       req.Value,
+		Expiration:  req.Expiration,
+	}))
+	return err == nil, err
+}
+
+// SetIfNotExists sets the key unless it holds a value that hasn't expired.
+func (c *LocalClient) SetIfNotExists(ctx context.Context, req *models.SetValue) (bool, error) {
+	if isInput(req.NamespaceId, req.Key) {
+		return false, nil
+	}
+	if _, err := keyPath(req.NamespaceId, req.Key); err != nil {
+		return false, err
+	}
+	unlock, err := lockNamespace(req.NamespaceId)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	kv, err := readKey(req.NamespaceId, req.Key)
+	if err != nil || kv != nil {
+		return false, err
+	}
+	err = writeKey(newLocalValue(req))
+	return err == nil, err
+}
+
+// Increment adds delta to the integer value of the key, keeping its expiry,
+// and returns the new value. A missing key counts as 0.
+func (c *LocalClient) Increment(ctx context.Context, namespaceId string, key string, delta int64) (int64, error) {
+	if isInput(namespaceId, key) {
+		return 0, fmt.Errorf("%w: key %s is read-only", errs.ErrInvalidArgument, key)
+	}
+	if _, err := keyPath(namespaceId, key); err != nil {
+		return 0, err
+	}
+	unlock, err := lockNamespace(namespaceId)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	kv, err := readKey(namespaceId, key)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	if kv == nil {
+		local := newLocalValue(&models.SetValue{NamespaceId: namespaceId, Key: key})
+		kv = &local
+	} else if n, err = strconv.ParseInt(kv.Value, 10, 64); err != nil {
+		return 0, fmt.Errorf("%w: value of key %s is not an integer", errs.ErrInvalidArgument, key)
+	}
+	n += delta
+	kv.Value = strconv.FormatInt(n, 10)
+	kv.Size = len(kv.Value)
+	if err = writeKey(*kv); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Touch resets the expiry of the key to expiration seconds from now. It
+// returns false if the key doesn't exist.
+func (c *LocalClient) Touch(ctx context.Context, namespaceId string, key string, expiration uint) (bool, error) {
+	if isInput(namespaceId, key) {
 		return false, nil
 	}
-	keyFile := fmt.Sprintf("%s.json", req.Key)
+	if _, err := keyPath(namespaceId, key); err != nil {
+		return false, err
+	}
+	unlock, err := lockNamespace(namespaceId)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	kv, err := readKey(namespaceId, key)
+	if err != nil || kv == nil {
+		return false, err
+	}
+	kv.Expiration = expiration
+	err = writeKey(newLocalValue(&kv.SetValue))
+	return err == nil, err
+}
+
+// GetWithMetadata returns the value of the key with its size and expiry.
+func (c *LocalClient) GetWithMetadata(ctx context.Context, namespaceId string, key string) (*models.KvValue, error) {
+	if !isDirExists(filepath.Join(storageDir, keyValueDir, namespaceId)) {
+		return nil, ErrResourceNotFound
+	}
+	if _, err := keyPath(namespaceId, key); err != nil {
+		return nil, err
+	}
+	kv, err := readKey(namespaceId, key)
+	if err != nil {
+		return nil, err
+	}
+	if kv == nil {
+		return nil, fmt.Errorf("key %s %w", key, errs.ErrNotFound)
+	}
+	return &models.KvValue{
+		Key:      kv.Key,
+		Value:    kv.Value,
+		Size:     kv.Size,
+		ExpireAt: kv.ExpireAt,
+	}, nil
+}
+
+// isInput reports whether the key is the input of the actor, which is
+// stored as is and can't be set.
+func isInput(namespaceId string, key string) bool {
+	return key == "INPUT" && namespaceId == "default"
+}
+
+// keyPath returns the path of the file of the key. Keys are escaped, so
+// hierarchical keys like "session/site/user" stay in the namespace directory.
+// Keys stored before they were escaped keep their unescaped file, which is
+// used as long as it exists.
+func keyPath(namespaceId string, key string) (string, error) {
+	keyFile := fmt.Sprintf("%s.json", url.PathEscape(key))
 	if keyFile == metadataFile {
-		return false, fmt.Errorf("key name can't use 'metadata'")
+		return "", fmt.Errorf("%w: key name can't use 'metadata'", errs.ErrInvalidArgument)
 	}
-	path := filepath.Join(storageDir, keyValueDir, req.NamespaceId)
-	file := filepath.Join(path, keyFile)
-	if req.Expiration == 0 {
-		req.Expiration = MaxExpireTime
+	nsPath := filepath.Join(storageDir, keyValueDir, namespaceId)
+	path := filepath.Join(nsPath, keyFile)
+	if legacyFile := key + ".json"; legacyFile != keyFile && legacyFile != metadataFile && !isFileExists(path) {
+		if legacy := filepath.Join(nsPath, legacyFile); filepath.Dir(legacy) == nsPath && isFileExists(legacy) {
+			return legacy, nil
+		}
 	}
-	local := models.SetValueLocal{
+	return path, nil
+}
+
+// keyName returns the key stored in the file name, without its extension:
+// the unescaped name, or the name itself for a key stored before keys were
+// escaped.
+func keyName(name string) string {
+	key, err := url.PathUnescape(name)
+	if err != nil || url.PathEscape(key) != name {
+		return name
+	}
+	return key
+}
+
+// lockNamespace takes the lock serializing the updates of the keys of the
+// namespace.
+func lockNamespace(namespaceId string) (func(), error) {
+	if !isDirExists(filepath.Join(storageDir, keyValueDir, namespaceId)) {
+		return nil, ErrResourceNotFound
+	}
+	return lockResource(keyValueDir, namespaceId)
+}
+
+func newLocalValue(req *models.SetValue) models.SetValueLocal {
+	expiration := req.Expiration
+	if expiration == 0 {
+		expiration = MaxExpireTime
+	}
+	return models.SetValueLocal{
 		SetValue: models.SetValue{
-			Expiration:  req.Expiration,
+			Expiration:  expiration,
 			Key:         req.Key,
 			Value:       req.Value,
 			NamespaceId: req.NamespaceId,
 		},
-		ExpireAt: time.Now().Add(time.Duration(req.Expiration) * time.Second),
+		ExpireAt: time.Now().Add(time.Duration(expiration) * time.Second),
 		Size:     len([]byte(req.Value)),
 	}
+}
 
-	marshal, err := json.Marshal(local)
+// readKey reads the key, or returns nil if it doesn't exist or has expired.
+func readKey(namespaceId string, key string) (*models.SetValueLocal, error) {
+	path, err := keyPath(namespaceId, key)
 	if err != nil {
-		return false, fmt.Errorf("json marshal failed: %s", err)
+		return nil, err
+	}
+	buff, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read file %s failed: %v", path, err)
 	}
-	err = os.WriteFile(file, marshal, os.ModePerm)
+	if isInput(namespaceId, key) {
+		return &models.SetValueLocal{
+			SetValue: models.SetValue{NamespaceId: namespaceId, Key: key, Value: string(buff)},
+			Size:     len(buff),
+		}, nil
+	}
+	var kv models.SetValueLocal
+	if err = json.Unmarshal(buff, &kv); err != nil {
+		return nil, fmt.Errorf("json unmarshal failed: %s", err)
+	}
+	if kv.ExpireAt.Before(time.Now()) {
+		return nil, nil
+	}
+	return &kv, nil
+}
 
-	return true, nil
+func writeKey(kv models.SetValueLocal) error {
+	path, err := keyPath(kv.NamespaceId, kv.Key)
+	if err != nil {
+		return err
+	}
+	return writeJSON(path, kv)
 }
 
 func (c *LocalClient) ListKeys(ctx context.Context, req *models.ListKeyInfo) (*models.KvKeys, error) {
@@ -295,6 +514,13 @@ func (c *LocalClient) ListKeys(ctx context.Context, req *models.ListKeyInfo) (*m
 		if err != nil {
 			return fmt.Errorf("read file %s failed: %v", path, err)
 		}
+		if d.Name() == inputJson && req.NamespaceId == defaultDir {
+			keys = append(keys, map[string]any{
+				"key":  "INPUT",
+				"size": len(kvFile),
+			})
+			return nil
+		}
 		var kv models.SetValueLocal
 		err = json.Unmarshal(kvFile, &kv)
 		if err != nil {
@@ -336,6 +562,54 @@ func (c *LocalClient) ListKeys(ctx context.Context, req *models.ListKeyInfo) (*m
 	return kvKeys, nil
 }
 
+// ScanKeys returns the keys of the namespace starting with req.Prefix, in
+// ascending order, after req.After.
+func (c *LocalClient) ScanKeys(ctx context.Context, req *models.ScanKeysRequest) (*models.KvScan, error) {
+	dirPath := filepath.Join(storageDir, keyValueDir, req.NamespaceId)
+	entries, err := os.ReadDir(dirPath)
+	if os.IsNotExist(err) {
+		return nil, ErrResourceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dir: %v", err)
+	}
+
+	var keys []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok || entry.Name() == metadataFile {
+			continue
+		}
+		key := keyName(name)
+		if !strings.HasPrefix(key, req.Prefix) || key <= req.After {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	scan := &models.KvScan{}
+	for _, key := range keys {
+		if int64(len(scan.Items)) == req.Limit {
+			scan.More = true
+			break
+		}
+		kv, err := readKey(req.NamespaceId, key)
+		if err != nil {
+			log.Warnf("read key %s failed: %v", key, err)
+			continue
+		}
+		if kv == nil {
+			continue
+		}
+		scan.Items = append(scan.Items, map[string]any{
+			"key":  key,
+			"size": kv.Size,
+		})
+	}
+	return scan, nil
+}
+
 func (c *LocalClient) BulkSetValue(ctx context.Context, req *models.BulkSet) (int64, error) {
 	var success int64
 	for i := range req.Items {
@@ -354,12 +628,16 @@ func (c *LocalClient) BulkSetValue(ctx context.Context, req *models.BulkSet) (in
 }
 
 func (c *LocalClient) DelValue(ctx context.Context, namespaceId string, key string) (bool, error) {
-	file := fmt.Sprintf("%s.json", key)
-	if file == metadataFile {
+	path, err := keyPath(namespaceId, key)
+	if err != nil {
 		return true, nil
 	}
-	path := filepath.Join(storageDir, keyValueDir, namespaceId, file)
-	err := os.Remove(path)
+	unlock, err := lockNamespace(namespaceId)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	err = os.Remove(path)
 	if err != nil {
 		return false, fmt.Errorf("delete file %s failed: %v", path, err)
 	}
@@ -382,8 +660,10 @@ func (c *LocalClient) GetValue(ctx context.Context, namespaceId string, key stri
 	if !isDirExists(namespacePath) {
 		return "", ErrResourceNotFound
 	}
-	file := fmt.Sprintf("%s.json", key)
-	path := filepath.Join(namespacePath, file)
+	path, err := keyPath(namespaceId, key)
+	if err != nil {
+		return "", err
+	}
 	buff, err := os.ReadFile(path)
 	if err != nil {
 		return "", fmt.Errorf("read file %s failed: %v", path, err)
@@ -0,0 +1,148 @@
+package storage_memory
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+func newNamespace(t *testing.T) string {
+	t.Helper()
+	id, err := local.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{Name: uuid.NewString()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _, _ = local.DelNamespace(ctx, id) })
+	return id
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	ns := newNamespace(t)
+	req := &models.CompareAndSwap{NamespaceId: ns, Key: "state", OldValue: "", Value: "running"}
+	if ok, err := local.CompareAndSwap(ctx, req); ok || err != nil {
+		t.Fatalf("CompareAndSwap on a missing key = %v, %v", ok, err)
+	}
+
+	set := &models.SetValue{NamespaceId: ns, Key: "state", Value: "idle"}
+	if ok, err := local.SetIfNotExists(ctx, set); !ok || err != nil {
+		t.Fatalf("SetIfNotExists = %v, %v", ok, err)
+	}
+	set.Value = "other"
+	if ok, err := local.SetIfNotExists(ctx, set); ok || err != nil {
+		t.Fatalf("SetIfNotExists on an existing key = %v, %v", ok, err)
+	}
+
+	req.OldValue = "idle"
+	if ok, err := local.CompareAndSwap(ctx, req); !ok || err != nil {
+		t.Fatalf("CompareAndSwap = %v, %v", ok, err)
+	}
+	if ok, err := local.CompareAndSwap(ctx, req); ok || err != nil {
+		t.Fatalf("second CompareAndSwap = %v, %v", ok, err)
+	}
+	if v, _ := local.GetValue(ctx, ns, "state"); v != "running" {
+		t.Errorf("value = %q, want running", v)
+	}
+}
+
+func TestIncrement(t *testing.T) {
+	ns := newNamespace(t)
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := local.Increment(ctx, ns, "count", 2); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n, err := local.Increment(ctx, ns, "count", -5); n != 35 || err != nil {
+		t.Fatalf("Increment = %d, %v, want 35", n, err)
+	}
+
+	_, _ = local.SetValue(ctx, &models.SetValue{NamespaceId: ns, Key: "name", Value: "x"})
+	if _, err := local.Increment(ctx, ns, "name", 1); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("Increment of a string = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestTouchAndMetadata(t *testing.T) {
+	ns := newNamespace(t)
+	_, _ = local.SetValue(ctx, &models.SetValue{NamespaceId: ns, Key: "session", Value: "abc", Expiration: 60})
+	v, err := local.GetWithMetadata(ctx, ns, "session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Value != "abc" || v.Size != 3 || time.Until(v.ExpireAt) > time.Minute {
+		t.Errorf("metadata = %+v", v)
+	}
+
+	if ok, err := local.Touch(ctx, ns, "session", 3600); !ok || err != nil {
+		t.Fatalf("Touch = %v, %v", ok, err)
+	}
+	v, _ = local.GetWithMetadata(ctx, ns, "session")
+	if left := time.Until(v.ExpireAt); left < 59*time.Minute || v.Value != "abc" {
+		t.Errorf("after Touch: %+v, expires in %s", v, left)
+	}
+
+	if ok, err := local.Touch(ctx, ns, "missing", 60); ok || err != nil {
+		t.Errorf("Touch of a missing key = %v, %v", ok, err)
+	}
+	if _, err = local.GetWithMetadata(ctx, ns, "missing"); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("GetWithMetadata of a missing key = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLegacyKeyFiles(t *testing.T) {
+	ns := newNamespace(t)
+	// Keys were stored unescaped before hierarchical keys were supported.
+	for _, key := range []string{"a b", "100%"} {
+		value := newLocalValue(&models.SetValue{NamespaceId: ns, Key: key, Value: "old " + key})
+		if err := writeJSON(filepath.Join(storageDir, keyValueDir, ns, key+".json"), value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, _ = local.SetValue(ctx, &models.SetValue{NamespaceId: ns, Key: "a/b", Value: "new"})
+
+	if v, err := local.GetValue(ctx, ns, "a b"); v != "old a b" || err != nil {
+		t.Errorf("GetValue(legacy) = %q, %v", v, err)
+	}
+	scan, err := local.ScanKeys(ctx, &models.ScanKeysRequest{NamespaceId: ns, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, item := range scan.Items {
+		keys = append(keys, item["key"].(string))
+	}
+	if want := []string{"100%", "a b", "a/b"}; !slices.Equal(keys, want) {
+		t.Errorf("ScanKeys() = %q, want %q", keys, want)
+	}
+
+	// Legacy files are updated and deleted in place.
+	if ok, err := local.SetValue(ctx, &models.SetValue{NamespaceId: ns, Key: "100%", Value: "updated"}); !ok || err != nil {
+		t.Fatalf("SetValue(legacy) = %v, %v", ok, err)
+	}
+	if v, err := local.GetValue(ctx, ns, "100%"); v != "updated" || err != nil {
+		t.Errorf("GetValue(updated) = %q, %v", v, err)
+	}
+	if _, err = local.DelValue(ctx, ns, "a b"); err != nil {
+		t.Fatal(err)
+	}
+	if kv, err := readKey(ns, "a b"); kv != nil || err != nil {
+		t.Errorf("readKey(deleted) = %+v, %v", kv, err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(storageDir, keyValueDir, ns))
+	if len(entries) != 3 {
+		t.Errorf("namespace holds %d files, want metadata and 2 keys", len(entries))
+	}
+}
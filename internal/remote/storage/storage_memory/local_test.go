@@ -5,7 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func init() {
@@ -19,6 +26,192 @@ var (
 	ctx         = context.Background()
 )
 
+// The tests after the concurrency ones depend on resources missing from a
+// fresh storage. The tests of the other features live in their own files, on
+// a temporary storage.
+
+func TestConcurrentGetMsg(t *testing.T) {
+	useTempStorage(t)
+	queue, err := local.CreateQueue(ctx, &models.CreateQueueRequest{Name: "concurrent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 50
+	for range n {
+		if _, err = local.CreateMsg(ctx, &models.CreateMsgRequest{QueueId: queue.Id, Timeout: 600, Deadline: time.Now().Unix() + 3600}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var mu sync.Mutex
+	leased := make(map[string]int)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				msgs, err := local.GetMsg(ctx, &models.GetMsgRequest{QueueId: queue.Id, Limit: 3})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if len(*msgs) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, msg := range *msgs {
+					leased[msg.ID]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(leased) != n {
+		t.Errorf("leased %d msgs, want %d", len(leased), n)
+	}
+	for id, count := range leased {
+		if count != 1 {
+			t.Errorf("msg %s leased %d times", id, count)
+		}
+	}
+}
+
+func TestConcurrentAddDatasetItem(t *testing.T) {
+	useTempStorage(t)
+	dataset, err := local.CreateDataset(ctx, &models.CreateDatasetRequest{Name: "concurrent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 10 {
+				items := []map[string]any{{"g": g, "i": i}, {"g": g, "i": i, "second": true}}
+				if _, err := local.AddDatasetItem(ctx, dataset.Id, items); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assertDatasetCount(t, dataset.Id, 160)
+}
+
+// assertDatasetCount checks that the dataset holds want items, counted in its
+// metadata as well.
+func assertDatasetCount(t *testing.T, datasetId string, want int) {
+	t.Helper()
+	items, err := local.GetDataset(ctx, &models.GetDataset{DatasetId: datasetId, Page: 1, PageSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items.Total != want {
+		t.Errorf("dataset holds %d items, want %d", items.Total, want)
+	}
+	var meta models.Dataset
+	if err = readJSON(filepath.Join(storageDir, datasetDir, datasetId, metadataFile), &meta); err != nil {
+		t.Fatal(err)
+	}
+	if meta.Stats.Count != uint64(want) {
+		t.Errorf("metadata counts %d items, want %d", meta.Stats.Count, want)
+	}
+}
+
+func TestConcurrentReadWrite(t *testing.T) {
+	useTempStorage(t)
+	ns, err := local.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{Name: "concurrent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	large := strings.Repeat("x", 1<<20)
+	if _, err = local.SetValue(ctx, &models.SetValue{NamespaceId: ns, Key: "k", Value: large}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 20 {
+			value := strings.Repeat(strconv.Itoa(i%10), 1<<20)
+			if _, err := local.SetValue(ctx, &models.SetValue{NamespaceId: ns, Key: "k", Value: value}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	// Readers never see a partially written file.
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		value, err := local.GetValue(ctx, ns, "k")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(value) != len(large) {
+			t.Fatalf("read a value of %d bytes", len(value))
+		}
+	}
+}
+
+// TestMultiProcess adds items to a dataset from this process and a child
+// process running TestHelperProcess.
+func TestMultiProcess(t *testing.T) {
+	if os.Getenv("STORAGE_HELPER_DIR") != "" {
+		t.Skip("helper process")
+	}
+	useTempStorage(t)
+	dataset, err := local.CreateDataset(ctx, &models.CreateDatasetRequest{Name: "multi-process"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$", "-test.count=1")
+	cmd.Env = append(os.Environ(), "STORAGE_HELPER_DIR="+storageDir, "STORAGE_HELPER_DATASET="+dataset.Id)
+	var out strings.Builder
+	cmd.Stdout, cmd.Stderr = &out, &out
+	if err = cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	addItems(t, dataset.Id)
+	if err = cmd.Wait(); err != nil {
+		t.Fatalf("helper process: %v\n%s", err, out.String())
+	}
+	assertDatasetCount(t, dataset.Id, 2*4*25)
+}
+
+func TestHelperProcess(t *testing.T) {
+	dir := os.Getenv("STORAGE_HELPER_DIR")
+	if dir == "" {
+		t.Skip("run by TestMultiProcess")
+	}
+	storageDir = dir
+	addItems(t, os.Getenv("STORAGE_HELPER_DATASET"))
+}
+
+// addItems adds 4*25 items to the dataset from 4 goroutines.
+func addItems(t *testing.T, datasetId string) {
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 25 {
+				if _, err := local.AddDatasetItem(ctx, datasetId, []map[string]any{{"pid": os.Getpid(), "g": g, "i": i}}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 func TestAddDataset(t *testing.T) {
 	maps := []map[string]interface{}{
 		{"name": "hq", "sex": "man", "age": "18"},
@@ -59,7 +252,7 @@ func TestGetItems(t *testing.T) {
 		PageSize:  2,
 	})
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 	marshal, _ := json.Marshal(items.Items)
 	fmt.Println(string(marshal))
@@ -265,7 +458,7 @@ func TestGetMsg(t *testing.T) {
 		Limit:   3,
 	})
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
 	for i := 0; i < len(*resp); i++ {
 		fmt.Println((*resp)[i])
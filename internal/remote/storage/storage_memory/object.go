@@ -1,38 +1,376 @@
 package storage_memory
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 )
 
+// Objects are stored as storage/objects_stores/<bucketId>/<objectId>/<filename>,
+// next to a metadata.json describing the object. Each bucket directory holds
+// its own metadata.json as well.
+
 func (c *LocalClient) ListBuckets(ctx context.Context, page, size int) (*models.Object, error) {
-	return nil, ErrLocalStorageUnimplemented
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+	dirPath := filepath.Join(storageDir, objectDir)
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dir: %v", err)
+	}
+
+	var buckets []models.Bucket
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		bucket, err := readBucket(entry.Name())
+		if err != nil {
+			continue
+		}
+		buckets = append(buckets, *bucket)
+	}
+	sort.SliceStable(buckets, func(i, j int) bool {
+		return buckets[i].CreatedAt < buckets[j].CreatedAt
+	})
+
+	total := int64(len(buckets))
+	start := min(int64(page-1)*int64(size), total)
+	end := min(start+int64(size), total)
+	pagedItems := buckets[start:end]
+	for i := range pagedItems {
+		objects, err := readObjects(pagedItems[i].Id)
+		if err != nil {
+			return nil, err
+		}
+		pagedItems[i].Size = objectsSize(objects)
+	}
+
+	return &models.Object{
+		Buckets:   pagedItems,
+		Total:     total,
+		TotalPage: totalPage(total, int64(size)),
+		Page:      int64(page),
+		PageSize:  int64(size),
+	}, nil
 }
 
 func (c *LocalClient) CreateBucket(ctx context.Context, req *models.CreateBucketRequest) (string, error) {
-	return "", ErrLocalStorageUnimplemented
+	unlock, err := lockCategory(objectDir)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	exists, err := isNameExists(filepath.Join(storageDir, objectDir), req.Name)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", fmt.Errorf("bucket %s %w", req.Name, errs.ErrAlreadyExists)
+	}
+
+	id := uuid.NewString()
+	path := filepath.Join(storageDir, objectDir, id)
+	if err = os.MkdirAll(path, os.ModePerm); err != nil {
+		return "", fmt.Errorf("create bucket failed, cause: %v", err)
+	}
+	now := time.Now().Format(time.RFC3339)
+	bucket := &models.Bucket{
+		Id:          id,
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		ActorId:     req.ActorId,
+		RunId:       req.RunId,
+	}
+	if err = writeJSON(filepath.Join(path, metadataFile), bucket); err != nil {
+		return "", err
+	}
+	return id, nil
 }
 
 func (c *LocalClient) DeleteBucket(ctx context.Context, bucketId string) (bool, error) {
-	return false, ErrLocalStorageUnimplemented
+	path := filepath.Join(storageDir, objectDir, bucketId)
+	if !isDirExists(path) {
+		return false, ErrResourceNotFound
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return false, fmt.Errorf("delete bucket failed, cause: %v", err)
+	}
+	return true, nil
 }
 
 func (c *LocalClient) GetBucket(ctx context.Context, bucketId string) (*models.Bucket, error) {
-	return nil, ErrLocalStorageUnimplemented
+	bucket, err := readBucket(bucketId)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := readObjects(bucketId)
+	if err != nil {
+		return nil, err
+	}
+	bucket.Size = objectsSize(objects)
+	return bucket, nil
 }
 
+// ListObjects lists the objects of a bucket whose filename contains req.Search,
+// ignoring case.
 func (c *LocalClient) ListObjects(ctx context.Context, req *models.ListObjectsRequest) (*models.ObjectList, error) {
-	return nil, ErrLocalStorageUnimplemented
+	page, pageSize := req.Page, req.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if !isDirExists(filepath.Join(storageDir, objectDir, req.BucketId)) {
+		return nil, ErrResourceNotFound
+	}
+	objects, err := readObjects(req.BucketId)
+	if err != nil {
+		return nil, err
+	}
+
+	search := strings.ToLower(req.Search)
+	matched := make([]models.BucketObject, 0, len(objects))
+	for _, object := range objects {
+		if strings.Contains(strings.ToLower(object.Filename), search) {
+			matched = append(matched, object)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].CreatedAt < matched[j].CreatedAt
+	})
+
+	total := int64(len(matched))
+	start := min((page-1)*pageSize, total)
+	end := min(start+pageSize, total)
+	return &models.ObjectList{
+		Objects:   matched[start:end],
+		Total:     total,
+		TotalPage: totalPage(total, pageSize),
+		Page:      page,
+		PageSize:  pageSize,
+	}, nil
 }
 
 func (c *LocalClient) GetObject(ctx context.Context, req *models.ObjectRequest) ([]byte, error) {
-	return nil, ErrLocalStorageUnimplemented
+	object, err := readObject(req.BucketId, req.ObjectId)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(storageDir, objectDir, req.BucketId, req.ObjectId, object.Filename)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file %s failed: %v", path, err)
+	}
+	return data, nil
 }
 
 func (c *LocalClient) DeleteObject(ctx context.Context, req *models.ObjectRequest) (bool, error) {
-	return false, ErrLocalStorageUnimplemented
+	path := filepath.Join(storageDir, objectDir, req.BucketId, req.ObjectId)
+	if req.ObjectId == "" || !isDirExists(path) {
+		return false, ErrResourceNotFound
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return false, fmt.Errorf("delete object failed, cause: %v", err)
+	}
+	return true, nil
 }
 
 func (c *LocalClient) PutObject(ctx context.Context, req *models.PutObjectRequest) (string, error) {
-	return "", ErrLocalStorageUnimplemented
+	return c.PutObjectStream(ctx, &models.PutObjectStreamRequest{
+		BucketId: req.BucketId,
+		Filename: req.Filename,
+		Reader:   bytes.NewReader(req.Data),
+		Size:     int64(len(req.Data)),
+		ActorId:  req.ActorId,
+		RunId:    req.RunId,
+	})
+}
+
+// PutObjectStream copies req.Reader to the object file. When req.Size is known,
+// an object of another size is rejected.
+func (c *LocalClient) PutObjectStream(ctx context.Context, req *models.PutObjectStreamRequest) (string, error) {
+	filename := filepath.Base(req.Filename)
+	if filename == "." || filename == string(filepath.Separator) {
+		return "", fmt.Errorf("%w: filename is required", errs.ErrInvalidArgument)
+	}
+	if filename == metadataFile {
+		return "", fmt.Errorf("%w: filename can't be '%s'", errs.ErrInvalidArgument, metadataFile)
+	}
+	bucketPath := filepath.Join(storageDir, objectDir, req.BucketId)
+	if req.BucketId == "" || !isDirExists(bucketPath) {
+		return "", ErrResourceNotFound
+	}
+
+	id := uuid.NewString()
+	path := filepath.Join(bucketPath, id)
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return "", fmt.Errorf("create object failed, cause: %v", err)
+	}
+	size, err := writeObjectFile(ctx, filepath.Join(path, filename), req.Reader)
+	if err == nil && req.Size >= 0 && size != req.Size {
+		err = fmt.Errorf("%w: read %d bytes, expected %d", errs.ErrInvalidArgument, size, req.Size)
+	}
+	if err != nil {
+		os.RemoveAll(path)
+		return "", err
+	}
+	now := time.Now().Format(time.RFC3339)
+	object := &models.BucketObject{
+		Id:        id,
+		Path:      filepath.Join(objectDir, req.BucketId, id, filename),
+		Size:      int(size),
+		Filename:  filename,
+		BucketId:  req.BucketId,
+		ActorId:   req.ActorId,
+		RunId:     req.RunId,
+		FileType:  strings.TrimPrefix(filepath.Ext(filename), "."),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := writeJSON(filepath.Join(path, metadataFile), object); err != nil {
+		os.RemoveAll(path)
+		return "", err
+	}
+	return id, nil
+}
+
+// GetObjectStream opens the object file, positioned at req.Offset.
+func (c *LocalClient) GetObjectStream(ctx context.Context, req *models.GetObjectStreamRequest) (io.ReadCloser, error) {
+	object, err := readObject(req.BucketId, req.ObjectId)
+	if err != nil {
+		return nil, err
+	}
+	if req.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", errs.ErrInvalidArgument)
+	}
+	path := filepath.Join(storageDir, objectDir, req.BucketId, req.ObjectId, object.Filename)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open file %s failed: %v", path, err)
+	}
+	if _, err = file.Seek(req.Offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("seek file %s failed: %v", path, err)
+	}
+	return file, nil
+}
+
+// writeObjectFile copies r to a new file at path and returns the number of
+// bytes written. The copy stops when ctx is done.
+func writeObjectFile(ctx context.Context, path string, r io.Reader) (int64, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("create file %s failed: %v", path, err)
+	}
+	size, err := io.Copy(file, &ctxReader{ctx: ctx, r: r})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return size, fmt.Errorf("write file %s failed: %w", path, err)
+	}
+	return size, nil
+}
+
+// ctxReader stops reading from r once ctx is done.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+func readBucket(bucketId string) (*models.Bucket, error) {
+	path := filepath.Join(storageDir, objectDir, bucketId)
+	if bucketId == "" || !isDirExists(path) {
+		return nil, ErrResourceNotFound
+	}
+	var bucket models.Bucket
+	if err := readJSON(filepath.Join(path, metadataFile), &bucket); err != nil {
+		return nil, err
+	}
+	return &bucket, nil
+}
+
+func readObject(bucketId, objectId string) (*models.BucketObject, error) {
+	path := filepath.Join(storageDir, objectDir, bucketId, objectId)
+	if bucketId == "" || objectId == "" || !isDirExists(path) {
+		return nil, ErrResourceNotFound
+	}
+	var object models.BucketObject
+	if err := readJSON(filepath.Join(path, metadataFile), &object); err != nil {
+		return nil, err
+	}
+	return &object, nil
+}
+
+// readObjects returns the metadata of all objects in a bucket.
+func readObjects(bucketId string) ([]models.BucketObject, error) {
+	dirPath := filepath.Join(storageDir, objectDir, bucketId)
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dir: %v", err)
+	}
+	objects := make([]models.BucketObject, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		var object models.BucketObject
+		if err = readJSON(filepath.Join(dirPath, entry.Name(), metadataFile), &object); err != nil {
+			continue
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+func objectsSize(objects []models.BucketObject) int {
+	size := 0
+	for _, object := range objects {
+		size += object.Size
+	}
+	return size
+}
+
+func readJSON(path string, v any) error {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read file %s failed: %v", path, err)
+	}
+	if err = json.Unmarshal(buf, v); err != nil {
+		return fmt.Errorf("json unmarshal failed: %s", err)
+	}
+	return nil
+}
+
+func writeJSON(path string, v any) error {
+	marshal, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("json marshal failed: %s", err)
+	}
+	return writeFile(path, marshal)
 }
@@ -0,0 +1,93 @@
+package storage_memory
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+func TestObject(t *testing.T) {
+	useTempStorage(t)
+	bucketId, err := local.CreateBucket(ctx, &models.CreateBucketRequest{
+		Name: "test-bucket-" + uuid.NewString(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logoId, err := local.PutObject(ctx, &models.PutObjectRequest{BucketId: bucketId, Filename: "Logo.png", Data: []byte("png")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = local.PutObject(ctx, &models.PutObjectRequest{BucketId: bucketId, Filename: "page.html", Data: []byte("<html></html>")}); err != nil {
+		t.Fatal(err)
+	}
+
+	objects, err := local.ListObjects(ctx, &models.ListObjectsRequest{BucketId: bucketId, Search: "logo", Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objects.Total != 1 || objects.Objects[0].Id != logoId || objects.Objects[0].FileType != "png" {
+		t.Fatalf("unexpected objects %+v", objects)
+	}
+	bucket, err := local.GetBucket(ctx, bucketId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bucket.Size != len("png")+len("<html></html>") {
+		t.Fatalf("unexpected bucket size %d", bucket.Size)
+	}
+
+	data, err := local.GetObject(ctx, &models.ObjectRequest{BucketId: bucketId, ObjectId: logoId})
+	if err != nil || string(data) != "png" {
+		t.Fatalf("get object: %q, %v", data, err)
+	}
+	if ok, err := local.DeleteObject(ctx, &models.ObjectRequest{BucketId: bucketId, ObjectId: logoId}); !ok || err != nil {
+		t.Fatalf("delete object: %v", err)
+	}
+	if _, err = local.GetObject(ctx, &models.ObjectRequest{BucketId: bucketId, ObjectId: logoId}); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestObjectStream(t *testing.T) {
+	useTempStorage(t)
+	bucketId, err := local.CreateBucket(ctx, &models.CreateBucketRequest{
+		Name: "test-bucket-" + uuid.NewString(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := strings.Repeat("pdf", 1000)
+	if _, err = local.PutObjectStream(ctx, &models.PutObjectStreamRequest{
+		BucketId: bucketId, Filename: "short.pdf", Reader: strings.NewReader(content), Size: 1,
+	}); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+	objectId, err := local.PutObjectStream(ctx, &models.PutObjectStreamRequest{
+		BucketId: bucketId, Filename: "doc.pdf", Reader: strings.NewReader(content), Size: -1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := local.GetObjectStream(ctx, &models.GetObjectStreamRequest{BucketId: bucketId, ObjectId: objectId, Offset: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil || string(data) != content[3:] {
+		t.Fatalf("get object stream: %d bytes, %v", len(data), err)
+	}
+	objects, err := local.ListObjects(ctx, &models.ListObjectsRequest{BucketId: bucketId})
+	if err != nil || objects.Total != 1 || objects.Objects[0].Size != len(content) {
+		t.Fatalf("unexpected objects %+v, %v", objects, err)
+	}
+}
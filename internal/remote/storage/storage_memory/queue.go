@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -16,12 +17,20 @@ import (
 
 func (c *LocalClient) CreateQueue(ctx context.Context, req *models.CreateQueueRequest) (*models.CreateQueueResponse, error) {
 	id := uuid.NewString()
+	unlock, err := lockCategory(queueDir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 	exists, err := isNameExists(filepath.Join(storageDir, queueDir), req.Name)
 	if err != nil {
 		return nil, err
 	}
 	if exists {
-		return nil, fmt.Errorf("queue %s already exists", req.Name)
+		return nil, fmt.Errorf("queue %s %w", req.Name, errs.ErrAlreadyExists)
+	}
+	if req.DeadLetterQueueId != "" && !isDirExists(filepath.Join(storageDir, queueDir, req.DeadLetterQueueId)) {
+		return nil, fmt.Errorf("%w: dead-letter queue %s not found", errs.ErrInvalidArgument, req.DeadLetterQueueId)
 	}
 
 	path := filepath.Join(storageDir, queueDir, id)
@@ -36,6 +45,8 @@ func (c *LocalClient) CreateQueue(ctx context.Context, req *models.CreateQueueRe
 		RunId:       req.RunId,
 		ActorId:     req.ActorId,
 		CreatedAt:   time.Now().Format(time.RFC3339Nano),
+
+		DeadLetterQueueId: req.DeadLetterQueueId,
 	}
 
 	if err = c.updateMetadata(queue); err != nil {
@@ -136,6 +147,11 @@ func (c *LocalClient) UpdateQueue(ctx context.Context, req *models.UpdateQueueRe
 	if !ok {
 		return nil
 	}
+	unlock, err := lockResource(queueDir, req.QueueId)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	metaPath := filepath.Join(queuePath, metadataFile)
 	buf, err := os.ReadFile(metaPath)
@@ -166,7 +182,7 @@ func (c *LocalClient) DelQueue(ctx context.Context, req *models.DelQueueRequest)
 func (c *LocalClient) CreateMsg(ctx context.Context, req *models.CreateMsgRequest) (*models.CreateMsgResponse, error) {
 	id := uuid.NewString()
 	if req.Deadline < time.Now().Unix()+300 {
-		return nil, fmt.Errorf("deadline must after now + 300s")
+		return nil, fmt.Errorf("%w: deadline must after now + 300s", errs.ErrInvalidArgument)
 	}
 	queuePath := filepath.Join(storageDir, queueDir, req.QueueId)
 	if !isDirExists(queuePath) {
@@ -191,7 +207,7 @@ func (c *LocalClient) CreateMsg(ctx context.Context, req *models.CreateMsgReques
 		return nil, fmt.Errorf("json marshal failed: %s", err)
 	}
 
-	if err = os.WriteFile(msgPath, marshal, os.ModePerm); err != nil {
+	if err = writeFile(msgPath, marshal); err != nil {
 		return nil, err
 	}
 	return &models.CreateMsgResponse{
@@ -201,10 +217,20 @@ func (c *LocalClient) CreateMsg(ctx context.Context, req *models.CreateMsgReques
 
 func (c *LocalClient) GetMsg(ctx context.Context, req *models.GetMsgRequest) (*models.GetMsgResponse, error) {
 	queuePath := filepath.Join(storageDir, queueDir, req.QueueId)
+	// Pulling leases the messages: concurrent pulls must not lease the same.
+	unlock, err := lockResource(queueDir, req.QueueId)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
+	var queue models.Queue
+	if err := readJSON(filepath.Join(queuePath, metadataFile), &queue); err != nil {
+		return nil, err
+	}
 	msgs := make([]*models.MsgLocal, 0)
 	now := time.Now()
-	err := filepath.WalkDir(queuePath, func(path string, d fs.DirEntry, err error) error {
+	err = filepath.WalkDir(queuePath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -222,11 +248,18 @@ func (c *LocalClient) GetMsg(ctx context.Context, req *models.GetMsgRequest) (*m
 			return fmt.Errorf("json unmarshal failed: %s", err)
 		}
 		// msg is finished
-		if msg.SuccessAt > 0 || msg.FailedAt > 0 || msg.Deadline < now.Unix() ||
-			(msg.ReenterTime.Before(now) && msg.Retried >= msg.Retry) {
+		if msg.SuccessAt > 0 || msg.FailedAt > 0 {
 			_ = os.Remove(msgPath)
 			return nil
 		}
+		// msg failed for good; a msg is always delivered at least once
+		if msg.Deadline < now.Unix() || (msg.Retried > 0 && msg.ReenterTime.Before(now) && msg.Retried >= msg.Retry) {
+			reason := "retries exhausted"
+			if msg.Deadline < now.Unix() {
+				reason = "deadline exceeded"
+			}
+			return c.deadLetter(&queue, msgPath, &msg, reason)
+		}
 		// msg is not reenter queue
 		if !msg.ReenterTime.Equal(time.Time{}) && msg.ReenterTime.After(now) {
 			return nil
@@ -255,8 +288,8 @@ func (c *LocalClient) GetMsg(ctx context.Context, req *models.GetMsgRequest) (*m
 		if err != nil {
 			return nil, fmt.Errorf("json marshal failed: %s", err)
 		}
-		if err = os.WriteFile(msgPath, marshal, os.ModePerm); err != nil {
-			return nil, fmt.Errorf("write file %s failed: %v", msgPath, err)
+		if err = writeFile(msgPath, marshal); err != nil {
+			return nil, err
 		}
 
 		respMsg = append(respMsg, &models.Msg{
@@ -271,6 +304,9 @@ func (c *LocalClient) GetMsg(ctx context.Context, req *models.GetMsgRequest) (*m
 			SuccessAt: msg.SuccessAt,
 			FailedAt:  msg.FailedAt,
 			Desc:      msg.Desc,
+
+			FailedReason:  msg.FailedReason,
+			SourceQueueId: msg.SourceQueueId,
 		})
 	}
 	resp := models.GetMsgResponse(respMsg)
@@ -278,6 +314,11 @@ func (c *LocalClient) GetMsg(ctx context.Context, req *models.GetMsgRequest) (*m
 }
 
 func (c *LocalClient) AckMsg(ctx context.Context, req *models.AckMsgRequest) error {
+	unlock, err := lockResource(queueDir, req.QueueId)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	msgPath := filepath.Join(storageDir, queueDir, req.QueueId, fmt.Sprintf("%s.json", req.MsgId))
 	if !isFileExists(msgPath) {
 		return ErrResourceNotFound
@@ -306,11 +347,160 @@ func (c *LocalClient) AckMsg(ctx context.Context, req *models.AckMsgRequest) err
 	return nil
 }
 
+// NackMsg returns a pulled message to the queue, to be pulled again after
+// req.Delay seconds. A message without retries left isn't delivered again:
+// the first pull after the delay dead-letters it.
+func (c *LocalClient) NackMsg(ctx context.Context, req *models.NackMsgRequest) error {
+	unlock, err := lockResource(queueDir, req.QueueId)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	msgPath := filepath.Join(storageDir, queueDir, req.QueueId, fmt.Sprintf("%s.json", req.MsgId))
+	if !isFileExists(msgPath) {
+		return ErrResourceNotFound
+	}
+	var msg models.MsgLocal
+	if err := readJSON(msgPath, &msg); err != nil {
+		return err
+	}
+	if msg.ReenterTime.Equal(time.Time{}) {
+		return ErrResourceNotFound
+	}
+	if req.Delay < 0 {
+		return fmt.Errorf("%w: delay must not be negative", errs.ErrInvalidArgument)
+	}
+	now := time.Now()
+	if msg.ReenterTime.Before(now) {
+		return errors.New("msg is timeout, you must nack within the timeout period")
+	}
+	msg.ReenterTime = now.Add(time.Duration(req.Delay) * time.Second)
+	msg.UpdateTime = now
+	return writeJSON(msgPath, &msg)
+}
+
+// deadLetter moves the failed message at msgPath to the dead-letter queue of
+// queue, or deletes it when queue has none. The caller holds the lock of
+// queue; the lock of the dead-letter queue is taken after it. A dead-letter
+// queue exists before the queues using it, so the locks are always taken in
+// the same order.
+func (c *LocalClient) deadLetter(queue *models.Queue, msgPath string, msg *models.MsgLocal, reason string) error {
+	dlqPath := filepath.Join(storageDir, queueDir, queue.DeadLetterQueueId)
+	if queue.DeadLetterQueueId != "" && isDirExists(dlqPath) {
+		unlock, err := lockResource(queueDir, queue.DeadLetterQueueId)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		now := time.Now()
+		dead := models.MsgLocal{
+			Msg: models.Msg{
+				ID:            msg.ID,
+				QueueID:       queue.DeadLetterQueueId,
+				Name:          msg.Name,
+				Payload:       msg.Payload,
+				Timeout:       msg.Timeout,
+				Deadline:      max(msg.Deadline, now.Unix()+86400),
+				Retry:         msg.Retry,
+				Desc:          msg.Desc,
+				FailedReason:  reason,
+				SourceQueueId: msg.QueueID,
+			},
+			UpdateTime: now,
+		}
+		if err := writeJSON(filepath.Join(dlqPath, fmt.Sprintf("%s.json", msg.ID)), &dead); err != nil {
+			return err
+		}
+	}
+	_ = os.Remove(msgPath)
+	return nil
+}
+
+// RenewMsg extends the lease of a pulled message by its timeout.
+func (c *LocalClient) RenewMsg(ctx context.Context, req *models.RenewMsgRequest) error {
+	unlock, err := lockResource(queueDir, req.QueueId)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	msgPath := filepath.Join(storageDir, queueDir, req.QueueId, fmt.Sprintf("%s.json", req.MsgId))
+	if !isFileExists(msgPath) {
+		return ErrResourceNotFound
+	}
+	var msg models.MsgLocal
+	if err := readJSON(msgPath, &msg); err != nil {
+		return err
+	}
+	now := time.Now()
+	if msg.ReenterTime.Equal(time.Time{}) {
+		return ErrResourceNotFound
+	}
+	if msg.ReenterTime.Before(now) {
+		return errors.New("msg is timeout, you must renew within the timeout period")
+	}
+	msg.ReenterTime = now.Add(time.Duration(msg.Timeout) * time.Second)
+	return writeJSON(msgPath, &msg)
+}
+
 func (c *LocalClient) updateMetadata(queue *models.Queue) error {
 	path := filepath.Join(storageDir, queueDir, queue.Id, metadataFile)
 	marshal, err := json.Marshal(queue)
 	if err != nil {
 		return fmt.Errorf("json marshal failed: %s", err)
 	}
-	return os.WriteFile(path, marshal, os.ModePerm)
+	return writeFile(path, marshal)
+}
+
+// ListMsgs lists the messages of the queue not acked yet, leased or not.
+func (c *LocalClient) ListMsgs(ctx context.Context, req *models.ListMsgsRequest) (*models.ListMsgsResponse, error) {
+	queuePath := filepath.Join(storageDir, queueDir, req.QueueId)
+	if req.QueueId == "" || !isDirExists(queuePath) {
+		return nil, ErrResourceNotFound
+	}
+	entries, err := os.ReadDir(queuePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dir: %v", err)
+	}
+	var msgs []*models.MsgLocal
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == metadataFile {
+			continue
+		}
+		var msg models.MsgLocal
+		if err := readJSON(filepath.Join(queuePath, entry.Name()), &msg); err != nil {
+			// Acked meanwhile.
+			continue
+		}
+		if msg.SuccessAt > 0 || msg.FailedAt > 0 {
+			continue
+		}
+		msgs = append(msgs, &msg)
+	}
+	sort.SliceStable(msgs, func(i, j int) bool {
+		return msgDue(msgs[i]).Before(msgDue(msgs[j]))
+	})
+
+	total := int64(len(msgs))
+	start := min(max(req.Page-1, 0)*max(req.PageSize, 0), total)
+	end := min(start+max(req.PageSize, 0), total)
+	items := make([]*models.Msg, 0, end-start)
+	for _, msg := range msgs[start:end] {
+		items = append(items, &msg.Msg)
+	}
+	return &models.ListMsgsResponse{
+		Items:     items,
+		Total:     total,
+		TotalPage: totalPage(total, req.PageSize),
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+	}, nil
+}
+
+// msgDue returns when msg can be pulled: from its last update while
+// pending, when its lease ends once pulled.
+func msgDue(msg *models.MsgLocal) time.Time {
+	if !msg.ReenterTime.Equal(time.Time{}) {
+		return msg.ReenterTime
+	}
+	return msg.UpdateTime
 }
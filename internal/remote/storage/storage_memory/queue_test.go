@@ -0,0 +1,62 @@
+package storage_memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+func TestNackAndDeadLetter(t *testing.T) {
+	useTempStorage(t)
+	dlq, err := local.CreateQueue(ctx, &models.CreateQueueRequest{Name: "test-dlq-" + uuid.NewString()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	queue, err := local.CreateQueue(ctx, &models.CreateQueueRequest{Name: "test-queue-" + uuid.NewString(), DeadLetterQueueId: dlq.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := local.CreateMsg(ctx, &models.CreateMsgRequest{
+		QueueId:  queue.Id,
+		PayLoad:  "payload",
+		Retry:    2,
+		Timeout:  60,
+		Deadline: time.Now().Unix() + 400,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for attempt := 1; attempt <= 2; attempt++ {
+		msgs, err := local.GetMsg(ctx, &models.GetMsgRequest{QueueId: queue.Id, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(*msgs) != 1 || (*msgs)[0].ID != msg.MsgId {
+			t.Fatalf("attempt %d: unexpected msgs %+v", attempt, *msgs)
+		}
+		if err = local.NackMsg(ctx, &models.NackMsgRequest{QueueId: queue.Id, MsgId: msg.MsgId}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msgs, err := local.GetMsg(ctx, &models.GetMsgRequest{QueueId: queue.Id, Limit: 10})
+	if err != nil || len(*msgs) != 0 {
+		t.Fatalf("exhausted msg still delivered: %+v, %v", msgs, err)
+	}
+	dead, err := local.GetMsg(ctx, &models.GetMsgRequest{QueueId: dlq.Id, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*dead) != 1 || (*dead)[0].FailedReason != "retries exhausted" || (*dead)[0].SourceQueueId != queue.Id || (*dead)[0].Payload != "payload" {
+		t.Fatalf("unexpected dead-lettered msgs %+v", *dead)
+	}
+
+	if _, err = local.CreateQueue(ctx, &models.CreateQueueRequest{Name: "test-queue-" + uuid.NewString(), DeadLetterQueueId: "missing"}); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+}
@@ -2,49 +2,364 @@ package storage_memory
 
 import (
 	"context"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/vector"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
 )
 
+// Collections are stored as storage/vectors_stores/<collId>/metadata.json and
+// storage/vectors_stores/<collId>/docs.json, the latter holding all documents
+// keyed by id. They are searched with the vector package.
+
+const docsFile = "docs.json"
+
 func (c *LocalClient) ListCollections(ctx context.Context, req *models.ListCollectionsRequest) (*models.ListCollectionsResponse, error) {
-	return nil, ErrLocalStorageUnimplemented
+	page, pageSize := req.Page, req.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	dirPath := filepath.Join(storageDir, vectorDir)
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dir: %v", err)
+	}
+
+	var collections []models.Collection
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		coll, err := readCollection(entry.Name())
+		if err != nil {
+			continue
+		}
+		collections = append(collections, *coll)
+	}
+	sort.SliceStable(collections, func(i, j int) bool {
+		if req.Desc {
+			return collections[i].CreatedAt.After(collections[j].CreatedAt)
+		}
+		return collections[i].CreatedAt.Before(collections[j].CreatedAt)
+	})
+
+	total := int64(len(collections))
+	start := min((page-1)*pageSize, total)
+	end := min(start+pageSize, total)
+	pagedItems := collections[start:end]
+	for i := range pagedItems {
+		if pagedItems[i].Stats, err = collectionStats(pagedItems[i].Id); err != nil {
+			return nil, err
+		}
+	}
+
+	return &models.ListCollectionsResponse{
+		Items:     pagedItems,
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+		TotalPage: totalPage(total, pageSize),
+	}, nil
 }
 
 func (c *LocalClient) CreateCollections(ctx context.Context, req *models.CreateCollectionRequest) (*models.CreateCollectionResponse, error) {
-	return nil, ErrLocalStorageUnimplemented
+	metric, err := vector.NormalizeMetric(req.Metric)
+	if err != nil {
+		return nil, err
+	}
+	if req.Dimension < 0 {
+		return nil, fmt.Errorf("%w: dimension must not be negative", errs.ErrInvalidArgument)
+	}
+	unlock, err := lockCategory(vectorDir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	exists, err := isNameExists(filepath.Join(storageDir, vectorDir), req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("collection %s %w", req.Name, errs.ErrAlreadyExists)
+	}
+
+	id := uuid.NewString()
+	path := filepath.Join(storageDir, vectorDir, id)
+	if err = os.MkdirAll(path, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("create collection failed, cause: %v", err)
+	}
+	now := time.Now()
+	coll := models.Collection{
+		Id:          id,
+		Name:        req.Name,
+		ActorId:     req.ActorId,
+		RunId:       req.RunId,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Dimension:   uint32(req.Dimension),
+		Metric:      metric,
+	}
+	if err = writeJSON(filepath.Join(path, metadataFile), &coll); err != nil {
+		return nil, err
+	}
+	if err = writeJSON(filepath.Join(path, docsFile), map[string]models.Doc{}); err != nil {
+		return nil, err
+	}
+	return &models.CreateCollectionResponse{Coll: coll}, nil
 }
 
 func (c *LocalClient) UpdateCollection(ctx context.Context, req *models.UpdateCollectionRequest) error {
-	return ErrLocalStorageUnimplemented
+	unlock, err := lockResource(vectorDir, req.CollId)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	coll, err := readCollection(req.CollId)
+	if err != nil {
+		return err
+	}
+	if req.Name != "" {
+		coll.Name = req.Name
+	}
+	coll.Description = req.Description
+	coll.UpdatedAt = time.Now()
+	return writeJSON(filepath.Join(storageDir, vectorDir, req.CollId, metadataFile), coll)
 }
 
 func (c *LocalClient) DelCollection(ctx context.Context, collId string) error {
-	return ErrLocalStorageUnimplemented
+	c.vectorMu.Lock()
+	defer c.vectorMu.Unlock()
+	path := filepath.Join(storageDir, vectorDir, collId)
+	if collId == "" || !isDirExists(path) {
+		return ErrResourceNotFound
+	}
+	unlock, err := lockResource(vectorDir, collId)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("delete collection failed, cause: %v", err)
+	}
+	delete(c.indexes, collId)
+	return nil
 }
 
 func (c *LocalClient) GetCollection(ctx context.Context, collId string) (*models.Collection, error) {
-	return nil, ErrLocalStorageUnimplemented
+	coll, err := readCollection(collId)
+	if err != nil {
+		return nil, err
+	}
+	if coll.Stats, err = collectionStats(collId); err != nil {
+		return nil, err
+	}
+	return coll, nil
 }
 
 func (c *LocalClient) CreateDocs(ctx context.Context, req *models.CreateDocsRequest) (*models.DocOpResponse, error) {
-	return nil, ErrLocalStorageUnimplemented
+	return c.writeDocs(req.CollId, "insert", req.Docs)
 }
 
 func (c *LocalClient) UpdateDocs(ctx context.Context, req *models.UpdateDocsRequest) (*models.DocOpResponse, error) {
-	return nil, ErrLocalStorageUnimplemented
+	return c.writeDocs(req.CollId, "update", req.Docs)
 }
 
 func (c *LocalClient) UpsertDocs(ctx context.Context, req *models.UpsertVectorDocsParam) (*models.DocOpResponse, error) {
-	return nil, ErrLocalStorageUnimplemented
+	return c.writeDocs(req.CollId, "upsert", req.Docs)
 }
 
 func (c *LocalClient) DelDocs(ctx context.Context, req *models.DeleteDocsRequest) (*models.DocOpResponse, error) {
-	return nil, ErrLocalStorageUnimplemented
+	c.vectorMu.Lock()
+	defer c.vectorMu.Unlock()
+	unlock, err := lockResource(vectorDir, req.CollId)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if _, err := readCollection(req.CollId); err != nil {
+		return nil, err
+	}
+	before := docsVersion(req.CollId)
+	docs, err := readDocs(req.CollId)
+	if err != nil {
+		return nil, err
+	}
+	resp := &models.DocOpResponse{Output: make([]models.DocOpResult, 0, len(req.Ids))}
+	var removed []string
+	for _, id := range req.Ids {
+		if _, ok := docs[id]; !ok {
+			resp.Output = append(resp.Output, vector.OpFailed("delete", id, "doc not found"))
+			continue
+		}
+		delete(docs, id)
+		removed = append(removed, id)
+		resp.Output = append(resp.Output, vector.OpSucceeded("delete", id))
+	}
+	if err = writeJSON(filepath.Join(storageDir, vectorDir, req.CollId, docsFile), docs); err != nil {
+		return nil, err
+	}
+	c.indexes.Written(req.CollId, before, docsVersion(req.CollId), nil, removed)
+	return resp, nil
 }
 
 func (c *LocalClient) QueryDocs(ctx context.Context, req *models.QueryVectorRequest) ([]*models.Doc, error) {
-	return nil, ErrLocalStorageUnimplemented
+	c.vectorMu.Lock()
+	defer c.vectorMu.Unlock()
+
+	coll, err := readCollection(req.CollId)
+	if err != nil {
+		return nil, err
+	}
+	if err = vector.CheckQuery(coll, req); err != nil {
+		return nil, err
+	}
+	// The version is taken before reading, so that docs written meanwhile by
+	// another process can't be cached under their new version.
+	version := docsVersion(req.CollId)
+	docs, err := readDocs(req.CollId)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]models.Doc, 0, len(docs))
+	if index := c.indexes.For(coll, docs, version, req); index != nil {
+		for _, id := range index.Search(req.Vector, vector.Topk(req)) {
+			candidates = append(candidates, docs[id])
+		}
+	} else {
+		for _, doc := range docs {
+			candidates = append(candidates, doc)
+		}
+	}
+	return vector.Rank(coll.Metric, candidates, req), nil
 }
 
 func (c *LocalClient) QueryDocsByIds(ctx context.Context, req *models.QueryDocsByIdsRequest) (map[string]*models.Doc, error) {
-	return nil, ErrLocalStorageUnimplemented
+	if _, err := readCollection(req.CollId); err != nil {
+		return nil, err
+	}
+	docs, err := readDocs(req.CollId)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]*models.Doc, len(req.Ids))
+	for _, id := range req.Ids {
+		if doc, ok := docs[id]; ok {
+			result[id] = &doc
+		}
+	}
+	return result, nil
+}
+
+// writeDocs applies an insert, update or upsert of docs to a collection.
+// Documents are validated one by one and reported in the response; only the
+// valid ones are stored.
+func (c *LocalClient) writeDocs(collId string, op string, in []models.Doc) (*models.DocOpResponse, error) {
+	c.vectorMu.Lock()
+	defer c.vectorMu.Unlock()
+	unlock, err := lockResource(vectorDir, collId)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	coll, err := readCollection(collId)
+	if err != nil {
+		return nil, err
+	}
+	before := docsVersion(collId)
+	docs, err := readDocs(collId)
+	if err != nil {
+		return nil, err
+	}
+	dimension := coll.Dimension
+	resp := &models.DocOpResponse{Output: make([]models.DocOpResult, 0, len(in))}
+	var written []models.Doc
+	for _, doc := range in {
+		if doc.ID == "" && op != "update" {
+			doc.ID = uuid.NewString()
+		}
+		_, exists := docs[doc.ID]
+		if reason := vector.CheckDoc(op, doc, exists, dimension); reason != "" {
+			resp.Output = append(resp.Output, vector.OpFailed(op, doc.ID, reason))
+			continue
+		}
+		// A collection created without a dimension adopts the one of its first vector.
+		if dimension == 0 && len(doc.Vector) > 0 {
+			dimension = uint32(len(doc.Vector))
+		}
+		doc.Score = 0
+		docs[doc.ID] = doc
+		written = append(written, doc)
+		resp.Output = append(resp.Output, vector.OpSucceeded(op, doc.ID))
+	}
+
+	if err = writeJSON(filepath.Join(storageDir, vectorDir, collId, docsFile), docs); err != nil {
+		return nil, err
+	}
+	c.indexes.Written(collId, before, docsVersion(collId), written, nil)
+	if dimension != coll.Dimension {
+		coll.Dimension = dimension
+		coll.UpdatedAt = time.Now()
+		if err = writeJSON(filepath.Join(storageDir, vectorDir, collId, metadataFile), coll); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+func readCollection(collId string) (*models.Collection, error) {
+	path := filepath.Join(storageDir, vectorDir, collId)
+	if collId == "" || !isDirExists(path) {
+		return nil, ErrResourceNotFound
+	}
+	var coll models.Collection
+	if err := readJSON(filepath.Join(path, metadataFile), &coll); err != nil {
+		return nil, err
+	}
+	return &coll, nil
+}
+
+func readDocs(collId string) (map[string]models.Doc, error) {
+	path := filepath.Join(storageDir, vectorDir, collId, docsFile)
+	docs := make(map[string]models.Doc)
+	if !isFileExists(path) {
+		return docs, nil
+	}
+	if err := readJSON(path, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// docsVersion identifies the stored docs of a collection by the time and
+// size of its docs file, which every write replaces.
+func docsVersion(collId string) string {
+	info, err := os.Stat(filepath.Join(storageDir, vectorDir, collId, docsFile))
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", info.ModTime().UnixNano(), info.Size())
+}
+
+func collectionStats(collId string) (models.Stats, error) {
+	docs, err := readDocs(collId)
+	if err != nil {
+		return models.Stats{}, err
+	}
+	stats := models.Stats{Count: uint64(len(docs))}
+	if info, err := os.Stat(filepath.Join(storageDir, vectorDir, collId, docsFile)); err == nil {
+		stats.Size = uint64(info.Size())
+	}
+	return stats, nil
 }
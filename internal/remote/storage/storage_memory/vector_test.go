@@ -0,0 +1,153 @@
+package storage_memory
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/vector"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+func TestDelCollectionWhileQuerying(t *testing.T) {
+	useTempStorage(t)
+	resp, err := local.CreateCollections(ctx, &models.CreateCollectionRequest{
+		Name:   "test-collection-" + uuid.NewString(),
+		Metric: "l2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	collId := resp.Coll.Id
+	// Enough docs for queries to build an index.
+	docs := make([]models.Doc, vector.IndexMinDocs)
+	for i := range docs {
+		docs[i] = models.Doc{ID: fmt.Sprint(i), Vector: []float64{float64(i), 1}}
+	}
+	if _, err = local.CreateDocs(ctx, &models.CreateDocsRequest{CollId: collId, Docs: docs}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Queries racing the deletion either succeed or find no collection.
+	started, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; ; i++ {
+			_, err := local.QueryDocs(ctx, &models.QueryVectorRequest{CollId: collId, Vector: []float64{1, 1}, Topk: 1})
+			if i == 0 {
+				close(started)
+			}
+			if errors.Is(err, errs.ErrNotFound) {
+				return
+			}
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	<-started
+	if err = local.DelCollection(ctx, collId); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if _, err = local.QueryDocs(ctx, &models.QueryVectorRequest{CollId: collId, Vector: []float64{1, 1}}); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("QueryDocs(deleted) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestQueryDocsWrittenElsewhere(t *testing.T) {
+	useTempStorage(t)
+	resp, err := local.CreateCollections(ctx, &models.CreateCollectionRequest{
+		Name:   "test-collection-" + uuid.NewString(),
+		Metric: "l2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	collId := resp.Coll.Id
+	docs := make([]models.Doc, vector.IndexMinDocs)
+	for i := range docs {
+		docs[i] = models.Doc{ID: fmt.Sprint(i), Vector: []float64{float64(i), 1}}
+	}
+	if _, err = local.CreateDocs(ctx, &models.CreateDocsRequest{CollId: collId, Docs: docs}); err != nil {
+		t.Fatal(err)
+	}
+	query := &models.QueryVectorRequest{CollId: collId, Vector: []float64{-1, 1}, Topk: 1}
+	if got, err := local.QueryDocs(ctx, query); err != nil || len(got) != 1 || got[0].ID != "0" {
+		t.Fatalf("QueryDocs() = %v, %v, want doc 0", got, err)
+	}
+
+	// Another process moves a doc without changing the doc count.
+	other := LocalClient{}
+	moved := []models.Doc{{ID: "7", Vector: []float64{-1, 1}}}
+	if _, err = other.UpdateDocs(ctx, &models.UpdateDocsRequest{CollId: collId, Docs: moved}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := local.QueryDocs(ctx, query); err != nil || len(got) != 1 || got[0].ID != "7" {
+		t.Errorf("QueryDocs() = %v, %v, want doc 7", got, err)
+	}
+}
+
+func TestVector(t *testing.T) {
+	useTempStorage(t)
+	resp, err := local.CreateCollections(ctx, &models.CreateCollectionRequest{
+		Name:   "test-collection-" + uuid.NewString(),
+		Metric: "l2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	collId := resp.Coll.Id
+
+	ops, err := local.CreateDocs(ctx, &models.CreateDocsRequest{CollId: collId, Docs: []models.Doc{
+		{ID: "a", Vector: []float64{0, 0}, Content: "origin"},
+		{ID: "b", Vector: []float64{1, 1}, SparseVector: map[string]float64{"x": 1}},
+		{ID: "c", Vector: []float64{5, 5}},
+		{ID: "d", Vector: []float64{1, 2, 3}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ops.Output[0].Code != 0 || ops.Output[3].Code == 0 {
+		t.Fatalf("unexpected doc ops %+v", ops.Output)
+	}
+	coll, err := local.GetCollection(ctx, collId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if coll.Dimension != 2 || coll.Metric != "euclidean" || coll.Stats.Count != 3 {
+		t.Fatalf("unexpected collection %+v", coll)
+	}
+
+	docs, err := local.QueryDocs(ctx, &models.QueryVectorRequest{CollId: collId, Vector: []float64{4, 4}, Topk: 2, IncludeContent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0].ID != "c" || docs[1].ID != "b" || docs[0].Vector != nil {
+		t.Fatalf("unexpected query result %+v", docs)
+	}
+	docs, err = local.QueryDocs(ctx, &models.QueryVectorRequest{CollId: collId, SparseVector: map[string]float64{"x": 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].ID != "b" || docs[0].Score != 2 {
+		t.Fatalf("unexpected sparse query result %+v", docs)
+	}
+	if _, err = local.QueryDocs(ctx, &models.QueryVectorRequest{CollId: collId, Vector: []float64{1}}); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+
+	if _, err = local.DelDocs(ctx, &models.DeleteDocsRequest{CollId: collId, Ids: []string{"c"}}); err != nil {
+		t.Fatal(err)
+	}
+	byIds, err := local.QueryDocsByIds(ctx, &models.QueryDocsByIdsRequest{CollId: collId, Ids: []string{"a", "c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byIds) != 1 || byIds["a"].Content != "origin" {
+		t.Fatalf("unexpected docs by ids %+v", byIds)
+	}
+}
@@ -0,0 +1,478 @@
+package storage_redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+// The value of a key is a Redis string expiring with the key. The keys set
+// of the namespace orders the key names for listing and scanning; names
+// left there by expired keys are removed when met.
+
+const MaxExpireTime = 24 * 60 * 60 * 7
+
+// scanBatch is how many key names are read from the keys set at once.
+const scanBatch = 256
+
+// incrementScript adds ARGV[1] to the integer value KEYS[1], giving a new key
+// the expiry ARGV[2], and indexes ARGV[3] in the keys set KEYS[2].
+var incrementScript = redis.NewScript(`
+local n = redis.call('INCRBY', KEYS[1], ARGV[1])
+if redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('EXPIRE', KEYS[1], ARGV[2])
+end
+redis.call('ZADD', KEYS[2], 0, ARGV[3])
+return n
+`)
+
+// unindexScript removes from the keys set KEYS[1] the names ARGV whose
+// values, KEYS[2:] in the same order, don't exist, unless they were set again
+// in the meantime.
+var unindexScript = redis.NewScript(`
+for i = 2, #KEYS do
+	if redis.call('EXISTS', KEYS[i]) == 0 then
+		redis.call('ZREM', KEYS[1], ARGV[i - 1])
+	end
+end
+return 0
+`)
+
+func (c *RedisClient) GetNamespace(ctx context.Context, namespaceId string) (*models.KvNamespaceItem, error) {
+	var namespace models.KvNamespaceItem
+	if err := c.readMeta(ctx, c.rdb, kvCategory, namespaceId, &namespace); err != nil {
+		return nil, err
+	}
+	stats, err := c.keysStats(ctx, namespaceId)
+	if err != nil {
+		return nil, err
+	}
+	namespace.Stats = stats
+	return &namespace, nil
+}
+
+func (c *RedisClient) ListNamespaces(ctx context.Context, page int64, pageSize int64, desc bool) (*models.KvNamespace, error) {
+	namespaces, total, err := listResources[models.KvNamespaceItem](ctx, c, kvCategory, page, pageSize, desc)
+	if err != nil {
+		return nil, err
+	}
+	for i := range namespaces {
+		if namespaces[i].Stats, err = c.keysStats(ctx, namespaces[i].Id); err != nil {
+			return nil, err
+		}
+	}
+	return &models.KvNamespace{
+		Items:     namespaces,
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+		TotalPage: totalPage(total, pageSize),
+	}, nil
+}
+
+func (c *RedisClient) CreateNamespace(ctx context.Context, req *models.CreateKvNamespaceRequest) (string, error) {
+	id := uuid.NewString()
+	now := time.Now().Format(time.RFC3339)
+	namespace := &models.KvNamespaceItem{
+		Id:        id,
+		Name:      req.Name,
+		ActorId:   req.ActorId,
+		RunId:     req.RunId,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := c.createResource(ctx, kvCategory, id, req.Name, namespace); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// DelNamespace deletes the namespace with all its keys.
+func (c *RedisClient) DelNamespace(ctx context.Context, namespaceId string) (bool, error) {
+	var namespace models.KvNamespaceItem
+	keysKey := c.keysKey(namespaceId)
+	return c.deleteResource(ctx, kvCategory, namespaceId, &namespace,
+		func() string { return namespace.Name },
+		func() ([]string, error) {
+			keys, err := c.rdb.ZRange(ctx, keysKey, 0, -1).Result()
+			if err != nil {
+				return nil, err
+			}
+			owned := []string{keysKey}
+			for _, key := range keys {
+				owned = append(owned, c.valueKey(namespaceId, key))
+			}
+			return owned, nil
+		})
+}
+
+func (c *RedisClient) RenameNamespace(ctx context.Context, namespaceId string, name string) (bool, error) {
+	var namespace models.KvNamespaceItem
+	err := c.renameResource(ctx, kvCategory, namespaceId, name, &namespace, func() string {
+		oldName := namespace.Name
+		namespace.Name = name
+		namespace.UpdatedAt = time.Now().Format(time.RFC3339)
+		return oldName
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *RedisClient) SetValue(ctx context.Context, req *models.SetValue) (bool, error) {
+	n, err := c.BulkSetValue(ctx, &models.BulkSet{
+		NamespaceId: req.NamespaceId,
+		Items:       []models.BulkItem{{Key: req.Key, Value: req.Value, Expiration: req.Expiration}},
+	})
+	return n == 1, err
+}
+
+// BulkSetValue sets all the items in one transaction.
+func (c *RedisClient) BulkSetValue(ctx context.Context, req *models.BulkSet) (int64, error) {
+	for _, item := range req.Items {
+		if item.Key == "" {
+			return 0, fmt.Errorf("%w: key is required", errs.ErrInvalidArgument)
+		}
+	}
+	if err := c.checkResource(ctx, kvCategory, req.NamespaceId); err != nil {
+		return 0, err
+	}
+	_, err := c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, item := range req.Items {
+			c.setKey(ctx, pipe, req.NamespaceId, item.Key, item.Value, item.Expiration)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(req.Items)), nil
+}
+
+// CompareAndSwap sets the key to req.Value if it holds req.OldValue.
+func (c *RedisClient) CompareAndSwap(ctx context.Context, req *models.CompareAndSwap) (bool, error) {
+	if req.Key == "" {
+		return false, fmt.Errorf("%w: key is required", errs.ErrInvalidArgument)
+	}
+	if err := c.checkResource(ctx, kvCategory, req.NamespaceId); err != nil {
+		return false, err
+	}
+	var ok bool
+	valueKey := c.valueKey(req.NamespaceId, req.Key)
+	err := c.update(ctx, func(tx *redis.Tx) error {
+		value, err := tx.Get(ctx, valueKey).Result()
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		if err != nil || value != req.OldValue {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			c.setKey(ctx, pipe, req.NamespaceId, req.Key, req.Value, req.Expiration)
+			return nil
+		})
+		ok = err == nil
+		return err
+	}, valueKey)
+	return ok, err
+}
+
+// SetIfNotExists sets the key unless it holds a value that hasn't expired.
+func (c *RedisClient) SetIfNotExists(ctx context.Context, req *models.SetValue) (bool, error) {
+	if req.Key == "" {
+		return false, fmt.Errorf("%w: key is required", errs.ErrInvalidArgument)
+	}
+	if err := c.checkResource(ctx, kvCategory, req.NamespaceId); err != nil {
+		return false, err
+	}
+	var set *redis.BoolCmd
+	_, err := c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		set = pipe.SetNX(ctx, c.valueKey(req.NamespaceId, req.Key), req.Value, expiry(req.Expiration))
+		pipe.ZAdd(ctx, c.keysKey(req.NamespaceId), redis.Z{Member: req.Key})
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return set.Val(), nil
+}
+
+// Increment adds delta to the integer value of the key, keeping its expiry,
+// and returns the new value. A missing key counts as 0.
+func (c *RedisClient) Increment(ctx context.Context, namespaceId string, key string, delta int64) (int64, error) {
+	if key == "" {
+		return 0, fmt.Errorf("%w: key is required", errs.ErrInvalidArgument)
+	}
+	if err := c.checkResource(ctx, kvCategory, namespaceId); err != nil {
+		return 0, err
+	}
+	keys := []string{c.valueKey(namespaceId, key), c.keysKey(namespaceId)}
+	n, err := incrementScript.Run(ctx, c.rdb, keys, delta, MaxExpireTime, key).Int64()
+	if err != nil && strings.Contains(err.Error(), "not an integer") {
+		return 0, fmt.Errorf("%w: value of key %s is not an integer", errs.ErrInvalidArgument, key)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Touch resets the expiry of the key to expiration seconds from now. It
+// returns false if the key doesn't exist.
+func (c *RedisClient) Touch(ctx context.Context, namespaceId string, key string, expiration uint) (bool, error) {
+	if err := c.checkResource(ctx, kvCategory, namespaceId); err != nil {
+		return false, err
+	}
+	return c.rdb.Expire(ctx, c.valueKey(namespaceId, key), expiry(expiration)).Result()
+}
+
+// GetWithMetadata returns the value of the key with its size and expiry.
+func (c *RedisClient) GetWithMetadata(ctx context.Context, namespaceId string, key string) (*models.KvValue, error) {
+	if err := c.checkResource(ctx, kvCategory, namespaceId); err != nil {
+		return nil, err
+	}
+	var get *redis.StringCmd
+	var ttl *redis.DurationCmd
+	_, err := c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, c.valueKey(namespaceId, key))
+		ttl = pipe.PTTL(ctx, c.valueKey(namespaceId, key))
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("key %s %w", key, errs.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	value := &models.KvValue{
+		Key:   key,
+		Value: get.Val(),
+		Size:  len(get.Val()),
+	}
+	if ttl.Val() > 0 {
+		value.ExpireAt = time.Now().Add(ttl.Val())
+	}
+	return value, nil
+}
+
+func (c *RedisClient) GetValue(ctx context.Context, namespaceId string, key string) (string, error) {
+	if err := c.checkResource(ctx, kvCategory, namespaceId); err != nil {
+		return "", err
+	}
+	value, err := c.rdb.Get(ctx, c.valueKey(namespaceId, key)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", fmt.Errorf("key %s %w", key, errs.ErrNotFound)
+	}
+	return value, err
+}
+
+func (c *RedisClient) DelValue(ctx context.Context, namespaceId string, key string) (bool, error) {
+	return c.BulkDelValue(ctx, namespaceId, []string{key})
+}
+
+// BulkDelValue deletes all the keys in one transaction.
+func (c *RedisClient) BulkDelValue(ctx context.Context, namespaceId string, keys []string) (bool, error) {
+	if err := c.checkResource(ctx, kvCategory, namespaceId); err != nil {
+		return false, err
+	}
+	if len(keys) == 0 {
+		return true, nil
+	}
+	valueKeys := make([]string, len(keys))
+	members := make([]any, len(keys))
+	for i, key := range keys {
+		valueKeys[i] = c.valueKey(namespaceId, key)
+		members[i] = key
+	}
+	_, err := c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, valueKeys...)
+		pipe.ZRem(ctx, c.keysKey(namespaceId), members...)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *RedisClient) ListKeys(ctx context.Context, req *models.ListKeyInfo) (*models.KvKeys, error) {
+	if err := c.checkResource(ctx, kvCategory, req.NamespaceId); err != nil {
+		return nil, err
+	}
+	keys, err := c.rdb.ZRange(ctx, c.keysKey(req.NamespaceId), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	sizes, err := c.keySizes(ctx, req.NamespaceId, keys)
+	if err != nil {
+		return nil, err
+	}
+	var items []map[string]any
+	for i, key := range keys {
+		if sizes[i] >= 0 {
+			items = append(items, map[string]any{
+				"key":  key,
+				"size": sizes[i],
+			})
+		}
+	}
+	total := int64(len(items))
+	start, end := pageBounds(req.Page, req.Size, total)
+	kvKeys := &models.KvKeys{
+		Total:     total,
+		Page:      req.Page,
+		PageSize:  req.Size,
+		TotalPage: totalPage(total, req.Size),
+	}
+	if start < end {
+		kvKeys.Items = items[start:end]
+	}
+	return kvKeys, nil
+}
+
+// ScanKeys returns the keys of the namespace starting with req.Prefix, in
+// ascending order, after req.After. It reads the names from the first of
+// them in batches rather than reading the whole namespace.
+func (c *RedisClient) ScanKeys(ctx context.Context, req *models.ScanKeysRequest) (*models.KvScan, error) {
+	if err := c.checkResource(ctx, kvCategory, req.NamespaceId); err != nil {
+		return nil, err
+	}
+	scan := &models.KvScan{}
+	from := "-"
+	if req.Prefix != "" {
+		from = "[" + req.Prefix
+	}
+	if req.After > req.Prefix {
+		from = "(" + req.After
+	}
+	for {
+		keys, err := c.rdb.ZRangeByLex(ctx, c.keysKey(req.NamespaceId), &redis.ZRangeBy{
+			Min:   from,
+			Max:   "+",
+			Count: scanBatch,
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+		done := len(keys) < scanBatch
+		if len(keys) > 0 {
+			from = "(" + keys[len(keys)-1]
+		}
+		for i, key := range keys {
+			if !strings.HasPrefix(key, req.Prefix) {
+				keys, done = keys[:i], true
+				break
+			}
+		}
+		sizes, err := c.keySizes(ctx, req.NamespaceId, keys)
+		if err != nil {
+			return nil, err
+		}
+		for i, key := range keys {
+			if sizes[i] < 0 || key <= req.After {
+				continue
+			}
+			if int64(len(scan.Items)) == req.Limit {
+				scan.More = true
+				return scan, nil
+			}
+			scan.Items = append(scan.Items, map[string]any{
+				"key":  key,
+				"size": sizes[i],
+			})
+		}
+		if done {
+			return scan, nil
+		}
+	}
+}
+
+func (c *RedisClient) valueKey(namespaceId string, key string) string {
+	return c.dataKey(kvCategory, namespaceId, "v", key)
+}
+
+func (c *RedisClient) keysKey(namespaceId string) string {
+	return c.dataKey(kvCategory, namespaceId, "keys")
+}
+
+// setKey queues the commands setting the key on pipe.
+func (c *RedisClient) setKey(ctx context.Context, pipe redis.Pipeliner, namespaceId string, key string, value string, expiration uint) {
+	pipe.Set(ctx, c.valueKey(namespaceId, key), value, expiry(expiration))
+	pipe.ZAdd(ctx, c.keysKey(namespaceId), redis.Z{Member: key})
+}
+
+// keySizes returns the size of the values of keys, or -1 for the keys that
+// have expired, which are removed from the keys set.
+func (c *RedisClient) keySizes(ctx context.Context, namespaceId string, keys []string) ([]int64, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	exists := make([]*redis.IntCmd, len(keys))
+	lengths := make([]*redis.IntCmd, len(keys))
+	_, err := c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			exists[i] = pipe.Exists(ctx, c.valueKey(namespaceId, key))
+			lengths[i] = pipe.StrLen(ctx, c.valueKey(namespaceId, key))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sizes := make([]int64, len(keys))
+	scriptKeys := []string{c.keysKey(namespaceId)}
+	var expired []any
+	for i, key := range keys {
+		sizes[i] = lengths[i].Val()
+		if exists[i].Val() == 0 {
+			sizes[i] = -1
+			scriptKeys = append(scriptKeys, c.valueKey(namespaceId, key))
+			expired = append(expired, key)
+		}
+	}
+	if len(expired) == 0 {
+		return sizes, nil
+	}
+	err = unindexScript.Run(ctx, c.rdb, scriptKeys, expired...).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	return sizes, nil
+}
+
+// keysStats counts the live keys of the namespace and their size.
+func (c *RedisClient) keysStats(ctx context.Context, namespaceId string) (models.Stats, error) {
+	var stats models.Stats
+	keys, err := c.rdb.ZRange(ctx, c.keysKey(namespaceId), 0, -1).Result()
+	if err != nil {
+		return stats, err
+	}
+	sizes, err := c.keySizes(ctx, namespaceId, keys)
+	if err != nil {
+		return stats, err
+	}
+	for _, size := range sizes {
+		if size >= 0 {
+			stats.Count++
+			stats.Size += uint64(size)
+		}
+	}
+	return stats, nil
+}
+
+// expiry returns the expiry of a key set with expiration seconds, 0 meaning
+// MaxExpireTime.
+func expiry(expiration uint) time.Duration {
+	if expiration == 0 {
+		expiration = MaxExpireTime
+	}
+	return time.Duration(expiration) * time.Second
+}
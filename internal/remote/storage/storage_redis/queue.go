@@ -0,0 +1,461 @@
+package storage_redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+// A message is a hash of its fields, times in unix milliseconds. The ready
+// set of its queue scores it by the time it can be pulled: its last update
+// while pending, the end of its lease once pulled. Pulling and settling
+// messages run as scripts, so concurrent workers never lease the same
+// message.
+
+// deadLetterWindow is how long, in seconds, a message outlives its
+// deadline, and the least time a dead-lettered message has to be handled.
+const deadLetterWindow = 86400
+
+// pullScript leases the messages ARGV[3:] of hashes KEYS[2:], in the same
+// order, from the ready set KEYS[1] if they are still due at ARGV[1] (unix
+// ms). It returns the fields of the messages leased, and the ids of those
+// past their deadline (ARGV[2], unix seconds) or out of retries, which it
+// removes from the ready set.
+var pullScript = redis.NewScript(`
+local now, deadline = tonumber(ARGV[1]), tonumber(ARGV[2])
+local leased, dead = {}, {}
+for i = 2, #KEYS do
+	local key, id = KEYS[i], ARGV[i + 1]
+	local due = redis.call('ZSCORE', KEYS[1], id)
+	-- Skipped if leased or acked by another worker since it was read.
+	if due and tonumber(due) <= now then
+		local m = redis.call('HMGET', key, 'deadline', 'retry', 'retried', 'timeout')
+		if not m[1] then
+			redis.call('ZREM', KEYS[1], id)
+		elseif tonumber(m[1]) < deadline or (tonumber(m[3]) > 0 and tonumber(m[3]) >= tonumber(m[2])) then
+			-- msg failed for good; a msg is always delivered at least once
+			redis.call('ZREM', KEYS[1], id)
+			table.insert(dead, id)
+		else
+			local reenter = now + 1000 * tonumber(m[4])
+			redis.call('HSET', key, 'reenterTime', string.format('%d', reenter))
+			redis.call('HINCRBY', key, 'retried', 1)
+			-- Not due again before the next pull, even without a timeout.
+			redis.call('ZADD', KEYS[1], math.max(reenter, now + 1), id)
+			table.insert(leased, redis.call('HGETALL', key))
+		end
+	end
+end
+return {leased, dead}
+`)
+
+// settleScript acks, nacks or renews (ARGV[2]) the message ARGV[1] of hash
+// KEYS[1] and ready set KEYS[2] at ARGV[3] (unix ms). A nack delays it by
+// ARGV[4] ms. It returns 0 if the message isn't leased, -1 if its lease has
+// run out, and 1 once done.
+var settleScript = redis.NewScript(`
+local now = tonumber(ARGV[3])
+local reenter = tonumber(redis.call('HGET', KEYS[1], 'reenterTime') or '0')
+if reenter == 0 then
+	return 0
+end
+if reenter < now then
+	return -1
+end
+if ARGV[2] == 'ack' then
+	redis.call('DEL', KEYS[1])
+	redis.call('ZREM', KEYS[2], ARGV[1])
+	return 1
+end
+local due
+if ARGV[2] == 'nack' then
+	due = now + tonumber(ARGV[4])
+	redis.call('HSET', KEYS[1], 'updateTime', ARGV[3])
+else
+	due = now + 1000 * tonumber(redis.call('HGET', KEYS[1], 'timeout') or '0')
+end
+redis.call('HSET', KEYS[1], 'reenterTime', string.format('%d', due))
+redis.call('ZADD', KEYS[2], due, ARGV[1])
+return 1
+`)
+
+func (c *RedisClient) CreateQueue(ctx context.Context, req *models.CreateQueueRequest) (*models.CreateQueueResponse, error) {
+	if req.DeadLetterQueueId != "" {
+		if err := c.checkResource(ctx, queuesCategory, req.DeadLetterQueueId); err != nil {
+			return nil, fmt.Errorf("%w: dead-letter queue %s not found", errs.ErrInvalidArgument, req.DeadLetterQueueId)
+		}
+	}
+	id := uuid.NewString()
+	now := time.Now().Format(time.RFC3339Nano)
+	queue := &models.Queue{
+		Id:          id,
+		Name:        req.Name,
+		ActorId:     req.ActorId,
+		RunId:       req.RunId,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+
+		DeadLetterQueueId: req.DeadLetterQueueId,
+	}
+	if err := c.createResource(ctx, queuesCategory, id, req.Name, queue); err != nil {
+		return nil, err
+	}
+	return &models.CreateQueueResponse{Id: id}, nil
+}
+
+func (c *RedisClient) GetQueue(ctx context.Context, req *models.GetQueueRequest) (*models.GetQueueResponse, error) {
+	var queue models.Queue
+	if err := c.readMeta(ctx, c.rdb, queuesCategory, req.Id, &queue); err != nil {
+		return nil, err
+	}
+	return &models.GetQueueResponse{Queue: queue}, nil
+}
+
+func (c *RedisClient) GetQueues(ctx context.Context, req *models.GetQueuesRequest) (*models.ListQueuesResponse, error) {
+	queues, total, err := listResources[*models.Queue](ctx, c, queuesCategory, req.Page, req.PageSize, req.Desc)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListQueuesResponse{
+		Items:     queues,
+		Total:     total,
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+		TotalPage: totalPage(total, req.PageSize),
+	}, nil
+}
+
+func (c *RedisClient) UpdateQueue(ctx context.Context, req *models.UpdateQueueRequest) error {
+	var queue models.Queue
+	err := c.renameResource(ctx, queuesCategory, req.QueueId, req.Name, &queue, func() string {
+		oldName := queue.Name
+		queue.Name = req.Name
+		queue.Description = req.Description
+		queue.UpdatedAt = time.Now().Format(time.RFC3339Nano)
+		return oldName
+	})
+	if errors.Is(err, ErrResourceNotFound) {
+		return nil
+	}
+	return err
+}
+
+// DelQueue deletes the queue with all its messages.
+func (c *RedisClient) DelQueue(ctx context.Context, req *models.DelQueueRequest) error {
+	var queue models.Queue
+	readyKey := c.readyKey(req.QueueId)
+	_, err := c.deleteResource(ctx, queuesCategory, req.QueueId, &queue,
+		func() string { return queue.Name },
+		func() ([]string, error) {
+			ids, err := c.rdb.ZRange(ctx, readyKey, 0, -1).Result()
+			if err != nil {
+				return nil, err
+			}
+			owned := []string{readyKey}
+			for _, id := range ids {
+				owned = append(owned, c.msgKey(req.QueueId, id))
+			}
+			return owned, nil
+		})
+	return err
+}
+
+func (c *RedisClient) CreateMsg(ctx context.Context, req *models.CreateMsgRequest) (*models.CreateMsgResponse, error) {
+	if req.Deadline < time.Now().Unix()+300 {
+		return nil, fmt.Errorf("%w: deadline must after now + 300s", errs.ErrInvalidArgument)
+	}
+	if err := c.checkResource(ctx, queuesCategory, req.QueueId); err != nil {
+		return nil, err
+	}
+	msg := &models.MsgLocal{
+		Msg: models.Msg{
+			ID:       uuid.NewString(),
+			QueueID:  req.QueueId,
+			Name:     req.Name,
+			Payload:  req.PayLoad,
+			Deadline: req.Deadline,
+			Retry:    req.Retry,
+			Timeout:  req.Timeout,
+		},
+		UpdateTime: time.Now(),
+	}
+	_, err := c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		c.putMsg(ctx, pipe, msg)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.CreateMsgResponse{MsgId: msg.ID}, nil
+}
+
+// GetMsg leases up to req.Limit messages due, oldest first. The messages
+// that failed for good on the way are moved to the dead-letter queue. The
+// messages due are read first, then leased by pullScript unless another
+// worker leased them in the meantime, until enough are leased or none is
+// due.
+func (c *RedisClient) GetMsg(ctx context.Context, req *models.GetMsgRequest) (*models.GetMsgResponse, error) {
+	var queue models.Queue
+	if err := c.readMeta(ctx, c.rdb, queuesCategory, req.QueueId, &queue); err != nil {
+		return nil, err
+	}
+	resp := make(models.GetMsgResponse, 0)
+	if req.Limit <= 0 {
+		return &resp, nil
+	}
+	// The same time is used throughout, so that a message leased without a
+	// timeout isn't due again before the pull ends.
+	now := time.Now()
+	readyKey := c.readyKey(req.QueueId)
+	for len(resp) < int(req.Limit) {
+		ids, err := c.rdb.ZRangeByScore(ctx, readyKey, &redis.ZRangeBy{
+			Min:   "-inf",
+			Max:   strconv.FormatInt(now.UnixMilli(), 10),
+			Count: int64(int(req.Limit) - len(resp)),
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		keys := []string{readyKey}
+		args := []any{now.UnixMilli(), now.Unix()}
+		for _, id := range ids {
+			keys = append(keys, c.msgKey(req.QueueId, id))
+			args = append(args, id)
+		}
+		result, err := pullScript.Run(ctx, c.rdb, keys, args...).Slice()
+		if err != nil {
+			return nil, err
+		}
+		leased, _ := result[0].([]any)
+		dead, _ := result[1].([]any)
+		for _, fields := range leased {
+			values, _ := fields.([]any)
+			msg := parseMsg(pairs(values))
+			resp = append(resp, &msg.Msg)
+		}
+		for _, id := range dead {
+			if err = c.deadLetter(ctx, &queue, fmt.Sprint(id), now); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &resp, nil
+}
+
+func (c *RedisClient) AckMsg(ctx context.Context, req *models.AckMsgRequest) error {
+	return c.settle(ctx, req.QueueId, req.MsgId, "ack", 0)
+}
+
+// NackMsg returns a pulled message to the queue, to be pulled again after
+// req.Delay seconds. A message without retries left is dead-lettered when
+// pulled again.
+func (c *RedisClient) NackMsg(ctx context.Context, req *models.NackMsgRequest) error {
+	if req.Delay < 0 {
+		return fmt.Errorf("%w: delay must not be negative", errs.ErrInvalidArgument)
+	}
+	return c.settle(ctx, req.QueueId, req.MsgId, "nack", req.Delay*1000)
+}
+
+// RenewMsg extends the lease of a pulled message by its timeout.
+func (c *RedisClient) RenewMsg(ctx context.Context, req *models.RenewMsgRequest) error {
+	return c.settle(ctx, req.QueueId, req.MsgId, "renew", 0)
+}
+
+// ListMsgs lists the messages of the queue not acked yet, leased or not.
+func (c *RedisClient) ListMsgs(ctx context.Context, req *models.ListMsgsRequest) (*models.ListMsgsResponse, error) {
+	if err := c.checkResource(ctx, queuesCategory, req.QueueId); err != nil {
+		return nil, err
+	}
+	readyKey := c.readyKey(req.QueueId)
+	total, err := c.rdb.ZCard(ctx, readyKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*models.Msg, 0)
+	if start, end := pageBounds(req.Page, req.PageSize, total); start < end {
+		ids, err := c.rdb.ZRange(ctx, readyKey, start, end-1).Result()
+		if err != nil {
+			return nil, err
+		}
+		cmds := make([]*redis.MapStringStringCmd, len(ids))
+		_, err = c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, id := range ids {
+				cmds[i] = pipe.HGetAll(ctx, c.msgKey(req.QueueId, id))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, cmd := range cmds {
+			// Acked meanwhile.
+			if len(cmd.Val()) == 0 {
+				continue
+			}
+			items = append(items, &parseMsg(cmd.Val()).Msg)
+		}
+	}
+	return &models.ListMsgsResponse{
+		Items:     items,
+		Total:     total,
+		TotalPage: totalPage(total, req.PageSize),
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+	}, nil
+}
+
+// settle runs op on a message whose lease is still running.
+func (c *RedisClient) settle(ctx context.Context, queueId string, msgId string, op string, delay int64) error {
+	if msgId == "" {
+		return ErrResourceNotFound
+	}
+	keys := []string{c.msgKey(queueId, msgId), c.readyKey(queueId)}
+	n, err := settleScript.Run(ctx, c.rdb, keys, msgId, op, time.Now().UnixMilli(), delay).Int()
+	if err != nil {
+		return err
+	}
+	switch n {
+	case 0:
+		return ErrResourceNotFound
+	case -1:
+		return fmt.Errorf("msg is timeout, you must %s within the timeout period", op)
+	}
+	return nil
+}
+
+// deadLetter moves the message id, which failed for good, from queue to its
+// dead-letter queue, or deletes it when queue has none. The queues are in
+// different slots, so the message is added to the dead-letter queue before
+// it is deleted from queue: it can't be lost in between, only kept twice.
+func (c *RedisClient) deadLetter(ctx context.Context, queue *models.Queue, id string, now time.Time) error {
+	fields, err := c.rdb.HGetAll(ctx, c.msgKey(queue.Id, id)).Result()
+	if err != nil || len(fields) == 0 {
+		return err
+	}
+	msg := parseMsg(fields)
+	reason := "retries exhausted"
+	if msg.Deadline < now.Unix() {
+		reason = "deadline exceeded"
+	}
+	if c.checkResource(ctx, queuesCategory, queue.DeadLetterQueueId) != nil {
+		return c.rdb.Del(ctx, c.msgKey(queue.Id, id)).Err()
+	}
+	_, err = c.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		c.putMsg(ctx, pipe, &models.MsgLocal{
+			Msg: models.Msg{
+				ID:            msg.ID,
+				QueueID:       queue.DeadLetterQueueId,
+				Name:          msg.Name,
+				Payload:       msg.Payload,
+				Timeout:       msg.Timeout,
+				Deadline:      max(msg.Deadline, now.Unix()+deadLetterWindow),
+				Retry:         msg.Retry,
+				Desc:          msg.Desc,
+				FailedReason:  reason,
+				SourceQueueId: msg.QueueID,
+			},
+			UpdateTime: now,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return c.rdb.Del(ctx, c.msgKey(queue.Id, id)).Err()
+}
+
+func (c *RedisClient) msgKey(queueId string, msgId string) string {
+	return c.dataKey(queuesCategory, queueId, "msg", msgId)
+}
+
+func (c *RedisClient) readyKey(queueId string) string {
+	return c.dataKey(queuesCategory, queueId, "ready")
+}
+
+// putMsg queues the commands storing msg and making it due on pipe.
+func (c *RedisClient) putMsg(ctx context.Context, pipe redis.Pipeliner, msg *models.MsgLocal) {
+	key := c.msgKey(msg.QueueID, msg.ID)
+	pipe.HSet(ctx, key,
+		"id", msg.ID,
+		"queueId", msg.QueueID,
+		"name", msg.Name,
+		"payload", msg.Payload,
+		"timeout", msg.Timeout,
+		"deadline", msg.Deadline,
+		"retry", msg.Retry,
+		"retried", msg.Retried,
+		"successAt", msg.SuccessAt,
+		"failedAt", msg.FailedAt,
+		"desc", msg.Desc,
+		"failedReason", msg.FailedReason,
+		"sourceQueueId", msg.SourceQueueId,
+		"reenterTime", unixMilli(msg.ReenterTime),
+		"updateTime", unixMilli(msg.UpdateTime),
+	)
+	pipe.ExpireAt(ctx, key, time.Unix(msg.Deadline+deadLetterWindow, 0))
+	due := msg.UpdateTime
+	if !msg.ReenterTime.IsZero() {
+		due = msg.ReenterTime
+	}
+	pipe.ZAdd(ctx, c.readyKey(msg.QueueID), redis.Z{Score: float64(unixMilli(due)), Member: msg.ID})
+}
+
+// pairs returns the fields of a hash as returned by HGETALL in a script.
+func pairs(values []any) map[string]string {
+	fields := make(map[string]string, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		fields[fmt.Sprint(values[i])] = fmt.Sprint(values[i+1])
+	}
+	return fields
+}
+
+// parseMsg decodes a message from the fields of its hash.
+func parseMsg(fields map[string]string) *models.MsgLocal {
+	num := func(field string) int64 {
+		n, _ := strconv.ParseInt(fields[field], 10, 64)
+		return n
+	}
+	msg := &models.MsgLocal{
+		Msg: models.Msg{
+			ID:            fields["id"],
+			QueueID:       fields["queueId"],
+			Name:          fields["name"],
+			Payload:       fields["payload"],
+			Timeout:       num("timeout"),
+			Deadline:      num("deadline"),
+			Retry:         num("retry"),
+			Retried:       num("retried"),
+			SuccessAt:     num("successAt"),
+			FailedAt:      num("failedAt"),
+			Desc:          fields["desc"],
+			FailedReason:  fields["failedReason"],
+			SourceQueueId: fields["sourceQueueId"],
+		},
+	}
+	if ms := num("reenterTime"); ms > 0 {
+		msg.ReenterTime = time.UnixMilli(ms)
+	}
+	if ms := num("updateTime"); ms > 0 {
+		msg.UpdateTime = time.UnixMilli(ms)
+	}
+	return msg
+}
+
+// unixMilli returns t in unix milliseconds, or 0 for the zero time.
+func unixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
@@ -0,0 +1,468 @@
+package storage_redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+var ctx = context.Background()
+
+// newTemp returns a client of an in-process Redis server, failing t when
+// the client does what Redis Cluster would refuse.
+func newTemp(t *testing.T) (*RedisClient, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	rdb.AddHook(sameSlot{t})
+	c, err := New(ctx, rdb, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c, mr
+}
+
+func TestNew(t *testing.T) {
+	c, mr := newTemp(t)
+	if _, err := c.SetValue(ctx, &models.SetValue{NamespaceId: "default", Key: "a", Value: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	// The default resources are created once.
+	again, err := New(ctx, redis.NewClient(&redis.Options{Addr: mr.Addr()}), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer again.Close()
+	if v, err := again.GetValue(ctx, "default", "a"); err != nil || v != "1" {
+		t.Errorf("GetValue = %q, %v", v, err)
+	}
+	if _, err = again.GetQueue(ctx, &models.GetQueueRequest{Id: "default"}); err != nil {
+		t.Errorf("GetQueue(default) = %v", err)
+	}
+	if _, err = Open(ctx, "redis://"+mr.Addr()+"/0"); err != nil {
+		t.Errorf("Open = %v", err)
+	}
+	if _, err = Open(ctx, "localhost:6379"); err == nil {
+		t.Error("Open with a bad url succeeded")
+	}
+	if _, err = New(ctx, redis.NewClient(&redis.Options{Addr: mr.Addr()}), "{test}"); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("New(prefix with braces) = %v", err)
+	}
+
+	cluster, err := Open(ctx, "redis://"+mr.Addr()+"?cluster=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close()
+	if _, ok := cluster.rdb.(*redis.ClusterClient); !ok {
+		t.Errorf("Open(cluster) = %T", cluster.rdb)
+	}
+	if _, err = cluster.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{Name: "cluster"}); err != nil {
+		t.Errorf("CreateNamespace(cluster) = %v", err)
+	}
+}
+
+func TestKV(t *testing.T) {
+	c, mr := newTemp(t)
+	id, err := c.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{Name: "ns"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{Name: "ns"}); !errors.Is(err, errs.ErrAlreadyExists) {
+		t.Errorf("CreateNamespace(duplicate) = %v", err)
+	}
+	if _, err = c.SetValue(ctx, &models.SetValue{NamespaceId: "missing", Key: "a"}); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("SetValue(missing namespace) = %v", err)
+	}
+	if _, err = c.SetValue(ctx, &models.SetValue{NamespaceId: id, Key: ""}); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("SetValue(empty key) = %v", err)
+	}
+
+	n, err := c.BulkSetValue(ctx, &models.BulkSet{NamespaceId: id, Items: []models.BulkItem{
+		{Key: "page:1", Value: "one"},
+		{Key: "page:2", Value: "two"},
+		{Key: "page:3", Value: "three"},
+		{Key: "short", Value: "x", Expiration: 1},
+	}})
+	if err != nil || n != 4 {
+		t.Fatalf("BulkSetValue = %d, %v", n, err)
+	}
+	if v, err := c.GetValue(ctx, id, "page:1"); err != nil || v != "one" {
+		t.Errorf("GetValue = %q, %v", v, err)
+	}
+	if _, err = c.GetValue(ctx, id, "nope"); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("GetValue(missing) = %v", err)
+	}
+	value, err := c.GetWithMetadata(ctx, id, "page:3")
+	if err != nil || value.Value != "three" || value.Size != 5 || time.Until(value.ExpireAt) < 6*24*time.Hour {
+		t.Errorf("GetWithMetadata = %+v, %v", value, err)
+	}
+
+	if ok, err := c.CompareAndSwap(ctx, &models.CompareAndSwap{NamespaceId: id, Key: "page:1", OldValue: "x", Value: "y"}); ok || err != nil {
+		t.Errorf("CompareAndSwap(mismatch) = %v, %v", ok, err)
+	}
+	if ok, err := c.CompareAndSwap(ctx, &models.CompareAndSwap{NamespaceId: id, Key: "page:1", OldValue: "one", Value: "uno"}); !ok || err != nil {
+		t.Errorf("CompareAndSwap = %v, %v", ok, err)
+	}
+	if ok, err := c.SetIfNotExists(ctx, &models.SetValue{NamespaceId: id, Key: "page:1", Value: "z"}); ok || err != nil {
+		t.Errorf("SetIfNotExists(existing) = %v, %v", ok, err)
+	}
+	if ok, err := c.SetIfNotExists(ctx, &models.SetValue{NamespaceId: id, Key: "lock", Value: "me"}); !ok || err != nil {
+		t.Errorf("SetIfNotExists = %v, %v", ok, err)
+	}
+
+	if n, err := c.Increment(ctx, id, "count", 2); err != nil || n != 2 {
+		t.Errorf("Increment(new) = %d, %v", n, err)
+	}
+	if ok, err := c.Touch(ctx, id, "count", 60); !ok || err != nil {
+		t.Errorf("Touch = %v, %v", ok, err)
+	}
+	if n, err := c.Increment(ctx, id, "count", -5); err != nil || n != -3 {
+		t.Errorf("Increment = %d, %v", n, err)
+	}
+	if ttl := mr.TTL(c.valueKey(id, "count")); ttl != time.Minute {
+		t.Errorf("Increment changed the expiry to %v", ttl)
+	}
+	if _, err = c.Increment(ctx, id, "page:2", 1); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("Increment(not an integer) = %v", err)
+	}
+	if ok, err := c.Touch(ctx, id, "nope", 60); ok || err != nil {
+		t.Errorf("Touch(missing) = %v, %v", ok, err)
+	}
+
+	mr.FastForward(2 * time.Second)
+	if _, err = c.GetValue(ctx, id, "short"); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("GetValue(expired) = %v", err)
+	}
+	keys, err := c.ListKeys(ctx, &models.ListKeyInfo{NamespaceId: id, Page: 1, Size: 10})
+	if err != nil || keys.Total != 5 {
+		t.Fatalf("ListKeys = %+v, %v", keys, err)
+	}
+	if members, _ := mr.ZMembers(c.keysKey(id)); slices.Contains(members, "short") {
+		t.Error("expired key still indexed")
+	}
+
+	scan, err := c.ScanKeys(ctx, &models.ScanKeysRequest{NamespaceId: id, Prefix: "page:", Limit: 2})
+	if err != nil || len(scan.Items) != 2 || !scan.More || scan.Items[1]["key"] != "page:2" {
+		t.Fatalf("ScanKeys = %+v, %v", scan, err)
+	}
+	scan, err = c.ScanKeys(ctx, &models.ScanKeysRequest{NamespaceId: id, Prefix: "page:", After: "page:2", Limit: 2})
+	if err != nil || len(scan.Items) != 1 || scan.More || scan.Items[0]["key"] != "page:3" {
+		t.Errorf("ScanKeys(after) = %+v, %v", scan, err)
+	}
+	scan, err = c.ScanKeys(ctx, &models.ScanKeysRequest{NamespaceId: id, Limit: -1})
+	if err != nil || len(scan.Items) != 5 || scan.Items[0]["key"] != "count" {
+		t.Errorf("ScanKeys(all) = %+v, %v", scan, err)
+	}
+
+	if ok, err := c.BulkDelValue(ctx, id, []string{"page:1", "page:2"}); !ok || err != nil {
+		t.Errorf("BulkDelValue = %v, %v", ok, err)
+	}
+	namespace, err := c.GetNamespace(ctx, id)
+	if err != nil || namespace.Stats.Count != 3 {
+		t.Errorf("GetNamespace = %+v, %v", namespace, err)
+	}
+	if ok, err := c.RenameNamespace(ctx, id, "default"); ok || !errors.Is(err, errs.ErrAlreadyExists) {
+		t.Errorf("RenameNamespace(taken) = %v, %v", ok, err)
+	}
+	if ok, err := c.RenameNamespace(ctx, id, "renamed"); !ok || err != nil {
+		t.Errorf("RenameNamespace = %v, %v", ok, err)
+	}
+	if _, err = c.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{Name: "ns"}); err != nil {
+		t.Errorf("CreateNamespace(freed name) = %v", err)
+	}
+	list, err := c.ListNamespaces(ctx, 1, 2, true)
+	if err != nil || list.Total != 3 || len(list.Items) != 2 || list.Items[0].Name != "ns" || list.TotalPage != 2 {
+		t.Errorf("ListNamespaces = %+v, %v", list, err)
+	}
+
+	if ok, err := c.DelNamespace(ctx, id); !ok || err != nil {
+		t.Errorf("DelNamespace = %v, %v", ok, err)
+	}
+	for _, key := range mr.Keys() {
+		if strings.Contains(key, id) {
+			t.Errorf("DelNamespace left %s", key)
+		}
+	}
+	if ok, err := c.DelNamespace(ctx, id); ok || err != nil {
+		t.Errorf("DelNamespace(deleted) = %v, %v", ok, err)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	c, mr := newTemp(t)
+	dlq, err := c.CreateQueue(ctx, &models.CreateQueueRequest{Name: "dlq"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.CreateQueue(ctx, &models.CreateQueueRequest{Name: "q", DeadLetterQueueId: "missing"}); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("CreateQueue(missing dead-letter queue) = %v", err)
+	}
+	q, err := c.CreateQueue(ctx, &models.CreateQueueRequest{Name: "q", DeadLetterQueueId: dlq.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Unix() + 3600
+	if _, err = c.CreateMsg(ctx, &models.CreateMsgRequest{QueueId: q.Id, Deadline: time.Now().Unix()}); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("CreateMsg(past deadline) = %v", err)
+	}
+	ids := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		msg, err := c.CreateMsg(ctx, &models.CreateMsgRequest{
+			QueueId:  q.Id,
+			Name:     fmt.Sprint(i),
+			PayLoad:  "payload",
+			Retry:    1,
+			Timeout:  60,
+			Deadline: deadline,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[msg.MsgId] = true
+	}
+	if ttl := mr.TTL(c.msgKey(q.Id, firstKey(ids))); ttl < 24*time.Hour {
+		t.Errorf("msg expires in %v", ttl)
+	}
+	list, err := c.ListMsgs(ctx, &models.ListMsgsRequest{QueueId: q.Id, Page: 2, PageSize: 15})
+	if err != nil || list.Total != 20 || list.TotalPage != 2 || len(list.Items) != 5 || list.Items[0].Payload != "payload" {
+		t.Errorf("ListMsgs(page 2) = %+v, %v", list, err)
+	}
+
+	// Concurrent pulls never lease the same message.
+	var mu sync.Mutex
+	leased := make(map[string]int)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				resp, err := c.GetMsg(ctx, &models.GetMsgRequest{QueueId: q.Id, Limit: 3})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if len(*resp) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, msg := range *resp {
+					leased[msg.ID]++
+					if msg.Retried != 1 || msg.Payload != "payload" || msg.Deadline != deadline {
+						t.Errorf("leased %+v", msg)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(leased) != len(ids) {
+		t.Fatalf("leased %d messages, want %d", len(leased), len(ids))
+	}
+	for id, n := range leased {
+		if n != 1 || !ids[id] {
+			t.Errorf("msg %s leased %d times", id, n)
+		}
+	}
+
+	var acked, nacked, renewed string
+	for id := range ids {
+		switch {
+		case acked == "":
+			acked = id
+		case nacked == "":
+			nacked = id
+		case renewed == "":
+			renewed = id
+		}
+	}
+	if err = c.AckMsg(ctx, &models.AckMsgRequest{QueueId: q.Id, MsgId: acked}); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.AckMsg(ctx, &models.AckMsgRequest{QueueId: q.Id, MsgId: acked}); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("AckMsg(acked) = %v", err)
+	}
+	if err = c.RenewMsg(ctx, &models.RenewMsgRequest{QueueId: q.Id, MsgId: renewed}); err != nil {
+		t.Error(err)
+	}
+	if err = c.NackMsg(ctx, &models.NackMsgRequest{QueueId: q.Id, MsgId: nacked, Delay: -1}); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("NackMsg(negative delay) = %v", err)
+	}
+	if err = c.NackMsg(ctx, &models.NackMsgRequest{QueueId: q.Id, MsgId: nacked}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The nacked message is out of retries: the next pull dead-letters it.
+	time.Sleep(2 * time.Millisecond)
+	resp, err := c.GetMsg(ctx, &models.GetMsgRequest{QueueId: q.Id, Limit: 10})
+	if err != nil || len(*resp) != 0 {
+		t.Fatalf("GetMsg = %v, %v", resp, err)
+	}
+	resp, err = c.GetMsg(ctx, &models.GetMsgRequest{QueueId: dlq.Id, Limit: 10})
+	if err != nil || len(*resp) != 1 {
+		t.Fatalf("GetMsg(dead-letter queue) = %v, %v", resp, err)
+	}
+	dead := (*resp)[0]
+	if dead.ID != nacked || dead.FailedReason != "retries exhausted" || dead.SourceQueueId != q.Id || dead.Retried != 1 {
+		t.Errorf("dead-lettered %+v", dead)
+	}
+	if mr.Exists(c.msgKey(q.Id, nacked)) {
+		t.Error("dead-lettered msg left in its queue")
+	}
+
+	// A lease that ran out can't be acked, and the message is pulled again.
+	expiring, err := c.CreateMsg(ctx, &models.CreateMsgRequest{QueueId: "default", Retry: 3, Deadline: deadline})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err = c.GetMsg(ctx, &models.GetMsgRequest{QueueId: "default", Limit: 10}); err != nil || len(*resp) != 1 {
+		t.Fatalf("GetMsg = %v, %v", resp, err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	err = c.AckMsg(ctx, &models.AckMsgRequest{QueueId: "default", MsgId: expiring.MsgId})
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("AckMsg(expired lease) = %v", err)
+	}
+	if resp, err = c.GetMsg(ctx, &models.GetMsgRequest{QueueId: "default", Limit: 10}); err != nil || len(*resp) != 1 || (*resp)[0].Retried != 2 {
+		t.Errorf("GetMsg(expired lease) = %v, %v", resp, err)
+	}
+
+	if err = c.UpdateQueue(ctx, &models.UpdateQueueRequest{QueueId: q.Id, Name: "dlq"}); !errors.Is(err, errs.ErrAlreadyExists) {
+		t.Errorf("UpdateQueue(taken) = %v", err)
+	}
+	if err = c.UpdateQueue(ctx, &models.UpdateQueueRequest{QueueId: q.Id, Name: "jobs", Description: "d"}); err != nil {
+		t.Error(err)
+	}
+	queues, err := c.GetQueues(ctx, &models.GetQueuesRequest{Page: 1, PageSize: 10})
+	if err != nil || queues.Total != 3 || queues.Items[2].Name != "jobs" || queues.Items[2].Description != "d" {
+		t.Errorf("GetQueues = %+v, %v", queues, err)
+	}
+	if err = c.DelQueue(ctx, &models.DelQueueRequest{QueueId: q.Id}); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range mr.Keys() {
+		if strings.Contains(key, q.Id) {
+			t.Errorf("DelQueue left %s", key)
+		}
+	}
+	if _, err = c.GetQueue(ctx, &models.GetQueueRequest{Id: q.Id}); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("GetQueue(deleted) = %v", err)
+	}
+}
+
+func TestQueueDeadline(t *testing.T) {
+	c, _ := newTemp(t)
+	msg, err := c.CreateMsg(ctx, &models.CreateMsgRequest{QueueId: "default", Retry: 3, Timeout: 60, Deadline: time.Now().Unix() + 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without a dead-letter queue, a message past its deadline is dropped.
+	if err = c.rdb.HSet(ctx, c.msgKey("default", msg.MsgId), "deadline", time.Now().Unix()-1).Err(); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.GetMsg(ctx, &models.GetMsgRequest{QueueId: "default", Limit: 1})
+	if err != nil || len(*resp) != 0 {
+		t.Fatalf("GetMsg = %v, %v", resp, err)
+	}
+	if n := c.rdb.Exists(ctx, c.msgKey("default", msg.MsgId)).Val(); n != 0 {
+		t.Error("expired msg not deleted")
+	}
+}
+
+// sameSlot fails t when a command, transaction or script touches keys of
+// different hash tags, which Redis Cluster refuses as they can be in
+// different slots.
+type sameSlot struct {
+	t *testing.T
+}
+
+func (h sameSlot) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h sameSlot) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.check(cmd)
+		return next(ctx, cmd)
+	}
+}
+
+func (h sameSlot) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if slices.ContainsFunc(cmds, func(cmd redis.Cmder) bool { return cmd.Name() == "multi" }) {
+			h.check(cmds...)
+		} else {
+			for _, cmd := range cmds {
+				h.check(cmd)
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func (h sameSlot) check(cmds ...redis.Cmder) {
+	tags := make(map[string]bool)
+	for _, cmd := range cmds {
+		for _, key := range cmdKeys(cmd) {
+			tags[hashTag(key)] = true
+		}
+	}
+	if len(tags) > 1 {
+		h.t.Errorf("%v touch keys of the hash tags %v", cmds, slices.Sorted(maps.Keys(tags)))
+	}
+}
+
+// cmdKeys returns the keys of the commands the client sends.
+func cmdKeys(cmd redis.Cmder) []string {
+	args := cmd.Args()
+	var keys []any
+	switch cmd.Name() {
+	case "eval", "evalsha":
+		n, _ := strconv.Atoi(fmt.Sprint(args[2]))
+		keys = args[3 : 3+n]
+	case "del", "exists", "mget", "watch":
+		keys = args[1:]
+	case "multi", "exec", "unwatch", "ping", "hello", "client", "script", "cluster":
+	default:
+		if len(args) > 1 {
+			keys = args[1:2]
+		}
+	}
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = fmt.Sprint(key)
+	}
+	return names
+}
+
+// hashTag returns the part of key Redis Cluster hashes to find its slot.
+func hashTag(key string) string {
+	if i := strings.IndexByte(key, '{'); i >= 0 {
+		if j := strings.IndexByte(key[i+1:], '}'); j > 0 {
+			return key[i+1 : i+1+j]
+		}
+	}
+	return key
+}
+
+func firstKey(m map[string]bool) string {
+	for k := range m {
+		return k
+	}
+	return ""
+}
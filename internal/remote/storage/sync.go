@@ -0,0 +1,626 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+)
+
+// The categories of resources Sync copies.
+const (
+	SyncDatasets = "datasets"
+	SyncKV       = "kv"
+	SyncQueues   = "queues"
+	SyncBuckets  = "buckets"
+)
+
+// defaultPageSize is how many entries Sync reads per request by default.
+const defaultPageSize = 100
+
+// ConflictPolicy decides what Sync does with a resource whose name is
+// already used in the destination.
+type ConflictPolicy string
+
+const (
+	// ConflictSkip leaves the destination resource as it is.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictMerge adds the items, messages and objects of the source to
+	// the destination resource, and overwrites its keys.
+	ConflictMerge ConflictPolicy = "merge"
+	// ConflictOverwrite deletes the destination resource and copies the
+	// source in its place. The default resources of the local storage are
+	// merged instead, as the actors refer to them by id.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+)
+
+// SyncAction is what Sync did, or would do in a dry run, with a resource.
+type SyncAction string
+
+const (
+	SyncCreate    SyncAction = "create"
+	SyncMerge     SyncAction = "merge"
+	SyncOverwrite SyncAction = "overwrite"
+	SyncSkip      SyncAction = "skip"
+)
+
+type SyncOptions struct {
+	// Categories limits the sync to these categories, all of them when empty.
+	Categories []string
+	// Names limits the sync to the resources with these names, all of them
+	// when empty.
+	Names []string
+	// Conflict applies to the resources whose name is used in the
+	// destination. ConflictSkip when empty.
+	Conflict ConflictPolicy
+	// DryRun reads the source and reports what would be copied without
+	// writing to the destination.
+	DryRun bool
+	// PageSize is how many entries are read per request, 100 when 0.
+	PageSize int64
+}
+
+// SyncResult reports the sync of one resource.
+type SyncResult struct {
+	Category string
+	Name     string
+	SourceId string
+	// TargetId is the resource in the destination, empty when it would be
+	// created by a dry run.
+	TargetId string
+	Action   SyncAction
+	// Copied counts the dataset items, keys, messages or objects copied.
+	Copied int
+	// Dropped counts those that couldn't be: keys expired and messages
+	// within five minutes of their deadline, which can't be created.
+	Dropped int
+	// Skipped tells what of the resource wasn't read at all, and why, like
+	// the messages of a queue of a storage that can't list them.
+	Skipped string
+}
+
+// Sync copies the datasets, KV namespaces, queues with the messages not
+// acked yet, and buckets of src to dst, matching resources by name. The
+// copies are new resources and messages: ids, creation times and retry
+// counts aren't kept. The messages of a storage that can't list them without
+// leasing them, like the cloud storage, aren't copied: their queue is
+// created empty, and its result tells they were skipped.
+//
+// It returns the results of the resources synced so far along with the
+// first error met.
+func Sync(ctx context.Context, src Storage, dst Storage, opts SyncOptions) ([]SyncResult, error) {
+	if opts.PageSize <= 0 {
+		opts.PageSize = defaultPageSize
+	}
+	switch opts.Conflict {
+	case "":
+		opts.Conflict = ConflictSkip
+	case ConflictSkip, ConflictMerge, ConflictOverwrite:
+	default:
+		return nil, fmt.Errorf("%w: unknown conflict policy %q", errs.ErrInvalidArgument, opts.Conflict)
+	}
+	s := &syncer{src: src, dst: dst, opts: opts}
+	for _, category := range []struct {
+		name string
+		sync func(ctx context.Context) error
+	}{
+		{SyncDatasets, s.datasets},
+		{SyncKV, s.namespaces},
+		{SyncQueues, s.queues},
+		{SyncBuckets, s.buckets},
+	} {
+		if len(opts.Categories) > 0 && !slices.Contains(opts.Categories, category.name) {
+			continue
+		}
+		if err := category.sync(ctx); err != nil {
+			return s.results, err
+		}
+	}
+	return s.results, nil
+}
+
+type syncer struct {
+	src, dst Storage
+	opts     SyncOptions
+	results  []SyncResult
+}
+
+// selected reports whether the resource name is to be synced.
+func (s *syncer) selected(name string) bool {
+	return len(s.opts.Names) == 0 || slices.Contains(s.opts.Names, name)
+}
+
+// plan returns what to do with the resource name given the ids of the
+// destination resources by name.
+func (s *syncer) plan(category string, name string, sourceId string, existing map[string]string) *SyncResult {
+	result := &SyncResult{Category: category, Name: name, SourceId: sourceId, Action: SyncCreate}
+	id, ok := existing[name]
+	if name == "" || !ok {
+		return result
+	}
+	result.TargetId = id
+	switch {
+	case s.opts.Conflict == ConflictOverwrite && id != "default":
+		result.Action = SyncOverwrite
+	case s.opts.Conflict == ConflictSkip:
+		result.Action = SyncSkip
+	default:
+		result.Action = SyncMerge
+	}
+	return result
+}
+
+// run creates the resource of result with create, after deleting the one
+// it overwrites with del, then copies its content with content. Nothing is
+// written in a dry run.
+func (s *syncer) run(ctx context.Context, result *SyncResult, del func(id string) error, create func() (string, error), content func(dstId string) error) error {
+	defer func() { s.results = append(s.results, *result) }()
+	if result.Action == SyncSkip {
+		return nil
+	}
+	if !s.opts.DryRun {
+		if result.Action == SyncOverwrite {
+			if err := del(result.TargetId); err != nil {
+				return s.fail(result, "delete", err)
+			}
+		}
+		if result.Action != SyncMerge {
+			id, err := create()
+			if err != nil {
+				return s.fail(result, "create", err)
+			}
+			result.TargetId = id
+		}
+	}
+	if err := content(result.TargetId); err != nil {
+		return s.fail(result, "copy", err)
+	}
+	log.Infof("sync %s %s: %s, %d copied, %d dropped", result.Category, result.Name, result.Action, result.Copied, result.Dropped)
+	return nil
+}
+
+func (s *syncer) fail(result *SyncResult, op string, err error) error {
+	return fmt.Errorf("sync %s %s: %s: %w", result.Category, result.Name, op, err)
+}
+
+// pages calls fn with the page numbers from 1 until it returns no entries
+// or the total it reports has been read.
+func pages[T any](fn func(page int64) ([]T, int64, error)) ([]T, error) {
+	var all []T
+	for page := int64(1); ; page++ {
+		items, total, err := fn(page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) == 0 || int64(len(all)) >= total {
+			return all, nil
+		}
+	}
+}
+
+func (s *syncer) datasets(ctx context.Context) error {
+	list := func(storage Storage) ([]models.Dataset, error) {
+		return pages(func(page int64) ([]models.Dataset, int64, error) {
+			resp, err := storage.ListDatasets(ctx, &models.ListDatasetsRequest{Page: page, PageSize: s.opts.PageSize})
+			if err != nil {
+				return nil, 0, err
+			}
+			return resp.Items, resp.Total, nil
+		})
+	}
+	datasets, err := list(s.src)
+	if err != nil {
+		return fmt.Errorf("list source datasets: %w", err)
+	}
+	existing, err := list(s.dst)
+	if err != nil {
+		return fmt.Errorf("list destination datasets: %w", err)
+	}
+	names := make(map[string]string)
+	for _, dataset := range existing {
+		if _, ok := names[dataset.Name]; !ok {
+			names[dataset.Name] = dataset.Id
+		}
+	}
+	for _, dataset := range datasets {
+		if !s.selected(dataset.Name) {
+			continue
+		}
+		result := s.plan(SyncDatasets, dataset.Name, dataset.Id, names)
+		err = s.run(ctx, result,
+			func(id string) error {
+				_, err := s.dst.DelDataset(ctx, id)
+				return err
+			},
+			func() (string, error) {
+				created, err := s.dst.CreateDataset(ctx, &models.CreateDatasetRequest{
+					Name:    dataset.Name,
+					ActorId: &dataset.ActorId,
+					RunId:   &dataset.RunId,
+				})
+				if err != nil {
+					return "", err
+				}
+				if len(dataset.Schema) > 0 {
+					err = s.dst.SetDatasetSchema(ctx, created.Id, dataset.Schema)
+				}
+				return created.Id, err
+			},
+			func(dstId string) error {
+				for page := 1; ; page++ {
+					resp, err := s.src.GetDataset(ctx, &models.GetDataset{DatasetId: dataset.Id, Page: page, PageSize: int(s.opts.PageSize)})
+					if err != nil {
+						return err
+					}
+					if len(resp.Items) == 0 {
+						return nil
+					}
+					if !s.opts.DryRun {
+						if _, err = s.dst.AddDatasetItem(ctx, dstId, resp.Items); err != nil {
+							return err
+						}
+					}
+					result.Copied += len(resp.Items)
+					if result.Copied >= resp.Total {
+						return nil
+					}
+				}
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *syncer) namespaces(ctx context.Context) error {
+	list := func(storage Storage) ([]models.KvNamespaceItem, error) {
+		return pages(func(page int64) ([]models.KvNamespaceItem, int64, error) {
+			resp, err := storage.ListNamespaces(ctx, page, s.opts.PageSize, false)
+			if err != nil {
+				return nil, 0, err
+			}
+			return resp.Items, resp.Total, nil
+		})
+	}
+	namespaces, err := list(s.src)
+	if err != nil {
+		return fmt.Errorf("list source namespaces: %w", err)
+	}
+	existing, err := list(s.dst)
+	if err != nil {
+		return fmt.Errorf("list destination namespaces: %w", err)
+	}
+	names := make(map[string]string)
+	for _, namespace := range existing {
+		names[namespace.Name] = namespace.Id
+	}
+	for _, namespace := range namespaces {
+		if !s.selected(namespace.Name) {
+			continue
+		}
+		result := s.plan(SyncKV, namespace.Name, namespace.Id, names)
+		err = s.run(ctx, result,
+			func(id string) error {
+				_, err := s.dst.DelNamespace(ctx, id)
+				return err
+			},
+			func() (string, error) {
+				return s.dst.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{
+					Name:    namespace.Name,
+					ActorId: namespace.ActorId,
+					RunId:   namespace.RunId,
+				})
+			},
+			func(dstId string) error {
+				return s.eachKeys(ctx, namespace.Id, func(keys []string) error {
+					return s.copyKeys(ctx, result, namespace.Id, dstId, keys)
+				})
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eachKeys calls fn with the keys of the source namespace, a page at a time.
+// Storages that can't scan keys are listed by page number instead.
+func (s *syncer) eachKeys(ctx context.Context, namespaceId string, fn func(keys []string) error) error {
+	after := ""
+	for {
+		scan, err := s.src.ScanKeys(ctx, &models.ScanKeysRequest{NamespaceId: namespaceId, After: after, Limit: s.opts.PageSize})
+		if errors.Is(err, errors.ErrUnsupported) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		keys := itemKeys(scan.Items)
+		if len(keys) > 0 {
+			if err = fn(keys); err != nil {
+				return err
+			}
+		}
+		if !scan.More || len(keys) == 0 {
+			return nil
+		}
+		after = keys[len(keys)-1]
+	}
+	read := 0
+	for page := int64(1); ; page++ {
+		list, err := s.src.ListKeys(ctx, &models.ListKeyInfo{NamespaceId: namespaceId, Page: page, Size: s.opts.PageSize})
+		if err != nil {
+			return err
+		}
+		if len(list.Items) == 0 {
+			return nil
+		}
+		if err = fn(itemKeys(list.Items)); err != nil {
+			return err
+		}
+		if read += len(list.Items); int64(read) >= list.Total {
+			return nil
+		}
+	}
+}
+
+func itemKeys(items []map[string]any) []string {
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		if key, ok := item["key"].(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// copyKeys copies keys of the source namespace to the destination one with
+// the time they have left.
+func (s *syncer) copyKeys(ctx context.Context, result *SyncResult, srcId string, dstId string, keys []string) error {
+	items := make([]models.BulkItem, 0, len(keys))
+	for _, key := range keys {
+		item := models.BulkItem{Key: key}
+		value, err := s.src.GetWithMetadata(ctx, srcId, key)
+		switch {
+		case errors.Is(err, errors.ErrUnsupported):
+			if item.Value, err = s.src.GetValue(ctx, srcId, key); err != nil {
+				return err
+			}
+		case errors.Is(err, errs.ErrNotFound):
+			result.Dropped++
+			continue
+		case err != nil:
+			return err
+		default:
+			item.Value = value.Value
+			if !value.ExpireAt.IsZero() {
+				left := math.Ceil(time.Until(value.ExpireAt).Seconds())
+				if left <= 0 {
+					result.Dropped++
+					continue
+				}
+				item.Expiration = uint(left)
+			}
+		}
+		items = append(items, item)
+	}
+	if len(items) > 0 && !s.opts.DryRun {
+		if _, err := s.dst.BulkSetValue(ctx, &models.BulkSet{NamespaceId: dstId, Items: items}); err != nil {
+			return err
+		}
+	}
+	result.Copied += len(items)
+	return nil
+}
+
+func (s *syncer) queues(ctx context.Context) error {
+	list := func(storage Storage) ([]*models.Queue, error) {
+		return pages(func(page int64) ([]*models.Queue, int64, error) {
+			resp, err := storage.GetQueues(ctx, &models.GetQueuesRequest{Page: page, PageSize: s.opts.PageSize})
+			if err != nil {
+				return nil, 0, err
+			}
+			return resp.Items, resp.Total, nil
+		})
+	}
+	queues, err := list(s.src)
+	if err != nil {
+		return fmt.Errorf("list source queues: %w", err)
+	}
+	existing, err := list(s.dst)
+	if err != nil {
+		return fmt.Errorf("list destination queues: %w", err)
+	}
+	names := make(map[string]string)
+	for _, queue := range existing {
+		names[queue.Name] = queue.Id
+	}
+	byId := make(map[string]*models.Queue, len(queues))
+	for _, queue := range queues {
+		byId[queue.Id] = queue
+	}
+	// Dead-letter queues are synced before the queues using them, to link
+	// the copies.
+	synced := make(map[string]string)
+	var syncQueue func(queue *models.Queue) error
+	syncQueue = func(queue *models.Queue) error {
+		if _, ok := synced[queue.Id]; ok {
+			return nil
+		}
+		synced[queue.Id] = ""
+		if dlq, ok := byId[queue.DeadLetterQueueId]; ok && s.selected(dlq.Name) {
+			if err := syncQueue(dlq); err != nil {
+				return err
+			}
+		}
+		result := s.plan(SyncQueues, queue.Name, queue.Id, names)
+		err := s.run(ctx, result,
+			func(id string) error {
+				return s.dst.DelQueue(ctx, &models.DelQueueRequest{QueueId: id})
+			},
+			func() (string, error) {
+				resp, err := s.dst.CreateQueue(ctx, &models.CreateQueueRequest{
+					Name:              queue.Name,
+					ActorId:           queue.ActorId,
+					RunId:             queue.RunId,
+					Description:       queue.Description,
+					DeadLetterQueueId: synced[queue.DeadLetterQueueId],
+				})
+				if err != nil {
+					return "", err
+				}
+				return resp.Id, nil
+			},
+			func(dstId string) error {
+				return s.copyMsgs(ctx, result, queue.Id, dstId)
+			})
+		synced[queue.Id] = result.TargetId
+		return err
+	}
+	for _, queue := range queues {
+		if !s.selected(queue.Name) {
+			continue
+		}
+		if err = syncQueue(queue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyMsgs creates the messages of the source queue not acked yet in the
+// destination one.
+func (s *syncer) copyMsgs(ctx context.Context, result *SyncResult, srcId string, dstId string) error {
+	read := 0
+	for page := int64(1); ; page++ {
+		list, err := s.src.ListMsgs(ctx, &models.ListMsgsRequest{QueueId: srcId, Page: page, PageSize: s.opts.PageSize})
+		if errors.Is(err, errors.ErrUnsupported) {
+			log.Warnf("sync queue %s: the source can't list messages, none copied", result.Name)
+			result.Skipped = "messages: the source can't list them without leasing them"
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(list.Items) == 0 {
+			return nil
+		}
+		for _, msg := range list.Items {
+			if msg.Deadline < time.Now().Unix()+300 {
+				result.Dropped++
+				continue
+			}
+			if !s.opts.DryRun {
+				_, err = s.dst.CreateMsg(ctx, &models.CreateMsgRequest{
+					QueueId:  dstId,
+					Name:     msg.Name,
+					PayLoad:  msg.Payload,
+					Retry:    msg.Retry,
+					Timeout:  msg.Timeout,
+					Deadline: msg.Deadline,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			result.Copied++
+		}
+		if read += len(list.Items); int64(read) >= list.Total {
+			return nil
+		}
+	}
+}
+
+func (s *syncer) buckets(ctx context.Context) error {
+	list := func(storage Storage) ([]models.Bucket, error) {
+		return pages(func(page int64) ([]models.Bucket, int64, error) {
+			resp, err := storage.ListBuckets(ctx, int(page), int(s.opts.PageSize))
+			if err != nil {
+				return nil, 0, err
+			}
+			return resp.Buckets, resp.Total, nil
+		})
+	}
+	buckets, err := list(s.src)
+	if err != nil {
+		return fmt.Errorf("list source buckets: %w", err)
+	}
+	existing, err := list(s.dst)
+	if err != nil {
+		return fmt.Errorf("list destination buckets: %w", err)
+	}
+	names := make(map[string]string)
+	for _, bucket := range existing {
+		names[bucket.Name] = bucket.Id
+	}
+	for _, bucket := range buckets {
+		if !s.selected(bucket.Name) {
+			continue
+		}
+		result := s.plan(SyncBuckets, bucket.Name, bucket.Id, names)
+		err = s.run(ctx, result,
+			func(id string) error {
+				_, err := s.dst.DeleteBucket(ctx, id)
+				return err
+			},
+			func() (string, error) {
+				return s.dst.CreateBucket(ctx, &models.CreateBucketRequest{
+					Name:        bucket.Name,
+					Description: bucket.Description,
+					ActorId:     bucket.ActorId,
+					RunId:       bucket.RunId,
+				})
+			},
+			func(dstId string) error {
+				objects, err := pages(func(page int64) ([]models.BucketObject, int64, error) {
+					resp, err := s.src.ListObjects(ctx, &models.ListObjectsRequest{BucketId: bucket.Id, Page: page, PageSize: s.opts.PageSize})
+					if err != nil {
+						return nil, 0, err
+					}
+					return resp.Objects, resp.Total, nil
+				})
+				if err != nil {
+					return err
+				}
+				for _, object := range objects {
+					if !s.opts.DryRun {
+						if err = s.copyObject(ctx, bucket.Id, dstId, object); err != nil {
+							return err
+						}
+					}
+					result.Copied++
+				}
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyObject streams object from the source bucket to the destination one.
+func (s *syncer) copyObject(ctx context.Context, srcId string, dstId string, object models.BucketObject) error {
+	r, err := s.src.GetObjectStream(ctx, &models.GetObjectStreamRequest{BucketId: srcId, ObjectId: object.Id})
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = s.dst.PutObjectStream(ctx, &models.PutObjectStreamRequest{
+		BucketId: dstId,
+		Filename: object.Filename,
+		Reader:   r,
+		Size:     int64(object.Size),
+		ActorId:  object.ActorId,
+		RunId:    object.RunId,
+	})
+	return err
+}
@@ -0,0 +1,342 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/env"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/storage_bolt"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/storage_http"
+)
+
+var ctx = context.Background()
+
+func openTemp(t *testing.T) *storage_bolt.BoltClient {
+	t.Helper()
+	c, err := storage_bolt.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+// result returns the result of the resource name of category.
+func result(t *testing.T, results []SyncResult, category string, name string) SyncResult {
+	t.Helper()
+	for _, r := range results {
+		if r.Category == category && r.Name == name {
+			return r
+		}
+	}
+	t.Fatalf("no result for %s %s in %+v", category, name, results)
+	return SyncResult{}
+}
+
+func keys(t *testing.T, s Storage, namespaceId string) map[string]string {
+	t.Helper()
+	list, err := s.ListKeys(ctx, &models.ListKeyInfo{NamespaceId: namespaceId, Page: 1, Size: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]string)
+	for _, key := range itemKeys(list.Items) {
+		if values[key], err = s.GetValue(ctx, namespaceId, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return values
+}
+
+func TestSync(t *testing.T) {
+	src, dst := openTemp(t), openTemp(t)
+
+	dataset, err := src.CreateDataset(ctx, &models.CreateDatasetRequest{Name: "items"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = src.SetDatasetSchema(ctx, dataset.Id, []byte(`{"type":"object"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = src.AddDatasetItem(ctx, dataset.Id, []map[string]any{{"n": 1}, {"n": 2}, {"n": 3}}); err != nil {
+		t.Fatal(err)
+	}
+	namespaceId, err := src.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{Name: "ns"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = src.BulkSetValue(ctx, &models.BulkSet{NamespaceId: namespaceId, Items: []models.BulkItem{
+		{Key: "a", Value: "1"},
+		{Key: "b", Value: "2", Expiration: 3600},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dlq, err := src.CreateQueue(ctx, &models.CreateQueueRequest{Name: "dlq"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	queue, err := src.CreateQueue(ctx, &models.CreateQueueRequest{Name: "jobs", DeadLetterQueueId: dlq.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	for _, deadline := range []int64{now + 3600, now + 7200} {
+		_, err = src.CreateMsg(ctx, &models.CreateMsgRequest{QueueId: queue.Id, Name: "job", PayLoad: "p", Retry: 2, Timeout: 30, Deadline: deadline})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	bucketId, err := src.CreateBucket(ctx, &models.CreateBucketRequest{Name: "files"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = src.PutObject(ctx, &models.PutObjectRequest{BucketId: bucketId, Filename: "a.txt", Data: []byte("hello")}); err != nil {
+		t.Fatal(err)
+	}
+
+	dstNamespaceId, err := dst.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{Name: "ns"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = dst.SetValue(ctx, &models.SetValue{NamespaceId: dstNamespaceId, Key: "old", Value: "0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A dry run reports without writing.
+	results, err := Sync(ctx, src, dst, SyncOptions{DryRun: true, PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := result(t, results, SyncDatasets, "items"); r.Action != SyncCreate || r.Copied != 3 || r.TargetId != "" {
+		t.Errorf("dry run dataset = %+v", r)
+	}
+	if r := result(t, results, SyncKV, "ns"); r.Action != SyncSkip {
+		t.Errorf("dry run namespace = %+v, want skipped", r)
+	}
+	if list, _ := dst.ListDatasets(ctx, &models.ListDatasetsRequest{Page: 1, PageSize: 10}); list.Total != 1 {
+		t.Errorf("dry run created datasets: %+v", list.Items)
+	}
+
+	results, err = Sync(ctx, src, dst, SyncOptions{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := result(t, results, SyncDatasets, "items")
+	items, err := dst.GetDataset(ctx, &models.GetDataset{DatasetId: r.TargetId, Page: 1, PageSize: 10})
+	if err != nil || items.Total != 3 {
+		t.Errorf("GetDataset(copy) = %+v, %v", items, err)
+	}
+	copied, err := dst.ListDatasets(ctx, &models.ListDatasetsRequest{Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range copied.Items {
+		if d.Id == r.TargetId && string(d.Schema) != `{"type":"object"}` {
+			t.Errorf("dataset copy schema = %s", d.Schema)
+		}
+	}
+	if got := keys(t, dst, dstNamespaceId); len(got) != 1 {
+		t.Errorf("skipped namespace keys = %v", got)
+	}
+	r = result(t, results, SyncQueues, "jobs")
+	if r.Action != SyncCreate || r.Copied != 2 || r.Dropped != 0 {
+		t.Errorf("queue result = %+v", r)
+	}
+	got, err := dst.GetQueue(ctx, &models.GetQueueRequest{Id: r.TargetId})
+	if err != nil || got.DeadLetterQueueId != result(t, results, SyncQueues, "dlq").TargetId {
+		t.Errorf("GetQueue(copy) = %+v, %v, want the dead-letter queue copy", got, err)
+	}
+	msgs, err := dst.GetMsg(ctx, &models.GetMsgRequest{QueueId: r.TargetId, Limit: 10})
+	if err != nil || len(*msgs) != 2 || (*msgs)[0].Payload != "p" || (*msgs)[0].Retry != 2 {
+		t.Errorf("GetMsg(copy) = %+v, %v", msgs, err)
+	}
+	r = result(t, results, SyncBuckets, "files")
+	objects, err := dst.ListObjects(ctx, &models.ListObjectsRequest{BucketId: r.TargetId, Page: 1, PageSize: 10})
+	if err != nil || objects.Total != 1 {
+		t.Fatalf("ListObjects(copy) = %+v, %v", objects, err)
+	}
+	if data, err := dst.GetObject(ctx, &models.ObjectRequest{BucketId: r.TargetId, ObjectId: objects.Objects[0].Id}); err != nil || string(data) != "hello" {
+		t.Errorf("GetObject(copy) = %q, %v", data, err)
+	}
+
+	// Merging keeps the keys of the destination.
+	results, err = Sync(ctx, src, dst, SyncOptions{Categories: []string{SyncKV}, Names: []string{"ns"}, Conflict: ConflictMerge})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Action != SyncMerge || results[0].TargetId != dstNamespaceId {
+		t.Errorf("merge results = %+v", results)
+	}
+	if got := keys(t, dst, dstNamespaceId); len(got) != 3 || got["a"] != "1" || got["b"] != "2" {
+		t.Errorf("merged namespace keys = %v", got)
+	}
+	value, err := dst.GetWithMetadata(ctx, dstNamespaceId, "b")
+	if err != nil || value.ExpireAt.IsZero() || time.Until(value.ExpireAt) > time.Hour {
+		t.Errorf("GetWithMetadata(b) = %+v, %v, want the expiration kept", value, err)
+	}
+
+	// Overwriting replaces them.
+	results, err = Sync(ctx, src, dst, SyncOptions{Categories: []string{SyncKV}, Names: []string{"ns"}, Conflict: ConflictOverwrite})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r = result(t, results, SyncKV, "ns")
+	if r.Action != SyncOverwrite || r.TargetId == dstNamespaceId {
+		t.Errorf("overwrite result = %+v", r)
+	}
+	if got := keys(t, dst, r.TargetId); len(got) != 2 {
+		t.Errorf("overwritten namespace keys = %v", got)
+	}
+
+	if _, err = Sync(ctx, src, dst, SyncOptions{Conflict: "replace"}); err == nil {
+		t.Error("Sync(unknown conflict policy) succeeded")
+	}
+}
+
+// datasetAPI serves the dataset endpoints of the storage API from memory.
+type datasetAPI struct {
+	t        *testing.T
+	mu       sync.Mutex
+	datasets []*models.Dataset
+	items    map[string][]map[string]any
+}
+
+func (a *datasetAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	size, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	bounds := func(total int) (int, int) {
+		start := min(max(page-1, 0)*size, total)
+		return start, min(start+size, total)
+	}
+	var data any
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/dataset")
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		// Listing without actor and run filters lists the datasets of the team.
+		if q := r.URL.Query(); q.Has("actorId") || q.Has("runId") {
+			a.t.Errorf("list datasets filtered by %s", r.URL.RawQuery)
+		}
+		start, end := bounds(len(a.datasets))
+		data = &models.ListDatasetsResponse{Items: derefAll(a.datasets[start:end]), Total: int64(len(a.datasets))}
+	case path == "" && r.Method == http.MethodPost:
+		var req models.CreateDatasetRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		dataset := &models.Dataset{Id: fmt.Sprint("ds", len(a.datasets)+1), Name: req.Name}
+		a.datasets = append(a.datasets, dataset)
+		data = dataset
+	case strings.HasSuffix(path, "/items") && r.Method == http.MethodGet:
+		items := a.items[strings.Trim(strings.TrimSuffix(path, "/items"), "/")]
+		start, end := bounds(len(items))
+		data = &models.DatasetItem{Items: items[start:end], Total: len(items)}
+	case strings.HasSuffix(path, "/items") && r.Method == http.MethodPost:
+		var req struct {
+			Items []map[string]any `json:"items"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		id := strings.Trim(strings.TrimSuffix(path, "/items"), "/")
+		a.items[id] = append(a.items[id], req.Items...)
+		data = true
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+func derefAll(datasets []*models.Dataset) []models.Dataset {
+	items := make([]models.Dataset, len(datasets))
+	for i, dataset := range datasets {
+		items[i] = *dataset
+	}
+	return items
+}
+
+func TestSyncHTTP(t *testing.T) {
+	api := &datasetAPI{
+		t:        t,
+		datasets: []*models.Dataset{{Id: "ds1", Name: "cloud"}},
+		items:    map[string][]map[string]any{"ds1": {{"n": 1.0}, {"n": 2.0}, {"n": 3.0}}},
+	}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	cloud, err := storage_http.New(env.NewConfig(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := openTemp(t)
+	localDataset, err := local.CreateDataset(ctx, &models.CreateDatasetRequest{Name: "local"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = local.AddDatasetItem(ctx, localDataset.Id, []map[string]any{{"n": 4.0}}); err != nil {
+		t.Fatal(err)
+	}
+	opts := SyncOptions{Categories: []string{SyncDatasets}, PageSize: 2}
+
+	// Pulled from the cloud.
+	results, err := Sync(ctx, cloud, local, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := result(t, results, SyncDatasets, "cloud")
+	if r.Action != SyncCreate || r.Copied != 3 {
+		t.Errorf("pull result = %+v", r)
+	}
+	items, err := local.GetDataset(ctx, &models.GetDataset{DatasetId: r.TargetId, Page: 1, PageSize: 10})
+	if err != nil || items.Total != 3 {
+		t.Errorf("GetDataset(pulled) = %+v, %v", items, err)
+	}
+
+	// Pushed to it.
+	results, err = Sync(ctx, local, cloud, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r = result(t, results, SyncDatasets, "cloud"); r.Action != SyncSkip {
+		t.Errorf("push result of the pulled dataset = %+v, want skipped", r)
+	}
+	r = result(t, results, SyncDatasets, "local")
+	if r.Action != SyncCreate || r.Copied != 1 || len(api.items[r.TargetId]) != 1 {
+		t.Errorf("push result = %+v, items %v", r, api.items[r.TargetId])
+	}
+}
+
+// unlistedMsgs is a storage that can't list messages, like the cloud one.
+type unlistedMsgs struct {
+	Storage
+}
+
+func (unlistedMsgs) ListMsgs(ctx context.Context, req *models.ListMsgsRequest) (*models.ListMsgsResponse, error) {
+	return nil, fmt.Errorf("list messages %w", errors.ErrUnsupported)
+}
+
+func TestSyncUnlistedMsgs(t *testing.T) {
+	src := openTemp(t)
+	queue, err := src.CreateQueue(ctx, &models.CreateQueueRequest{Name: "jobs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = src.CreateMsg(ctx, &models.CreateMsgRequest{QueueId: queue.Id, PayLoad: "p", Retry: 1, Timeout: 60, Deadline: time.Now().Unix() + 3600}); err != nil {
+		t.Fatal(err)
+	}
+	results, err := Sync(ctx, unlistedMsgs{src}, openTemp(t), SyncOptions{Categories: []string{SyncQueues}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := result(t, results, SyncQueues, "jobs"); r.Action != SyncCreate || r.Copied != 0 || r.Skipped == "" {
+		t.Errorf("result = %+v, want the messages skipped", r)
+	}
+}
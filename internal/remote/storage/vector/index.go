@@ -0,0 +1,304 @@
+package vector
+
+import (
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+)
+
+// Index is an in-memory Hierarchical Navigable Small World graph used to
+// answer approximate nearest neighbour queries on large collections.
+//
+// Removed or replaced documents are only marked as deleted: they keep routing
+// searches through the graph but are never returned. The index is rebuilt
+// once too many nodes are deleted.
+type Index struct {
+	dist           func(a, b []float64) float64
+	m              int
+	mMax0          int
+	efConstruction int
+	levelMult      float64
+
+	nodes    []*hnswNode
+	ids      map[string]int
+	entry    int
+	maxLevel int
+	deleted  int
+	rng      *rand.Rand
+}
+
+type hnswNode struct {
+	id      string
+	vec     []float64
+	links   [][]int
+	deleted bool
+}
+
+const (
+	hnswM              = 16
+	hnswEfConstruction = 200
+	hnswEfSearch       = 64
+)
+
+func NewIndex(dist func(a, b []float64) float64) *Index {
+	return &Index{
+		dist:           dist,
+		m:              hnswM,
+		mMax0:          2 * hnswM,
+		efConstruction: hnswEfConstruction,
+		levelMult:      1 / math.Log(hnswM),
+		ids:            make(map[string]int),
+		entry:          -1,
+		rng:            rand.New(rand.NewPCG(1, 2)),
+	}
+}
+
+// Len returns the number of live nodes.
+func (h *Index) Len() int {
+	return len(h.ids)
+}
+
+// NeedsRebuild reports whether deleted nodes make up more than a quarter of the graph.
+func (h *Index) NeedsRebuild() bool {
+	return h.deleted > len(h.nodes)/4
+}
+
+// Remove removes the vector of id from the results.
+func (h *Index) Remove(id string) {
+	i, ok := h.ids[id]
+	if !ok {
+		return
+	}
+	h.nodes[i].deleted = true
+	delete(h.ids, id)
+	h.deleted++
+}
+
+// Add inserts vec under id, replacing any previous vector of id.
+func (h *Index) Add(id string, vec []float64) {
+	h.Remove(id)
+
+	level := int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMult))
+	idx := len(h.nodes)
+	node := &hnswNode{id: id, vec: vec, links: make([][]int, level+1)}
+	h.nodes = append(h.nodes, node)
+	h.ids[id] = idx
+
+	if h.entry < 0 {
+		h.entry, h.maxLevel = idx, level
+		return
+	}
+
+	cur := h.entry
+	for l := h.maxLevel; l > level; l-- {
+		cur = h.greedy(vec, cur, l)
+	}
+	for l := min(level, h.maxLevel); l >= 0; l-- {
+		candidates := h.searchLayer(vec, cur, h.efConstruction, l)
+		maxLinks := h.m
+		if l == 0 {
+			maxLinks = h.mMax0
+		}
+		neighbours := candidates
+		if len(neighbours) > h.m {
+			neighbours = neighbours[:h.m]
+		}
+		for _, n := range neighbours {
+			node.links[l] = append(node.links[l], n.node)
+			h.link(n.node, idx, l, maxLinks)
+		}
+		cur = candidates[0].node
+	}
+	if level > h.maxLevel {
+		h.entry, h.maxLevel = idx, level
+	}
+}
+
+// link adds a link from node from to node to on level l, keeping only the
+// maxLinks closest neighbours of from.
+func (h *Index) link(from, to, l, maxLinks int) {
+	n := h.nodes[from]
+	n.links[l] = append(n.links[l], to)
+	if len(n.links[l]) <= maxLinks {
+		return
+	}
+	sort.Slice(n.links[l], func(i, j int) bool {
+		return h.dist(n.vec, h.nodes[n.links[l][i]].vec) < h.dist(n.vec, h.nodes[n.links[l][j]].vec)
+	})
+	n.links[l] = n.links[l][:maxLinks]
+}
+
+// greedy walks level l from entry towards the node closest to q.
+func (h *Index) greedy(q []float64, entry, l int) int {
+	cur, curDist := entry, h.dist(q, h.nodes[entry].vec)
+	for changed := true; changed; {
+		changed = false
+		for _, n := range h.nodes[cur].links[l] {
+			if d := h.dist(q, h.nodes[n].vec); d < curDist {
+				cur, curDist, changed = n, d, true
+			}
+		}
+	}
+	return cur
+}
+
+// searchLayer returns up to ef nodes of level l closest to q, nearest first.
+func (h *Index) searchLayer(q []float64, entry, ef, l int) []hnswCandidate {
+	start := hnswCandidate{node: entry, dist: h.dist(q, h.nodes[entry].vec)}
+	visited := map[int]struct{}{entry: {}}
+	candidates := &hnswMinHeap{start}
+	results := &hnswMaxHeap{start}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(hnswCandidate)
+		if c.dist > (*results)[0].dist && results.Len() >= ef {
+			break
+		}
+		for _, n := range h.nodes[c.node].links[l] {
+			if _, ok := visited[n]; ok {
+				continue
+			}
+			visited[n] = struct{}{}
+			d := h.dist(q, h.nodes[n].vec)
+			if results.Len() < ef || d < (*results)[0].dist {
+				heap.Push(candidates, hnswCandidate{node: n, dist: d})
+				heap.Push(results, hnswCandidate{node: n, dist: d})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	out := make([]hnswCandidate, results.Len())
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(results).(hnswCandidate)
+	}
+	return out
+}
+
+// Search returns the ids of up to k live nodes closest to q.
+func (h *Index) Search(q []float64, k int) []string {
+	if h.entry < 0 || k <= 0 {
+		return nil
+	}
+	cur := h.entry
+	for l := h.maxLevel; l > 0; l-- {
+		cur = h.greedy(q, cur, l)
+	}
+	// Deleted nodes take up room in the candidate list, so widen it accordingly.
+	ef := max(hnswEfSearch, k) + h.deleted
+	ids := make([]string, 0, k)
+	for _, c := range h.searchLayer(q, cur, ef, 0) {
+		if n := h.nodes[c.node]; !n.deleted {
+			ids = append(ids, n.id)
+			if len(ids) == k {
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// Indexes caches the index of the large collections by id, along with the
+// version of the docs it was built from. It isn't safe for concurrent use.
+type Indexes map[string]*cachedIndex
+
+type cachedIndex struct {
+	index   *Index
+	version string
+}
+
+// For returns the index to answer req on the docs of coll with, building it
+// if needed, or nil when the docs should be scanned instead. version
+// identifies the stored docs: a cached index built from another version is
+// rebuilt, as the docs may have been rewritten by another process.
+func (x *Indexes) For(coll *models.Collection, docs map[string]models.Doc, version string, req *models.QueryVectorRequest) *Index {
+	// Sparse scores can reorder results, so hybrid queries are always exact.
+	if len(req.Vector) == 0 || len(req.SparseVector) > 0 || len(docs) < IndexMinDocs {
+		delete(*x, coll.Id)
+		return nil
+	}
+	if *x == nil {
+		*x = make(Indexes)
+	}
+	cached := (*x)[coll.Id]
+	if cached == nil || cached.version != version || cached.index.NeedsRebuild() {
+		metric := coll.Metric
+		index := NewIndex(func(a, b []float64) float64 {
+			score := DenseScore(metric, a, b)
+			if metric == MetricDotProduct {
+				return -score
+			}
+			return score
+		})
+		for id, doc := range docs {
+			if len(doc.Vector) > 0 {
+				index.Add(id, doc.Vector)
+			}
+		}
+		cached = &cachedIndex{index: index, version: version}
+		(*x)[coll.Id] = cached
+	}
+	return cached.index
+}
+
+// Written records that the docs of the collection went from version before
+// to after by storing the written docs and deleting the removed ids. The
+// cached index, if any, is updated when it was built from before and dropped
+// otherwise.
+func (x Indexes) Written(collId string, before, after string, written []models.Doc, removed []string) {
+	cached := x[collId]
+	if cached == nil {
+		return
+	}
+	if cached.version != before {
+		delete(x, collId)
+		return
+	}
+	for _, doc := range written {
+		if len(doc.Vector) > 0 {
+			cached.index.Add(doc.ID, doc.Vector)
+		} else {
+			cached.index.Remove(doc.ID)
+		}
+	}
+	for _, id := range removed {
+		cached.index.Remove(id)
+	}
+	cached.version = after
+}
+
+type hnswCandidate struct {
+	node int
+	dist float64
+}
+
+type hnswMinHeap []hnswCandidate
+
+func (h hnswMinHeap) Len() int           { return len(h) }
+func (h hnswMinHeap) Less(i, j int) bool { return h[i].dist < h[j].dist }
+func (h hnswMinHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *hnswMinHeap) Push(x any)        { *h = append(*h, x.(hnswCandidate)) }
+func (h *hnswMinHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+type hnswMaxHeap []hnswCandidate
+
+func (h hnswMaxHeap) Len() int           { return len(h) }
+func (h hnswMaxHeap) Less(i, j int) bool { return h[i].dist > h[j].dist }
+func (h hnswMaxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *hnswMaxHeap) Push(x any)        { *h = append(*h, x.(hnswCandidate)) }
+func (h *hnswMaxHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,62 @@
+package vector
+
+import (
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestHNSWRecall(t *testing.T) {
+	const n, dim, k = 2000, 16, 10
+	rng := rand.New(rand.NewPCG(3, 4))
+	dist := func(a, b []float64) float64 { return DenseScore(MetricEuclidean, a, b) }
+	index := NewIndex(dist)
+	vectors := make(map[string][]float64, n)
+	for i := 0; i < n; i++ {
+		vec := make([]float64, dim)
+		for j := range vec {
+			vec[j] = rng.Float64()
+		}
+		id := strconv.Itoa(i)
+		vectors[id] = vec
+		index.Add(id, vec)
+	}
+	for i := 0; i < n; i += 10 {
+		index.Remove(strconv.Itoa(i))
+		delete(vectors, strconv.Itoa(i))
+	}
+
+	hits, total := 0, 0
+	for q := 0; q < 50; q++ {
+		query := make([]float64, dim)
+		for j := range query {
+			query[j] = rng.Float64()
+		}
+		exact := make(map[string]struct{}, k)
+		for _, c := range bruteForce(vectors, query, k, dist) {
+			exact[c] = struct{}{}
+		}
+		for _, id := range index.Search(query, k) {
+			if _, ok := vectors[id]; !ok {
+				t.Fatalf("search returned deleted id %s", id)
+			}
+			if _, ok := exact[id]; ok {
+				hits++
+			}
+		}
+		total += k
+	}
+	if recall := float64(hits) / float64(total); recall < 0.9 {
+		t.Fatalf("recall %.2f below 0.9", recall)
+	}
+}
+
+func bruteForce(vectors map[string][]float64, q []float64, k int, dist func(a, b []float64) float64) []string {
+	ids := make([]string, 0, len(vectors))
+	for id := range vectors {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return dist(q, vectors[ids[i]]) < dist(q, vectors[ids[j]]) })
+	return ids[:k]
+}
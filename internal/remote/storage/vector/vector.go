@@ -0,0 +1,180 @@
+// Package vector searches vector collections on the client side. Backends
+// without server-side search score and rank documents with this package, so
+// that every backend returns the same results.
+//
+// Scores follow the collection metric:
+//   - cosine: 1 - cosine similarity, lower is closer.
+//   - euclidean: L2 distance, lower is closer.
+//   - dotproduct: inner product, higher is closer.
+//
+// Sparse vectors are scored by their inner product. A query with only a sparse
+// vector ranks documents by that score; with the dotproduct metric it is added
+// to the dense score.
+package vector
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+const (
+	MetricCosine     = "cosine"
+	MetricEuclidean  = "euclidean"
+	MetricDotProduct = "dotproduct"
+
+	DefaultTopk = 10
+	// IndexMinDocs is the collection size from which dense queries use an
+	// HNSW index instead of scanning every document.
+	IndexMinDocs = 1000
+)
+
+// NormalizeMetric returns the canonical name of metric, cosine by default.
+func NormalizeMetric(metric string) (string, error) {
+	switch strings.ToLower(metric) {
+	case "", MetricCosine:
+		return MetricCosine, nil
+	case MetricEuclidean, "l2":
+		return MetricEuclidean, nil
+	case MetricDotProduct, "dot", "ip":
+		return MetricDotProduct, nil
+	}
+	return "", fmt.Errorf("%w: unsupported metric %q", errs.ErrInvalidArgument, metric)
+}
+
+// DenseScore scores b against a with metric.
+func DenseScore(metric string, a, b []float64) float64 {
+	switch metric {
+	case MetricEuclidean:
+		var sum float64
+		for i := range a {
+			d := a[i] - b[i]
+			sum += d * d
+		}
+		return math.Sqrt(sum)
+	case MetricDotProduct:
+		var dot float64
+		for i := range a {
+			dot += a[i] * b[i]
+		}
+		return dot
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 1
+	}
+	return 1 - dot/(math.Sqrt(na)*math.Sqrt(nb))
+}
+
+// SparseDot returns the inner product of two sparse vectors.
+func SparseDot(a, b map[string]float64) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	var dot float64
+	for k, v := range a {
+		dot += v * b[k]
+	}
+	return dot
+}
+
+// CheckQuery validates req against the collection it queries.
+func CheckQuery(coll *models.Collection, req *models.QueryVectorRequest) error {
+	if len(req.Vector) == 0 && len(req.SparseVector) == 0 {
+		return fmt.Errorf("%w: vector or sparseVector is required", errs.ErrInvalidArgument)
+	}
+	if len(req.Vector) > 0 && coll.Dimension > 0 && len(req.Vector) != int(coll.Dimension) {
+		return fmt.Errorf("%w: vector dimension %d does not match collection dimension %d", errs.ErrInvalidArgument, len(req.Vector), coll.Dimension)
+	}
+	return nil
+}
+
+// CheckDoc returns why op (insert, update or upsert) can't write doc to a
+// collection of the given dimension, or "" if it can. exists tells whether
+// the collection holds a doc with the same id.
+func CheckDoc(op string, doc models.Doc, exists bool, dimension uint32) string {
+	switch {
+	case doc.ID == "":
+		return "id is required"
+	case op == "insert" && exists:
+		return "doc already exists"
+	case op == "update" && !exists:
+		return "doc not found"
+	case len(doc.Vector) == 0 && len(doc.SparseVector) == 0:
+		return "vector or sparseVector is required"
+	case len(doc.Vector) > 0 && dimension > 0 && len(doc.Vector) != int(dimension):
+		return fmt.Sprintf("vector dimension %d does not match collection dimension %d", len(doc.Vector), dimension)
+	}
+	return ""
+}
+
+// Topk returns the number of results req asks for.
+func Topk(req *models.QueryVectorRequest) int {
+	if req.Topk <= 0 {
+		return DefaultTopk
+	}
+	return int(req.Topk)
+}
+
+// Rank scores the candidates against req with metric and returns the best
+// Topk(req), best first.
+func Rank(metric string, candidates []models.Doc, req *models.QueryVectorRequest) []*models.Doc {
+	// Lower scores rank first for distance metrics, higher scores otherwise.
+	ascending := len(req.Vector) > 0 && metric != MetricDotProduct
+	results := make([]*models.Doc, 0, len(candidates))
+	for _, doc := range candidates {
+		var score float64
+		if len(req.Vector) > 0 {
+			if len(doc.Vector) != len(req.Vector) {
+				continue
+			}
+			score = DenseScore(metric, req.Vector, doc.Vector)
+			if metric == MetricDotProduct {
+				score += SparseDot(req.SparseVector, doc.SparseVector)
+			}
+		} else {
+			if len(doc.SparseVector) == 0 {
+				continue
+			}
+			score = SparseDot(req.SparseVector, doc.SparseVector)
+		}
+		result := &models.Doc{ID: doc.ID, Score: score}
+		if req.IncludeVector {
+			result.Vector = doc.Vector
+			result.SparseVector = doc.SparseVector
+		}
+		if req.IncludeContent {
+			result.Content = doc.Content
+		}
+		results = append(results, result)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if ascending {
+			return results[i].Score < results[j].Score
+		}
+		return results[i].Score > results[j].Score
+	})
+	if topk := Topk(req); len(results) > topk {
+		results = results[:topk]
+	}
+	return results
+}
+
+// OpSucceeded is the result of a successful op on the doc id.
+func OpSucceeded(op, id string) models.DocOpResult {
+	return models.DocOpResult{DocOp: op, Id: id, Message: "Success"}
+}
+
+// OpFailed is the result of an op on the doc id rejected for message.
+func OpFailed(op, id, message string) models.DocOpResult {
+	return models.DocOpResult{DocOp: op, Id: id, Code: errs.CodeInvalidArgument, Message: message}
+}
@@ -2,6 +2,7 @@ package universal
 
 import (
 	"context"
+	"github.com/scrapeless-ai/sdk-go/env"
 	universal_http "github.com/scrapeless-ai/sdk-go/internal/remote/universal/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/universal/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
@@ -10,18 +11,21 @@ import (
 type Browser interface {
 	CreateTask(ctx context.Context, req *models.UniversalTaskRequest) ([]byte, error)
 	GetTaskResult(ctx context.Context, taskIKd string) ([]byte, error)
+	Close() error
 }
 
-var ClientInterface Browser
-
-func NewClient(serverMode, baseUrl string) {
+func NewClient(serverMode string, cfg *env.Config, baseUrl string) Browser {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		universal_http.Init(baseUrl)
-		ClientInterface = universal_http.Default()
+		c, err := universal_http.New(cfg, baseUrl)
+		if err != nil {
+			panic(err)
+		}
+		return c
 	}
+	return nil
 }
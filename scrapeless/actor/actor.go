@@ -12,9 +12,13 @@ import (
 	"github.com/scrapeless-ai/sdk-go/scrapeless/services/proxies"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/services/router"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/services/storage"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/services/storage/dataset"
 
 	"github.com/tidwall/gjson"
+	"io"
+	"iter"
 	"reflect"
+	"time"
 )
 
 type Actor struct {
@@ -24,6 +28,7 @@ type Actor struct {
 	storage      *storage.Storage
 	Server       *httpserver.Server
 	Router       *router.Router
+	cfg          *env.Config
 	closeFun     []func() error
 	datasetId    string
 	namespaceId  string
@@ -37,29 +42,43 @@ const (
 	typeHttp = "http"
 )
 
-// New creates a new Actor.
-func New() *Actor {
+// New creates a new Actor. The optional cfg selects the credentials and the
+// storage identifiers the actor works with; env.Default is used when it is omitted.
+func New(cfg ...*env.Config) *Actor {
+	c := env.Or(cfg...)
 	var actor = new(Actor)
-	actor.storage = storage.NewStorage(typeHttp)
-	actor.Browser = browser.NewBrowser(typeHttp)
-	actor.Captcha = captcha.NewCaptcha(typeHttp)
-	actor.Proxy = proxies.NewProxy(typeHttp)
-	actor.Router = router.New(typeHttp)
-	actor.Server = httpserver.New()
-
-	actor.datasetId = env.Env.Actor.DatasetId
-	actor.namespaceId = env.Env.Actor.KvNamespaceId
-	actor.bucketId = env.Env.Actor.BucketId
-	actor.queueId = env.Env.Actor.QueueId
-	actor.collectionId = env.Env.Actor.CollectionId
+	actor.cfg = c
+	actor.storage = storage.NewStorage(typeHttp, c)
+	actor.Browser = browser.NewBrowser(typeHttp, c)
+	actor.Captcha = captcha.NewCaptcha(typeHttp, c)
+	actor.Proxy = proxies.NewProxy(typeHttp, c)
+	actor.Router = router.New(typeHttp, c)
+	actor.Server = httpserver.NewWithConfig(c)
+	actor.closeFun = append(actor.closeFun,
+		actor.storage.Close,
+		actor.Browser.Close,
+		actor.Captcha.Close,
+		actor.Proxy.Close,
+		actor.Router.Close,
+	)
+
+	actor.datasetId = c.Actor.DatasetId
+	actor.namespaceId = c.Actor.KvNamespaceId
+	actor.bucketId = c.Actor.BucketId
+	actor.queueId = c.Actor.QueueId
+	actor.collectionId = c.Actor.CollectionId
 	return actor
 }
 
-// Close closes the actor.
-func (a *Actor) Close() {
+// Close closes the actor: the dataset writers it created first, then its
+// services. It returns the errors of all of them, such as the items a
+// dataset writer failed to save.
+func (a *Actor) Close() error {
+	var errs []error
 	for _, f := range a.closeFun {
-		_ = f()
+		errs = append(errs, f())
 	}
+	return errors.Join(errs...)
 }
 
 // Input get input data from env.
@@ -77,7 +96,7 @@ func (a *Actor) Input(data any) error {
 }
 
 func (a *Actor) Start() error {
-	return a.Server.Start(fmt.Sprintf(":%s", env.Env.Actor.HttpPort))
+	return a.Server.Start(fmt.Sprintf(":%s", a.cfg.Actor.HttpPort))
 }
 
 /**
@@ -114,6 +133,16 @@ func (a *Actor) ListKeys(ctx context.Context, page int, pageSize int) (*storage.
 	return a.storage.KV.ListKeys(ctx, a.namespaceId, int64(page), int64(pageSize))
 }
 
+// IterKeys Iterate over all keys of the default namespace (from environment variable)
+func (a *Actor) IterKeys(ctx context.Context, pageSize int) iter.Seq2[map[string]any, error] {
+	return a.storage.KV.IterKeys(ctx, a.namespaceId, int64(pageSize))
+}
+
+// ScanKeys Scan the keys with a prefix of the default namespace (from environment variable)
+func (a *Actor) ScanKeys(ctx context.Context, prefix string, cursor string, limit int64) (*storage.KeyScan, error) {
+	return a.storage.KV.ScanKeys(ctx, a.namespaceId, prefix, cursor, limit)
+}
+
 // SetValue Set a key-value pair in the default namespace (from environment variable)
 func (a *Actor) SetValue(ctx context.Context, key string, value string, expiration uint) (bool, error) {
 	return a.storage.KV.SetValue(ctx, a.namespaceId, key, value, expiration)
@@ -139,6 +168,56 @@ func (a *Actor) GetValue(ctx context.Context, key string) (string, error) {
 	return a.storage.KV.GetValue(ctx, a.namespaceId, key)
 }
 
+// CompareAndSwap Set a key of the default namespace (from environment variable) to value if it holds old
+// Offline or with the Redis storage only: online it fails with errors.ErrUnsupported.
+func (a *Actor) CompareAndSwap(ctx context.Context, key string, old string, value string, expiration uint) (bool, error) {
+	return a.storage.KV.CompareAndSwap(ctx, a.namespaceId, key, old, value, expiration)
+}
+
+// SetIfNotExists Set a key of the default namespace (from environment variable) unless it exists
+// Offline or with the Redis storage only: online it fails with errors.ErrUnsupported.
+func (a *Actor) SetIfNotExists(ctx context.Context, key string, value string, expiration uint) (bool, error) {
+	return a.storage.KV.SetIfNotExists(ctx, a.namespaceId, key, value, expiration)
+}
+
+// Increment Add delta to an integer value of the default namespace (from environment variable)
+// Offline or with the Redis storage only: online it fails with errors.ErrUnsupported.
+func (a *Actor) Increment(ctx context.Context, key string, delta int64) (int64, error) {
+	return a.storage.KV.Increment(ctx, a.namespaceId, key, delta)
+}
+
+// Touch Reset the expiration of a key of the default namespace (from environment variable)
+// Offline or with the Redis storage only: online it fails with errors.ErrUnsupported.
+func (a *Actor) Touch(ctx context.Context, key string, expiration uint) (bool, error) {
+	return a.storage.KV.Touch(ctx, a.namespaceId, key, expiration)
+}
+
+// GetWithMetadata Get a value with its size and expiry from the default namespace (from environment variable)
+// Offline or with the Redis storage only: online it fails with errors.ErrUnsupported.
+func (a *Actor) GetWithMetadata(ctx context.Context, key string) (*storage.KvValue, error) {
+	return a.storage.KV.GetWithMetadata(ctx, a.namespaceId, key)
+}
+
+// SetBytes Set a binary value in the default namespace (from environment variable)
+func (a *Actor) SetBytes(ctx context.Context, key string, data []byte, expiration uint, opts *storage.BytesOptions) (bool, error) {
+	return a.storage.KV.SetBytes(ctx, a.namespaceId, key, data, expiration, opts)
+}
+
+// GetBytes Get a binary value and its content type from the default namespace (from environment variable)
+func (a *Actor) GetBytes(ctx context.Context, key string) ([]byte, string, error) {
+	return a.storage.KV.GetBytes(ctx, a.namespaceId, key)
+}
+
+// DelBytes Delete a binary value from the default namespace (from environment variable)
+func (a *Actor) DelBytes(ctx context.Context, key string) (bool, error) {
+	return a.storage.KV.DelBytes(ctx, a.namespaceId, key)
+}
+
+// NewLock Create a lock shared by the runs using the default namespace (from environment variable)
+func (a *Actor) NewLock(key string, ttl time.Duration) *storage.Lock {
+	return storage.NewLock(a.storage.KV, a.namespaceId, key, ttl)
+}
+
 /**
  * Dataset convenience methods
  */
@@ -173,6 +252,29 @@ func (a *Actor) GetItems(ctx context.Context, page int, pageSize int, desc bool)
 	return a.storage.Dataset.GetItems(ctx, a.datasetId, page, pageSize, desc)
 }
 
+// SetSchema Set the schema items of the default dataset (from environment variable) must match
+func (a *Actor) SetSchema(ctx context.Context, schema any) error {
+	return a.storage.Dataset.SetSchema(ctx, a.datasetId, schema)
+}
+
+// QueryItems Query items of the default dataset (from environment variable)
+func (a *Actor) QueryItems(ctx context.Context, filter *storage.DatasetQuery) (*storage.ItemsResponse, error) {
+	return a.storage.Dataset.Query(ctx, a.datasetId, filter)
+}
+
+// IterItems Iterate over all items of the default dataset (from environment variable)
+func (a *Actor) IterItems(ctx context.Context, pageSize int, desc bool) iter.Seq2[map[string]any, error] {
+	return a.storage.Dataset.IterItems(ctx, a.datasetId, pageSize, desc)
+}
+
+// NewDatasetWriter Create a buffered writer of the default dataset (from environment variable).
+// The actor closes it on Close, before its storage, so buffered items are not lost at shutdown.
+func (a *Actor) NewDatasetWriter(opts ...dataset.Option) *dataset.Writer {
+	w := dataset.NewWriter(a.storage.Dataset, a.datasetId, opts...)
+	a.closeFun = append([]func() error{w.Close}, a.closeFun...)
+	return w
+}
+
 /**
  * Queue convenience methods with environment variables
  */
@@ -217,6 +319,11 @@ func (a *Actor) AckMessage(ctx context.Context, msgId string) error {
 	return a.storage.Queue.Ack(ctx, a.queueId, msgId)
 }
 
+// NackMessage Return a message to the queue, to be delivered again after delay
+func (a *Actor) NackMessage(ctx context.Context, msgId string, delay time.Duration) error {
+	return a.storage.Queue.Nack(ctx, a.queueId, msgId, delay)
+}
+
 /**
  * Object storage convenience methods with environment variables
  */
@@ -256,6 +363,16 @@ func (a *Actor) PutObject(ctx context.Context, filename string, data []byte) (st
 	return a.storage.Object.PutObject(ctx, a.bucketId, filename, data)
 }
 
+// GetObjectStream Get an object from the default bucket as a stream, starting at offset
+func (a *Actor) GetObjectStream(ctx context.Context, objectId string, offset int64) (io.ReadCloser, error) {
+	return a.storage.Object.GetObjectStream(ctx, a.bucketId, objectId, offset)
+}
+
+// PutObjectStream Upload an object read from r to the default bucket; size is -1 if unknown
+func (a *Actor) PutObjectStream(ctx context.Context, filename string, r io.Reader, size int64) (string, error) {
+	return a.storage.Object.PutObjectStream(ctx, a.bucketId, filename, r, size)
+}
+
 // DeleteObject Delete an object from a bucket
 func (a *Actor) DeleteObject(ctx context.Context, objectId string) (bool, error) {
 	return a.storage.Object.DeleteObject(ctx, a.bucketId, objectId)
@@ -2,6 +2,7 @@ package actor
 
 import (
 	"context"
+	"errors"
 	proxy2 "github.com/scrapeless-ai/sdk-go/scrapeless/services/proxies"
 	"testing"
 )
@@ -16,3 +17,20 @@ func TestNew(t *testing.T) {
 	})
 	t.Log(p)
 }
+
+func TestCloseJoinsErrors(t *testing.T) {
+	errWriter, errStorage := errors.New("dropped items"), errors.New("storage")
+	var closed []string
+	actor := &Actor{closeFun: []func() error{
+		func() error { closed = append(closed, "writer"); return errWriter },
+		func() error { closed = append(closed, "proxy"); return nil },
+		func() error { closed = append(closed, "storage"); return errStorage },
+	}}
+	err := actor.Close()
+	if !errors.Is(err, errWriter) || !errors.Is(err, errStorage) {
+		t.Errorf("Close() = %v, want both errors", err)
+	}
+	if len(closed) != 3 {
+		t.Errorf("closed %v, want all three", closed)
+	}
+}
@@ -1,6 +1,9 @@
 package scrapeless
 
 import (
+	"net/http"
+
+	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/services/actor"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/services/browser"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/services/captcha"
@@ -29,17 +32,50 @@ type Client struct {
 	Crawl     *crawl.Crawl
 	Profile   *profile.Profile
 	CloseFun  []func() error
+
+	config     *env.Config
+	httpClient *http.Client
+	transport  http.RoundTripper
 }
 
+// New creates a Client with the services selected by opts. Services use the
+// configuration given by WithConfig, or env.Default when it is not set.
 func New(opts ...Option) *Client {
 	var client = new(Client)
 	for _, opt := range opts {
-		opt.Apply(client)
+		if _, ok := opt.(setupOption); ok {
+			opt.Apply(client)
+		}
 	}
-	client.Router = router.New(typeHttp)
+	client.setupConfig()
+	for _, opt := range opts {
+		if _, ok := opt.(setupOption); !ok {
+			opt.Apply(client)
+		}
+	}
+	client.Router = router.New(typeHttp, client.config)
+	client.CloseFun = append(client.CloseFun, client.Router.Close)
 	return client
 }
 
+// setupConfig resolves the config shared by all services from the setup options.
+func (c *Client) setupConfig() {
+	if c.config == nil {
+		c.config = env.Default().Clone()
+	}
+	if c.transport != nil {
+		hc := &http.Client{}
+		if c.httpClient != nil {
+			*hc = *c.httpClient
+		}
+		hc.Transport = c.transport
+		c.httpClient = hc
+	}
+	if c.httpClient != nil {
+		c.config.HTTPClient = c.httpClient
+	}
+}
+
 // Close closes the Client.
 func (c *Client) Close() {
 	for _, f := range c.CloseFun {
@@ -56,12 +92,71 @@ type Option interface {
 	Apply(*Client)
 }
 
+// setupOption is implemented by options that must be applied before any
+// service is created, regardless of their position in the option list.
+type setupOption interface {
+	Option
+	setup()
+}
+
+type ConfigOption struct {
+	cfg *env.Config
+}
+
+func (o *ConfigOption) Apply(c *Client) {
+	if o.cfg != nil {
+		c.config = o.cfg.Clone()
+	}
+}
+
+func (o *ConfigOption) setup() {}
+
+// WithConfig sets the configuration used by every service of the Client.
+// The config is copied, so later changes to cfg do not affect the Client.
+func WithConfig(cfg *env.Config) Option {
+	return &ConfigOption{cfg: cfg}
+}
+
+type HTTPClientOption struct {
+	client *http.Client
+}
+
+func (o *HTTPClientOption) Apply(c *Client) {
+	c.httpClient = o.client
+}
+
+func (o *HTTPClientOption) setup() {}
+
+// WithHTTPClient sets the http.Client used by every service of the Client,
+// e.g. to configure timeouts, proxies, TLS or connection pooling.
+// The Client does not close idle connections of hc on Close.
+func WithHTTPClient(hc *http.Client) Option {
+	return &HTTPClientOption{client: hc}
+}
+
+type TransportOption struct {
+	transport http.RoundTripper
+}
+
+func (o *TransportOption) Apply(c *Client) {
+	c.transport = o.transport
+}
+
+func (o *TransportOption) setup() {}
+
+// WithTransport sets the http.RoundTripper used by every service of the Client.
+// When combined with WithHTTPClient, the transport replaces the one of that client
+// on a copy, leaving the original untouched.
+func WithTransport(rt http.RoundTripper) Option {
+	return &TransportOption{transport: rt}
+}
+
 type BrowserOption struct {
 	tp string
 }
 
 func (o *BrowserOption) Apply(c *Client) {
-	c.Browser = browser.NewBrowser(o.tp)
+	c.Browser = browser.NewBrowser(o.tp, c.config)
 	c.CloseFun = append(c.CloseFun, c.Browser.Close)
 }
 
@@ -80,7 +175,7 @@ type ProxyOption struct {
 }
 
 func (o *ProxyOption) Apply(a *Client) {
-	a.Proxy = proxies.NewProxy(o.tp)
+	a.Proxy = proxies.NewProxy(o.tp, a.config)
 	a.CloseFun = append(a.CloseFun, a.Proxy.Close)
 }
 
@@ -99,7 +194,7 @@ type CaptchaOption struct {
 }
 
 func (o *CaptchaOption) Apply(a *Client) {
-	a.Captcha = captcha.NewCaptcha(o.tp)
+	a.Captcha = captcha.NewCaptcha(o.tp, a.config)
 	a.CloseFun = append(a.CloseFun, a.Captcha.Close)
 }
 
@@ -118,7 +213,8 @@ type StorageOption struct {
 }
 
 func (o *StorageOption) Apply(a *Client) {
-	a.Storage = storage.NewStorage(o.tp)
+	a.Storage = storage.NewStorage(o.tp, a.config)
+	a.CloseFun = append(a.CloseFun, a.Storage.Close)
 }
 
 // WithStorage choose storage type.
@@ -136,7 +232,7 @@ type ServerOption struct {
 }
 
 func (s *ServerOption) Apply(a *Client) {
-	a.Server = httpserver.New(s.mode)
+	a.Server = httpserver.NewWithConfig(a.config, s.mode)
 }
 
 // WithServer choose server mode.
@@ -152,7 +248,7 @@ type DeepSerpOption struct {
 }
 
 func (d *DeepSerpOption) Apply(c *Client) {
-	c.DeepSerp = deepserp.NewDeepSerp(d.tp)
+	c.DeepSerp = deepserp.NewDeepSerp(d.tp, c.config)
 	c.CloseFun = append(c.CloseFun, c.DeepSerp.Close)
 }
 
@@ -171,7 +267,7 @@ type ScrapingOption struct {
 }
 
 func (s *ScrapingOption) Apply(c *Client) {
-	c.Scraping = scraping.New(s.tp)
+	c.Scraping = scraping.New(s.tp, c.config)
 	c.CloseFun = append(c.CloseFun, c.Scraping.Close)
 }
 
@@ -190,7 +286,7 @@ type UniversalOption struct {
 }
 
 func (s *UniversalOption) Apply(c *Client) {
-	c.Universal = universal.New(s.tp)
+	c.Universal = universal.New(s.tp, c.config)
 	c.CloseFun = append(c.CloseFun, c.Universal.Close)
 }
 
@@ -209,7 +305,7 @@ type ActorOption struct {
 }
 
 func (s *ActorOption) Apply(c *Client) {
-	c.Actor = actor.NewActor(s.tp)
+	c.Actor = actor.NewActor(s.tp, c.config)
 	c.CloseFun = append(c.CloseFun, c.Actor.Close)
 }
 
@@ -228,7 +324,7 @@ type CrawlOption struct {
 }
 
 func (o *CrawlOption) Apply(c *Client) {
-	c.Crawl = crawl.New()
+	c.Crawl = crawl.New(c.config)
 	c.CloseFun = append(c.CloseFun, c.Crawl.Close)
 }
 
@@ -247,7 +343,7 @@ type ProfileOption struct {
 }
 
 func (o *ProfileOption) Apply(c *Client) {
-	c.Profile = profile.New()
+	c.Profile = profile.New(c.config)
 	c.CloseFun = append(c.CloseFun, c.Profile.Close)
 }
 
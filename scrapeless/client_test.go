@@ -2,8 +2,15 @@ package scrapeless
 
 import (
 	"context"
+	"github.com/scrapeless-ai/sdk-go/env"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/services/httpserver"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/services/proxies"
+	"io"
+	"net"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
@@ -61,3 +68,68 @@ func TestNew(t *testing.T) {
 	//}
 	//log.Infof("%v", captchaResult)
 }
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestWithTransport(t *testing.T) {
+	cfg := env.NewConfig()
+	cfg.Actor.ApiKey = "test-key"
+	var got []string
+	rt := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = append(got, r.Header.Get(cfg.HTTPHeader))
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{}`)),
+			Header:     make(http.Header),
+			Request:    r,
+		}, nil
+	})
+
+	client := New(WithProfile(), WithConfig(cfg), WithTransport(rt))
+	defer client.Close()
+	_, _ = client.Profile.GetProfile(context.Background(), "profile-id")
+
+	if len(got) != 1 || got[0] != "test-key" {
+		t.Fatalf("expected one request with the configured api key, got %v", got)
+	}
+	if cfg.HTTPClient != nil {
+		t.Fatal("WithTransport must not modify the config passed to WithConfig")
+	}
+}
+
+func TestWithServerConfig(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+	cfg := env.NewConfig()
+	cfg.Actor.HttpPort = addr
+
+	client := New(WithConfig(cfg), WithServer(httpserver.TestMode))
+	defer client.Close()
+	client.Server.AddHandleGet("/ping", func(input []byte) (httpserver.Response, error) {
+		return httpserver.Response{Msg: "pong"}, nil
+	})
+	go func() { _ = client.Server.Start() }()
+
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		if resp, err = http.Get("http://" + addr + "/ping"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("server not listening on the configured port: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /ping = %s", resp.Status)
+	}
+}
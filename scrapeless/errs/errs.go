@@ -0,0 +1,132 @@
+// Package errs defines the errors returned by the Scrapeless services.
+//
+// Every service error can be inspected with errors.As to get an *APIError,
+// and classified with errors.Is against the sentinel errors of this package:
+//
+//	if errors.Is(err, errs.ErrNotFound) {
+//		// ...
+//	}
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Sentinel errors matched by errors.Is.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrAlreadyExists   = errors.New("already exists")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrRateLimited     = errors.New("rate limited")
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+// Scrapeless error codes.
+const (
+	CodeDefault         = 200000
+	CodeNotFound        = 200005
+	CodeAlreadyExists   = 200006
+	CodeInvalidArgument = 400403
+	CodeSystem          = 500000
+	CodeUnavailable     = 500014
+	CodeUnauthorized    = 500401
+)
+
+// APIError is the error returned by the Scrapeless services.
+type APIError struct {
+	// Code is the Scrapeless error code, or a gRPC status code.
+	Code    int
+	Message string
+	// HTTPStatus is the status of the HTTP response, or 0 when the error did
+	// not come from an HTTP response.
+	HTTPStatus int
+	// RequestID identifies the failed request in the Scrapeless API, if known.
+	RequestID string
+
+	err error
+}
+
+// New returns an APIError that wraps err, so the cause stays reachable with
+// errors.Is and errors.As.
+func New(code int, message string, err error) *APIError {
+	return &APIError{Code: code, Message: message, err: err}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%d | %s", e.Code, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether e belongs to the class of the sentinel target, based on
+// its Code and HTTPStatus.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.HTTPStatus == http.StatusNotFound ||
+			e.Code == CodeNotFound || e.Code == int(codes.NotFound)
+	case ErrAlreadyExists:
+		return e.HTTPStatus == http.StatusConflict ||
+			e.Code == CodeAlreadyExists || e.Code == int(codes.AlreadyExists)
+	case ErrUnauthorized:
+		return e.HTTPStatus == http.StatusUnauthorized || e.HTTPStatus == http.StatusForbidden ||
+			e.Code == CodeUnauthorized || e.Code == int(codes.Unauthenticated) || e.Code == int(codes.PermissionDenied)
+	case ErrRateLimited:
+		return e.HTTPStatus == http.StatusTooManyRequests || e.Code == int(codes.ResourceExhausted)
+	case ErrInvalidArgument:
+		return e.HTTPStatus == http.StatusBadRequest || e.HTTPStatus == http.StatusUnprocessableEntity ||
+			e.Code == CodeInvalidArgument || e.Code == int(codes.InvalidArgument)
+	}
+	return false
+}
+
+// CodeOf returns the Scrapeless error code matching the sentinel err wraps,
+// or CodeDefault.
+func CodeOf(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return CodeNotFound
+	case errors.Is(err, ErrAlreadyExists):
+		return CodeAlreadyExists
+	case errors.Is(err, ErrUnauthorized):
+		return CodeUnauthorized
+	case errors.Is(err, ErrInvalidArgument):
+		return CodeInvalidArgument
+	}
+	return CodeDefault
+}
+
+// ItemError describes why an item doesn't match the schema of its dataset.
+type ItemError struct {
+	// Index is the position of the item among the items being added.
+	Index int
+	// Reasons lists the violations, each prefixed with the JSON pointer of
+	// the offending value.
+	Reasons []string
+}
+
+// ValidationError is returned when items don't match the schema of their
+// dataset; none of the items are stored. It matches ErrInvalidArgument.
+type ValidationError struct {
+	Items []ItemError
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d invalid item(s)", len(e.Items))
+	for _, item := range e.Items {
+		fmt.Fprintf(&b, "; item %d: %s", item.Index, strings.Join(item.Reasons, ", "))
+	}
+	return b.String()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidArgument
+}
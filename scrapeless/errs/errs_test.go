@@ -0,0 +1,52 @@
+package errs_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/internal/code"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/storage_memory"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAPIErrorIs(t *testing.T) {
+	tests := []struct {
+		err    error
+		target error
+	}{
+		{&errs.APIError{Code: errs.CodeDefault, HTTPStatus: http.StatusNotFound}, errs.ErrNotFound},
+		{&errs.APIError{Code: errs.CodeNotFound}, errs.ErrNotFound},
+		{&errs.APIError{HTTPStatus: http.StatusTooManyRequests}, errs.ErrRateLimited},
+		{&errs.APIError{HTTPStatus: http.StatusForbidden}, errs.ErrUnauthorized},
+		{code.Format(status.Error(codes.InvalidArgument, "api key is required")), errs.ErrInvalidArgument},
+		{code.Format(storage_memory.ErrResourceNotFound), errs.ErrNotFound},
+		{code.Format(storage_memory.ErrResourceNotFound), storage_memory.ErrResourceNotFound},
+		{code.Format(fmt.Errorf("queue q %w", errs.ErrAlreadyExists)), errs.ErrAlreadyExists},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.target) {
+			t.Errorf("errors.Is(%v, %v) = false", tt.err, tt.target)
+		}
+	}
+	if errors.Is(&errs.APIError{HTTPStatus: http.StatusInternalServerError}, errs.ErrNotFound) {
+		t.Error("server error must not match ErrNotFound")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	err := code.Format(storage_memory.ErrResourceNotFound)
+	var apiErr *errs.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *errs.APIError, got %T", err)
+	}
+	if apiErr.Code != errs.CodeNotFound || err.Error() != "200005 | resource not found" {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if code.Format(err) != err {
+		t.Fatal("formatting an *errs.APIError must return it unchanged")
+	}
+}
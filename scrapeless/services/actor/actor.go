@@ -4,25 +4,31 @@ import (
 	"context"
 	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/code"
+	"github.com/scrapeless-ai/sdk-go/internal/helper"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/actor"
-	actor_http "github.com/scrapeless-ai/sdk-go/internal/remote/actor/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/actor/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"iter"
 )
 
-func NewActor(serverMode string) *ActorService {
+// NewActor creates an ActorService. The optional cfg selects the credentials
+// and base URL to use; env.Default is used when it is omitted.
+func NewActor(serverMode string, cfg ...*env.Config) *ActorService {
 	log.Info("Actor init")
-	actor.NewClient(serverMode, env.Env.ScrapelessActorUrl)
-	return &ActorService{}
+	c := env.Or(cfg...)
+	return &ActorService{
+		client: actor.NewClient(serverMode, c, c.ScrapelessActorUrl),
+	}
 }
 
 type ActorService struct {
+	client actor.Actor
 }
 
 // Run starts an actor run with the provided context and request data.
 // Returns the run ID or an error.
 func (ah *ActorService) Run(ctx context.Context, req IRunActorData) (string, error) {
-	runId, err := actor_http.Default().Run(ctx, &models.IRunActorData{
+	runId, err := ah.client.Run(ctx, &models.IRunActorData{
 		ActorId: req.ActorId,
 		Input:   req.Input,
 		RunOptions: models.RunOptions{
@@ -38,7 +44,7 @@ func (ah *ActorService) Run(ctx context.Context, req IRunActorData) (string, err
 // GetRunInfo retrieves information about a specific actor run by run ID.
 // Returns a pointer to RunInfo or an error.
 func (ah *ActorService) GetRunInfo(ctx context.Context, runId string) (*RunInfo, error) {
-	runInfo, err := actor_http.Default().GetRunInfo(ctx, runId)
+	runInfo, err := ah.client.GetRunInfo(ctx, runId)
 	if err != nil {
 		log.Errorf("get runInfo err:%v", err)
 		return nil, code.Format(err)
@@ -72,21 +78,21 @@ func (ah *ActorService) GetRunInfo(ctx context.Context, runId string) (*RunInfo,
 // AbortRun aborts a running actor by actor ID and run ID.
 // Returns true if successful and an error otherwise.
 func (ah *ActorService) AbortRun(ctx context.Context, actorId, runId string) (bool, error) {
-	success, err := actor_http.Default().AbortRun(ctx, actorId, runId)
+	success, err := ah.client.AbortRun(ctx, actorId, runId)
 	return success, code.Format(err)
 }
 
 // Build triggers a build process for the specified actor and version.
 // Returns the build ID or an error.
 func (ah *ActorService) Build(ctx context.Context, actorId string, version string) (string, error) {
-	buildId, err := actor_http.Default().Build(ctx, actorId, version)
+	buildId, err := ah.client.Build(ctx, actorId, version)
 	return buildId, code.Format(err)
 }
 
 // GetBuildStatus retrieves the status of a build by actor ID and build ID.
 // Returns a pointer to BuildInfo or an error.
 func (ah *ActorService) GetBuildStatus(ctx context.Context, actorId string, buildId string) (*BuildInfo, error) {
-	success, err := actor_http.Default().GetBuildStatus(ctx, actorId, buildId)
+	success, err := ah.client.GetBuildStatus(ctx, actorId, buildId)
 	if err != nil {
 		log.Errorf("get build status err:%v", err)
 		return nil, code.Format(err)
@@ -109,14 +115,14 @@ func (ah *ActorService) GetBuildStatus(ctx context.Context, actorId string, buil
 // AbortBuild aborts an ongoing build process by actor ID and build ID.
 // Returns true if successful and an error otherwise.
 func (ah *ActorService) AbortBuild(ctx context.Context, actorId string, buildId string) (bool, error) {
-	success, err := actor_http.Default().AbortBuild(ctx, actorId, buildId)
+	success, err := ah.client.AbortBuild(ctx, actorId, buildId)
 	return success, code.Format(err)
 }
 
 // GetRunList retrieves a list of actor runs with pagination.
 // Returns a slice of Payload containing run data or an error.
 func (ah *ActorService) GetRunList(ctx context.Context, paginationParams *IPaginationParams) ([]Payload, error) {
-	runList, err := actor_http.Default().GetRunList(ctx, &models.IPaginationParams{
+	runList, err := ah.client.GetRunList(ctx, &models.IPaginationParams{
 		Page:     paginationParams.Page,
 		PageSize: paginationParams.PageSize,
 		Desc:     paginationParams.Desc,
@@ -158,6 +164,26 @@ func (ah *ActorService) GetRunList(ctx context.Context, paginationParams *IPagin
 	return runListArray, nil
 }
 
+// IterRuns returns an iterator over all the actor runs. Pages are fetched
+// lazily, the next one while the current one is consumed. The iteration
+// stops at the first error.
+func (ah *ActorService) IterRuns(ctx context.Context, pageSize uint, desc bool) iter.Seq2[Payload, error] {
+	if pageSize == 0 {
+		pageSize = 10
+	}
+	return helper.Paginate(ctx, func(ctx context.Context, page int64) ([]Payload, bool, error) {
+		runs, err := ah.GetRunList(ctx, &IPaginationParams{Page: uint(page), PageSize: pageSize, Desc: desc})
+		if err != nil {
+			return nil, false, err
+		}
+		// The run list has no total: a short page is the last one.
+		return runs, helper.HasMore(page, int64(pageSize), len(runs), 0), nil
+	})
+}
+
 func (ah *ActorService) Close() error {
-	return actor_http.Default().Close()
+	if ah.client == nil {
+		return nil
+	}
+	return ah.client.Close()
 }
@@ -6,7 +6,6 @@ import (
 	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/code"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/browser"
-	"github.com/scrapeless-ai/sdk-go/internal/remote/browser/http"
 	remote_brwoser "github.com/scrapeless-ai/sdk-go/internal/remote/browser/models"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/extension"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
@@ -17,21 +16,29 @@ import (
 )
 
 type Browser struct {
+	cfg       *env.Config
+	client    browser.Browser
+	extension extension.Extension
 }
 
-func NewBrowser(serverMode string) *Browser {
+// NewBrowser creates a Browser service. The optional cfg selects the
+// credentials and base URLs to use; env.Default is used when it is omitted.
+func NewBrowser(serverMode string, cfg ...*env.Config) *Browser {
 	log.Info("browser init")
-	browser.NewClient(serverMode, env.Env.ScrapelessBrowserUrl)
-	extension.NewClient(serverMode, env.Env.ScrapelessBaseApiUrl)
-	return &Browser{}
+	c := env.Or(cfg...)
+	return &Browser{
+		cfg:       c,
+		client:    browser.NewClient(serverMode, c, c.ScrapelessBrowserUrl),
+		extension: extension.NewClient(serverMode, c, c.ScrapelessBaseApiUrl),
+	}
 }
 
 func (b *Browser) Create(ctx context.Context, req Actor) (*CreateResp, error) {
 	if req.ProxyCountry == "" {
 		req.ProxyCountry = "ANY"
 	}
-	create, err := browser.ClientInterface.ScrapingBrowserCreate(ctx, &remote_brwoser.CreateBrowserRequest{
-		ApiKey:           env.GetActorEnv().ApiKey,
+	create, err := b.client.ScrapingBrowserCreate(ctx, &remote_brwoser.CreateBrowserRequest{
+		ApiKey:           b.cfg.Actor.ApiKey,
 		SessionName:      req.SessionName,
 		SessionTtl:       req.SessionTtl,
 		SessionRecording: req.SessionRecording,
@@ -66,13 +73,13 @@ func (b *Browser) Create(ctx context.Context, req Actor) (*CreateResp, error) {
 }
 
 func (b *Browser) CreateOnce(ctx context.Context, req ActorOnce) (*CreateResp, error) {
-	u, err := url.Parse(env.Env.ScrapelessBrowserUrl)
+	u, err := url.Parse(b.cfg.ScrapelessBrowserUrl)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "parse url error: %s", err.Error())
+		return nil, code.Format(status.Errorf(codes.Internal, "parse url error: %s", err.Error()))
 	}
 	devtoolsUrl := fmt.Sprintf("wss://%s/browser", u.Host)
 	value := &url.Values{}
-	value.Set("token", env.GetActorEnv().ApiKey)
+	value.Set("token", b.cfg.Actor.ApiKey)
 	value.Set("session_ttl", req.Input.SessionTtl)
 	value.Set("proxy_country", strings.ToUpper(req.ProxyCountry))
 	return &CreateResp{
@@ -82,7 +89,7 @@ func (b *Browser) CreateOnce(ctx context.Context, req ActorOnce) (*CreateResp, e
 
 // Upload upload extension
 func (b *Browser) Upload(ctx context.Context, filePath, pluginName string) (uploadExtension *UploadExtensionResponse, err error) {
-	upload, err := extension.ClientInterface.Upload(ctx, filePath, pluginName)
+	upload, err := b.extension.Upload(ctx, filePath, pluginName)
 	if err != nil {
 		return nil, err
 	}
@@ -96,12 +103,12 @@ func (b *Browser) Upload(ctx context.Context, filePath, pluginName string) (uplo
 
 // Update update extension
 func (b *Browser) Update(ctx context.Context, extensionId, filePath, pluginName string) (success bool, err error) {
-	return extension.ClientInterface.Update(ctx, extensionId, filePath, pluginName)
+	return b.extension.Update(ctx, extensionId, filePath, pluginName)
 }
 
 // Get get extension detail by extensionId
 func (b *Browser) Get(ctx context.Context, extensionId string) (extensionDetail *ExtensionDetail, err error) {
-	detail, err := extension.ClientInterface.Get(ctx, extensionId)
+	detail, err := b.extension.Get(ctx, extensionId)
 	if err != nil {
 		return nil, err
 	}
@@ -119,7 +126,7 @@ func (b *Browser) Get(ctx context.Context, extensionId string) (extensionDetail
 
 // List list extension
 func (b *Browser) List(ctx context.Context) (extensionList []ExtensionListItem, err error) {
-	list, err := extension.ClientInterface.List(ctx)
+	list, err := b.extension.List(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -137,9 +144,12 @@ func (b *Browser) List(ctx context.Context) (extensionList []ExtensionListItem,
 
 // Delete delete extension by extensionId
 func (b *Browser) Delete(ctx context.Context, extensionId string) (success bool, err error) {
-	return extension.ClientInterface.Delete(ctx, extensionId)
+	return b.extension.Delete(ctx, extensionId)
 }
 
 func (b *Browser) Close() error {
-	return http.Default().Close()
+	if b.client == nil {
+		return nil
+	}
+	return b.client.Close()
 }
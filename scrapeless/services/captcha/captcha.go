@@ -6,22 +6,25 @@ import (
 	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/code"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/captcha"
-	"github.com/scrapeless-ai/sdk-go/internal/remote/captcha/http"
 	gateway_captcha "github.com/scrapeless-ai/sdk-go/internal/remote/captcha/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"github.com/tidwall/gjson"
 )
 
 type Captcha struct {
+	cfg    *env.Config
+	client captcha.Captcha
 }
 
-func NewCaptcha(serverMode string) *Captcha {
+// NewCaptcha creates a Captcha service. The optional cfg selects the
+// credentials and base URL to use; env.Default is used when it is omitted.
+func NewCaptcha(serverMode string, cfg ...*env.Config) *Captcha {
 	log.Info("captcha init")
-	captcha.NewClient(serverMode, env.Env.ScrapelessBaseApiUrl)
-	if http.Default() == nil {
-		http.Init(env.Env.ScrapelessBaseApiUrl)
+	conf := env.Or(cfg...)
+	return &Captcha{
+		cfg:    conf,
+		client: captcha.NewClient(serverMode, conf, conf.ScrapelessBaseApiUrl),
 	}
-	return &Captcha{}
 }
 
 // Solver solves the captcha task by submitting it to the captcha solving service
@@ -39,8 +42,8 @@ func (c *Captcha) Solver(ctx context.Context, req *CaptchaSolverReq) (*CaptchaSo
 	_ = json.Unmarshal(input, &inputMap)
 
 	// Submit the captcha solving task to the remote service with provided parameters
-	response, err := captcha.ClientInterface.CaptchaSolverSolverTask(ctx, &gateway_captcha.CreateTaskRequest{
-		ApiKey: env.GetActorEnv().ApiKey,
+	response, err := c.client.CaptchaSolverSolverTask(ctx, &gateway_captcha.CreateTaskRequest{
+		ApiKey: c.cfg.Actor.ApiKey,
 		Actor:  req.Actor,
 		Input:  inputMap,
 		Proxy: &gateway_captcha.ProxyParams{
@@ -78,8 +81,8 @@ func (c *Captcha) Create(ctx context.Context, req *CaptchaSolverReq) (string, er
 	_ = json.Unmarshal(input, &inputMap)
 
 	// Submit captcha solving task to remote service with provided configuration
-	taskId, err := captcha.ClientInterface.CaptchaSolverCreateTask(ctx, &gateway_captcha.CreateTaskRequest{
-		ApiKey: env.GetActorEnv().ApiKey,
+	taskId, err := c.client.CaptchaSolverCreateTask(ctx, &gateway_captcha.CreateTaskRequest{
+		ApiKey: c.cfg.Actor.ApiKey,
 		Actor:  req.Actor,
 		Input:  inputMap,
 		Proxy: &gateway_captcha.ProxyParams{
@@ -107,8 +110,8 @@ func (c *Captcha) Create(ctx context.Context, req *CaptchaSolverReq) (string, er
 //	ctx: context object for controlling the request lifecycle and timeouts
 //	req: captcha solving request parameters containing the task ID
 func (c *Captcha) ResultGet(ctx context.Context, req *CaptchaSolverReq) (*CaptchaSolverResp, error) {
-	response, err := captcha.ClientInterface.CaptchaSolverGetTaskResult(ctx, &gateway_captcha.GetTaskResultRequest{
-		ApiKey: env.GetActorEnv().ApiKey,
+	response, err := c.client.CaptchaSolverGetTaskResult(ctx, &gateway_captcha.GetTaskResultRequest{
+		ApiKey: c.cfg.Actor.ApiKey,
 		TaskId: req.TaskId,
 	})
 	if err != nil {
@@ -123,5 +126,8 @@ func (c *Captcha) ResultGet(ctx context.Context, req *CaptchaSolverReq) (*Captch
 }
 
 func (c *Captcha) Close() error {
-	return nil
+	if c.client == nil {
+		return nil
+	}
+	return c.client.Close()
 }
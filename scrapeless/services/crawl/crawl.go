@@ -12,12 +12,18 @@ import (
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
-type Crawl struct{}
+type Crawl struct {
+	client crawl.Captcha
+}
 
-func New() *Crawl {
+// New creates a Crawl service. The optional cfg selects the credentials and
+// base URL to use; env.Default is used when it is omitted.
+func New(cfg ...*env.Config) *Crawl {
 	log.Info("Internal Crawl init")
-	crawl.NewClient("http", env.Env.ScrapelessCrawlApiUrl)
-	return &Crawl{}
+	conf := env.Or(cfg...)
+	return &Crawl{
+		client: crawl.NewClient("http", conf, conf.ScrapelessCrawlApiUrl),
+	}
 }
 
 func (c *Crawl) ScrapeUrl(ctx context.Context, url string, crawlScrapeOptions ScrapeOptions) (scrapeStatusResponse *ScrapeStatusResponse, err error) {
@@ -43,7 +49,7 @@ func (c *Crawl) ScrapeUrl(ctx context.Context, url string, crawlScrapeOptions Sc
 }
 
 func (c *Crawl) AsyncScrapeUrl(ctx context.Context, url string, crawlScrapeOptions ScrapeOptions) (id string, err error) {
-	id, err = crawl.ClientInterface.ScrapeUrl(ctx, &models.ScrapeOptions{
+	id, err = c.client.ScrapeUrl(ctx, &models.ScrapeOptions{
 		Url:             url,
 		Formats:         crawlScrapeOptions.Formats,
 		Headers:         crawlScrapeOptions.Headers,
@@ -64,7 +70,7 @@ func (c *Crawl) AsyncScrapeUrl(ctx context.Context, url string, crawlScrapeOptio
 	return
 }
 func (c *Crawl) CheckScrapeStatus(ctx context.Context, id string) (scrapeStatusResponse *ScrapeStatusResponse, err error) {
-	response, err := crawl.ClientInterface.CheckScrapeStatus(ctx, id)
+	response, err := c.client.CheckScrapeStatus(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +126,7 @@ func (c *Crawl) internalScrapingCrawlDocumentFormat(in *models.ScrapingCrawlDocu
 	}
 }
 func (c *Crawl) BatchScrapeUrls(ctx context.Context, urls []string, params ScrapeParams) (scrapeResponse *ScrapeResponse, err error) {
-	response, err := crawl.ClientInterface.BatchScrapeUrls(ctx, &models.ScrapeOptionsMultiple{
+	response, err := c.client.BatchScrapeUrls(ctx, &models.ScrapeOptionsMultiple{
 		Url:             urls,
 		Formats:         params.Formats,
 		Headers:         params.Headers,
@@ -143,7 +149,7 @@ func (c *Crawl) BatchScrapeUrls(ctx context.Context, urls []string, params Scrap
 }
 
 func (c *Crawl) CheckBatchScrapeStatus(ctx context.Context, id string) (scrapeStatusResponseMultiple *ScrapeStatusResponseMultiple, err error) {
-	response, err := crawl.ClientInterface.CheckBatchScrapeStatus(ctx, id)
+	response, err := c.client.CheckBatchScrapeStatus(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -164,7 +170,7 @@ func (c *Crawl) internalScrapeStatusResponseMultipleFormat(in *models.ScrapeStat
 }
 
 func (c *Crawl) AsyncCrawlUrl(ctx context.Context, url string, params CrawlParams) (id string, err error) {
-	crawlUrl, err := crawl.ClientInterface.CrawlUrl(ctx, &models.CrawlParams{
+	crawlUrl, err := c.client.CrawlUrl(ctx, &models.CrawlParams{
 		Url:                    url,
 		IncludePaths:           params.IncludePaths,
 		ExcludePaths:           params.ExcludePaths,
@@ -225,7 +231,7 @@ func (c *Crawl) CrawlUrl(ctx context.Context, url string, params CrawlParams) (c
 
 }
 func (c *Crawl) CheckCrawlStatus(ctx context.Context, id string) (crawlStatusResponse *CrawlStatusResponse, err error) {
-	response, err := crawl.ClientInterface.CheckCrawlStatus(ctx, id)
+	response, err := c.client.CheckCrawlStatus(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -240,7 +246,7 @@ func (c *Crawl) CheckCrawlStatus(ctx context.Context, id string) (crawlStatusRes
 	}, nil
 }
 func (c *Crawl) CheckCrawlErrors(ctx context.Context, id string) (crawlErrorsResponse *CrawlErrorsResponse, err error) {
-	response, err := crawl.ClientInterface.CheckCrawlErrors(ctx, id)
+	response, err := c.client.CheckCrawlErrors(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -263,7 +269,7 @@ func (c *Crawl) CheckCrawlErrors(ctx context.Context, id string) (crawlErrorsRes
 	}, nil
 }
 func (c *Crawl) CancelCrawl(ctx context.Context, id string) (success bool, err error) {
-	_, err = crawl.ClientInterface.CancelCrawl(ctx, id)
+	_, err = c.client.CancelCrawl(ctx, id)
 	if err != nil {
 		return false, err
 	}
@@ -271,5 +277,5 @@ func (c *Crawl) CancelCrawl(ctx context.Context, id string) (success bool, err e
 }
 
 func (c *Crawl) Close() error {
-	return nil
+	return c.client.Close()
 }
@@ -5,7 +5,6 @@ import (
 	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/code"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/deepserp"
-	dh "github.com/scrapeless-ai/sdk-go/internal/remote/deepserp/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/deepserp/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"github.com/tidwall/gjson"
@@ -13,20 +12,28 @@ import (
 	"time"
 )
 
-type DeepSerp struct{}
+type DeepSerp struct {
+	cfg    *env.Config
+	client deepserp.DeepSerp
+}
 
-func NewDeepSerp(serverMode string) *DeepSerp {
+// NewDeepSerp creates a DeepSerp service. The optional cfg selects the credentials,
+// base URL and proxy defaults to use; env.Default is used when it is omitted.
+func NewDeepSerp(serverMode string, cfg ...*env.Config) *DeepSerp {
 	log.Info("Internal DeepSerp init")
-	deepserp.NewClient(serverMode, env.Env.ScrapelessBaseApiUrl)
-	return &DeepSerp{}
+	c := env.Or(cfg...)
+	return &DeepSerp{
+		cfg:    c,
+		client: deepserp.NewClient(serverMode, c, c.ScrapelessBaseApiUrl),
+	}
 }
 
 // CreateTask creates a new deepSerp task with the given context and request parameters.
 func (s *DeepSerp) CreateTask(ctx context.Context, req DeepserpTaskRequest) ([]byte, error) {
 	if req.ProxyCountry == "" {
-		req.ProxyCountry = env.Env.ProxyCountry
+		req.ProxyCountry = s.cfg.ProxyCountry
 	}
-	response, err := deepserp.ClientInterface.CreateTask(ctx, &models.DeepserpTaskRequest{
+	response, err := s.client.CreateTask(ctx, &models.DeepserpTaskRequest{
 		Actor: string(req.Actor),
 		Input: req.Input,
 		Proxy: models.TaskProxy{Country: strings.ToUpper(req.ProxyCountry)},
@@ -39,12 +46,12 @@ func (s *DeepSerp) CreateTask(ctx context.Context, req DeepserpTaskRequest) ([]b
 }
 
 func (s *DeepSerp) Close() error {
-	return dh.Default().Close()
+	return s.client.Close()
 }
 
 // GetTaskResult retrieves the result of a deepSerp task by its ID.
 func (s *DeepSerp) GetTaskResult(ctx context.Context, taskId string) ([]byte, error) {
-	result, err := deepserp.ClientInterface.GetTaskResult(ctx, taskId)
+	result, err := s.client.GetTaskResult(ctx, taskId)
 	if err != nil {
 		log.Errorf("get task result err:%v", err)
 		return nil, code.Format(err)
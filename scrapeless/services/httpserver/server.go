@@ -26,9 +26,18 @@ const (
 
 type Server struct {
 	handler http.Handler
+	cfg     *env.Config
 }
 
+// New creates a Server listening on the port of env.Default unless Start is
+// given an address.
 func New(mode ...ServerMode) *Server {
+	return NewWithConfig(nil, mode...)
+}
+
+// NewWithConfig creates a Server listening on the port of cfg unless Start is
+// given an address; env.Default is used when cfg is nil.
+func NewWithConfig(cfg *env.Config, mode ...ServerMode) *Server {
 	if len(mode) > 0 {
 		gin.SetMode(string(mode[0]))
 	} else {
@@ -36,6 +45,7 @@ func New(mode ...ServerMode) *Server {
 	}
 	return &Server{
 		handler: gin.Default(),
+		cfg:     cfg,
 	}
 }
 
@@ -82,7 +92,7 @@ func (s *Server) AddHandleGet(path string, f func(input []byte) (Response, error
 
 func (s *Server) Start(addr ...string) error {
 	if len(addr) == 0 {
-		addr = append(addr, env.Env.Actor.HttpPort)
+		addr = append(addr, env.Or(s.cfg).Actor.HttpPort)
 	}
 	if !strings.Contains(addr[0], ":") {
 		addr[0] = fmt.Sprintf(":%s", addr[0])
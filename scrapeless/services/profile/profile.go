@@ -5,17 +5,25 @@ import (
 	"errors"
 	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/code"
+	"github.com/scrapeless-ai/sdk-go/internal/helper"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/profile"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/profile/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"iter"
 )
 
-type Profile struct{}
+type Profile struct {
+	client profile.Profile
+}
 
-func New() *Profile {
+// New creates a Profile service. The optional cfg selects the credentials and
+// base URL to use; env.Default is used when it is omitted.
+func New(cfg ...*env.Config) *Profile {
 	log.Info("Internal Profile init")
-	profile.NewClient("http", env.Env.ScrapelessBaseApiUrl)
-	return &Profile{}
+	c := env.Or(cfg...)
+	return &Profile{
+		client: profile.NewClient("http", c, c.ScrapelessBaseApiUrl),
+	}
 }
 
 // CreateProfile creates a new profile.
@@ -27,7 +35,7 @@ func (p *Profile) CreateProfile(ctx context.Context, name string) (*ProfileInfo,
 	if name == "" {
 		name = "untitled"
 	}
-	resp, err := profile.ClientInterface.Create(ctx, name)
+	resp, err := p.client.Create(ctx, name)
 	if err != nil {
 		log.Errorf("create profile err:%v", err)
 		return nil, code.Format(err)
@@ -49,7 +57,7 @@ func (p *Profile) CreateProfile(ctx context.Context, name string) (*ProfileInfo,
 //	ctx: The request context.
 //	profileId: Id of the profile.
 func (p *Profile) GetProfile(ctx context.Context, profileId string) (*ProfileInfo, error) {
-	resp, err := profile.ClientInterface.Get(ctx, profileId)
+	resp, err := p.client.Get(ctx, profileId)
 	if err != nil {
 		log.Errorf("get profile err:%v", err)
 		return nil, code.Format(err)
@@ -74,7 +82,7 @@ func (p *Profile) ListProfiles(ctx context.Context, req *ListProfileRequest) (*L
 	if req == nil {
 		return nil, errors.New("req is nil")
 	}
-	resp, err := profile.ClientInterface.List(ctx, &models.ListProfileRequest{
+	resp, err := p.client.List(ctx, &models.ListProfileRequest{
 		Name:     req.Name,
 		Page:     req.Page,
 		PageSize: req.PageSize,
@@ -104,6 +112,27 @@ func (p *Profile) ListProfiles(ctx context.Context, req *ListProfileRequest) (*L
 	}, nil
 }
 
+// IterProfiles returns an iterator over all the profiles matching name. Pages
+// are fetched lazily, the next one while the current one is consumed. The
+// iteration stops at the first error.
+// Parameters:
+//
+//	ctx: The context for the requests.
+//	name: The name to search for, nil for all profiles.
+//	pageSize: The number of profiles fetched per request.
+func (p *Profile) IterProfiles(ctx context.Context, name *string, pageSize int64) iter.Seq2[ProfileInfo, error] {
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	return helper.Paginate(ctx, func(ctx context.Context, page int64) ([]ProfileInfo, bool, error) {
+		resp, err := p.ListProfiles(ctx, &ListProfileRequest{Name: name, Page: page, PageSize: pageSize})
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Items, helper.HasMore(page, pageSize, len(resp.Items), resp.Total), nil
+	})
+}
+
 // UpdateProfile update profile's name
 // Parameters:
 //
@@ -111,7 +140,7 @@ func (p *Profile) ListProfiles(ctx context.Context, req *ListProfileRequest) (*L
 //	profileId: profile's id.
 //	name: profile's name.
 func (p *Profile) UpdateProfile(ctx context.Context, profileId string, name string) (bool, error) {
-	resp, err := profile.ClientInterface.Update(ctx, profileId, name)
+	resp, err := p.client.Update(ctx, profileId, name)
 	if err != nil {
 		log.Errorf("delete profile err:%v", err)
 		return false, code.Format(err)
@@ -126,7 +155,7 @@ func (p *Profile) UpdateProfile(ctx context.Context, profileId string, name stri
 //	ctx: The context for the request.
 //	profileId: profile's id.
 func (p *Profile) DeleteProfile(ctx context.Context, profileId string) (bool, error) {
-	resp, err := profile.ClientInterface.Delete(ctx, profileId)
+	resp, err := p.client.Delete(ctx, profileId)
 	if err != nil {
 		log.Errorf("delete profile err:%v", err)
 		return false, code.Format(err)
@@ -136,5 +165,5 @@ func (p *Profile) DeleteProfile(ctx context.Context, profileId string) (bool, er
 }
 
 func (p *Profile) Close() error {
-	return nil
+	return p.client.Close()
 }
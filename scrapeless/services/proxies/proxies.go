@@ -5,18 +5,24 @@ import (
 	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/code"
 	rp "github.com/scrapeless-ai/sdk-go/internal/remote/proxy"
-	"github.com/scrapeless-ai/sdk-go/internal/remote/proxy/http"
 	proxy2 "github.com/scrapeless-ai/sdk-go/internal/remote/proxy/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
 type Proxy struct {
+	cfg    *env.Config
+	client rp.Proxy
 }
 
-func NewProxy(serverMode string) *Proxy {
+// NewProxy creates a Proxy service. The optional cfg selects the credentials
+// and proxy defaults to use; env.Default is used when it is omitted.
+func NewProxy(serverMode string, cfg ...*env.Config) *Proxy {
 	log.Infof("proxies init")
-	rp.NewClient(serverMode)
-	return &Proxy{}
+	c := env.Or(cfg...)
+	return &Proxy{
+		cfg:    c,
+		client: rp.NewClient(serverMode, c),
+	}
 }
 
 // Proxy retrieves proxies information.
@@ -26,13 +32,13 @@ func NewProxy(serverMode string) *Proxy {
 //	ctx: context.Context - Context for the request.
 //	proxies: ProxyActor - Struct containing proxies request parameters like country, session duration, etc.
 func (ph *Proxy) Proxy(ctx context.Context, proxy ProxyActor) (string, error) {
-	proxyUrl, err := rp.ClientInterface.ProxyGetProxy(ctx, &proxy2.GetProxyRequest{
-		ApiKey:          env.GetActorEnv().ApiKey,
+	proxyUrl, err := ph.client.ProxyGetProxy(ctx, &proxy2.GetProxyRequest{
+		ApiKey:          ph.cfg.Actor.ApiKey,
 		Country:         proxy.Country,
 		SessionDuration: proxy.SessionDuration,
 		SessionId:       proxy.SessionId,
 		Gateway:         proxy.Gateway,
-		TaskId:          env.GetActorEnv().RunId,
+		TaskId:          ph.cfg.Actor.RunId,
 	})
 	if err != nil {
 		log.Errorf("get proxies err:%v", err)
@@ -42,5 +48,8 @@ func (ph *Proxy) Proxy(ctx context.Context, proxy ProxyActor) (string, error) {
 }
 
 func (ph *Proxy) Close() error {
-	return http.Default().Close()
+	if ph.client == nil {
+		return nil
+	}
+	return ph.client.Close()
 }
@@ -1,25 +1,35 @@
 package router
 
 import (
+	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/router"
 	"io"
 
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
-type Router struct{}
+type Router struct {
+	client router.Router
+}
 
-func New(serverMode string) *Router {
+// New creates a Router. The optional cfg selects the credentials and base URL
+// to use; env.Default is used when it is omitted.
+func New(serverMode string, cfg ...*env.Config) *Router {
 	log.Info("Internal Router init")
-	router.NewClient(serverMode)
-	return &Router{}
+	c := env.Or(cfg...)
+	return &Router{
+		client: router.NewClient(serverMode, c, c.ScrapelessActorUrl),
+	}
 }
 
 // Request keyword is the actor's keyword-->Now its value is runnerId
 func (r *Router) Request(keyword string, method string, path string, body io.Reader, headers map[string]string) (data []byte, err error) {
-	return router.ClientInterface.Request(keyword, method, path, body, headers)
+	return r.client.Request(keyword, method, path, body, headers)
 }
 
 func (r *Router) Close() error {
-	return nil
+	if r.client == nil {
+		return nil
+	}
+	return r.client.Close()
 }
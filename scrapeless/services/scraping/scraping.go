@@ -5,7 +5,6 @@ import (
 	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/code"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/scraping"
-	sh "github.com/scrapeless-ai/sdk-go/internal/remote/scraping/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/scraping/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"github.com/tidwall/gjson"
@@ -13,20 +12,28 @@ import (
 	"time"
 )
 
-type Scraping struct{}
+type Scraping struct {
+	cfg    *env.Config
+	client scraping.Scraping
+}
 
-func New(serverMode string) *Scraping {
+// New creates a Scraping service. The optional cfg selects the credentials,
+// base URL and proxy defaults to use; env.Default is used when it is omitted.
+func New(serverMode string, cfg ...*env.Config) *Scraping {
 	log.Info("Internal Router init")
-	scraping.NewClient(serverMode, env.Env.ScrapelessBaseApiUrl)
-	return &Scraping{}
+	c := env.Or(cfg...)
+	return &Scraping{
+		cfg:    c,
+		client: scraping.NewClient(serverMode, c, c.ScrapelessBaseApiUrl),
+	}
 }
 
 // CreateTask creates a new scraping task with the given context and request parameters.
 func (s *Scraping) CreateTask(ctx context.Context, req ScrapingTaskRequest) ([]byte, error) {
 	if req.ProxyCountry == "" {
-		req.ProxyCountry = env.Env.ProxyCountry
+		req.ProxyCountry = s.cfg.ProxyCountry
 	}
-	response, err := scraping.ClientInterface.CreateTask(ctx, &models.ScrapingTaskRequest{
+	response, err := s.client.CreateTask(ctx, &models.ScrapingTaskRequest{
 		Actor: string(req.Actor),
 		Input: req.Input,
 		Proxy: models.TaskProxy{Country: strings.ToUpper(req.ProxyCountry)},
@@ -39,12 +46,12 @@ func (s *Scraping) CreateTask(ctx context.Context, req ScrapingTaskRequest) ([]b
 }
 
 func (s *Scraping) Close() error {
-	return sh.Default().Close()
+	return s.client.Close()
 }
 
 // GetTaskResult retrieves the result of a scraping task by its ID.
 func (s *Scraping) GetTaskResult(ctx context.Context, taskId string) ([]byte, error) {
-	result, err := scraping.ClientInterface.GetTaskResult(ctx, taskId)
+	result, err := s.client.GetTaskResult(ctx, taskId)
 	if err != nil {
 		log.Errorf("get task result err:%v", err)
 		return nil, code.Format(err)
@@ -2,14 +2,23 @@ package storage
 
 import (
 	"context"
-	"github.com/scrapeless-ai/sdk-go/env"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/scrapeless-ai/sdk-go/internal/code"
-	"github.com/scrapeless-ai/sdk-go/internal/remote/storage"
+	"github.com/scrapeless-ai/sdk-go/internal/helper"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/query"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/schema"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"iter"
+	"reflect"
 )
 
-type Dataset struct{}
+type Dataset struct {
+	backend
+}
 
 // ListDatasets retrieves a list of dataset with pagination and sorting options.
 // Parameters:
@@ -25,9 +34,9 @@ func (s *Dataset) ListDatasets(ctx context.Context, page int64, pageSize int64,
 	if pageSize < 10 {
 		pageSize = 10
 	}
-	datasets, err := storage.ClientInterface.ListDatasets(ctx, &models.ListDatasetsRequest{
-		ActorId:  &env.GetActorEnv().ActorId,
-		RunId:    &env.GetActorEnv().RunId,
+	datasets, err := s.client.ListDatasets(ctx, &models.ListDatasetsRequest{
+		ActorId:  &s.cfg.Actor.ActorId,
+		RunId:    &s.cfg.Actor.RunId,
 		Page:     page,
 		PageSize: pageSize,
 		Desc:     desc,
@@ -60,11 +69,11 @@ func (s *Dataset) ListDatasets(ctx context.Context, page int64, pageSize int64,
 //	ctx:The request context.
 //	name: The name of the dataset to create.
 func (s *Dataset) CreateDataset(ctx context.Context, name string) (id string, datasetName string, err error) {
-	name = name + "-" + env.GetActorEnv().RunId
-	dataset, err := storage.ClientInterface.CreateDataset(ctx, &models.CreateDatasetRequest{
+	name = name + "-" + s.cfg.Actor.RunId
+	dataset, err := s.client.CreateDataset(ctx, &models.CreateDatasetRequest{
 		Name:    name,
-		ActorId: &env.GetActorEnv().ActorId,
-		RunId:   &env.GetActorEnv().RunId,
+		ActorId: &s.cfg.Actor.ActorId,
+		RunId:   &s.cfg.Actor.RunId,
 	})
 	if err != nil {
 		log.Errorf("failed to create dataset: %v", code.Format(err))
@@ -80,8 +89,8 @@ func (s *Dataset) CreateDataset(ctx context.Context, name string) (id string, da
 //	ctx: The request context.
 //	name: Original dataset name (will be combined with runtime ID internally)
 func (s *Dataset) UpdateDataset(ctx context.Context, datasetId string, name string) (ok bool, datasetName string, err error) {
-	name = name + "-" + env.GetActorEnv().RunId
-	ok, err = storage.ClientInterface.UpdateDataset(ctx, datasetId, name)
+	name = name + "-" + s.cfg.Actor.RunId
+	ok, err = s.client.UpdateDataset(ctx, datasetId, name)
 	if err != nil {
 		log.Errorf("failed to update dataset: %v", code.Format(err))
 		return false, "", code.Format(err)
@@ -95,7 +104,7 @@ func (s *Dataset) UpdateDataset(ctx context.Context, datasetId string, name stri
 //
 //	ctx: The context for the request, used for cancellation and timeouts.
 func (s *Dataset) DelDataset(ctx context.Context, datasetId string) (bool, error) {
-	ok, err := storage.ClientInterface.DelDataset(ctx, datasetId)
+	ok, err := s.client.DelDataset(ctx, datasetId)
 	if err != nil {
 		log.Errorf("failed to delete dataset: %v", code.Format(err))
 		return false, code.Format(err)
@@ -109,7 +118,7 @@ func (s *Dataset) DelDataset(ctx context.Context, datasetId string) (bool, error
 //   - ctx: The context for the request.
 //   - items: A slice of maps representing the items to add. Each map contains key-value pairs of any type.
 func (s *Dataset) AddItems(ctx context.Context, datasetId string, items []map[string]any) (bool, error) {
-	ok, err := storage.ClientInterface.AddDatasetItem(ctx, datasetId, items)
+	ok, err := s.client.AddDatasetItem(ctx, datasetId, items)
 	if err != nil {
 		log.Errorf("failed to add items: %v", err)
 		return false, code.Format(err)
@@ -117,6 +126,58 @@ func (s *Dataset) AddItems(ctx context.Context, datasetId string, items []map[st
 	return ok, nil
 }
 
+// SetSchema attaches a JSON Schema to the dataset: AddItems then rejects
+// batches holding items that don't match it with an *errs.ValidationError
+// describing each invalid item, and stores none of them.
+//
+// schema is a JSON Schema document, as []byte, json.RawMessage, string or
+// map[string]any, or a Go struct (or its reflect.Type) the schema is derived
+// from, following encoding/json rules. A nil schema removes the schema.
+//
+// The API doesn't store schemas yet: against it, a schema only applies to
+// items added with this client. The local backend stores it with the dataset.
+//
+// Parameters:
+//
+//	ctx: The context for the request.
+//	schema: The schema items must match.
+func (s *Dataset) SetSchema(ctx context.Context, datasetId string, schema any) error {
+	doc, err := schemaDocument(schema)
+	if err != nil {
+		return code.Format(err)
+	}
+	if err = s.client.SetDatasetSchema(ctx, datasetId, doc); err != nil {
+		log.Errorf("failed to set dataset schema: %v", code.Format(err))
+		return code.Format(err)
+	}
+	return nil
+}
+
+func schemaDocument(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	case json.RawMessage:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	case map[string]any:
+		return json.Marshal(v)
+	case reflect.Type:
+		return json.Marshal(schema.FromType(v))
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: schema must be a JSON Schema document or a struct, not %s", errs.ErrInvalidArgument, t)
+	}
+	return json.Marshal(schema.FromType(t))
+}
+
 // GetItems retrieves a list of items based on the provided pagination and sorting parameters.
 //
 // Parameters:
@@ -132,7 +193,7 @@ func (s *Dataset) GetItems(ctx context.Context, datasetId string, page int, page
 	if pageSize < 10 {
 		pageSize = 10
 	}
-	items, err := storage.ClientInterface.GetDataset(ctx, &models.GetDataset{
+	items, err := s.client.GetDataset(ctx, &models.GetDataset{
 		DatasetId: datasetId,
 		Desc:      desc,
 		Page:      page,
@@ -152,6 +213,90 @@ func (s *Dataset) GetItems(ctx context.Context, datasetId string, page int, page
 	}, nil
 }
 
+// Query retrieves the items matching filter. The returned Total is the
+// number of matching items, before Offset and Limit apply.
+//
+// Ranges compare numbers with numbers and strings with strings; missing
+// fields compare as null and sort last. Queries are evaluated on the client,
+// page by page, when the backend can't evaluate them.
+//
+// Parameters:
+//
+//	ctx: The context for the request.
+//	filter: The conditions, sort keys, projection and range of the query.
+func (s *Dataset) Query(ctx context.Context, datasetId string, filter *DatasetQuery) (*ItemsResponse, error) {
+	if filter == nil {
+		filter = &DatasetQuery{}
+	}
+	req := &models.QueryDatasetRequest{
+		DatasetId: datasetId,
+		Fields:    filter.Fields,
+		Offset:    filter.Offset,
+		Limit:     filter.Limit,
+	}
+	for _, cond := range filter.Where {
+		req.Where = append(req.Where, models.QueryCondition{Field: cond.Field, Op: models.QueryOp(cond.Op), Value: cond.Value})
+	}
+	for _, sort := range filter.Sort {
+		req.Sort = append(req.Sort, models.QuerySort{Field: sort.Field, Desc: sort.Desc})
+	}
+	if err := query.Validate(req); err != nil {
+		return nil, code.Format(err)
+	}
+
+	items, err := s.client.QueryDataset(ctx, req)
+	if errors.Is(err, errors.ErrUnsupported) {
+		items, err = s.queryPages(ctx, req)
+	}
+	if err != nil {
+		log.Errorf("failed to query items: %v", code.Format(err))
+		return nil, code.Format(err)
+	}
+	return &ItemsResponse{
+		Items: items.Items,
+		Total: items.Total,
+	}, nil
+}
+
+// queryPages evaluates req on the items of every page of the dataset.
+func (s *Dataset) queryPages(ctx context.Context, req *models.QueryDatasetRequest) (*models.DatasetItem, error) {
+	var matches []map[string]any
+	for item, err := range s.IterItems(ctx, req.DatasetId, 100, false) {
+		if err != nil {
+			return nil, err
+		}
+		if query.Match(item, req.Where) {
+			matches = append(matches, item)
+		}
+	}
+	return &models.DatasetItem{
+		Items: query.Apply(matches, req),
+		Total: len(matches),
+	}, nil
+}
+
+// IterItems returns an iterator over all the items of the dataset. Pages of
+// pageSize items are fetched lazily, the next one while the current one is
+// consumed. The iteration stops at the first error.
+//
+// Parameters:
+//
+//	ctx: The context for the requests.
+//	pageSize: The number of items fetched per request. Minimum 10.
+//	desc: Whether to iterate in descending order.
+func (s *Dataset) IterItems(ctx context.Context, datasetId string, pageSize int, desc bool) iter.Seq2[map[string]any, error] {
+	if pageSize < 10 {
+		pageSize = 10
+	}
+	return helper.Paginate(ctx, func(ctx context.Context, page int64) ([]map[string]any, bool, error) {
+		resp, err := s.GetItems(ctx, datasetId, int(page), pageSize, desc)
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Items, helper.HasMore(page, int64(pageSize), len(resp.Items), int64(resp.Total)), nil
+	})
+}
+
 func (s *Dataset) Close() error {
 	return nil
 }
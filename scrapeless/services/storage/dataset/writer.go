@@ -0,0 +1,337 @@
+// Package dataset provides a buffered writer for Scrapeless datasets.
+//
+//	w := dataset.NewWriter(client.Storage.Dataset, datasetId, dataset.WithFlushInterval(time.Second))
+//	defer w.Close()
+//	for item := range scrape() {
+//		if err := w.Write(item); err != nil {
+//			return err
+//		}
+//	}
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+)
+
+// ErrClosed is returned by the methods of a closed Writer.
+var ErrClosed = errors.New("dataset writer closed")
+
+// Sink is the dataset a Writer adds items to. *storage.Dataset implements it.
+type Sink interface {
+	AddItems(ctx context.Context, datasetId string, items []map[string]any) (bool, error)
+}
+
+// Writer buffers items and adds them to a dataset in batches, from a
+// background goroutine. A batch is sent once the buffer holds enough items
+// or bytes, or the flush interval elapses; Close sends what's left.
+//
+// Batches that fail are retried. Items that still can't be added, including
+// those rejected by the dataset schema, are dropped and reported to the error
+// handler and by Close.
+type Writer struct {
+	sink      Sink
+	datasetId string
+
+	maxItems    int
+	maxBytes    int
+	maxBuffered int
+	interval    time.Duration
+	chunkItems  int
+	chunkBytes  int
+	retries     int
+	retryDelay  time.Duration
+	onError     func(items []map[string]any, err error)
+
+	mu       sync.Mutex
+	drained  *sync.Cond
+	buf      []map[string]any
+	sizes    []int
+	bufBytes int
+	closed   bool
+	dropped  int
+	lastErr  error
+
+	kick    chan struct{}
+	flushes chan chan error
+	closing chan struct{}
+	done    chan struct{}
+}
+
+// Option configures a Writer.
+type Option func(*Writer)
+
+// WithFlushSize sets the number of items and bytes (of JSON) that trigger a
+// flush. Defaults to 100 items and 1 MiB.
+func WithFlushSize(items, bytes int) Option {
+	return func(w *Writer) {
+		if items > 0 {
+			w.maxItems = items
+		}
+		if bytes > 0 {
+			w.maxBytes = bytes
+		}
+	}
+}
+
+// WithFlushInterval sets the longest time an item stays buffered. Defaults to 5s.
+func WithFlushInterval(d time.Duration) Option {
+	return func(w *Writer) {
+		if d > 0 {
+			w.interval = d
+		}
+	}
+}
+
+// WithChunkSize bounds the number of items and bytes (of JSON) of one
+// request. Defaults to 500 items and 5 MiB; an item larger than the byte
+// limit is sent alone.
+func WithChunkSize(items, bytes int) Option {
+	return func(w *Writer) {
+		if items > 0 {
+			w.chunkItems = items
+		}
+		if bytes > 0 {
+			w.chunkBytes = bytes
+		}
+	}
+}
+
+// WithMaxBuffered sets the number of buffered items above which Write blocks
+// until the background goroutine catches up. Defaults to 10 times the flush
+// size.
+func WithMaxBuffered(items int) Option {
+	return func(w *Writer) {
+		w.maxBuffered = items
+	}
+}
+
+// WithRetry sets how many times a failed batch is retried, waiting delay
+// before the first retry and twice as long before each next one. Defaults to
+// 3 retries from 1s.
+func WithRetry(retries int, delay time.Duration) Option {
+	return func(w *Writer) {
+		if retries >= 0 {
+			w.retries = retries
+		}
+		if delay > 0 {
+			w.retryDelay = delay
+		}
+	}
+}
+
+// WithErrorHandler sets a function called with the items that were dropped
+// and the reason. It is called from the background goroutine.
+func WithErrorHandler(f func(items []map[string]any, err error)) Option {
+	return func(w *Writer) {
+		w.onError = f
+	}
+}
+
+// NewWriter creates a Writer of the dataset datasetId of sink, and starts its
+// background goroutine. It must be closed to send the buffered items.
+func NewWriter(sink Sink, datasetId string, opts ...Option) *Writer {
+	w := &Writer{
+		sink:       sink,
+		datasetId:  datasetId,
+		maxItems:   100,
+		maxBytes:   1 << 20,
+		interval:   5 * time.Second,
+		chunkItems: 500,
+		chunkBytes: 5 << 20,
+		retries:    3,
+		retryDelay: time.Second,
+		kick:       make(chan struct{}, 1),
+		flushes:    make(chan chan error),
+		closing:    make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	w.drained = sync.NewCond(&w.mu)
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.maxBuffered <= 0 {
+		w.maxBuffered = 10 * w.maxItems
+	}
+	go w.run()
+	return w
+}
+
+// Write buffers items. It blocks while the buffer is full, and fails if an
+// item can't be encoded to JSON.
+func (w *Writer) Write(items ...map[string]any) error {
+	sizes := make([]int, len(items))
+	for i, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("%w: encode item %d: %v", errs.ErrInvalidArgument, i, err)
+		}
+		sizes[i] = len(data)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for !w.closed && len(w.buf) >= w.maxBuffered {
+		w.drained.Wait()
+	}
+	if w.closed {
+		return ErrClosed
+	}
+	w.buf = append(w.buf, items...)
+	w.sizes = append(w.sizes, sizes...)
+	for _, size := range sizes {
+		w.bufBytes += size
+	}
+	if len(w.buf) >= w.maxItems || w.bufBytes >= w.maxBytes {
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Flush sends the buffered items and waits until they are added or dropped.
+// It returns the error of the items dropped meanwhile, if any.
+func (w *Writer) Flush(ctx context.Context) error {
+	reply := make(chan error, 1)
+	select {
+	case w.flushes <- reply:
+	case <-w.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-reply:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close sends the buffered items and stops the writer. It returns an error
+// if any item was dropped since the writer was created.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		<-w.done
+		return nil
+	}
+	w.closed = true
+	w.drained.Broadcast()
+	w.mu.Unlock()
+
+	close(w.closing)
+	<-w.done
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.dropped > 0 {
+		return fmt.Errorf("dataset %s: dropped %d items: %w", w.datasetId, w.dropped, w.lastErr)
+	}
+	return nil
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.flush()
+		case <-w.kick:
+			w.flush()
+		case reply := <-w.flushes:
+			reply <- w.flush()
+		case <-w.closing:
+			w.flush()
+			return
+		}
+	}
+}
+
+// flush sends the buffered items in chunks, and returns the error of the
+// items it dropped.
+func (w *Writer) flush() error {
+	w.mu.Lock()
+	items, sizes := w.buf, w.sizes
+	w.buf, w.sizes, w.bufBytes = nil, nil, 0
+	w.drained.Broadcast()
+	w.mu.Unlock()
+
+	var failures []error
+	for len(items) > 0 {
+		n, bytes := 0, 0
+		for n < len(items) && n < w.chunkItems && (n == 0 || bytes+sizes[n] <= w.chunkBytes) {
+			bytes += sizes[n]
+			n++
+		}
+		if err := w.send(items[:n]); err != nil {
+			failures = append(failures, err)
+		}
+		items, sizes = items[n:], sizes[n:]
+	}
+	return errors.Join(failures...)
+}
+
+// send adds a chunk, retrying on failure. Items rejected by the dataset
+// schema are dropped and the others sent again.
+func (w *Writer) send(items []map[string]any) error {
+	delay := w.retryDelay
+	var dropErrs []error
+	for attempt := 0; ; attempt++ {
+		_, err := w.sink.AddItems(context.Background(), w.datasetId, items)
+		if err == nil {
+			return errors.Join(dropErrs...)
+		}
+
+		var ve *errs.ValidationError
+		if errors.As(err, &ve) && len(ve.Items) > 0 {
+			bad := make(map[int]bool, len(ve.Items))
+			for _, item := range ve.Items {
+				bad[item.Index] = true
+			}
+			var valid, invalid []map[string]any
+			for i, item := range items {
+				if bad[i] {
+					invalid = append(invalid, item)
+				} else {
+					valid = append(valid, item)
+				}
+			}
+			dropErrs = append(dropErrs, w.drop(invalid, err))
+			if items = valid; len(items) == 0 {
+				return errors.Join(dropErrs...)
+			}
+			continue
+		}
+		if attempt >= w.retries || errors.Is(err, errs.ErrInvalidArgument) {
+			return errors.Join(append(dropErrs, w.drop(items, err))...)
+		}
+		log.Warnf("add %d items to dataset %s failed (attempt %d/%d), retrying in %s: %v", len(items), w.datasetId, attempt+1, w.retries+1, delay, err)
+		time.Sleep(delay)
+		delay = min(delay*2, 30*time.Second)
+	}
+}
+
+func (w *Writer) drop(items []map[string]any, err error) error {
+	log.Errorf("dropped %d items of dataset %s: %v", len(items), w.datasetId, err)
+	w.mu.Lock()
+	w.dropped += len(items)
+	w.lastErr = err
+	w.mu.Unlock()
+	if w.onError != nil {
+		w.onError(items, err)
+	}
+	return err
+}
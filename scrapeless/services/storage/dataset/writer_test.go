@@ -0,0 +1,215 @@
+package dataset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+// fakeSink records the batches it receives. fail, when set, decides whether
+// a batch is rejected.
+type fakeSink struct {
+	mu      sync.Mutex
+	batches [][]map[string]any
+	calls   int
+	fail    func(call int, items []map[string]any) error
+}
+
+func (s *fakeSink) AddItems(_ context.Context, _ string, items []map[string]any) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.fail != nil {
+		if err := s.fail(s.calls, items); err != nil {
+			return false, err
+		}
+	}
+	s.batches = append(s.batches, items)
+	return true, nil
+}
+
+func (s *fakeSink) items() []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []map[string]any
+	for _, b := range s.batches {
+		items = append(items, b...)
+	}
+	return items
+}
+
+func (s *fakeSink) batchSizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sizes []int
+	for _, b := range s.batches {
+		sizes = append(sizes, len(b))
+	}
+	return sizes
+}
+
+func item(i int) map[string]any {
+	return map[string]any{"i": i}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWriterFlushBySize(t *testing.T) {
+	sink := &fakeSink{}
+	w := NewWriter(sink, "ds", WithFlushSize(3, 0), WithFlushInterval(time.Hour))
+	defer w.Close()
+	for i := range 7 {
+		if err := w.Write(item(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, func() bool { return len(sink.items()) >= 6 })
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := sink.items(); len(got) != 7 || got[6]["i"] != 6 {
+		t.Errorf("items = %v", got)
+	}
+
+	// Bytes trigger a flush as well: {"i":0} is 7 bytes.
+	sink = &fakeSink{}
+	w = NewWriter(sink, "ds", WithFlushSize(100, 14), WithFlushInterval(time.Hour))
+	defer w.Close()
+	_ = w.Write(item(0), item(1))
+	waitFor(t, func() bool { return len(sink.items()) == 2 })
+}
+
+func TestWriterFlushByInterval(t *testing.T) {
+	sink := &fakeSink{}
+	w := NewWriter(sink, "ds", WithFlushInterval(10*time.Millisecond))
+	defer w.Close()
+	_ = w.Write(item(0))
+	waitFor(t, func() bool { return len(sink.items()) == 1 })
+}
+
+func TestWriterChunks(t *testing.T) {
+	sink := &fakeSink{}
+	w := NewWriter(sink, "ds", WithFlushInterval(time.Hour), WithChunkSize(4, 0))
+	for i := range 10 {
+		_ = w.Write(item(i))
+	}
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(sink.batchSizes()); got != "[4 4 2]" {
+		t.Errorf("batches = %s, want [4 4 2]", got)
+	}
+
+	// The byte limit splits chunks too, and an oversized item is sent alone.
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sink = &fakeSink{}
+	w = NewWriter(sink, "ds", WithFlushInterval(time.Hour), WithChunkSize(10, 14))
+	_ = w.Write(item(0), item(1), map[string]any{"big": "0123456789abcdef"}, item(2))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(sink.batchSizes()); got != "[2 1 1]" {
+		t.Errorf("batches = %s, want [2 1 1]", got)
+	}
+}
+
+func TestWriterRetry(t *testing.T) {
+	sink := &fakeSink{fail: func(call int, _ []map[string]any) error {
+		if call < 3 {
+			return errors.New("unavailable")
+		}
+		return nil
+	}}
+	w := NewWriter(sink, "ds", WithRetry(3, time.Millisecond))
+	_ = w.Write(item(0), item(1))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.items()) != 2 || sink.calls != 3 {
+		t.Errorf("items = %v after %d calls", sink.items(), sink.calls)
+	}
+}
+
+func TestWriterDrop(t *testing.T) {
+	var dropped []map[string]any
+	sink := &fakeSink{fail: func(_ int, items []map[string]any) error {
+		var invalid []errs.ItemError
+		for i, item := range items {
+			if item["i"].(int)%2 == 1 {
+				invalid = append(invalid, errs.ItemError{Index: i, Reasons: []string{"/i: odd"}})
+			}
+		}
+		if invalid != nil {
+			return &errs.ValidationError{Items: invalid}
+		}
+		return nil
+	}}
+	w := NewWriter(sink, "ds", WithRetry(0, time.Millisecond), WithErrorHandler(func(items []map[string]any, err error) {
+		dropped = append(dropped, items...)
+	}))
+	for i := range 6 {
+		_ = w.Write(item(i))
+	}
+	err := w.Flush(context.Background())
+	var ve *errs.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("Flush() = %v, want a ValidationError", err)
+	}
+	if got := fmt.Sprint(sink.items()); got != "[map[i:0] map[i:2] map[i:4]]" {
+		t.Errorf("added items = %s", got)
+	}
+	if got := fmt.Sprint(dropped); got != "[map[i:1] map[i:3] map[i:5]]" {
+		t.Errorf("dropped items = %s", got)
+	}
+	if err = w.Close(); err == nil {
+		t.Error("Close() = nil, want the dropped items")
+	}
+}
+
+func TestWriterClose(t *testing.T) {
+	sink := &fakeSink{}
+	w := NewWriter(sink, "ds", WithFlushInterval(time.Hour), WithMaxBuffered(1000))
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 250 {
+				_ = w.Write(item(g*250 + i))
+			}
+		}()
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(sink.items()); n != 1000 {
+		t.Errorf("added %d items, want 1000", n)
+	}
+
+	if err := w.Write(item(0)); !errors.Is(err, ErrClosed) {
+		t.Errorf("Write() = %v, want ErrClosed", err)
+	}
+	if err := w.Flush(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("Flush() = %v, want ErrClosed", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close() = %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+func TestIterItems(t *testing.T) {
+	dataset, datasetId := newExportDataset(t, 25)
+	var urls []string
+	for item, err := range dataset.IterItems(context.Background(), datasetId, 10, false) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		urls = append(urls, item["url"].(string))
+	}
+	if len(urls) != 25 || urls[24] != "https://example.com/24" {
+		t.Fatalf("got %d items: %v", len(urls), urls)
+	}
+
+	type product struct {
+		URL   string `json:"url"`
+		Price struct {
+			Amount float64 `json:"amount"`
+		} `json:"price"`
+	}
+	var n int
+	for p, err := range NewTypedDataset[product](dataset).IterItems(context.Background(), datasetId, 10, true) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("https://example.com/%d", 24-n); p.URL != want || p.Price.Amount != float64(24-n) {
+			t.Fatalf("item %d = %+v, want %s", n, p, want)
+		}
+		n++
+	}
+	if n != 25 {
+		t.Errorf("got %d typed items, want 25", n)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	dataset, datasetId := newExportDataset(t, 25)
+	filter := &DatasetQuery{
+		Where: []QueryCondition{
+			{Field: "price.amount", Op: QueryGte, Value: 10},
+			{Field: "url", Op: QueryContains, Value: "/1"},
+		},
+		Sort:   []QuerySort{{Field: "price.amount", Desc: true}},
+		Fields: []string{"url", "price.amount"},
+		Limit:  3,
+	}
+	resp, err := dataset.Query(context.Background(), datasetId, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 10 {
+		t.Errorf("total = %d, want 10", resp.Total)
+	}
+	want := []map[string]any{
+		{"url": "https://example.com/19", "price": map[string]any{"amount": 19.0}},
+		{"url": "https://example.com/18", "price": map[string]any{"amount": 18.0}},
+		{"url": "https://example.com/17", "price": map[string]any{"amount": 17.0}},
+	}
+	if !reflect.DeepEqual(resp.Items, want) {
+		t.Errorf("items = %v, want %v", resp.Items, want)
+	}
+
+	// Backends without queries evaluate them page by page, with the same result.
+	req := &models.QueryDatasetRequest{
+		DatasetId: datasetId,
+		Where: []models.QueryCondition{
+			{Field: "price.amount", Op: models.QueryGte, Value: 10.0},
+			{Field: "url", Op: models.QueryContains, Value: "/1"},
+		},
+		Sort:   []models.QuerySort{{Field: "price.amount", Desc: true}},
+		Fields: filter.Fields,
+		Limit:  filter.Limit,
+	}
+	fallback, err := dataset.queryPages(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fallback.Total != resp.Total || !reflect.DeepEqual(fallback.Items, resp.Items) {
+		t.Errorf("client-side query = %v (total %d), want %v", fallback.Items, fallback.Total, resp.Items)
+	}
+
+	_, err = dataset.Query(context.Background(), datasetId, &DatasetQuery{Where: []QueryCondition{{Field: "url", Op: "like"}}})
+	var apiErr *errs.APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("Query(unknown op) = %v, want an APIError wrapping ErrInvalidArgument", err)
+	}
+}
+
+func TestSetSchema(t *testing.T) {
+	s := newLocalStorage(t)
+	ctx := context.Background()
+	datasetId, _, err := s.Dataset.CreateDataset(ctx, "schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type product struct {
+		URL   string  `json:"url"`
+		Price float64 `json:"price"`
+	}
+	if err = s.Dataset.SetSchema(ctx, datasetId, product{}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.Dataset.AddItems(ctx, datasetId, []map[string]any{
+		{"url": "https://example.com/1", "price": 1},
+		{"url": "https://example.com/2", "price": "2"},
+		{"price": 3},
+	})
+	var ve *errs.ValidationError
+	if !errors.As(err, &ve) || !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Fatalf("err = %v, want a ValidationError", err)
+	}
+	if len(ve.Items) != 2 || ve.Items[0].Index != 1 || ve.Items[1].Index != 2 {
+		t.Errorf("invalid items = %+v", ve.Items)
+	}
+	if items, _ := s.Dataset.GetItems(ctx, datasetId, 1, 10, false); items.Total != 0 {
+		t.Errorf("stored %d items of an invalid batch", items.Total)
+	}
+
+	if _, err = s.Dataset.AddItems(ctx, datasetId, []map[string]any{{"url": "https://example.com/1", "price": 1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing the schema accepts any item again.
+	if err = s.Dataset.SetSchema(ctx, datasetId, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.Dataset.AddItems(ctx, datasetId, []map[string]any{{"price": "free"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = s.Dataset.SetSchema(ctx, datasetId, 42); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("SetSchema(42) = %v, want ErrInvalidArgument", err)
+	}
+}
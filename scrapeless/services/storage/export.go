@@ -0,0 +1,488 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/scrapeless-ai/sdk-go/internal/code"
+	"github.com/scrapeless-ai/sdk-go/internal/parquet"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/query"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"github.com/xuri/excelize/v2"
+)
+
+// ExportFormat is the file format of a dataset export.
+type ExportFormat string
+
+const (
+	ExportCSV     ExportFormat = "csv"
+	ExportJSONL   ExportFormat = "jsonl"
+	ExportParquet ExportFormat = "parquet"
+	ExportXLSX    ExportFormat = "xlsx"
+)
+
+// ExportOptions configures Dataset.Export.
+type ExportOptions struct {
+	// Fields selects the exported fields, in order. Nested fields are selected
+	// with dotted paths such as "price.amount". By default every field is
+	// exported.
+	Fields []string
+	// Flatten exports nested objects as one field per leaf, named with dotted
+	// paths, instead of a single JSON-encoded field.
+	Flatten bool
+	// PageSize is the number of items fetched per request, at least 10.
+	// Defaults to 100.
+	PageSize int
+	// Desc exports the items in descending order.
+	Desc bool
+}
+
+// Export writes every item of the dataset to w in the given format, fetching
+// them page by page.
+//
+// CSV, Parquet and XLSX exports have a fixed set of columns: opts.Fields when
+// set, otherwise the dataset fields (DatasetInfo.Fields) in order, followed by
+// any other field of the first page. Fields first seen after the first page
+// are left out. Nested values that aren't flattened are written as JSON.
+//
+// Parquet columns are typed after the values of the first page: a field
+// holding only booleans is a boolean column, one holding only numbers an
+// int64 column if they are all integers and a double column otherwise, and
+// any other field a string column. A later value that doesn't fit the type
+// of its column fails the export.
+//
+// Parameters:
+//
+//	ctx: The context for the request.
+//	format: The format to write.
+//	w: Where to write the export.
+//	opts: The export options, nil for defaults.
+func (s *Dataset) Export(ctx context.Context, datasetId string, format ExportFormat, w io.Writer, opts *ExportOptions) error {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+	switch format {
+	case ExportCSV, ExportJSONL, ExportParquet, ExportXLSX:
+	default:
+		return fmt.Errorf("%w: unknown export format %q", errs.ErrInvalidArgument, format)
+	}
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	pageSize = max(pageSize, 10)
+
+	var ew exportWriter
+	for page := 1; ; page++ {
+		resp, err := s.GetItems(ctx, datasetId, page, pageSize, opts.Desc)
+		if err != nil {
+			return err
+		}
+		if ew == nil {
+			columns := opts.Fields
+			if len(columns) == 0 && format != ExportJSONL {
+				columns = exportColumns(s.datasetFields(ctx, datasetId), resp.Items, opts.Flatten)
+			}
+			ew = newExportWriter(format, w, columns, opts.Flatten, resp.Items)
+		}
+		for _, item := range resp.Items {
+			if err = ew.WriteItem(item); err != nil {
+				return fmt.Errorf("export dataset %s: %w", datasetId, err)
+			}
+		}
+		if len(resp.Items) < pageSize || (resp.Total > 0 && page*pageSize >= resp.Total) {
+			break
+		}
+	}
+	if err := ew.Close(); err != nil {
+		return fmt.Errorf("export dataset %s: %w", datasetId, err)
+	}
+	return nil
+}
+
+// datasetFields returns the fields of the dataset, looking it up among the
+// datasets of the run. It returns nil if the dataset isn't found.
+func (s *Dataset) datasetFields(ctx context.Context, datasetId string) []string {
+	for page := int64(1); ; page++ {
+		datasets, err := s.client.ListDatasets(ctx, &models.ListDatasetsRequest{
+			ActorId:  &s.cfg.Actor.ActorId,
+			RunId:    &s.cfg.Actor.RunId,
+			Page:     page,
+			PageSize: 100,
+		})
+		if err != nil {
+			log.Warnf("failed to get the fields of dataset %s: %v", datasetId, code.Format(err))
+			return nil
+		}
+		for _, dataset := range datasets.Items {
+			if dataset.Id == datasetId {
+				return dataset.Fields
+			}
+		}
+		if len(datasets.Items) < 100 || page*100 >= datasets.Total {
+			return nil
+		}
+	}
+}
+
+// exportColumns returns the columns of an export: the dataset fields first,
+// even those no item sets, then the other fields of items in name order.
+func exportColumns(fields []string, items []map[string]any, flatten bool) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, item := range items {
+		if flatten {
+			item = flattenItem(item)
+		}
+		for key := range item {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	columns := make([]string, 0, len(keys))
+	added := map[string]bool{}
+	add := func(column string) {
+		if !added[column] {
+			added[column] = true
+			columns = append(columns, column)
+		}
+	}
+	for _, field := range fields {
+		if !flatten || seen[field] {
+			add(field)
+			continue
+		}
+		// The field was flattened into its leaves, or isn't set by the items
+		// read yet and is kept as is.
+		leaves := false
+		for _, key := range keys {
+			if strings.HasPrefix(key, field+".") {
+				add(key)
+				leaves = true
+			}
+		}
+		if !leaves {
+			add(field)
+		}
+	}
+	for _, key := range keys {
+		add(key)
+	}
+	return columns
+}
+
+// flattenItem flattens nested objects of item into dotted fields.
+func flattenItem(item map[string]any) map[string]any {
+	flat := make(map[string]any, len(item))
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for key, value := range m {
+			if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+				walk(prefix+key+".", nested)
+				continue
+			}
+			flat[prefix+key] = value
+		}
+	}
+	walk("", item)
+	return flat
+}
+
+// formatValue formats a field value as text; nested values are JSON-encoded.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+type exportWriter interface {
+	WriteItem(item map[string]any) error
+	Close() error
+}
+
+// newExportWriter returns the writer of an export with the given columns,
+// whose types may be taken from the items of the first page.
+func newExportWriter(format ExportFormat, w io.Writer, columns []string, flatten bool, items []map[string]any) exportWriter {
+	switch format {
+	case ExportCSV:
+		return &csvExport{w: csv.NewWriter(w), columns: columns}
+	case ExportParquet:
+		typed := parquetColumns(columns, items)
+		return &parquetExport{w: parquet.NewWriter(w, typed), columns: typed}
+	case ExportXLSX:
+		return newXLSXExport(w, columns)
+	default:
+		return &jsonlExport{w: bufio.NewWriter(w), columns: columns, flatten: flatten}
+	}
+}
+
+type csvExport struct {
+	w       *csv.Writer
+	columns []string
+	started bool
+	row     []string
+}
+
+func (e *csvExport) WriteItem(item map[string]any) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.row = e.row[:0]
+	for _, column := range e.columns {
+		value, _ := query.Lookup(item, column)
+		e.row = append(e.row, formatValue(value))
+	}
+	return e.w.Write(e.row)
+}
+
+func (e *csvExport) writeHeader() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+	return e.w.Write(e.columns)
+}
+
+func (e *csvExport) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonlExport struct {
+	w       *bufio.Writer
+	columns []string
+	flatten bool
+}
+
+func (e *jsonlExport) WriteItem(item map[string]any) error {
+	if len(e.columns) == 0 {
+		if e.flatten {
+			item = flattenItem(item)
+		}
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		e.w.Write(data)
+		return e.w.WriteByte('\n')
+	}
+	// Write the selected fields in order, which a map would lose.
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, column := range e.columns {
+		value, _ := query.Lookup(item, column)
+		key, _ := json.Marshal(column)
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(data)
+	}
+	buf.WriteString("}\n")
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+func (e *jsonlExport) Close() error {
+	return e.w.Flush()
+}
+
+type parquetExport struct {
+	w       *parquet.Writer
+	columns []parquet.Column
+}
+
+// parquetColumns types the columns after the values items hold.
+func parquetColumns(columns []string, items []map[string]any) []parquet.Column {
+	typed := make([]parquet.Column, len(columns))
+	for i, column := range columns {
+		typed[i] = parquet.Column{Name: column, Type: parquet.String}
+		var bools, ints, floats, others int
+		for _, item := range items {
+			switch v, _ := query.Lookup(item, column); v := v.(type) {
+			case nil:
+			case bool:
+				bools++
+			case float64:
+				if _, ok := toInt64(v); ok {
+					ints++
+				} else {
+					floats++
+				}
+			case json.Number:
+				if _, err := v.Int64(); err == nil {
+					ints++
+				} else {
+					floats++
+				}
+			default:
+				others++
+			}
+		}
+		switch {
+		case others > 0:
+		case bools > 0 && ints+floats == 0:
+			typed[i].Type = parquet.Boolean
+		case ints > 0 && bools+floats == 0:
+			typed[i].Type = parquet.Int64
+		case floats > 0 && bools == 0:
+			typed[i].Type = parquet.Double
+		}
+	}
+	return typed
+}
+
+// toInt64 returns f as an int64 if it is an integer in the int64 range.
+func toInt64(f float64) (int64, bool) {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+func (e *parquetExport) WriteItem(item map[string]any) error {
+	row := make([]any, len(e.columns))
+	for i, column := range e.columns {
+		value, ok := query.Lookup(item, column.Name)
+		if !ok || value == nil {
+			continue
+		}
+		if row[i] = parquetValue(column.Type, value); row[i] == nil {
+			return fmt.Errorf("%w: field %s holds %v, which doesn't fit its %s column", errs.ErrInvalidArgument, column.Name, formatValue(value), column.Type)
+		}
+	}
+	return e.w.Write(row)
+}
+
+// parquetValue converts value to the type t, returning nil if it doesn't fit.
+func parquetValue(t parquet.Type, value any) any {
+	switch t {
+	case parquet.Boolean:
+		if v, ok := value.(bool); ok {
+			return v
+		}
+	case parquet.Int64:
+		switch v := value.(type) {
+		case float64:
+			if n, ok := toInt64(v); ok {
+				return n
+			}
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				return n
+			}
+		}
+	case parquet.Double:
+		switch v := value.(type) {
+		case float64:
+			return v
+		case json.Number:
+			if f, err := v.Float64(); err == nil {
+				return f
+			}
+		}
+	default:
+		return formatValue(value)
+	}
+	return nil
+}
+
+func (e *parquetExport) Close() error {
+	return e.w.Close()
+}
+
+type xlsxExport struct {
+	out     io.Writer
+	file    *excelize.File
+	sheet   *excelize.StreamWriter
+	columns []string
+	row     int
+	err     error
+}
+
+func newXLSXExport(w io.Writer, columns []string) *xlsxExport {
+	e := &xlsxExport{out: w, file: excelize.NewFile(), columns: columns, row: 1}
+	e.sheet, e.err = e.file.NewStreamWriter("Sheet1")
+	if e.err == nil {
+		header := make([]any, len(columns))
+		for i, column := range columns {
+			header[i] = column
+		}
+		e.err = e.writeRow(header)
+	}
+	return e
+}
+
+func (e *xlsxExport) writeRow(values []any) error {
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err != nil {
+		return err
+	}
+	e.row++
+	return e.sheet.SetRow(cell, values)
+}
+
+func (e *xlsxExport) WriteItem(item map[string]any) error {
+	if e.err != nil {
+		return e.err
+	}
+	values := make([]any, len(e.columns))
+	for i, column := range e.columns {
+		value, _ := query.Lookup(item, column)
+		switch value.(type) {
+		case nil, string, bool, float64:
+			values[i] = value
+		default:
+			values[i] = formatValue(value)
+		}
+	}
+	return e.writeRow(values)
+}
+
+func (e *xlsxExport) Close() error {
+	defer e.file.Close()
+	if e.err != nil {
+		return e.err
+	}
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	return e.file.Write(e.out)
+}
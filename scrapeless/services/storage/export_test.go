@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/internal/parquet"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+	"github.com/xuri/excelize/v2"
+)
+
+func newExportDataset(t *testing.T, n int) (*Dataset, string) {
+	s := newLocalStorage(t)
+	ctx := context.Background()
+	datasetId, _, err := s.Dataset.CreateDataset(ctx, "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := make([]map[string]any, n)
+	for i := range items {
+		items[i] = map[string]any{
+			"url":   fmt.Sprintf("https://example.com/%d", i),
+			"price": map[string]any{"amount": float64(i), "currency": "USD"},
+			"tags":  []any{"a", "b"},
+		}
+	}
+	if _, err = s.Dataset.AddItems(ctx, datasetId, items); err != nil {
+		t.Fatal(err)
+	}
+	return s.Dataset, datasetId
+}
+
+func TestExportCSV(t *testing.T) {
+	dataset, datasetId := newExportDataset(t, 25)
+	var buf bytes.Buffer
+	err := dataset.Export(context.Background(), datasetId, ExportCSV, &buf, &ExportOptions{Flatten: true, PageSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 26 {
+		t.Fatalf("got %d records, want 26", len(records))
+	}
+	wantHeader := []string{"price.amount", "price.currency", "tags", "url"}
+	if !reflect.DeepEqual(records[0], wantHeader) {
+		t.Errorf("header = %v, want %v", records[0], wantHeader)
+	}
+	if want := []string{"24", "USD", `["a","b"]`, "https://example.com/24"}; !reflect.DeepEqual(records[25], want) {
+		t.Errorf("last record = %v, want %v", records[25], want)
+	}
+}
+
+func TestExportColumns(t *testing.T) {
+	items := []map[string]any{{"url": "u", "price": map[string]any{"amount": 1.0}, "tags": []any{}}}
+	fields := []string{"price", "title", "url"}
+	if got, want := exportColumns(fields, items, true), []string{"price.amount", "title", "url", "tags"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("exportColumns(flatten) = %v, want %v", got, want)
+	}
+	if got, want := exportColumns(fields, items, false), []string{"price", "title", "url", "tags"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("exportColumns() = %v, want %v", got, want)
+	}
+}
+
+func TestExportJSONLFields(t *testing.T) {
+	dataset, datasetId := newExportDataset(t, 12)
+	var buf bytes.Buffer
+	err := dataset.Export(context.Background(), datasetId, ExportJSONL, &buf, &ExportOptions{Fields: []string{"url", "price.amount", "missing"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 12 {
+		t.Fatalf("got %d lines, want 12", len(lines))
+	}
+	if want := `{"url":"https://example.com/0","price.amount":0,"missing":null}`; lines[0] != want {
+		t.Errorf("line = %s, want %s", lines[0], want)
+	}
+
+	buf.Reset()
+	if err = dataset.Export(context.Background(), datasetId, ExportJSONL, &buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	var item map[string]any
+	if err = json.Unmarshal([]byte(strings.SplitN(buf.String(), "\n", 2)[0]), &item); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := item["price"].(map[string]any); !ok {
+		t.Errorf("item = %v, want nested price", item)
+	}
+}
+
+func TestExportXLSX(t *testing.T) {
+	dataset, datasetId := newExportDataset(t, 3)
+	var buf bytes.Buffer
+	if err := dataset.Export(context.Background(), datasetId, ExportXLSX, &buf, &ExportOptions{Fields: []string{"url", "price"}}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := f.GetRows("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"url", "price"},
+		{"https://example.com/0", `{"amount":0,"currency":"USD"}`},
+		{"https://example.com/1", `{"amount":1,"currency":"USD"}`},
+		{"https://example.com/2", `{"amount":2,"currency":"USD"}`},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+}
+
+func TestExportParquet(t *testing.T) {
+	dataset, datasetId := newExportDataset(t, 3)
+	var buf bytes.Buffer
+	if err := dataset.Export(context.Background(), datasetId, ExportParquet, &buf, &ExportOptions{Flatten: true}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		t.Fatal("not a parquet file")
+	}
+	if !bytes.Contains(data, []byte("https://example.com/2")) || !bytes.Contains(data, []byte("price.currency")) {
+		t.Error("parquet file is missing values")
+	}
+}
+
+func TestParquetColumns(t *testing.T) {
+	items := []map[string]any{
+		{"url": "a", "price": map[string]any{"amount": 1.0}, "rating": 4.0, "sold": true, "tags": []any{"x"}},
+		{"url": "b", "price": map[string]any{"amount": 2.0}, "rating": 4.5, "sold": nil, "code": json.Number("7")},
+	}
+	columns := parquetColumns([]string{"url", "price.amount", "rating", "sold", "tags", "code", "missing"}, items)
+	want := []parquet.Type{parquet.String, parquet.Int64, parquet.Double, parquet.Boolean, parquet.String, parquet.Int64, parquet.String}
+	for i, column := range columns {
+		if column.Type != want[i] {
+			t.Errorf("column %s is %s, want %s", column.Name, column.Type, want[i])
+		}
+	}
+}
+
+func TestExportParquetTypeMismatch(t *testing.T) {
+	dataset, datasetId := newExportDataset(t, 10)
+	ctx := context.Background()
+	if _, err := dataset.AddItems(ctx, datasetId, []map[string]any{{"price": map[string]any{"amount": 1.5}}}); err != nil {
+		t.Fatal(err)
+	}
+	err := dataset.Export(ctx, datasetId, ExportParquet, &bytes.Buffer{}, &ExportOptions{Flatten: true, PageSize: 10})
+	if !errors.Is(err, errs.ErrInvalidArgument) || !strings.Contains(err.Error(), "price.amount") {
+		t.Errorf("Export() = %v, want ErrInvalidArgument for price.amount", err)
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	dataset, datasetId := newExportDataset(t, 1)
+	err := dataset.Export(context.Background(), datasetId, "xml", &bytes.Buffer{}, nil)
+	if !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("err = %v, want ErrInvalidArgument", err)
+	}
+}
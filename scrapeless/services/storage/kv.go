@@ -2,14 +2,22 @@ package storage
 
 import (
 	"context"
-	"github.com/scrapeless-ai/sdk-go/env"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"github.com/scrapeless-ai/sdk-go/internal/code"
-	"github.com/scrapeless-ai/sdk-go/internal/remote/storage"
+	"github.com/scrapeless-ai/sdk-go/internal/helper"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"iter"
+	"sort"
+	"strings"
 )
 
-type KV struct{}
+type KV struct {
+	backend
+}
 
 // ListNamespaces retrieves a list of KV namespaces with pagination and sorting options.
 // Parameters:
@@ -25,7 +33,7 @@ func (s *KV) ListNamespaces(ctx context.Context, page int64, pageSize int64, des
 	if pageSize < 10 {
 		pageSize = 10
 	}
-	keyResp, err := storage.ClientInterface.ListNamespaces(ctx, page, pageSize, desc)
+	keyResp, err := s.client.ListNamespaces(ctx, page, pageSize, desc)
 	if err != nil {
 		log.Errorf("failed to list kv namespaces: %v", code.Format(err))
 		return nil, code.Format(err)
@@ -58,11 +66,11 @@ func (s *KV) ListNamespaces(ctx context.Context, page int64, pageSize int64, des
 //	ctx:The request context.
 //	name: The name of the namespace to create.
 func (s *KV) CreateNamespace(ctx context.Context, name string) (namespaceId string, namespaceName string, err error) {
-	name = name + "-" + env.GetActorEnv().RunId
-	namespaceId, err = storage.ClientInterface.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{
+	name = name + "-" + s.cfg.Actor.RunId
+	namespaceId, err = s.client.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{
 		Name:    name,
-		ActorId: env.GetActorEnv().ActorId,
-		RunId:   env.GetActorEnv().RunId,
+		ActorId: s.cfg.Actor.ActorId,
+		RunId:   s.cfg.Actor.RunId,
 	})
 	if err != nil {
 		log.Errorf("failed to create kv namespace: %v", code.Format(err))
@@ -77,7 +85,7 @@ func (s *KV) CreateNamespace(ctx context.Context, name string) (namespaceId stri
 //	ctx: The request context.
 //	namespaceName: Name of the namespace to retrieve
 func (s *KV) GetNamespace(ctx context.Context, namespaceName string) (*KvNamespaceItem, error) {
-	namespace, err := storage.ClientInterface.GetNamespace(ctx, namespaceName)
+	namespace, err := s.client.GetNamespace(ctx, namespaceName)
 	if err != nil {
 		log.Errorf("failed to get kv namespace: %v", code.Format(err))
 		return nil, code.Format(err)
@@ -102,7 +110,7 @@ func (s *KV) GetNamespace(ctx context.Context, namespaceName string) (*KvNamespa
 //
 //	ctx:The request context.
 func (s *KV) DelNamespace(ctx context.Context, namespaceId string) (bool, error) {
-	ok, err := storage.ClientInterface.DelNamespace(ctx, namespaceId)
+	ok, err := s.client.DelNamespace(ctx, namespaceId)
 	if err != nil {
 		log.Errorf("failed to delete kv namespace: %v", code.Format(err))
 		return false, code.Format(err)
@@ -116,8 +124,8 @@ func (s *KV) DelNamespace(ctx context.Context, namespaceId string) (bool, error)
 //	ctx: The request context.
 //	name: New namespace name
 func (s *KV) RenameNamespace(ctx context.Context, namespaceId string, name string) (ok bool, namespaceName string, err error) {
-	name = name + "-" + env.GetActorEnv().RunId
-	ok, err = storage.ClientInterface.RenameNamespace(ctx, namespaceId, name)
+	name = name + "-" + s.cfg.Actor.RunId
+	ok, err = s.client.RenameNamespace(ctx, namespaceId, name)
 	if err != nil {
 		log.Errorf("failed to rename kv namespace: %v", code.Format(err))
 		return false, "", code.Format(err)
@@ -138,7 +146,7 @@ func (s *KV) ListKeys(ctx context.Context, namespaceId string, page int64, pageS
 	if pageSize < 10 {
 		pageSize = 10
 	}
-	keys, err := storage.ClientInterface.ListKeys(ctx, &models.ListKeyInfo{
+	keys, err := s.client.ListKeys(ctx, &models.ListKeyInfo{
 		NamespaceId: namespaceId,
 		Page:        page,
 		Size:        pageSize,
@@ -160,6 +168,98 @@ func (s *KV) ListKeys(ctx context.Context, namespaceId string, page int64, pageS
 	return kvKeys, nil
 }
 
+// IterKeys returns an iterator over all the keys of the namespace. Pages are
+// fetched lazily, the next one while the current one is consumed. The
+// iteration stops at the first error.
+//
+// Parameters:
+//
+//	ctx: Request context
+//	namespaceId: Identifier of the namespace
+//	pageSize: Number of keys fetched per request. Minimum 10
+func (s *KV) IterKeys(ctx context.Context, namespaceId string, pageSize int64) iter.Seq2[map[string]any, error] {
+	if pageSize < 10 {
+		pageSize = 10
+	}
+	return helper.Paginate(ctx, func(ctx context.Context, page int64) ([]map[string]any, bool, error) {
+		keys, err := s.ListKeys(ctx, namespaceId, page, pageSize)
+		if err != nil || keys == nil {
+			return nil, false, err
+		}
+		return keys.Items, helper.HasMore(page, pageSize, len(keys.Items), keys.Total), nil
+	})
+}
+
+// ScanKeys returns the keys of the namespace starting with prefix, in
+// ascending order. Scans resume from the cursor of the previous page, which
+// stays valid while keys are added or deleted. With the local and Redis
+// storage, every key present during the whole scan is returned exactly once.
+//
+// The API has no scan, so online every call lists all the keys of the
+// namespace page by page, costing O(N) in the namespace size for each page
+// of the scan. Keys deleted while the pages are listed shift the following
+// ones, so a key present during the whole scan can be missed.
+// Parameters:
+//
+//	ctx: Request context
+//	namespaceId: Identifier of the namespace
+//	prefix: The prefix of the keys, "" for all of them
+//	cursor: The Cursor of the previous page, "" to start a scan
+//	limit: Maximum number of keys returned. Defaults to 100 if <=0, at most 1000
+func (s *KV) ScanKeys(ctx context.Context, namespaceId string, prefix string, cursor string, limit int64) (*KeyScan, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	limit = min(limit, 1000)
+	after, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor %q", errs.ErrInvalidArgument, cursor)
+	}
+	req := &models.ScanKeysRequest{
+		NamespaceId: namespaceId,
+		Prefix:      prefix,
+		After:       string(after),
+		Limit:       limit,
+	}
+	scan, err := s.client.ScanKeys(ctx, req)
+	if errors.Is(err, errors.ErrUnsupported) {
+		scan, err = s.scanPages(ctx, req)
+	}
+	if err != nil {
+		log.Errorf("failed to scan kv keys: %v", code.Format(err))
+		return nil, code.Format(err)
+	}
+	resp := &KeyScan{Items: scan.Items}
+	if scan.More && len(scan.Items) > 0 {
+		last, _ := scan.Items[len(scan.Items)-1]["key"].(string)
+		resp.Cursor = base64.RawURLEncoding.EncodeToString([]byte(last))
+	}
+	return resp, nil
+}
+
+// scanPages evaluates req on the keys of every page of the namespace, for
+// the storage without a native scan.
+func (s *KV) scanPages(ctx context.Context, req *models.ScanKeysRequest) (*models.KvScan, error) {
+	var matches []map[string]any
+	for item, err := range s.IterKeys(ctx, req.NamespaceId, 100) {
+		if err != nil {
+			return nil, err
+		}
+		key, _ := item["key"].(string)
+		if strings.HasPrefix(key, req.Prefix) && key > req.After {
+			matches = append(matches, item)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i]["key"].(string) < matches[j]["key"].(string)
+	})
+	scan := &models.KvScan{Items: matches}
+	if int64(len(matches)) > req.Limit {
+		scan.Items, scan.More = matches[:req.Limit], true
+	}
+	return scan, nil
+}
+
 // DelValue deletes the value associated with the specified key in the given namespace.
 // Parameters:
 //
@@ -167,7 +267,7 @@ func (s *KV) ListKeys(ctx context.Context, namespaceId string, page int64, pageS
 //	namespaceId: Identifier of the namespace
 //	key: The key to delete
 func (s *KV) DelValue(ctx context.Context, namespaceId string, key string) (bool, error) {
-	ok, err := storage.ClientInterface.DelValue(ctx, namespaceId, key)
+	ok, err := s.client.DelValue(ctx, namespaceId, key)
 	if err != nil {
 		log.Errorf("failed to delete kv value: %v", code.Format(err))
 		return false, code.Format(err)
@@ -191,7 +291,7 @@ func (s *KV) BulkSetValue(ctx context.Context, namespaceId string, data []BulkIt
 		})
 	}
 
-	val, err := storage.ClientInterface.BulkSetValue(ctx, &models.BulkSet{
+	val, err := s.client.BulkSetValue(ctx, &models.BulkSet{
 		NamespaceId: namespaceId,
 		Items:       items,
 	})
@@ -209,7 +309,7 @@ func (s *KV) BulkSetValue(ctx context.Context, namespaceId string, data []BulkIt
 //	namespaceId: Identifier of the namespace
 //	keys: A slice of keys to delete
 func (s *KV) BulkDelValue(ctx context.Context, namespaceId string, keys []string) (bool, error) {
-	ok, err := storage.ClientInterface.BulkDelValue(ctx, namespaceId, keys)
+	ok, err := s.client.BulkDelValue(ctx, namespaceId, keys)
 	if err != nil {
 		log.Errorf("failed to bulk delete kv value: %v", code.Format(err))
 		return false, code.Format(err)
@@ -226,7 +326,7 @@ func (s *KV) BulkDelValue(ctx context.Context, namespaceId string, keys []string
 //	value: kv value
 //	expiration: kv expiration  Time-to-live in seconds (s)
 func (s *KV) SetValue(ctx context.Context, namespaceId string, key string, value string, expiration uint) (bool, error) {
-	ok, err := storage.ClientInterface.SetValue(ctx, &models.SetValue{
+	ok, err := s.client.SetValue(ctx, &models.SetValue{
 		NamespaceId: namespaceId,
 		Key:         key,
 		Value:       value,
@@ -246,7 +346,7 @@ func (s *KV) SetValue(ctx context.Context, namespaceId string, key string, value
 //	namespaceId: Identifier of the namespace
 //	key: The key whose value is to be retrieved
 func (s *KV) GetValue(ctx context.Context, namespaceId string, key string) (string, error) {
-	val, err := storage.ClientInterface.GetValue(ctx, namespaceId, key)
+	val, err := s.client.GetValue(ctx, namespaceId, key)
 	if err != nil {
 		log.Errorf("failed to get kv value: %v", code.Format(err))
 		return "", code.Format(err)
@@ -254,6 +354,115 @@ func (s *KV) GetValue(ctx context.Context, namespaceId string, key string) (stri
 	return val, nil
 }
 
+// CompareAndSwap atomically sets the key to value if it currently holds old.
+// It returns false, leaving the key unchanged, if it holds another value or
+// doesn't exist. Online, it requires the Redis storage.
+// Parameters:
+//
+//	ctx: Request context
+//	namespaceId: Identifier of the namespace
+//	key: kv key
+//	old: The value the key must hold
+//	value: The new value
+//	expiration: Time-to-live of the new value in seconds (s)
+func (s *KV) CompareAndSwap(ctx context.Context, namespaceId string, key string, old string, value string, expiration uint) (bool, error) {
+	ok, err := s.client.CompareAndSwap(ctx, &models.CompareAndSwap{
+		NamespaceId: namespaceId,
+		Key:         key,
+		OldValue:    old,
+		Value:       value,
+		Expiration:  expiration,
+	})
+	if err != nil {
+		log.Errorf("failed to compare and swap kv value: %v", code.Format(err))
+		return false, code.Format(err)
+	}
+	return ok, nil
+}
+
+// SetIfNotExists atomically sets the key unless it already holds a value that
+// hasn't expired. It returns whether the key was set. Online, it requires the
+// Redis storage.
+// Parameters:
+//
+//	ctx: Request context
+//	namespaceId: Identifier of the namespace
+//	key: kv key
+//	value: kv value
+//	expiration: Time-to-live in seconds (s)
+func (s *KV) SetIfNotExists(ctx context.Context, namespaceId string, key string, value string, expiration uint) (bool, error) {
+	ok, err := s.client.SetIfNotExists(ctx, &models.SetValue{
+		NamespaceId: namespaceId,
+		Key:         key,
+		Value:       value,
+		Expiration:  expiration,
+	})
+	if err != nil {
+		log.Errorf("failed to set kv value if not exists: %v", code.Format(err))
+		return false, code.Format(err)
+	}
+	return ok, nil
+}
+
+// Increment atomically adds delta to the integer value of the key and returns
+// the new value. A missing key counts as 0; the expiry of an existing key is
+// kept. It fails with errs.ErrInvalidArgument if the value isn't an integer.
+// Online, it requires the Redis storage.
+// Parameters:
+//
+//	ctx: Request context
+//	namespaceId: Identifier of the namespace
+//	key: kv key
+//	delta: The amount to add, negative to decrement
+func (s *KV) Increment(ctx context.Context, namespaceId string, key string, delta int64) (int64, error) {
+	n, err := s.client.Increment(ctx, namespaceId, key, delta)
+	if err != nil {
+		log.Errorf("failed to increment kv value: %v", code.Format(err))
+		return 0, code.Format(err)
+	}
+	return n, nil
+}
+
+// Touch resets the time-to-live of the key without changing its value. It
+// returns false if the key doesn't exist. Online, it requires the Redis
+// storage.
+// Parameters:
+//
+//	ctx: Request context
+//	namespaceId: Identifier of the namespace
+//	key: kv key
+//	expiration: Time-to-live in seconds (s), counted from now
+func (s *KV) Touch(ctx context.Context, namespaceId string, key string, expiration uint) (bool, error) {
+	ok, err := s.client.Touch(ctx, namespaceId, key, expiration)
+	if err != nil {
+		log.Errorf("failed to touch kv value: %v", code.Format(err))
+		return false, code.Format(err)
+	}
+	return ok, nil
+}
+
+// GetWithMetadata retrieves the value of the key with its size and expiry.
+// It fails with errs.ErrNotFound if the key doesn't exist. Online, it
+// requires the Redis storage.
+// Parameters:
+//
+//	ctx: Request context
+//	namespaceId: Identifier of the namespace
+//	key: The key whose value is to be retrieved
+func (s *KV) GetWithMetadata(ctx context.Context, namespaceId string, key string) (*KvValue, error) {
+	val, err := s.client.GetWithMetadata(ctx, namespaceId, key)
+	if err != nil {
+		log.Errorf("failed to get kv value with metadata: %v", code.Format(err))
+		return nil, code.Format(err)
+	}
+	return &KvValue{
+		Key:      val.Key,
+		Value:    val.Value,
+		Size:     val.Size,
+		ExpireAt: val.ExpireAt,
+	}, nil
+}
+
 func (s *KV) Close() error {
 	return nil
 }
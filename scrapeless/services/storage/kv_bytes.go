@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/scrapeless-ai/sdk-go/internal/code"
+	"github.com/scrapeless-ai/sdk-go/internal/helper"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+)
+
+// DefaultSpillThreshold is the size above which SetBytes stores values in
+// object storage rather than in the KV store.
+const DefaultSpillThreshold = 256 << 10
+
+// minCompressSize is the size under which values aren't worth compressing.
+const minCompressSize = 1 << 10
+
+// blobPrefix marks the KV values written by SetBytes.
+const blobPrefix = "scrapeless:blob:"
+
+// BytesOptions configures how SetBytes stores a value.
+type BytesOptions struct {
+	// ContentType is the media type returned by GetBytes. Defaults to
+	// application/octet-stream.
+	ContentType string
+	// NoCompress stores the value as is. Otherwise values are gzipped when
+	// that makes them smaller.
+	NoCompress bool
+	// SpillThreshold is the stored size above which the value goes to object
+	// storage, the KV store only keeping a pointer to it. Defaults to
+	// DefaultSpillThreshold; a negative threshold never spills.
+	SpillThreshold int
+	// BucketId is the bucket spilled values are stored in. Defaults to the
+	// bucket of the actor.
+	BucketId string
+}
+
+// kvBlob is the KV value of a SetBytes value: the value itself, base64
+// encoded, or a pointer to the object holding it.
+type kvBlob struct {
+	ContentType string `json:"contentType"`
+	Encoding    string `json:"encoding,omitempty"`
+	Size        int    `json:"size"`
+	Data        string `json:"data,omitempty"`
+	BucketId    string `json:"bucketId,omitempty"`
+	ObjectId    string `json:"objectId,omitempty"`
+}
+
+// SetBytes stores a binary value. Values are gzipped when it pays off, and
+// those still larger than the spill threshold are stored in object storage.
+// The object of the value it replaces, if any, is deleted.
+// Parameters:
+//
+//	ctx: Request context
+//	namespaceId: Identifier of the namespace
+//	key: kv key
+//	data: The value
+//	expiration: Time-to-live in seconds (s). Spilled objects don't expire; DelBytes deletes them
+//	opts: Storage options, nil for the defaults
+func (s *KV) SetBytes(ctx context.Context, namespaceId string, key string, data []byte, expiration uint, opts *BytesOptions) (bool, error) {
+	if opts == nil {
+		opts = &BytesOptions{}
+	}
+	blob := kvBlob{ContentType: opts.ContentType, Size: len(data)}
+	if blob.ContentType == "" {
+		blob.ContentType = "application/octet-stream"
+	}
+	stored := data
+	if !opts.NoCompress && len(data) >= minCompressSize {
+		compressed, err := helper.GzipCompressData(data)
+		if err != nil {
+			return false, fmt.Errorf("compress value: %w", err)
+		}
+		if len(compressed) < len(data) {
+			stored, blob.Encoding = compressed, "gzip"
+		}
+	}
+
+	threshold := opts.SpillThreshold
+	if threshold == 0 {
+		threshold = DefaultSpillThreshold
+	}
+	if threshold > 0 && len(stored) > threshold {
+		blob.BucketId = opts.BucketId
+		if blob.BucketId == "" {
+			blob.BucketId = s.cfg.Actor.BucketId
+		}
+		objectId, err := s.client.PutObject(ctx, &models.PutObjectRequest{
+			BucketId: blob.BucketId,
+			Filename: key + ".bin",
+			Data:     stored,
+			ActorId:  s.cfg.Actor.ActorId,
+			RunId:    s.cfg.Actor.RunId,
+		})
+		if err != nil {
+			log.Errorf("failed to spill kv value: %v", code.Format(err))
+			return false, code.Format(err)
+		}
+		blob.ObjectId = objectId
+	} else {
+		blob.Data = base64.StdEncoding.EncodeToString(stored)
+	}
+
+	previous, _ := s.getBlob(ctx, namespaceId, key)
+	value, _ := json.Marshal(blob)
+	ok, err := s.SetValue(ctx, namespaceId, key, blobPrefix+string(value), expiration)
+	if err != nil {
+		if blob.ObjectId != "" {
+			s.deleteSpilled(ctx, &blob)
+		}
+		return false, err
+	}
+	if previous != nil && previous.ObjectId != "" && previous.ObjectId != blob.ObjectId {
+		s.deleteSpilled(ctx, previous)
+	}
+	return ok, nil
+}
+
+// GetBytes retrieves a value stored with SetBytes, and its content type.
+// Values stored with SetValue are returned as text/plain.
+// Parameters:
+//
+//	ctx: Request context
+//	namespaceId: Identifier of the namespace
+//	key: The key whose value is to be retrieved
+func (s *KV) GetBytes(ctx context.Context, namespaceId string, key string) (data []byte, contentType string, err error) {
+	value, err := s.GetValue(ctx, namespaceId, key)
+	if err != nil {
+		return nil, "", err
+	}
+	blob, err := parseBlob(value)
+	if err != nil {
+		return nil, "", err
+	}
+	if blob == nil {
+		return []byte(value), "text/plain; charset=utf-8", nil
+	}
+
+	if blob.ObjectId != "" {
+		data, err = s.client.GetObject(ctx, &models.ObjectRequest{BucketId: blob.BucketId, ObjectId: blob.ObjectId})
+		if err != nil {
+			log.Errorf("failed to get spilled kv value: %v", code.Format(err))
+			return nil, "", code.Format(err)
+		}
+	} else if data, err = base64.StdEncoding.DecodeString(blob.Data); err != nil {
+		return nil, "", fmt.Errorf("decode value of key %s: %w", key, err)
+	}
+	if blob.Encoding == "gzip" {
+		if data, err = helper.GzipDecompressData(data); err != nil {
+			return nil, "", fmt.Errorf("decompress value of key %s: %w", key, err)
+		}
+	}
+	return data, blob.ContentType, nil
+}
+
+// DelBytes deletes a value stored with SetBytes, and the object holding it
+// if it was spilled.
+// Parameters:
+//
+//	ctx: Request context
+//	namespaceId: Identifier of the namespace
+//	key: The key to delete
+func (s *KV) DelBytes(ctx context.Context, namespaceId string, key string) (bool, error) {
+	blob, _ := s.getBlob(ctx, namespaceId, key)
+	ok, err := s.DelValue(ctx, namespaceId, key)
+	if err != nil {
+		return false, err
+	}
+	if blob != nil && blob.ObjectId != "" {
+		s.deleteSpilled(ctx, blob)
+	}
+	return ok, nil
+}
+
+func (s *KV) getBlob(ctx context.Context, namespaceId string, key string) (*kvBlob, error) {
+	value, err := s.client.GetValue(ctx, namespaceId, key)
+	if err != nil {
+		return nil, err
+	}
+	return parseBlob(value)
+}
+
+func (s *KV) deleteSpilled(ctx context.Context, blob *kvBlob) {
+	_, err := s.client.DeleteObject(ctx, &models.ObjectRequest{BucketId: blob.BucketId, ObjectId: blob.ObjectId})
+	if err != nil {
+		log.Warnf("failed to delete spilled kv value %s: %v", blob.ObjectId, code.Format(err))
+	}
+}
+
+// parseBlob decodes a value written by SetBytes, or returns nil if value
+// wasn't.
+func parseBlob(value string) (*kvBlob, error) {
+	raw, ok := strings.CutPrefix(value, blobPrefix)
+	if !ok {
+		return nil, nil
+	}
+	var blob kvBlob
+	if err := json.Unmarshal([]byte(raw), &blob); err != nil {
+		return nil, fmt.Errorf("decode binary value: %w", err)
+	}
+	return &blob, nil
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func TestKVBytes(t *testing.T) {
+	s := newLocalStorage(t)
+	ctx := context.Background()
+	ns := "default"
+	s.KV.cfg.Actor.BucketId = "default"
+
+	binary := make([]byte, 4096)
+	for i := range binary {
+		binary[i] = byte(rand.N(256))
+	}
+	text := []byte(strings.Repeat("<p>hello</p>", 1000))
+	for _, tc := range []struct {
+		name     string
+		data     []byte
+		opts     *BytesOptions
+		encoding string
+		spilled  bool
+	}{
+		{name: "small", data: []byte{0, 1, 0xff, 0xfe}},
+		{name: "compressible", data: text, opts: &BytesOptions{ContentType: "text/html"}, encoding: "gzip"},
+		{name: "incompressible", data: binary},
+		{name: "no compression", data: text, opts: &BytesOptions{NoCompress: true}},
+		{name: "spilled", data: binary, opts: &BytesOptions{ContentType: "image/png", SpillThreshold: 1024}, spilled: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := s.KV.SetBytes(ctx, ns, "blob", tc.data, 0, tc.opts); err != nil {
+				t.Fatal(err)
+			}
+			blob, err := s.KV.getBlob(ctx, ns, "blob")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if blob.Encoding != tc.encoding || (blob.ObjectId != "") != tc.spilled {
+				t.Errorf("stored as %+v", blob)
+			}
+
+			data, contentType, err := s.KV.GetBytes(ctx, ns, "blob")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, tc.data) {
+				t.Errorf("got %d bytes, want %d", len(data), len(tc.data))
+			}
+			want := "application/octet-stream"
+			if tc.opts != nil && tc.opts.ContentType != "" {
+				want = tc.opts.ContentType
+			}
+			if contentType != want {
+				t.Errorf("content type = %q, want %q", contentType, want)
+			}
+		})
+	}
+
+	// Replacing or deleting a spilled value deletes its object.
+	spilled, _ := s.KV.getBlob(ctx, ns, "blob")
+	if _, err := s.KV.SetBytes(ctx, ns, "blob", binary, 0, &BytesOptions{SpillThreshold: 1024}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Object.GetObject(ctx, spilled.BucketId, spilled.ObjectId); err == nil {
+		t.Error("replaced object not deleted")
+	}
+	spilled, _ = s.KV.getBlob(ctx, ns, "blob")
+	if _, err := s.KV.DelBytes(ctx, ns, "blob"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Object.GetObject(ctx, spilled.BucketId, spilled.ObjectId); err == nil {
+		t.Error("deleted object still exists")
+	}
+
+	_, _ = s.KV.SetValue(ctx, ns, "text", "plain", 0)
+	if data, contentType, err := s.KV.GetBytes(ctx, ns, "text"); err != nil || string(data) != "plain" || !strings.HasPrefix(contentType, "text/plain") {
+		t.Errorf("GetBytes of a string = %q, %q, %v", data, contentType, err)
+	}
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+func TestScanKeys(t *testing.T) {
+	s := newLocalStorage(t)
+	ctx := context.Background()
+	ns := "default"
+	for _, key := range []string{"session/a/1", "session/a/2", "session/b/1", "session/b/2", "session/c/1", "cookie/a"} {
+		if _, err := s.KV.SetValue(ctx, ns, key, "v", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scanAll := func(prefix string, limit int64, during func()) []string {
+		var keys []string
+		cursor := ""
+		for {
+			scan, err := s.KV.ScanKeys(ctx, ns, prefix, cursor, limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, item := range scan.Items {
+				keys = append(keys, item["key"].(string))
+			}
+			if during != nil {
+				during()
+				during = nil
+			}
+			if cursor = scan.Cursor; cursor == "" {
+				return keys
+			}
+		}
+	}
+
+	want := []string{"session/a/1", "session/a/2", "session/b/1", "session/b/2", "session/c/1"}
+	if keys := scanAll("session/", 2, nil); !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+	if keys := scanAll("session/b/", 0, nil); !reflect.DeepEqual(keys, want[2:4]) {
+		t.Errorf("keys = %v, want %v", keys, want[2:4])
+	}
+
+	// Keys added behind the cursor are skipped, those ahead of it returned.
+	keys := scanAll("session/", 2, func() {
+		_, _ = s.KV.SetValue(ctx, ns, "session/a/0", "v", 0)
+		_, _ = s.KV.SetValue(ctx, ns, "session/b/3", "v", 0)
+	})
+	want = []string{"session/a/1", "session/a/2", "session/b/1", "session/b/2", "session/b/3", "session/c/1"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+
+	if _, err := s.KV.ScanKeys(ctx, ns, "", "not a cursor!", 10); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("ScanKeys with an invalid cursor = %v, want ErrInvalidArgument", err)
+	}
+
+	// Backends without scans evaluate them page by page, with the same result.
+	req := &models.ScanKeysRequest{NamespaceId: ns, Prefix: "session/", After: "session/a/2", Limit: 3}
+	local, err := s.KV.client.ScanKeys(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fallback, err := s.KV.scanPages(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(local, fallback) {
+		t.Errorf("client-side scan = %+v, want %+v", fallback, local)
+	}
+}
@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+)
+
+// ErrLockNotHeld is returned when releasing or refreshing a lock that isn't
+// held, or was lost because it expired before being renewed.
+var ErrLockNotHeld = errors.New("lock not held")
+
+// Lock is a mutual exclusion lock shared by the actor runs using the same KV
+// namespace. The lock is a key holding the id of its owner; it expires after
+// its ttl unless renewed, so a run that dies can't hold it forever. While
+// held, a goroutine renews it every third of its ttl. The Scrapeless API has
+// no atomic KV operations, so online a Lock requires the Redis storage.
+//
+// Every acquisition gets a fencing token, greater than the tokens of all the
+// previous ones. Passing it along with writes lets their receiver reject the
+// writes of a holder that lost the lock without noticing.
+//
+//	lock := storage.NewLock(client.Storage.KV, namespaceId, "refresh-cookie", time.Minute)
+//	if ok, err := lock.TryLock(ctx); err == nil && ok {
+//		defer lock.Unlock(ctx)
+//		// ...
+//	}
+type Lock struct {
+	kv          *KV
+	namespaceId string
+	key         string
+	ttl         time.Duration
+	owner       string
+
+	mu    sync.Mutex
+	value string
+	token int64
+	stop  chan struct{}
+	lost  chan struct{}
+}
+
+// NewLock creates a lock named key in the namespace. It isn't held until
+// Lock or TryLock succeed. A Lock must not be copied, and is held by one
+// goroutine at a time.
+func NewLock(kv *KV, namespaceId string, key string, ttl time.Duration) *Lock {
+	return &Lock{
+		kv:          kv,
+		namespaceId: namespaceId,
+		key:         key,
+		ttl:         max(ttl, time.Second),
+		owner:       uuid.NewString(),
+	}
+}
+
+// TryLock acquires the lock if it is free, and reports whether it did.
+func (l *Lock) TryLock(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.value != "" {
+		return false, fmt.Errorf("lock %s already held by this Lock", l.key)
+	}
+	token, err := l.kv.Increment(ctx, l.namespaceId, l.key+".fence", 1)
+	if err != nil {
+		return false, err
+	}
+	value := strconv.FormatInt(token, 10) + ":" + l.owner
+	ok, err := l.kv.SetIfNotExists(ctx, l.namespaceId, l.key, value, l.ttlSeconds())
+	if err != nil || !ok {
+		return false, err
+	}
+	l.value, l.token = value, token
+	l.stop, l.lost = make(chan struct{}), make(chan struct{})
+	go l.renew(l.value, l.stop, l.lost)
+	return true, nil
+}
+
+// Lock acquires the lock, waiting until it is free or ctx is done.
+func (l *Lock) Lock(ctx context.Context) error {
+	delay := 50 * time.Millisecond
+	maxDelay := min(l.ttl/4, time.Second)
+	for {
+		ok, err := l.TryLock(ctx)
+		if err != nil || ok {
+			return err
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay = min(delay*2, maxDelay)
+	}
+}
+
+// Unlock releases the lock. It returns ErrLockNotHeld if the lock was lost.
+func (l *Lock) Unlock(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	value := l.value
+	if value == "" {
+		return ErrLockNotHeld
+	}
+	close(l.stop)
+	l.value = ""
+	// Swapping the value makes sure the lock is still ours, and keeps the
+	// key, so no one else can take the lock, until it is deleted.
+	ok, err := l.kv.CompareAndSwap(ctx, l.namespaceId, l.key, value, "", l.ttlSeconds())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLockNotHeld
+	}
+	_, err = l.kv.DelValue(ctx, l.namespaceId, l.key)
+	return err
+}
+
+// Refresh extends the lock by its ttl. Held locks are refreshed in the
+// background; Refresh is only needed to check the lock is still held.
+func (l *Lock) Refresh(ctx context.Context) error {
+	l.mu.Lock()
+	value := l.value
+	l.mu.Unlock()
+	if value == "" {
+		return ErrLockNotHeld
+	}
+	return l.refresh(ctx, value)
+}
+
+// Token returns the fencing token of the current acquisition, or 0 if the
+// lock isn't held.
+func (l *Lock) Token() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.value == "" {
+		return 0
+	}
+	return l.token
+}
+
+// Lost returns a channel closed when the lock is found lost while held, which
+// happens if it can't be renewed before it expires. Unlock must still be
+// called before acquiring it again. It is nil while the lock isn't held.
+func (l *Lock) Lost() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.value == "" {
+		return nil
+	}
+	return l.lost
+}
+
+func (l *Lock) refresh(ctx context.Context, value string) error {
+	ok, err := l.kv.CompareAndSwap(ctx, l.namespaceId, l.key, value, value, l.ttlSeconds())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// renew refreshes the lock until stop is closed, and closes lost if it finds
+// the lock gone.
+func (l *Lock) renew(value string, stop, lost chan struct{}) {
+	interval := l.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		err := l.refresh(ctx, value)
+		cancel()
+		if errors.Is(err, ErrLockNotHeld) {
+			log.Warnf("lock %s of namespace %s lost", l.key, l.namespaceId)
+			close(lost)
+			return
+		}
+		if err != nil {
+			log.Warnf("renew lock %s of namespace %s failed: %v", l.key, l.namespaceId, err)
+		}
+	}
+}
+
+// ttlSeconds is the ttl rounded up to the second, the unit of KV expirations.
+func (l *Lock) ttlSeconds() uint {
+	return uint(math.Ceil(l.ttl.Seconds()))
+}
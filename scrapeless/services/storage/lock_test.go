@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	s := newLocalStorage(t)
+	ctx := context.Background()
+	namespaceId, _, err := s.KV.CreateNamespace(ctx, "locks")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewLock(s.KV, namespaceId, "cookie", time.Minute)
+	b := NewLock(s.KV, namespaceId, "cookie", time.Minute)
+	if ok, err := a.TryLock(ctx); !ok || err != nil {
+		t.Fatalf("a.TryLock = %v, %v", ok, err)
+	}
+	if ok, err := b.TryLock(ctx); ok || err != nil {
+		t.Fatalf("b.TryLock of a held lock = %v, %v", ok, err)
+	}
+	if err = a.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Refresh(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("b.Refresh = %v, want ErrLockNotHeld", err)
+	}
+	tokenA := a.Token()
+
+	acquired := make(chan error)
+	go func() { acquired <- b.Lock(ctx) }()
+	select {
+	case err = <-acquired:
+		t.Fatalf("b.Lock returned %v while a holds the lock", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	if err = a.Unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-acquired; err != nil {
+		t.Fatal(err)
+	}
+	if b.Token() <= tokenA {
+		t.Errorf("token of b = %d, want more than %d", b.Token(), tokenA)
+	}
+	if err = a.Unlock(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("second a.Unlock = %v, want ErrLockNotHeld", err)
+	}
+	if err = b.Unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, _ = a.TryLock(ctx)
+	if err = b.Lock(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("b.Lock = %v, want DeadlineExceeded", err)
+	}
+	_ = a.Unlock(ctx)
+}
+
+func TestLockRenewal(t *testing.T) {
+	s := newLocalStorage(t)
+	ctx := context.Background()
+	namespaceId, _, err := s.KV.CreateNamespace(ctx, "locks")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewLock(s.KV, namespaceId, "job", time.Second)
+	if ok, err := l.TryLock(ctx); !ok || err != nil {
+		t.Fatalf("TryLock = %v, %v", ok, err)
+	}
+	// The lock outlives its ttl while renewed.
+	time.Sleep(1500 * time.Millisecond)
+	other := NewLock(s.KV, namespaceId, "job", time.Second)
+	if ok, _ := other.TryLock(ctx); ok {
+		t.Fatal("acquired a renewed lock")
+	}
+
+	// Taking the key away is noticed by the next renewal.
+	if _, err = s.KV.DelValue(ctx, namespaceId, "job"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-l.Lost():
+	case <-time.After(2 * time.Second):
+		t.Fatal("lost lock not reported")
+	}
+	if err = l.Unlock(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("Unlock of a lost lock = %v, want ErrLockNotHeld", err)
+	}
+}
@@ -14,6 +14,40 @@ type ListDatasetsResponse struct {
 	Total int64         `json:"total,omitempty"`
 }
 
+// QueryOp is the operator of a query condition.
+type QueryOp string
+
+const (
+	QueryEq       QueryOp = "eq"
+	QueryNe       QueryOp = "ne"
+	QueryGt       QueryOp = "gt"
+	QueryGte      QueryOp = "gte"
+	QueryLt       QueryOp = "lt"
+	QueryLte      QueryOp = "lte"
+	QueryContains QueryOp = "contains" // substring of a string or element of an array
+)
+
+// DatasetQuery selects dataset items. Fields are addressed with dotted paths
+// such as "price.amount".
+type DatasetQuery struct {
+	Where  []QueryCondition // Conditions all matching items satisfy
+	Sort   []QuerySort      // Sort keys, in order; dataset order by default
+	Fields []string         // Fields to return, all by default
+	Offset int              // Number of matching items to skip
+	Limit  int              // Maximum number of items to return, 0 for all
+}
+
+type QueryCondition struct {
+	Field string
+	Op    QueryOp
+	Value any
+}
+
+type QuerySort struct {
+	Field string
+	Desc  bool
+}
+
 type DatasetInfo struct {
 	Id        string   `json:"id,omitempty"`
 	Name      string   `json:"name,omitempty"`
@@ -87,6 +121,31 @@ type BulkItem struct {
 	Expiration uint   `json:"expiration"`
 }
 
+// CompactStats reports what Storage.Compact removed.
+type CompactStats struct {
+	ExpiredKeys     int `json:"expiredKeys"`
+	ExpiredMessages int `json:"expiredMessages"`
+	// OrphanedFiles counts the metadata and lock files left behind by
+	// deleted data.
+	OrphanedFiles  int   `json:"orphanedFiles"`
+	ReclaimedBytes int64 `json:"reclaimedBytes"`
+}
+
+// KeyScan is a page of keys returned by KV.ScanKeys.
+type KeyScan struct {
+	Items []map[string]any `json:"items,omitempty"`
+	// Cursor resumes the scan after the last key of Items. It is empty once
+	// the scan is complete.
+	Cursor string `json:"cursor,omitempty"`
+}
+
+type KvValue struct {
+	Key      string    `json:"key"`
+	Value    string    `json:"value"`
+	Size     int       `json:"size"`
+	ExpireAt time.Time `json:"expireAt"`
+}
+
 type NamespacesResponse struct {
 	Items []KvNamespaceItem `json:"items,omitempty"`
 	Total int64             `json:"total,omitempty"`
@@ -145,11 +204,18 @@ type Item struct {
 	Description string `json:"description,omitempty"`
 	CreatedAt   string `json:"createdAt,omitempty"`
 	UpdatedAt   string `json:"updatedAt,omitempty"`
+	// DeadLetterQueueId is the queue that receives the messages failing for good.
+	DeadLetterQueueId string `json:"deadLetterQueueId,omitempty"`
 }
 
 type CreateQueueReq struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	// DeadLetterQueueId optionally names an existing queue where messages that
+	// exhaust their retries or pass their deadline are moved, instead of being dropped.
+	// Online, it requires the Redis storage: the queue API has no dead-letter
+	// queues, and creating a queue with one fails with errors.ErrUnsupported.
+	DeadLetterQueueId string `json:"deadLetterQueueId,omitempty"`
 }
 
 type PushQueue struct {
@@ -172,10 +238,22 @@ type Msg struct {
 	SuccessAt int64  `json:"successAt"`
 	FailedAt  int64  `json:"failedAt"`
 	Desc      string `json:"desc"`
+	// FailedReason and SourceQueueId are set on the messages of a dead-letter queue.
+	FailedReason  string `json:"failedReason,omitempty"`
+	SourceQueueId string `json:"sourceQueueId,omitempty"`
 }
 
 type GetMsgResponse []*Msg
 
+type ListMsgsResponse struct {
+	// Items are the messages not acked yet, in the order they are due.
+	Items     []*Msg `json:"items,omitempty"`
+	Total     int64  `json:"total"`
+	TotalPage int64  `json:"totalPage"`
+	Page      int64  `json:"page"`
+	PageSize  int64  `json:"pageSize"`
+}
+
 // Vector
 
 type Stats struct {
@@ -223,6 +301,7 @@ type CreateCollectionRequest struct {
 	ActorId     string `json:"actorId"`
 	Description string `json:"description"`
 	Dimension   int    `json:"dimension"`
+	Metric      string `json:"metric,omitempty"`
 	Name        string `json:"name"`
 	RunId       string `json:"runId"`
 }
@@ -249,3 +328,62 @@ type QueryVectorParam struct {
 	IncludeVector  bool               `json:"includeVector"`  // Whether to return the vector
 	IncludeContent bool               `json:"includeContent"` // Whether to return the content
 }
+
+// ConflictPolicy decides what Sync does with a resource whose name is
+// already used in the destination.
+type ConflictPolicy string
+
+const (
+	// ConflictSkip leaves the destination resource as it is.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictMerge adds the items, messages and objects of the source to
+	// the destination resource, and overwrites its keys.
+	ConflictMerge ConflictPolicy = "merge"
+	// ConflictOverwrite deletes the destination resource and copies the
+	// source in its place. The default resources of the local storage are
+	// merged instead.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+)
+
+// The categories of resources Sync copies.
+const (
+	SyncDatasets = "datasets"
+	SyncKV       = "kv"
+	SyncQueues   = "queues"
+	SyncBuckets  = "buckets"
+)
+
+type SyncOptions struct {
+	// Categories limits the sync to these categories, all of them when empty.
+	Categories []string `json:"categories,omitempty"`
+	// Names limits the sync to the resources with these names, all of them
+	// when empty.
+	Names []string `json:"names,omitempty"`
+	// Conflict applies to the resources whose name is used in the
+	// destination. ConflictSkip when empty.
+	Conflict ConflictPolicy `json:"conflict,omitempty"`
+	// DryRun reports what would be copied without writing to the destination.
+	DryRun bool `json:"dryRun,omitempty"`
+	// PageSize is how many entries are read per request, 100 when 0.
+	PageSize int64 `json:"pageSize,omitempty"`
+}
+
+// SyncResult reports the sync of one resource.
+type SyncResult struct {
+	Category string `json:"category"`
+	Name     string `json:"name"`
+	SourceId string `json:"sourceId"`
+	// TargetId is empty for the resources a dry run would create.
+	TargetId string `json:"targetId"`
+	// Action is create, merge, overwrite or skip.
+	Action string `json:"action"`
+	// Copied counts the dataset items, keys, messages or objects copied.
+	Copied int `json:"copied"`
+	// Dropped counts the keys expired and the messages too close to their
+	// deadline to be created again.
+	Dropped int `json:"dropped"`
+	// Skipped tells what of the resource wasn't read at all, and why, like
+	// the messages of a cloud queue, which can't be listed without leasing
+	// them.
+	Skipped string `json:"skipped,omitempty"`
+}
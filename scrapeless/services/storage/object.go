@@ -3,16 +3,19 @@ package storage
 import (
 	"context"
 	"errors"
-	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/code"
-	"github.com/scrapeless-ai/sdk-go/internal/remote/storage"
+	"github.com/scrapeless-ai/sdk-go/internal/helper"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"io"
+	"iter"
 	"path/filepath"
 	"strings"
 )
 
-type Object struct{}
+type Object struct {
+	backend
+}
 
 // ListBuckets retrieves the list of buckets with pagination support.
 // Parameters:
@@ -27,7 +30,7 @@ func (s *Object) ListBuckets(ctx context.Context, page int, pageSize int) (*List
 	if pageSize < 10 {
 		pageSize = 10
 	}
-	buckets, err := storage.ClientInterface.ListBuckets(ctx, page, pageSize)
+	buckets, err := s.client.ListBuckets(ctx, page, pageSize)
 	if err != nil {
 		log.Errorf("failed to list buckets: %v", code.Format(err))
 		return nil, code.Format(err)
@@ -60,12 +63,12 @@ func (s *Object) ListBuckets(ctx context.Context, page int, pageSize int) (*List
 //	name: Bucket name, must comply with storage service naming rules.
 //	description: Optional description for the bucket.
 func (s *Object) CreateBucket(ctx context.Context, name string, description string) (bucketId string, bucketName string, err error) {
-	name = name + "-" + env.GetActorEnv().RunId
-	bucketId, err = storage.ClientInterface.CreateBucket(ctx, &models.CreateBucketRequest{
+	name = name + "-" + s.cfg.Actor.RunId
+	bucketId, err = s.client.CreateBucket(ctx, &models.CreateBucketRequest{
 		Name:        name,
 		Description: description,
-		ActorId:     env.GetActorEnv().ActorId,
-		RunId:       env.GetActorEnv().RunId,
+		ActorId:     s.cfg.Actor.ActorId,
+		RunId:       s.cfg.Actor.RunId,
 	})
 	if err != nil {
 		log.Errorf("failed to create bucket: %v", code.Format(err))
@@ -79,7 +82,7 @@ func (s *Object) CreateBucket(ctx context.Context, name string, description stri
 //
 //	ctx: The context for the request.
 func (s *Object) DeleteBucket(ctx context.Context, bucketId string) (bool, error) {
-	ok, err := storage.ClientInterface.DeleteBucket(ctx, bucketId)
+	ok, err := s.client.DeleteBucket(ctx, bucketId)
 	if err != nil {
 		log.Errorf("failed to delete bucket: %v", code.Format(err))
 		return false, code.Format(err)
@@ -92,7 +95,7 @@ func (s *Object) DeleteBucket(ctx context.Context, bucketId string) (bool, error
 //
 //	ctx: The context for the request.
 func (s *Object) GetBucket(ctx context.Context, bucketId string) (*Bucket, error) {
-	bucket, err := storage.ClientInterface.GetBucket(ctx, bucketId)
+	bucket, err := s.client.GetBucket(ctx, bucketId)
 	if err != nil {
 		log.Errorf("failed to get bucket: %v", code.Format(err))
 		return nil, code.Format(err)
@@ -124,7 +127,7 @@ func (s *Object) ListObjects(ctx context.Context, bucketId string, fuzzyFileName
 	if pageSize < 10 {
 		pageSize = 10
 	}
-	objects, err := storage.ClientInterface.ListObjects(ctx, &models.ListObjectsRequest{
+	objects, err := s.client.ListObjects(ctx, &models.ListObjectsRequest{
 		BucketId: bucketId,
 		Search:   fuzzyFileName,
 		Page:     page,
@@ -156,6 +159,28 @@ func (s *Object) ListObjects(ctx context.Context, bucketId string, fuzzyFileName
 	}, nil
 }
 
+// IterObjects returns an iterator over all the objects of the bucket matching
+// fuzzyFileName. Pages are fetched lazily, the next one while the current one
+// is consumed. The iteration stops at the first error.
+//
+// Parameters:
+//
+//	ctx: The context for the requests.
+//	fuzzyFileName: Search pattern for matching object filenames.
+//	pageSize: Number of objects fetched per request, at least 10.
+func (s *Object) IterObjects(ctx context.Context, bucketId string, fuzzyFileName string, pageSize int64) iter.Seq2[ObjectInfo, error] {
+	if pageSize < 10 {
+		pageSize = 10
+	}
+	return helper.Paginate(ctx, func(ctx context.Context, page int64) ([]ObjectInfo, bool, error) {
+		objects, err := s.ListObjects(ctx, bucketId, fuzzyFileName, page, pageSize)
+		if err != nil {
+			return nil, false, err
+		}
+		return objects.Objects, helper.HasMore(page, pageSize, len(objects.Objects), objects.Total), nil
+	})
+}
+
 // GetObject retrieves an object by its ID using HTTP.
 //
 // Parameters:
@@ -163,7 +188,7 @@ func (s *Object) ListObjects(ctx context.Context, bucketId string, fuzzyFileName
 //	ctx: The context for the request.
 //	objectId: The unique identifier of the object to retrieve.
 func (s *Object) GetObject(ctx context.Context, bucketId string, objectId string) ([]byte, error) {
-	object, err := storage.ClientInterface.GetObject(ctx, &models.ObjectRequest{
+	object, err := s.client.GetObject(ctx, &models.ObjectRequest{
 		BucketId: bucketId,
 		ObjectId: objectId,
 	})
@@ -186,12 +211,12 @@ func (s *Object) PutObject(ctx context.Context, bucketId string, filename string
 	if !ok {
 		return "", errors.New("object type not supported")
 	}
-	object, err := storage.ClientInterface.PutObject(ctx, &models.PutObjectRequest{
+	object, err := s.client.PutObject(ctx, &models.PutObjectRequest{
 		BucketId: bucketId,
 		Filename: filename,
 		Data:     data,
-		ActorId:  env.GetActorEnv().ActorId,
-		RunId:    env.GetActorEnv().RunId,
+		ActorId:  s.cfg.Actor.ActorId,
+		RunId:    s.cfg.Actor.RunId,
 	})
 	if err != nil {
 		log.Errorf("failed to put object: %v", code.Format(err))
@@ -200,13 +225,64 @@ func (s *Object) PutObject(ctx context.Context, bucketId string, filename string
 	return object, nil
 }
 
+// PutObjectStream uploads the content of r to the object storage without
+// loading it in memory.
+//
+// Parameters:
+//
+//	ctx: The context for the request.
+//	filename: The name of the file to store.
+//	r: The content to upload. Uploads can only be retried when r is an io.Seeker.
+//	size: The number of bytes r yields, or -1 if unknown.
+func (s *Object) PutObjectStream(ctx context.Context, bucketId string, filename string, r io.Reader, size int64) (string, error) {
+	_, ok := getObjectType(filename)
+	if !ok {
+		return "", errors.New("object type not supported")
+	}
+	object, err := s.client.PutObjectStream(ctx, &models.PutObjectStreamRequest{
+		BucketId: bucketId,
+		Filename: filename,
+		Reader:   r,
+		Size:     size,
+		ActorId:  s.cfg.Actor.ActorId,
+		RunId:    s.cfg.Actor.RunId,
+	})
+	if err != nil {
+		log.Errorf("failed to put object stream: %v", code.Format(err))
+		return "", code.Format(err)
+	}
+	return object, nil
+}
+
+// GetObjectStream retrieves an object as a stream, which the caller must close.
+// A broken download is resumed from where it stopped, unless the object was
+// replaced meanwhile: reading then fails.
+//
+// Parameters:
+//
+//	ctx: The context for the request.
+//	objectId: The unique identifier of the object to retrieve.
+//	offset: The number of leading bytes to skip, e.g. to resume an earlier download.
+func (s *Object) GetObjectStream(ctx context.Context, bucketId string, objectId string, offset int64) (io.ReadCloser, error) {
+	object, err := s.client.GetObjectStream(ctx, &models.GetObjectStreamRequest{
+		BucketId: bucketId,
+		ObjectId: objectId,
+		Offset:   offset,
+	})
+	if err != nil {
+		log.Errorf("failed to get object stream: %v", code.Format(err))
+		return nil, code.Format(err)
+	}
+	return object, nil
+}
+
 // DeleteObject deletes an object from the specified bucket.
 // Parameters:
 //
 //	ctx: The context used for the HTTP request.
 //	objectId: The identifier of the object to delete.
 func (s *Object) DeleteObject(ctx context.Context, bucketId string, objectId string) (bool, error) {
-	resp, err := storage.ClientInterface.DeleteObject(ctx, &models.ObjectRequest{
+	resp, err := s.client.DeleteObject(ctx, &models.ObjectRequest{
 		BucketId: bucketId,
 		ObjectId: objectId,
 	})
@@ -226,6 +302,10 @@ var (
 		"json": {},
 		"html": {},
 		"png":  {},
+		"jpg":  {},
+		"jpeg": {},
+		"pdf":  {},
+		"har":  {},
 	}
 )
 
@@ -2,15 +2,17 @@ package storage
 
 import (
 	"context"
-	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/code"
-	"github.com/scrapeless-ai/sdk-go/internal/remote/storage"
+	"github.com/scrapeless-ai/sdk-go/internal/helper"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"iter"
 	"time"
 )
 
-type Queue struct{}
+type Queue struct {
+	backend
+}
 
 // ListQueues retrieves a list of queues with pagination and sorting options.
 // Parameters:
@@ -26,7 +28,7 @@ func (s *Queue) ListQueues(ctx context.Context, page int64, pageSize int64, desc
 	if pageSize < 10 {
 		pageSize = 10
 	}
-	queues, err := storage.ClientInterface.GetQueues(ctx, &models.GetQueuesRequest{
+	queues, err := s.client.GetQueues(ctx, &models.GetQueuesRequest{
 		Page:     page,
 		PageSize: pageSize,
 		Desc:     desc,
@@ -46,6 +48,8 @@ func (s *Queue) ListQueues(ctx context.Context, page int64, pageSize int64, desc
 			Description: item.Description,
 			CreatedAt:   item.CreatedAt,
 			UpdatedAt:   item.UpdatedAt,
+
+			DeadLetterQueueId: item.DeadLetterQueueId,
 		})
 	}
 	return &ListQueuesResponse{
@@ -57,18 +61,42 @@ func (s *Queue) ListQueues(ctx context.Context, page int64, pageSize int64, desc
 	}, nil
 }
 
+// IterQueues returns an iterator over all the queues. Pages are fetched
+// lazily, the next one while the current one is consumed. The iteration stops
+// at the first error.
+//
+// Parameters:
+//
+//	ctx: The context for the requests.
+//	pageSize: int64 - Number of queues fetched per request (minimum 10).
+//	desc: bool - Whether to iterate in descending order.
+func (s *Queue) IterQueues(ctx context.Context, pageSize int64, desc bool) iter.Seq2[Item, error] {
+	if pageSize < 10 {
+		pageSize = 10
+	}
+	return helper.Paginate(ctx, func(ctx context.Context, page int64) ([]Item, bool, error) {
+		queues, err := s.ListQueues(ctx, page, pageSize, desc)
+		if err != nil {
+			return nil, false, err
+		}
+		return queues.Items, helper.HasMore(page, pageSize, len(queues.Items), queues.Total), nil
+	})
+}
+
 // CreateQueue creates a new HTTP queue with the provided request parameters.
 // Parameters:
 //
 //	ctx: The context for the request.
 //	req: The request object containing queue configuration details.
 func (s *Queue) CreateQueue(ctx context.Context, req *CreateQueueReq) (queueId string, queueName string, err error) {
-	name := req.Name + "-" + env.GetActorEnv().RunId
-	queue, err := storage.ClientInterface.CreateQueue(ctx, &models.CreateQueueRequest{
-		ActorId:     env.GetActorEnv().ActorId,
-		RunId:       env.GetActorEnv().RunId,
+	name := req.Name + "-" + s.cfg.Actor.RunId
+	queue, err := s.client.CreateQueue(ctx, &models.CreateQueueRequest{
+		ActorId:     s.cfg.Actor.ActorId,
+		RunId:       s.cfg.Actor.RunId,
 		Name:        name,
 		Description: req.Description,
+
+		DeadLetterQueueId: req.DeadLetterQueueId,
 	})
 	if err != nil {
 		log.Errorf("failed to create queue: %v", code.Format(err))
@@ -84,8 +112,8 @@ func (s *Queue) CreateQueue(ctx context.Context, req *CreateQueueReq) (queueId s
 //	ctx: The context for the request.
 //	name: The name of the queue to retrieve.
 func (s *Queue) GetQueue(ctx context.Context, queueId string, name string) (*Item, error) {
-	name = name + "-" + env.GetActorEnv().RunId
-	queue, err := storage.ClientInterface.GetQueue(ctx, &models.GetQueueRequest{
+	name = name + "-" + s.cfg.Actor.RunId
+	queue, err := s.client.GetQueue(ctx, &models.GetQueueRequest{
 		Id:   queueId,
 		Name: name,
 	})
@@ -102,6 +130,8 @@ func (s *Queue) GetQueue(ctx context.Context, queueId string, name string) (*Ite
 		Description: queue.Description,
 		CreatedAt:   queue.CreatedAt,
 		UpdatedAt:   queue.UpdatedAt,
+
+		DeadLetterQueueId: queue.DeadLetterQueueId,
 	}, nil
 }
 
@@ -112,8 +142,8 @@ func (s *Queue) GetQueue(ctx context.Context, queueId string, name string) (*Ite
 //	name: The new name of the queue.
 //	description: The new description of the queue.
 func (s *Queue) UpdateQueue(ctx context.Context, queueId string, name string, description string) error {
-	name = name + "-" + env.GetActorEnv().RunId
-	err := storage.ClientInterface.UpdateQueue(ctx, &models.UpdateQueueRequest{
+	name = name + "-" + s.cfg.Actor.RunId
+	err := s.client.UpdateQueue(ctx, &models.UpdateQueueRequest{
 		QueueId:     queueId,
 		Name:        name,
 		Description: description,
@@ -126,7 +156,7 @@ func (s *Queue) UpdateQueue(ctx context.Context, queueId string, name string, de
 //
 //	ctx: The context for the request.
 func (s *Queue) DeleteQueue(ctx context.Context, queueId string) error {
-	err := storage.ClientInterface.DelQueue(ctx, &models.DelQueueRequest{QueueId: queueId})
+	err := s.client.DelQueue(ctx, &models.DelQueueRequest{QueueId: queueId})
 	if err != nil {
 		log.Errorf("failed to delete queue: %v", code.Format(err))
 		return code.Format(err)
@@ -158,7 +188,7 @@ func (s *Queue) Push(ctx context.Context, queueId string, req PushQueue) (string
 	}
 
 	unix := time.Now().UTC().Add(time.Duration(req.Deadline) * time.Second).Unix()
-	queue, err := storage.ClientInterface.CreateMsg(ctx, &models.CreateMsgRequest{
+	queue, err := s.client.CreateMsg(ctx, &models.CreateMsgRequest{
 		QueueId:  queueId,
 		Name:     req.Name,
 		PayLoad:  string(req.Payload),
@@ -185,7 +215,7 @@ func (s *Queue) Pull(ctx context.Context, queueId string, size int32) (GetMsgRes
 	if size > 100 {
 		size = 100
 	}
-	msgs, err := storage.ClientInterface.GetMsg(ctx, &models.GetMsgRequest{
+	msgs, err := s.client.GetMsg(ctx, &models.GetMsgRequest{
 		QueueId: queueId,
 		Limit:   size,
 	})
@@ -198,23 +228,67 @@ func (s *Queue) Pull(ctx context.Context, queueId string, size int32) (GetMsgRes
 	}
 	var items []*Msg
 	for _, msg := range *msgs {
-		items = append(items, &Msg{
-			ID:        msg.ID,
-			QueueID:   msg.QueueID,
-			Name:      msg.Name,
-			Payload:   msg.Payload,
-			Timeout:   msg.Timeout,
-			Deadline:  msg.Deadline,
-			Retry:     msg.Retry,
-			Retried:   msg.Retried,
-			SuccessAt: msg.SuccessAt,
-			FailedAt:  msg.FailedAt,
-			Desc:      msg.Desc,
-		})
+		items = append(items, toMsg(msg))
 	}
 	return items, nil
 }
 
+// ListMsgs lists the messages of the queue not acked yet, in the order they
+// are due, without pulling them. The cloud storage doesn't support it.
+// Parameters:
+//
+//	ctx: The context for the request.
+//	queueId: The queue to list.
+//	page: int64 - The page number (minimum 1, defaults to 1 if invalid).
+//	pageSize: int64 - Number of messages per page (minimum 10, defaults to 10 if invalid).
+func (s *Queue) ListMsgs(ctx context.Context, queueId string, page int64, pageSize int64) (*ListMsgsResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 10 {
+		pageSize = 10
+	}
+	msgs, err := s.client.ListMsgs(ctx, &models.ListMsgsRequest{
+		QueueId:  queueId,
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		log.Errorf("failed to list messages: %v", code.Format(err))
+		return nil, code.Format(err)
+	}
+	items := make([]*Msg, 0, len(msgs.Items))
+	for _, msg := range msgs.Items {
+		items = append(items, toMsg(msg))
+	}
+	return &ListMsgsResponse{
+		Items:     items,
+		Total:     msgs.Total,
+		TotalPage: msgs.TotalPage,
+		Page:      msgs.Page,
+		PageSize:  msgs.PageSize,
+	}, nil
+}
+
+func toMsg(msg *models.Msg) *Msg {
+	return &Msg{
+		ID:        msg.ID,
+		QueueID:   msg.QueueID,
+		Name:      msg.Name,
+		Payload:   msg.Payload,
+		Timeout:   msg.Timeout,
+		Deadline:  msg.Deadline,
+		Retry:     msg.Retry,
+		Retried:   msg.Retried,
+		SuccessAt: msg.SuccessAt,
+		FailedAt:  msg.FailedAt,
+		Desc:      msg.Desc,
+
+		FailedReason:  msg.FailedReason,
+		SourceQueueId: msg.SourceQueueId,
+	}
+}
+
 // Ack confirms that a message has been processed successfully.
 //
 // Parameters:
@@ -222,7 +296,7 @@ func (s *Queue) Pull(ctx context.Context, queueId string, size int32) (GetMsgRes
 //	ctx: The context used for request cancellation or timeout.
 //	msgId: The unique identifier of the message to acknowledge.
 func (s *Queue) Ack(ctx context.Context, queueId string, msgId string) error {
-	err := storage.ClientInterface.AckMsg(ctx, &models.AckMsgRequest{
+	err := s.client.AckMsg(ctx, &models.AckMsgRequest{
 		QueueId: queueId,
 		MsgId:   msgId,
 	})
@@ -233,6 +307,50 @@ func (s *Queue) Ack(ctx context.Context, queueId string, msgId string) error {
 	return nil
 }
 
+// Nack returns a pulled message to the queue without waiting for its timeout.
+// It can be pulled again after delay, rounded up to the second; a message
+// without retries left goes to the dead-letter queue instead, on the first
+// pull after the delay. The queue API can't nack messages: online, it fails
+// with errors.ErrUnsupported unless the queues are served by Redis.
+//
+// Parameters:
+//
+//	ctx: The context used for request cancellation or timeout.
+//	msgId: The unique identifier of the message to return.
+//	delay: How long to wait before the message is delivered again, 0 for immediately.
+func (s *Queue) Nack(ctx context.Context, queueId string, msgId string, delay time.Duration) error {
+	err := s.client.NackMsg(ctx, &models.NackMsgRequest{
+		QueueId: queueId,
+		MsgId:   msgId,
+		Delay:   int64((delay + time.Second - 1) / time.Second),
+	})
+	if err != nil {
+		log.Errorf("failed to nack msg: %v", code.Format(err))
+		return code.Format(err)
+	}
+	return nil
+}
+
+// Renew extends the lease of a pulled message by its timeout, so it isn't
+// handed out again while it is still being processed. Backends that can't
+// renew leases return an error wrapping errors.ErrUnsupported.
+//
+// Parameters:
+//
+//	ctx: The context used for request cancellation or timeout.
+//	msgId: The unique identifier of the message to renew.
+func (s *Queue) Renew(ctx context.Context, queueId string, msgId string) error {
+	err := s.client.RenewMsg(ctx, &models.RenewMsgRequest{
+		QueueId: queueId,
+		MsgId:   msgId,
+	})
+	if err != nil {
+		log.Errorf("failed to renew msg: %v", code.Format(err))
+		return code.Format(err)
+	}
+	return nil
+}
+
 func (s *Queue) Close() error {
 	return nil
 }
@@ -0,0 +1,289 @@
+// Package queue provides a long-running consumer for Scrapeless queues.
+//
+//	consumer := queue.NewConsumer(client.Storage.Queue, queueId, func(ctx context.Context, msg *storage.Msg) error {
+//		return process(ctx, msg.Payload)
+//	}, queue.WithConcurrency(8))
+//	err := consumer.Run(ctx)
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/services/storage"
+)
+
+// Handler processes a message. A nil error acknowledges the message; any
+// other error returns it to the queue, to be delivered again after the retry
+// delay until its retries are exhausted.
+type Handler func(ctx context.Context, msg *storage.Msg) error
+
+// TypedHandler returns a Handler that decodes message payloads as T with
+// codec, or storage.JSONCodec when codec is nil, before calling h. Messages
+// that can't be decoded fail like any other.
+func TypedHandler[T any](codec storage.Codec, h func(ctx context.Context, msg *storage.Msg, value T) error) Handler {
+	q := storage.NewTypedQueue[T](nil, codec)
+	return func(ctx context.Context, msg *storage.Msg) error {
+		value, err := q.Decode(msg)
+		if err != nil {
+			return err
+		}
+		return h(ctx, msg, value)
+	}
+}
+
+// Source is the queue a Consumer reads from. *storage.Queue implements it.
+type Source interface {
+	Pull(ctx context.Context, queueId string, size int32) (storage.GetMsgResponse, error)
+	Ack(ctx context.Context, queueId string, msgId string) error
+	Nack(ctx context.Context, queueId string, msgId string, delay time.Duration) error
+	Renew(ctx context.Context, queueId string, msgId string) error
+}
+
+// Consumer pulls messages from a queue and dispatches them to a pool of
+// concurrent handlers.
+type Consumer struct {
+	source  Source
+	queueId string
+	handler Handler
+
+	concurrency  int
+	minPoll      time.Duration
+	maxPoll      time.Duration
+	renewEvery   time.Duration
+	retryDelay   time.Duration
+	drainTimeout time.Duration
+
+	// renewUnsupported is set once the source refuses to renew leases.
+	renewUnsupported sync.Once
+	noRenew          chan struct{}
+}
+
+// Option configures a Consumer.
+type Option func(*Consumer)
+
+// WithConcurrency sets the number of messages handled at the same time. Defaults to 1.
+func WithConcurrency(n int) Option {
+	return func(c *Consumer) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+// WithPollInterval sets the delay between polls of an empty queue: it starts
+// at minDelay and doubles on every empty or failed poll, up to maxDelay.
+// Defaults to 1s and 30s.
+func WithPollInterval(minDelay, maxDelay time.Duration) Option {
+	return func(c *Consumer) {
+		if minDelay > 0 {
+			c.minPoll = minDelay
+		}
+		c.maxPoll = max(maxDelay, c.minPoll)
+	}
+}
+
+// WithLeaseRenewal sets how often the lease of a message is renewed while its
+// handler runs. Defaults to half of the message timeout.
+func WithLeaseRenewal(interval time.Duration) Option {
+	return func(c *Consumer) {
+		c.renewEvery = interval
+	}
+}
+
+// WithRetryDelay sets how long a failed message waits before it is delivered
+// again. Defaults to 0, for immediately.
+func WithRetryDelay(d time.Duration) Option {
+	return func(c *Consumer) {
+		c.retryDelay = d
+	}
+}
+
+// WithDrainTimeout bounds how long Run waits for running handlers once its
+// context is done; their context is cancelled when it elapses. By default
+// handlers are waited for until their message deadline.
+func WithDrainTimeout(d time.Duration) Option {
+	return func(c *Consumer) {
+		c.drainTimeout = d
+	}
+}
+
+// NewConsumer creates a Consumer of the queue queueId of source.
+func NewConsumer(source Source, queueId string, handler Handler, opts ...Option) *Consumer {
+	c := &Consumer{
+		source:      source,
+		queueId:     queueId,
+		handler:     handler,
+		concurrency: 1,
+		minPoll:     time.Second,
+		maxPoll:     30 * time.Second,
+		noRenew:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Run consumes messages until ctx is done, then stops pulling and waits for
+// the running handlers to finish. It returns nil once drained.
+func (c *Consumer) Run(ctx context.Context) error {
+	// Handlers outlive ctx while draining, until the drain timeout.
+	handlerCtx, cancelHandlers := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelHandlers()
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, c.concurrency)
+	delay := c.minPoll
+	for ctx.Err() == nil {
+		// Wait for a free worker, then take every other free one.
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			continue
+		}
+		free := 1
+	take:
+		for free < c.concurrency && free < 100 {
+			select {
+			case slots <- struct{}{}:
+				free++
+			default:
+				break take
+			}
+		}
+
+		msgs, err := c.source.Pull(ctx, c.queueId, int32(free))
+		if err != nil && ctx.Err() == nil {
+			log.Warnf("pull queue %s failed: %v", c.queueId, err)
+		}
+		for _, msg := range msgs {
+			if free == 0 {
+				// More messages than requested: leave them to their timeout.
+				break
+			}
+			free--
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+				c.handle(handlerCtx, msg)
+			}()
+		}
+		for ; free > 0; free-- {
+			<-slots
+		}
+
+		if len(msgs) > 0 {
+			delay = c.minPoll
+			continue
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		delay = min(delay*2, c.maxPoll)
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+	if c.drainTimeout > 0 {
+		timer := time.NewTimer(c.drainTimeout)
+		defer timer.Stop()
+		select {
+		case <-drained:
+			return nil
+		case <-timer.C:
+			cancelHandlers()
+		}
+	}
+	<-drained
+	return nil
+}
+
+// handle runs the handler on msg, renewing its lease meanwhile, and acks it
+// on success.
+func (c *Consumer) handle(ctx context.Context, msg *storage.Msg) {
+	if msg.Deadline > 0 {
+		deadline := time.Unix(msg.Deadline, 0)
+		if !deadline.After(time.Now()) {
+			log.Warnf("msg %s of queue %s is past its deadline, skipping", msg.ID, c.queueId)
+			return
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	stopRenew := c.renewLease(ctx, msg)
+	err := c.handler(ctx, msg)
+	stopRenew()
+	if err != nil {
+		log.Warnf("handle msg %s of queue %s failed (attempt %d/%d): %v", msg.ID, c.queueId, msg.Retried, msg.Retry, err)
+		// If the nack fails too, or isn't supported, the message comes back
+		// once its timeout elapses.
+		err = c.source.Nack(context.WithoutCancel(ctx), c.queueId, msg.ID, c.retryDelay)
+		if err != nil && !errors.Is(err, errors.ErrUnsupported) {
+			log.Warnf("nack msg %s of queue %s failed: %v", msg.ID, c.queueId, err)
+		}
+		return
+	}
+	if err = c.source.Ack(context.WithoutCancel(ctx), c.queueId, msg.ID); err != nil {
+		log.Errorf("ack msg %s of queue %s failed: %v", msg.ID, c.queueId, err)
+	}
+}
+
+// renewLease renews the lease of msg periodically until the returned func
+// is called.
+func (c *Consumer) renewLease(ctx context.Context, msg *storage.Msg) func() {
+	interval := c.renewEvery
+	if interval <= 0 {
+		interval = time.Duration(msg.Timeout) * time.Second / 2
+	}
+	if interval <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-c.noRenew:
+				return
+			case <-ticker.C:
+			}
+			err := c.source.Renew(ctx, c.queueId, msg.ID)
+			if errors.Is(err, errors.ErrUnsupported) {
+				c.renewUnsupported.Do(func() {
+					log.Warnf("queue %s can't renew leases, long handlers may see their messages delivered again", c.queueId)
+					close(c.noRenew)
+				})
+				return
+			}
+			if err != nil {
+				log.Warnf("renew msg %s of queue %s failed: %v", msg.ID, c.queueId, err)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
@@ -0,0 +1,208 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/scrapeless/services/storage"
+)
+
+// fakeSource is an in-memory queue where pulled messages become visible
+// again once their lease expires.
+type fakeSource struct {
+	mu      sync.Mutex
+	lease   time.Duration
+	msgs    []*storage.Msg
+	visible map[string]time.Time
+	acked   map[string]bool
+	nacked  int
+	renewed atomic.Int32
+	renew   error
+}
+
+func newFakeSource(lease time.Duration, n int) *fakeSource {
+	s := &fakeSource{lease: lease, visible: map[string]time.Time{}, acked: map[string]bool{}}
+	for i := 0; i < n; i++ {
+		s.msgs = append(s.msgs, &storage.Msg{
+			ID:       fmt.Sprint(i),
+			Retry:    3,
+			Deadline: time.Now().Add(time.Hour).Unix(),
+		})
+	}
+	return s
+}
+
+func (s *fakeSource) Pull(ctx context.Context, queueId string, size int32) (storage.GetMsgResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out storage.GetMsgResponse
+	now := time.Now()
+	for _, msg := range s.msgs {
+		if len(out) == int(size) {
+			break
+		}
+		if s.acked[msg.ID] || s.visible[msg.ID].After(now) || msg.Retried >= msg.Retry {
+			continue
+		}
+		msg.Retried++
+		s.visible[msg.ID] = now.Add(s.lease)
+		m := *msg
+		out = append(out, &m)
+	}
+	return out, nil
+}
+
+func (s *fakeSource) Ack(ctx context.Context, queueId string, msgId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.acked[msgId] = true
+	return nil
+}
+
+func (s *fakeSource) Nack(ctx context.Context, queueId string, msgId string, delay time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nacked++
+	s.visible[msgId] = time.Now().Add(delay)
+	return nil
+}
+
+func (s *fakeSource) Renew(ctx context.Context, queueId string, msgId string) error {
+	s.renewed.Add(1)
+	if s.renew != nil {
+		return s.renew
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.visible[msgId] = time.Now().Add(s.lease)
+	return nil
+}
+
+func (s *fakeSource) ackedCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.acked)
+}
+
+func runUntil(t *testing.T, c *Consumer, done func() bool) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() { result <- c.Run(ctx) }()
+	for deadline := time.Now().Add(5 * time.Second); !done(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+	}
+	cancel()
+	if err := <-result; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConsumerConcurrency(t *testing.T) {
+	source := newFakeSource(time.Minute, 20)
+	var running, peak atomic.Int32
+	c := NewConsumer(source, "q", func(ctx context.Context, msg *storage.Msg) error {
+		n := running.Add(1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	}, WithConcurrency(4), WithPollInterval(time.Millisecond, time.Millisecond))
+
+	runUntil(t, c, func() bool { return source.ackedCount() == 20 })
+	if peak.Load() > 4 || peak.Load() < 2 {
+		t.Fatalf("unexpected peak concurrency %d", peak.Load())
+	}
+}
+
+func TestConsumerRetriesFailures(t *testing.T) {
+	source := newFakeSource(time.Minute, 1)
+	var calls atomic.Int32
+	c := NewConsumer(source, "q", func(ctx context.Context, msg *storage.Msg) error {
+		if calls.Add(1) < 3 {
+			return errors.New("boom")
+		}
+		return nil
+	}, WithPollInterval(time.Millisecond, time.Millisecond))
+
+	runUntil(t, c, func() bool { return source.ackedCount() == 1 })
+	if calls.Load() != 3 || source.nacked != 2 {
+		t.Fatalf("handled %d times with %d nacks", calls.Load(), source.nacked)
+	}
+}
+
+func TestConsumerRenewsLease(t *testing.T) {
+	source := newFakeSource(20*time.Millisecond, 1)
+	var calls atomic.Int32
+	c := NewConsumer(source, "q", func(ctx context.Context, msg *storage.Msg) error {
+		calls.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}, WithLeaseRenewal(5*time.Millisecond), WithPollInterval(time.Millisecond, time.Millisecond))
+
+	runUntil(t, c, func() bool { return source.ackedCount() == 1 })
+	if calls.Load() != 1 || source.renewed.Load() == 0 {
+		t.Fatalf("handled %d times with %d renewals", calls.Load(), source.renewed.Load())
+	}
+}
+
+func TestConsumerStopsRenewingWhenUnsupported(t *testing.T) {
+	source := newFakeSource(time.Minute, 2)
+	source.renew = fmt.Errorf("renew msg %w", errors.ErrUnsupported)
+	c := NewConsumer(source, "q", func(ctx context.Context, msg *storage.Msg) error {
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	}, WithLeaseRenewal(time.Millisecond), WithPollInterval(time.Millisecond, time.Millisecond))
+
+	runUntil(t, c, func() bool { return source.ackedCount() == 2 })
+	if n := source.renewed.Load(); n != 1 {
+		t.Fatalf("renewed %d times", n)
+	}
+}
+
+func TestConsumerSkipsExpiredMessages(t *testing.T) {
+	source := newFakeSource(time.Minute, 1)
+	source.msgs[0].Deadline = time.Now().Add(-time.Second).Unix()
+	c := NewConsumer(source, "q", func(ctx context.Context, msg *storage.Msg) error {
+		t.Error("handled an expired message")
+		return nil
+	}, WithPollInterval(time.Millisecond, time.Millisecond))
+
+	runUntil(t, c, func() bool {
+		source.mu.Lock()
+		defer source.mu.Unlock()
+		return source.msgs[0].Retried > 0
+	})
+}
+
+func TestConsumerDrains(t *testing.T) {
+	source := newFakeSource(time.Minute, 1)
+	started := make(chan struct{})
+	var finished atomic.Bool
+	c := NewConsumer(source, "q", func(ctx context.Context, msg *storage.Msg) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(ctx.Err() == nil)
+		return nil
+	}, WithPollInterval(time.Millisecond, time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() { result <- c.Run(ctx) }()
+	<-started
+	cancel()
+	if err := <-result; err != nil {
+		t.Fatal(err)
+	}
+	if !finished.Load() || source.ackedCount() != 1 {
+		t.Fatal("running handler was not drained")
+	}
+}
@@ -0,0 +1,598 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+)
+
+// maxLaneUpdates is how many times a RequestQueue tries to register a new
+// lane while other runs keep registering theirs.
+const maxLaneUpdates = 10
+
+// maxClaims is how many times a RequestQueue tries to claim the seen-set key
+// of a request while other runs keep changing it.
+const maxClaims = 10
+
+// Request is a request to crawl, as stored in a RequestQueue.
+type Request struct {
+	Url string `json:"url"`
+	// UniqueKey identifies the request for deduplication. When empty, it is
+	// computed by the unique key function of the queue, the normalized Url by
+	// default.
+	UniqueKey string            `json:"uniqueKey,omitempty"`
+	Method    string            `json:"method,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Payload   string            `json:"payload,omitempty"`
+	UserData  map[string]any    `json:"userData,omitempty"`
+	// Priority orders the requests: those of higher priority are fetched
+	// first, and those of equal priority in the order they were added.
+	Priority int `json:"priority,omitempty"`
+
+	// Id, Retried and Retry are set on the requests returned by FetchNext:
+	// the id of the queue message, how many times it has been fetched, and
+	// how many times it can be.
+	Id      string `json:"-"`
+	Retried int64  `json:"-"`
+	Retry   int64  `json:"-"`
+	queueId string
+}
+
+// AddRequestResult reports the outcome of RequestQueue.AddRequest.
+type AddRequestResult struct {
+	UniqueKey string
+	// AlreadySeen is set when the request was added before, and wasn't added
+	// again.
+	AlreadySeen bool
+	// AlreadyHandled is set when the request seen before was also handled.
+	AlreadyHandled bool
+	// Id is the id of the queue message of a request added.
+	Id string
+}
+
+// RequestQueueStats counts the requests of a RequestQueue in the current run.
+type RequestQueueStats struct {
+	// Pending counts the requests added and not handled yet, leased or not.
+	// Requests whose deadline passed, or whose lease ran out on their last
+	// try, are dropped by the queue and stay counted.
+	Pending int64 `json:"pending"`
+	Handled int64 `json:"handled"`
+	// Failed counts the requests given up after exhausting their retries.
+	Failed int64 `json:"failed"`
+}
+
+// RequestQueue is a queue of requests to crawl that adds every request once.
+// Requests are identified by their unique key, and the keys of the requests
+// added are kept in a seen-set in KV, shared by the runs using the same
+// namespace and name, so a request handled or given up by a previous run
+// isn't added again while its key is remembered.
+//
+// A request pending in another run is left to it while that run is alive:
+// an open RequestQueue renews a lease of its run in the background, and only
+// once the lease of a run lapses are the requests it didn't finish added
+// again by the others. Close stops renewing the lease.
+//
+// The requests are stored in queues of the current run, one per priority
+// plus one for the requests added to the forefront, which are fetched before
+// all the others. Fetched requests must be marked handled, or reclaimed to be
+// fetched again later. The seen-set relies on atomic KV operations, so online
+// a RequestQueue requires the Redis storage.
+//
+//	rq, err := storage.NewRequestQueue(ctx, client.Storage.Queue, client.Storage.KV, namespaceId, "crawl")
+//	_, err = rq.AddRequest(ctx, &storage.Request{Url: "https://example.com/?b=2&a=1#top"}, false)
+//	req, err := rq.FetchNext(ctx)
+//	if req != nil {
+//		// crawl req.Url ...
+//		err = rq.MarkHandled(ctx, req)
+//	}
+//	rq.Close()
+type RequestQueue struct {
+	queue       *Queue
+	kv          *KV
+	namespaceId string
+	name        string
+	runId       string
+
+	uniqueKey func(req *Request) (string, error)
+	seenTTL   uint
+	retry     int64
+	timeout   int64
+	deadline  int64
+	leaseTTL  time.Duration
+
+	mu    sync.Mutex
+	lanes []requestLane
+	stop  chan struct{}
+	once  sync.Once
+}
+
+// requestLane is a queue holding the requests of a priority, or those added
+// to the forefront.
+type requestLane struct {
+	Priority  int    `json:"priority"`
+	Forefront bool   `json:"forefront,omitempty"`
+	QueueId   string `json:"queueId"`
+}
+
+// before reports whether the requests of lane l are fetched before those of
+// other.
+func (l requestLane) before(other requestLane) bool {
+	if l.Forefront != other.Forefront {
+		return l.Forefront
+	}
+	return l.Priority > other.Priority
+}
+
+// RequestQueueOption configures a RequestQueue.
+type RequestQueueOption func(*RequestQueue)
+
+// WithUniqueKey sets the function computing the unique key of the requests
+// added without one. Defaults to NormalizeUrl of their Url.
+func WithUniqueKey(fn func(req *Request) (string, error)) RequestQueueOption {
+	return func(q *RequestQueue) {
+		if fn != nil {
+			q.uniqueKey = fn
+		}
+	}
+}
+
+// WithSeenTTL sets how long the keys of the requests added are remembered.
+// Defaults to the longest the KV storage keeps a key.
+func WithSeenTTL(ttl time.Duration) RequestQueueOption {
+	return func(q *RequestQueue) {
+		if ttl > 0 {
+			q.seenTTL = uint(max(ttl/time.Second, 1))
+		}
+	}
+}
+
+// WithRequestRetry sets how many times a request is fetched before being
+// given up, its lease timeout and the deadline by which it must be handled,
+// both in seconds. Defaults to 3, 60 and 86400, the longest deadline allowed.
+func WithRequestRetry(retry int64, timeout int64, deadline int64) RequestQueueOption {
+	return func(q *RequestQueue) {
+		q.retry, q.timeout, q.deadline = retry, timeout, deadline
+	}
+}
+
+// WithRunLease sets how long the requests pending in the current run are left
+// to it after it stops renewing its lease, because it ended or was cut off
+// from the storage. Defaults to a minute.
+func WithRunLease(ttl time.Duration) RequestQueueOption {
+	return func(q *RequestQueue) {
+		if ttl > 0 {
+			q.leaseTTL = max(ttl, time.Second)
+		}
+	}
+}
+
+// NewRequestQueue opens the request queue name, whose seen-set and counters
+// are kept in the namespace. The queues of the lanes are created on demand.
+func NewRequestQueue(ctx context.Context, queue *Queue, kv *KV, namespaceId string, name string, opts ...RequestQueueOption) (*RequestQueue, error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: request queue name is empty", errs.ErrInvalidArgument)
+	}
+	q := &RequestQueue{
+		queue:       queue,
+		kv:          kv,
+		namespaceId: namespaceId,
+		name:        name,
+		runId:       queue.cfg.Actor.RunId,
+		uniqueKey: func(req *Request) (string, error) {
+			return NormalizeUrl(req.Url)
+		},
+		retry:    3,
+		timeout:  60,
+		deadline: 86400,
+		leaseTTL: time.Minute,
+		stop:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	if _, err := q.kv.SetIfNotExists(ctx, namespaceId, q.lanesKey(), "[]", 0); err != nil {
+		return nil, err
+	}
+	if _, err := q.loadLanes(ctx); err != nil {
+		return nil, err
+	}
+	if err := q.renewLease(ctx); err != nil {
+		return nil, err
+	}
+	go q.keepLease()
+	return q, nil
+}
+
+// Close stops renewing the lease of the current run. The requests it leaves
+// pending are added again by other runs once the lease lapses.
+func (q *RequestQueue) Close() {
+	q.once.Do(func() { close(q.stop) })
+}
+
+// renewLease extends the lease of the current run by its ttl.
+func (q *RequestQueue) renewLease(ctx context.Context) error {
+	ttl := uint(math.Ceil(q.leaseTTL.Seconds()))
+	_, err := q.kv.SetValue(ctx, q.namespaceId, q.leaseKey(q.runId), "alive", ttl)
+	return err
+}
+
+// keepLease renews the lease of the current run until Close is called.
+func (q *RequestQueue) keepLease() {
+	interval := q.leaseTTL / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		err := q.renewLease(ctx)
+		cancel()
+		if err != nil {
+			log.Warnf("renew lease of request queue %s failed: %v", q.name, err)
+		}
+	}
+}
+
+// NormalizeUrl returns the canonical form of rawUrl used as the default
+// unique key of requests: scheme and host lowercased, default port and
+// fragment stripped, query parameters sorted, and an empty path set to "/".
+func NormalizeUrl(rawUrl string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawUrl))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errs.ErrInvalidArgument, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("%w: url %q isn't absolute", errs.ErrInvalidArgument, rawUrl)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = strings.TrimSuffix(u.Host, ":"+port)
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.Fragment, u.RawFragment = "", ""
+	// Encode sorts the parameters by key; the values of a key are sorted too.
+	query := u.Query()
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	u.RawQuery = query.Encode()
+	u.ForceQuery = false
+	return u.String(), nil
+}
+
+// AddRequest adds req to the queue unless a request with the same unique key
+// was added before. Requests added to the forefront are fetched before all
+// the others.
+func (q *RequestQueue) AddRequest(ctx context.Context, req *Request, forefront bool) (*AddRequestResult, error) {
+	key := req.UniqueKey
+	if key == "" {
+		var err error
+		if key, err = q.uniqueKey(req); err != nil {
+			return nil, err
+		}
+	}
+	result := &AddRequestResult{UniqueKey: key}
+	seen := q.seenKey(key)
+	claimed, state, err := q.claim(ctx, seen)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		result.AlreadySeen = true
+		result.AlreadyHandled = state == "handled"
+		return result, nil
+	}
+
+	stored := *req
+	stored.UniqueKey = key
+	payload, err := json.Marshal(&stored)
+	if err != nil {
+		return nil, fmt.Errorf("json marshal failed: %s", err)
+	}
+	lane, err := q.lane(ctx, req.Priority, forefront)
+	if err == nil {
+		result.Id, err = q.queue.Push(ctx, lane.QueueId, PushQueue{
+			Name:     key,
+			Payload:  payload,
+			Retry:    q.retry,
+			Timeout:  q.timeout,
+			Deadline: q.deadline,
+		})
+	}
+	if err != nil {
+		// Forget the request, so it can be added again.
+		_, _ = q.kv.DelValue(ctx, q.namespaceId, seen)
+		return nil, err
+	}
+	if _, err = q.kv.Increment(ctx, q.namespaceId, q.counterKey("pending"), 1); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// claim records in the seen-set that the request of key seen is added by the
+// current run. It isn't claimed when the request was already handled, given
+// up, or added by a run whose lease is still alive, the current one
+// included, and then the state of the request is returned. A request pending
+// in a run whose lease lapsed is claimed again so it isn't lost.
+func (q *RequestQueue) claim(ctx context.Context, seen string) (claimed bool, state string, err error) {
+	for range maxClaims {
+		ok, err := q.kv.SetIfNotExists(ctx, q.namespaceId, seen, q.runId, q.seenTTL)
+		if err != nil || ok {
+			return ok, "", err
+		}
+		state, err := q.kv.GetValue(ctx, q.namespaceId, seen)
+		if errors.Is(err, errs.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return false, "", err
+		}
+		if state == "handled" || state == "failed" || state == q.runId {
+			return false, state, nil
+		}
+		_, err = q.kv.GetWithMetadata(ctx, q.namespaceId, q.leaseKey(state))
+		if err == nil {
+			return false, state, nil
+		}
+		if !errors.Is(err, errs.ErrNotFound) {
+			return false, "", err
+		}
+		if ok, err = q.kv.CompareAndSwap(ctx, q.namespaceId, seen, state, q.runId, q.seenTTL); err != nil || ok {
+			return ok, "", err
+		}
+	}
+	return false, "", fmt.Errorf("failed to add request: seen-set key %s keeps changing", seen)
+}
+
+// AddRequests adds reqs in order, stopping at the first error.
+func (q *RequestQueue) AddRequests(ctx context.Context, reqs []*Request, forefront bool) ([]*AddRequestResult, error) {
+	results := make([]*AddRequestResult, 0, len(reqs))
+	for _, req := range reqs {
+		result, err := q.AddRequest(ctx, req, forefront)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// FetchNext leases the next request, the forefront ones first and then by
+// priority. It returns nil when no request is available.
+func (q *RequestQueue) FetchNext(ctx context.Context) (*Request, error) {
+	lanes, err := q.loadLanes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, lane := range lanes {
+		msgs, err := q.queue.Pull(ctx, lane.QueueId, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(msgs) == 0 {
+			continue
+		}
+		msg := msgs[0]
+		var req Request
+		if err = json.Unmarshal([]byte(msg.Payload), &req); err != nil {
+			return nil, fmt.Errorf("json unmarshal failed: %s", err)
+		}
+		req.Id, req.Retried, req.Retry, req.queueId = msg.ID, msg.Retried, msg.Retry, lane.QueueId
+		return &req, nil
+	}
+	return nil, nil
+}
+
+// MarkHandled removes req, returned by FetchNext, from the queue.
+func (q *RequestQueue) MarkHandled(ctx context.Context, req *Request) error {
+	return q.finish(ctx, req, "handled")
+}
+
+// Reclaim returns req, returned by FetchNext, to the queue, to be fetched
+// again after delay. A request that exhausted its retries is given up
+// instead, and counted as failed; it stays seen. When the queue storage
+// can't nack messages, the request is fetched again once its lease runs out
+// rather than after delay.
+func (q *RequestQueue) Reclaim(ctx context.Context, req *Request, delay time.Duration) error {
+	if req.Retried >= max(req.Retry, 1) {
+		return q.finish(ctx, req, "failed")
+	}
+	err := q.queue.Nack(ctx, req.queueId, req.Id, delay)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return nil
+	}
+	return err
+}
+
+// finish acks req and records its final state.
+func (q *RequestQueue) finish(ctx context.Context, req *Request, state string) error {
+	if req.Id == "" || req.queueId == "" {
+		return fmt.Errorf("%w: request wasn't fetched from the queue", errs.ErrInvalidArgument)
+	}
+	if err := q.queue.Ack(ctx, req.queueId, req.Id); err != nil {
+		return err
+	}
+	if _, err := q.kv.SetValue(ctx, q.namespaceId, q.seenKey(req.UniqueKey), state, q.seenTTL); err != nil {
+		return err
+	}
+	if _, err := q.kv.Increment(ctx, q.namespaceId, q.counterKey("pending"), -1); err != nil {
+		return err
+	}
+	_, err := q.kv.Increment(ctx, q.namespaceId, q.counterKey(state), 1)
+	return err
+}
+
+// Stats returns the counts of requests of the current run.
+func (q *RequestQueue) Stats(ctx context.Context) (*RequestQueueStats, error) {
+	var stats RequestQueueStats
+	for _, counter := range []struct {
+		name  string
+		value *int64
+	}{
+		{"pending", &stats.Pending},
+		{"handled", &stats.Handled},
+		{"failed", &stats.Failed},
+	} {
+		// Adding 0 reads the counter, missing ones counting as 0.
+		n, err := q.kv.Increment(ctx, q.namespaceId, q.counterKey(counter.name), 0)
+		if err != nil {
+			return nil, err
+		}
+		*counter.value = n
+	}
+	return &stats, nil
+}
+
+// IsFinished reports whether every request added in the current run was
+// handled or given up.
+func (q *RequestQueue) IsFinished(ctx context.Context) (bool, error) {
+	stats, err := q.Stats(ctx)
+	if err != nil {
+		return false, err
+	}
+	return stats.Pending <= 0, nil
+}
+
+func (q *RequestQueue) seenKey(uniqueKey string) string {
+	sum := sha1.Sum([]byte(uniqueKey))
+	return q.name + ".seen." + hex.EncodeToString(sum[:])
+}
+
+func (q *RequestQueue) counterKey(counter string) string {
+	return q.name + "." + q.runId + "." + counter
+}
+
+func (q *RequestQueue) leaseKey(runId string) string {
+	return q.name + "." + runId + ".lease"
+}
+
+func (q *RequestQueue) lanesKey() string {
+	return q.name + "." + q.runId + ".lanes"
+}
+
+// loadLanes returns the lanes of the current run in fetching order.
+func (q *RequestQueue) loadLanes(ctx context.Context) ([]requestLane, error) {
+	value, err := q.kv.GetValue(ctx, q.namespaceId, q.lanesKey())
+	if errors.Is(err, errs.ErrNotFound) || (err == nil && value == "") {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lanes []requestLane
+	if err = json.Unmarshal([]byte(value), &lanes); err != nil {
+		return nil, fmt.Errorf("json unmarshal failed: %s", err)
+	}
+	q.mu.Lock()
+	q.lanes = lanes
+	q.mu.Unlock()
+	return lanes, nil
+}
+
+// lane returns the lane of priority, or the forefront one, creating its
+// queue and registering it for the other runs if needed.
+func (q *RequestQueue) lane(ctx context.Context, priority int, forefront bool) (requestLane, error) {
+	want := requestLane{Priority: priority, Forefront: forefront}
+	if forefront {
+		want.Priority = 0
+	}
+	find := func(lanes []requestLane) (requestLane, bool) {
+		i := slices.IndexFunc(lanes, func(l requestLane) bool {
+			return l.Priority == want.Priority && l.Forefront == want.Forefront
+		})
+		if i < 0 {
+			return requestLane{}, false
+		}
+		return lanes[i], true
+	}
+	q.mu.Lock()
+	lane, ok := find(q.lanes)
+	q.mu.Unlock()
+	if ok {
+		return lane, nil
+	}
+
+	name := fmt.Sprintf("%s-p%d", q.name, want.Priority)
+	if forefront {
+		name = q.name + "-forefront"
+	}
+	queueId, _, err := q.queue.CreateQueue(ctx, &CreateQueueReq{Name: name, Description: "request queue " + q.name})
+	if errors.Is(err, errs.ErrAlreadyExists) {
+		var item *Item
+		if item, err = q.queue.GetQueue(ctx, "", name); err == nil {
+			queueId = item.Id
+		}
+	}
+	if err != nil {
+		return requestLane{}, err
+	}
+	want.QueueId = queueId
+
+	for range maxLaneUpdates {
+		old, err := q.kv.GetValue(ctx, q.namespaceId, q.lanesKey())
+		if err != nil && !errors.Is(err, errs.ErrNotFound) {
+			return requestLane{}, err
+		}
+		var lanes []requestLane
+		if old != "" {
+			if err = json.Unmarshal([]byte(old), &lanes); err != nil {
+				return requestLane{}, fmt.Errorf("json unmarshal failed: %s", err)
+			}
+		}
+		if lane, ok := find(lanes); ok {
+			q.mu.Lock()
+			q.lanes = lanes
+			q.mu.Unlock()
+			return lane, nil
+		}
+		lanes = append(lanes, want)
+		slices.SortStableFunc(lanes, func(a, b requestLane) int {
+			switch {
+			case a.before(b):
+				return -1
+			case b.before(a):
+				return 1
+			}
+			return 0
+		})
+		value, err := json.Marshal(lanes)
+		if err != nil {
+			return requestLane{}, fmt.Errorf("json marshal failed: %s", err)
+		}
+		if old == "" {
+			ok, err = q.kv.SetIfNotExists(ctx, q.namespaceId, q.lanesKey(), string(value), 0)
+		} else {
+			ok, err = q.kv.CompareAndSwap(ctx, q.namespaceId, q.lanesKey(), old, string(value), 0)
+		}
+		if err != nil {
+			return requestLane{}, err
+		}
+		if ok {
+			q.mu.Lock()
+			q.lanes = lanes
+			q.mu.Unlock()
+			return want, nil
+		}
+	}
+	return requestLane{}, fmt.Errorf("register lane %s: too many concurrent updates", name)
+}
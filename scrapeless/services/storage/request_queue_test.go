@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/scrapeless/errs"
+)
+
+func TestNormalizeUrl(t *testing.T) {
+	for raw, want := range map[string]string{
+		"https://Example.COM":                    "https://example.com/",
+		"https://example.com:443/a?b=2&a=1#frag": "https://example.com/a?a=1&b=2",
+		"http://example.com:8080/a?x=2&x=1":      "http://example.com:8080/a?x=1&x=2",
+		"http://example.com/a?":                  "http://example.com/a",
+		" HTTP://example.com:80/A/ ":             "http://example.com/A/",
+	} {
+		got, err := NormalizeUrl(raw)
+		if err != nil || got != want {
+			t.Errorf("NormalizeUrl(%q) = %q, %v, want %q", raw, got, err, want)
+		}
+	}
+	if _, err := NormalizeUrl("/relative"); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("NormalizeUrl(relative) = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestRequestQueue(t *testing.T) {
+	s := newLocalStorage(t)
+	ctx := context.Background()
+	namespaceId, _, err := s.KV.CreateNamespace(ctx, "crawl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rq, err := NewRequestQueue(ctx, s.Queue, s.KV, namespaceId, "pages", WithRequestRetry(2, 60, 3600))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rq.Close()
+	if req, err := rq.FetchNext(ctx); req != nil || err != nil {
+		t.Fatalf("FetchNext(empty) = %+v, %v", req, err)
+	}
+
+	added, err := rq.AddRequests(ctx, []*Request{
+		{Url: "https://example.com/a?y=1&x=2"},
+		{Url: "https://EXAMPLE.com/a?x=2&y=1#top"},
+		{Url: "https://example.com/urgent", Priority: 5},
+		{Url: "https://example.com/b", UserData: map[string]any{"depth": 1.0}},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added[0].AlreadySeen || !added[1].AlreadySeen || added[1].UniqueKey != "https://example.com/a?x=2&y=1" {
+		t.Errorf("AddRequests() = %+v %+v, want the second one deduplicated", added[0], added[1])
+	}
+	if result, err := rq.AddRequest(ctx, &Request{Url: "https://example.com/first"}, true); err != nil || result.AlreadySeen {
+		t.Fatalf("AddRequest(forefront) = %+v, %v", result, err)
+	}
+	if _, err = rq.AddRequest(ctx, &Request{Url: "not a url"}, false); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("AddRequest(invalid url) = %v, want ErrInvalidArgument", err)
+	}
+
+	// Another process of the run sees the lanes registered by the first one.
+	other, err := NewRequestQueue(ctx, s.Queue, s.KV, namespaceId, "pages", WithRequestRetry(2, 60, 3600))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	var fetched []*Request
+	for _, want := range []string{"/first", "/urgent", "/a?x=2&y=1", "/b"} {
+		req, err := other.FetchNext(ctx)
+		if err != nil || req == nil || !strings.HasSuffix(req.UniqueKey, want) {
+			t.Fatalf("FetchNext() = %+v, %v, want %s", req, err, want)
+		}
+		fetched = append(fetched, req)
+	}
+	if req, err := other.FetchNext(ctx); req != nil || err != nil {
+		t.Errorf("FetchNext(all leased) = %+v, %v", req, err)
+	}
+	if depth := fetched[3].UserData["depth"]; depth != 1.0 {
+		t.Errorf("UserData[depth] = %v", depth)
+	}
+
+	for _, req := range fetched[:3] {
+		if err = other.MarkHandled(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if result, err := rq.AddRequest(ctx, &Request{Url: "https://example.com/urgent"}, false); err != nil || !result.AlreadyHandled {
+		t.Errorf("AddRequest(handled) = %+v, %v, want AlreadyHandled", result, err)
+	}
+
+	// Reclaimed, a request is fetched again until its retries are exhausted.
+	if err = other.Reclaim(ctx, fetched[3], 0); err != nil {
+		t.Fatal(err)
+	}
+	req, err := rq.FetchNext(ctx)
+	if err != nil || req == nil || req.Retried != 2 {
+		t.Fatalf("FetchNext(reclaimed) = %+v, %v", req, err)
+	}
+	if err = rq.Reclaim(ctx, req, 0); err != nil {
+		t.Fatal(err)
+	}
+	if req, err := rq.FetchNext(ctx); req != nil || err != nil {
+		t.Errorf("FetchNext(given up) = %+v, %v", req, err)
+	}
+
+	stats, err := rq.Stats(ctx)
+	if err != nil || *stats != (RequestQueueStats{Pending: 0, Handled: 3, Failed: 1}) {
+		t.Errorf("Stats() = %+v, %v", stats, err)
+	}
+	if done, err := rq.IsFinished(ctx); !done || err != nil {
+		t.Errorf("IsFinished() = %v, %v", done, err)
+	}
+	if err = rq.MarkHandled(ctx, &Request{Url: "https://example.com/"}); !errors.Is(err, errs.ErrInvalidArgument) {
+		t.Errorf("MarkHandled(not fetched) = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestRequestQueueNextRun(t *testing.T) {
+	s := newLocalStorage(t)
+	ctx := context.Background()
+	namespaceId, _, err := s.KV.CreateNamespace(ctx, "crawl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rq, err := NewRequestQueue(ctx, s.Queue, s.KV, namespaceId, "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range []string{"https://example.com/done", "https://example.com/pending"} {
+		if _, err = rq.AddRequest(ctx, &Request{Url: u}, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, err := rq.FetchNext(ctx)
+	if err != nil || req == nil {
+		t.Fatalf("FetchNext() = %+v, %v", req, err)
+	}
+	if err = rq.MarkHandled(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+
+	// The run ends with a request pending, which the next run adds again
+	// once the lease of the first one lapses.
+	rq.Close()
+	if _, err = s.KV.DelValue(ctx, namespaceId, rq.leaseKey(rq.runId)); err != nil {
+		t.Fatal(err)
+	}
+	next, err := NewRequestQueue(ctx, runQueue(s.Queue, "next-run"), s.KV, namespaceId, "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer next.Close()
+	if result, err := next.AddRequest(ctx, &Request{Url: "https://example.com/done"}, false); err != nil || !result.AlreadyHandled {
+		t.Errorf("AddRequest(handled) = %+v, %v, want AlreadyHandled", result, err)
+	}
+	for _, seen := range []bool{false, true} {
+		result, err := next.AddRequest(ctx, &Request{Url: "https://example.com/pending"}, false)
+		if err != nil || result.AlreadySeen != seen {
+			t.Errorf("AddRequest(pending) = %+v, %v, want AlreadySeen %v", result, err, seen)
+		}
+	}
+	req, err = next.FetchNext(ctx)
+	if err != nil || req == nil || req.UniqueKey != "https://example.com/pending" {
+		t.Fatalf("FetchNext(next run) = %+v, %v", req, err)
+	}
+}
+
+func TestRequestQueueLiveRuns(t *testing.T) {
+	s := newLocalStorage(t)
+	ctx := context.Background()
+	namespaceId, _, err := s.KV.CreateNamespace(ctx, "crawl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := NewRequestQueue(ctx, runQueue(s.Queue, "first-run"), s.KV, namespaceId, "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	second, err := NewRequestQueue(ctx, runQueue(s.Queue, "second-run"), s.KV, namespaceId, "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	// Both runs are alive, so the requests pending in the first one aren't
+	// added again by the second.
+	for _, step := range []struct {
+		rq   *RequestQueue
+		seen bool
+	}{{first, false}, {second, true}, {first, true}} {
+		for _, u := range []string{"https://example.com/a", "https://example.com/b"} {
+			result, err := step.rq.AddRequest(ctx, &Request{Url: u}, false)
+			if err != nil || result.AlreadySeen != step.seen {
+				t.Fatalf("AddRequest(%s, %s) = %+v, %v, want AlreadySeen %v", step.rq.runId, u, result, err, step.seen)
+			}
+		}
+	}
+	for rq, want := range map[*RequestQueue]int64{first: 2, second: 0} {
+		stats, err := rq.Stats(ctx)
+		if err != nil || stats.Pending != want {
+			t.Errorf("Stats(%s) = %+v, %v, want %d pending", rq.runId, stats, err, want)
+		}
+	}
+}
+
+// runQueue returns a Queue using the storage of queue in the run runId.
+func runQueue(queue *Queue, runId string) *Queue {
+	cfg := *queue.cfg
+	cfg.Actor.RunId = runId
+	return &Queue{backend{cfg: &cfg, client: queue.client}}
+}
@@ -1,9 +1,13 @@
 package storage
 
 import (
+	"context"
+	"errors"
+
 	"github.com/scrapeless-ai/sdk-go/env"
+	"github.com/scrapeless-ai/sdk-go/internal/code"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage"
-	"sync"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
 type Storage struct {
@@ -14,28 +18,56 @@ type Storage struct {
 	*Vector
 }
 
-var (
-	defaultStorage *Storage
-	once           = sync.Once{}
-)
+// backend is the configuration and remote client shared by the services of one Storage.
+type backend struct {
+	cfg    *env.Config
+	client storage.Storage
+}
 
-func init() {
-	defaultStorage = &Storage{
-		Dataset: &Dataset{},
-		KV:      &KV{},
-		Object:  &Object{},
-		Queue:   &Queue{},
-		Vector:  &Vector{},
+// NewStorage creates a Storage. The optional cfg selects the credentials,
+// base URL and actor identifiers to use; env.Default is used when it is omitted.
+func NewStorage(serverMode string, cfg ...*env.Config) *Storage {
+	c := env.Or(cfg...)
+	b := backend{
+		cfg:    c,
+		client: storage.NewClient(serverMode, c, c.ScrapelessStorageUrl),
+	}
+	return &Storage{
+		Dataset: &Dataset{b},
+		KV:      &KV{b},
+		Object:  &Object{b},
+		Queue:   &Queue{b},
+		Vector:  &Vector{b},
 	}
 }
 
-func NewStorage(serverMode string) *Storage {
-	once.Do(func() {
-		storage.NewClient(serverMode, env.Env.ScrapelessStorageUrl)
-	})
-	return defaultStorage
+func (s *Storage) Close() error {
+	if s.Dataset.client == nil {
+		return nil
+	}
+	return s.Dataset.client.Close()
 }
 
-func (s *Storage) Close() error {
-	return nil
+// Compact removes the expired keys, the finished and expired queue messages,
+// and the metadata orphaned by deleted data from the local storage. The local
+// storage is also compacted in the background every ten minutes. The remote
+// storage cleans up after itself, so online Compact does nothing.
+// Parameters:
+//
+//	ctx: Request context
+func (s *Storage) Compact(ctx context.Context) (*CompactStats, error) {
+	stats, err := s.Dataset.client.Compact(ctx)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return &CompactStats{}, nil
+	}
+	if err != nil {
+		log.Errorf("failed to compact storage: %v", code.Format(err))
+		return nil, code.Format(err)
+	}
+	return &CompactStats{
+		ExpiredKeys:     stats.ExpiredKeys,
+		ExpiredMessages: stats.ExpiredMessages,
+		OrphanedFiles:   stats.OrphanedFiles,
+		ReclaimedBytes:  stats.ReclaimedBytes,
+	}, nil
 }
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/scrapeless-ai/sdk-go/internal/code"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+)
+
+// Sync copies the datasets, KV namespaces, queues with their pending
+// messages, and buckets of src to dst, such as the local storage of a
+// development run to the cloud, or a cloud run down to the local storage for
+// debugging. Resources are matched by name, and opts.Conflict decides what
+// happens to those found in dst. The copies get new ids. The pending messages
+// of the cloud storage can't be listed, so cloud queues are copied empty.
+//
+// It returns the results of the resources synced so far along with the
+// first error met.
+// Parameters:
+//
+//	ctx: The context for the requests.
+//	src: The storage to copy from.
+//	dst: The storage to copy to.
+//	opts: What to copy, and how.
+func Sync(ctx context.Context, src *Storage, dst *Storage, opts SyncOptions) ([]SyncResult, error) {
+	results, err := storage.Sync(ctx, src.Dataset.client, dst.Dataset.client, storage.SyncOptions{
+		Categories: opts.Categories,
+		Names:      opts.Names,
+		Conflict:   storage.ConflictPolicy(opts.Conflict),
+		DryRun:     opts.DryRun,
+		PageSize:   opts.PageSize,
+	})
+	items := make([]SyncResult, 0, len(results))
+	for _, result := range results {
+		items = append(items, SyncResult{
+			Category: result.Category,
+			Name:     result.Name,
+			SourceId: result.SourceId,
+			TargetId: result.TargetId,
+			Action:   string(result.Action),
+			Copied:   result.Copied,
+			Dropped:  result.Dropped,
+			Skipped:  result.Skipped,
+		})
+	}
+	if err != nil {
+		log.Errorf("failed to sync storage: %v", code.Format(err))
+		return items, code.Format(err)
+	}
+	return items, nil
+}
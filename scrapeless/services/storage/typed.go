@@ -0,0 +1,254 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// TypedDataset stores values of type T as dataset items. Dataset items are
+// JSON documents, so T is converted with encoding/json whatever the codec of
+// the other typed services; T must encode to a JSON object.
+type TypedDataset[T any] struct {
+	*Dataset
+}
+
+// NewTypedDataset wraps dataset to store values of type T.
+func NewTypedDataset[T any](dataset *Dataset) *TypedDataset[T] {
+	return &TypedDataset[T]{Dataset: dataset}
+}
+
+// TypedItems is a page of dataset items decoded as T.
+type TypedItems[T any] struct {
+	Items []T
+	Total int
+}
+
+// AddItems adds items to the dataset.
+//
+// Parameters:
+//   - ctx: The context for the request.
+//   - items: The values to add, each stored as one item.
+func (d *TypedDataset[T]) AddItems(ctx context.Context, datasetId string, items []T) (bool, error) {
+	maps := make([]map[string]any, 0, len(items))
+	for i, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return false, fmt.Errorf("encode item %d: %w", i, err)
+		}
+		var m map[string]any
+		if err = json.Unmarshal(data, &m); err != nil {
+			return false, fmt.Errorf("item %d is not a JSON object: %w", i, err)
+		}
+		maps = append(maps, m)
+	}
+	return d.Dataset.AddItems(ctx, datasetId, maps)
+}
+
+// GetItems retrieves a page of items decoded as T.
+//
+// Parameters:
+//
+//	ctx: The context for the request.
+//	page: The page number to retrieve (starting from 1).
+//	pageSize: The number of items to return per page.
+//	desc: Whether to sort items in descending order (true) or ascending (false).
+func (d *TypedDataset[T]) GetItems(ctx context.Context, datasetId string, page int, pageSize int, desc bool) (*TypedItems[T], error) {
+	resp, err := d.Dataset.GetItems(ctx, datasetId, page, pageSize, desc)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]T, 0, len(resp.Items))
+	for i, m := range resp.Items {
+		item, err := decodeItem[T](m)
+		if err != nil {
+			return nil, fmt.Errorf("decode item %d: %w", i, err)
+		}
+		items = append(items, item)
+	}
+	return &TypedItems[T]{Items: items, Total: resp.Total}, nil
+}
+
+// IterItems returns an iterator over all the items of the dataset decoded as
+// T. See Dataset.IterItems.
+func (d *TypedDataset[T]) IterItems(ctx context.Context, datasetId string, pageSize int, desc bool) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for m, err := range d.Dataset.IterItems(ctx, datasetId, pageSize, desc) {
+			var item T
+			if err == nil {
+				if item, err = decodeItem[T](m); err != nil {
+					err = fmt.Errorf("decode item: %w", err)
+				}
+			}
+			if !yield(item, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+func decodeItem[T any](m map[string]any) (T, error) {
+	var item T
+	data, err := json.Marshal(m)
+	if err != nil {
+		return item, err
+	}
+	err = json.Unmarshal(data, &item)
+	return item, err
+}
+
+// TypedKV stores values of type T encoded with a Codec.
+type TypedKV[T any] struct {
+	*KV
+	codec Codec
+}
+
+// NewTypedKV wraps kv to store values of type T encoded with codec, or
+// JSONCodec when codec is nil.
+func NewTypedKV[T any](kv *KV, codec Codec) *TypedKV[T] {
+	if codec == nil {
+		codec = JSONCodec
+	}
+	return &TypedKV[T]{KV: kv, codec: codec}
+}
+
+// SetValue stores value under key.
+//
+// Parameters:
+//
+//	ctx: The context for the request.
+//	key: The key to set.
+//	value: The value to store.
+//	expiration: The expiration of the key in seconds, 0 for none.
+func (k *TypedKV[T]) SetValue(ctx context.Context, namespaceId string, key string, value T, expiration uint) (bool, error) {
+	encoded, err := encodeText(k.codec, value)
+	if err != nil {
+		return false, fmt.Errorf("encode value of key %s: %w", key, err)
+	}
+	return k.KV.SetValue(ctx, namespaceId, key, encoded, expiration)
+}
+
+// GetValue retrieves the value stored under key.
+//
+// Parameters:
+//
+//	ctx: The context for the request.
+//	key: The key to get.
+func (k *TypedKV[T]) GetValue(ctx context.Context, namespaceId string, key string) (T, error) {
+	var value T
+	encoded, err := k.KV.GetValue(ctx, namespaceId, key)
+	if err != nil {
+		return value, err
+	}
+	if err = decodeText(k.codec, encoded, &value); err != nil {
+		return value, fmt.Errorf("decode value of key %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// BulkSetValue stores several values at once.
+//
+// Parameters:
+//
+//	ctx: The context for the request.
+//	values: The values to store by key.
+//	expiration: The expiration of the keys in seconds, 0 for none.
+func (k *TypedKV[T]) BulkSetValue(ctx context.Context, namespaceId string, values map[string]T, expiration uint) (int64, error) {
+	items := make([]BulkItem, 0, len(values))
+	for key, value := range values {
+		encoded, err := encodeText(k.codec, value)
+		if err != nil {
+			return 0, fmt.Errorf("encode value of key %s: %w", key, err)
+		}
+		items = append(items, BulkItem{Key: key, Value: encoded, Expiration: expiration})
+	}
+	return k.KV.BulkSetValue(ctx, namespaceId, items)
+}
+
+// TypedQueue pushes and pulls messages whose payload is of type T, encoded
+// with a Codec.
+type TypedQueue[T any] struct {
+	*Queue
+	codec Codec
+}
+
+// TypedMsg is a queue message with its decoded payload.
+type TypedMsg[T any] struct {
+	*Msg
+	Value T
+}
+
+// NewTypedQueue wraps queue to carry payloads of type T encoded with codec, or
+// JSONCodec when codec is nil.
+func NewTypedQueue[T any](queue *Queue, codec Codec) *TypedQueue[T] {
+	if codec == nil {
+		codec = JSONCodec
+	}
+	return &TypedQueue[T]{Queue: queue, codec: codec}
+}
+
+// Push adds a message with payload value to the queue. req.Payload is ignored.
+func (q *TypedQueue[T]) Push(ctx context.Context, queueId string, req PushQueue, value T) (string, error) {
+	encoded, err := encodeText(q.codec, value)
+	if err != nil {
+		return "", fmt.Errorf("encode payload: %w", err)
+	}
+	req.Payload = []byte(encoded)
+	return q.Queue.Push(ctx, queueId, req)
+}
+
+// Pull retrieves up to size messages and decodes their payload. Messages that
+// can't be decoded are left out and reported in the returned error; they
+// return to the queue once their timeout elapses.
+func (q *TypedQueue[T]) Pull(ctx context.Context, queueId string, size int32) ([]*TypedMsg[T], error) {
+	msgs, err := q.Queue.Pull(ctx, queueId, size)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	typed := make([]*TypedMsg[T], 0, len(msgs))
+	for _, msg := range msgs {
+		value, err := q.Decode(msg)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		typed = append(typed, &TypedMsg[T]{Msg: msg, Value: value})
+	}
+	return typed, errors.Join(errs...)
+}
+
+// Decode decodes the payload of msg.
+func (q *TypedQueue[T]) Decode(msg *Msg) (T, error) {
+	var value T
+	if err := decodeText(q.codec, msg.Payload, &value); err != nil {
+		return value, fmt.Errorf("decode payload of msg %s: %w", msg.ID, err)
+	}
+	return value, nil
+}
+
+// encodeText encodes v as a string, base64-encoding the output of binary codecs.
+func encodeText(codec Codec, v any) (string, error) {
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	if codec.Binary() {
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+	return string(data), nil
+}
+
+func decodeText(codec Codec, s string, v any) error {
+	data := []byte(s)
+	if codec.Binary() {
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(s); err != nil {
+			return err
+		}
+	}
+	return codec.Unmarshal(data, v)
+}
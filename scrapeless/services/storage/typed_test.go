@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/env"
+)
+
+type page struct {
+	URL    string            `json:"url"`
+	Status int               `json:"status"`
+	Tags   []string          `json:"tags"`
+	Meta   map[string]string `json:"meta"`
+}
+
+var testPage = page{URL: "https://example.com", Status: 200, Tags: []string{"a", "b"}, Meta: map[string]string{"k": "v"}}
+
+func newLocalStorage(t *testing.T) *Storage {
+	t.Chdir(t.TempDir())
+	cfg := env.NewConfig()
+	cfg.IsOnline = false
+	s := NewStorage("dev", cfg)
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestCodecs(t *testing.T) {
+	for name, codec := range map[string]Codec{"json": JSONCodec, "gob": GobCodec, "msgpack": MsgpackCodec} {
+		encoded, err := encodeText(codec, testPage)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var got page
+		if err = decodeText(codec, encoded, &got); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, testPage) {
+			t.Fatalf("%s: got %+v", name, got)
+		}
+	}
+}
+
+func TestTypedKV(t *testing.T) {
+	s := newLocalStorage(t)
+	ctx := context.Background()
+	namespaceId, _, err := s.KV.CreateNamespace(ctx, "typed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kv := NewTypedKV[page](s.KV, MsgpackCodec)
+	if _, err = kv.SetValue(ctx, namespaceId, "home", testPage, 0); err != nil {
+		t.Fatal(err)
+	}
+	got, err := kv.GetValue(ctx, namespaceId, "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, testPage) {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+func TestTypedQueue(t *testing.T) {
+	s := newLocalStorage(t)
+	ctx := context.Background()
+	queueId, _, err := s.Queue.CreateQueue(ctx, &CreateQueueReq{Name: "typed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := NewTypedQueue[page](s.Queue, GobCodec)
+	if _, err = q.Push(ctx, queueId, PushQueue{Name: "page", Retry: 1, Deadline: int64(time.Hour / time.Second)}, testPage); err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := q.Pull(ctx, queueId, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || !reflect.DeepEqual(msgs[0].Value, testPage) {
+		t.Fatalf("unexpected msgs %+v", msgs)
+	}
+	if err = q.Ack(ctx, queueId, msgs[0].ID); err != nil {
+		t.Fatal(err)
+	}
+}
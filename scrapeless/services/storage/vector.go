@@ -3,14 +3,14 @@ package storage
 import (
 	"context"
 
-	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/code"
-	"github.com/scrapeless-ai/sdk-go/internal/remote/storage"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
-type Vector struct{}
+type Vector struct {
+	backend
+}
 
 // ListCollections retrieves a list of vector collections with pagination and sorting options.
 // Parameters:
@@ -26,12 +26,12 @@ func (s *Vector) ListCollections(ctx context.Context, page int64, pageSize int64
 	if pageSize < 10 {
 		pageSize = 10
 	}
-	resp, err := storage.ClientInterface.ListCollections(ctx, &models.ListCollectionsRequest{
+	resp, err := s.client.ListCollections(ctx, &models.ListCollectionsRequest{
 		Page:     page,
 		PageSize: pageSize,
 		Desc:     desc,
-		ActorId:  &env.GetActorEnv().ActorId,
-		RunId:    &env.GetActorEnv().RunId,
+		ActorId:  &s.cfg.Actor.ActorId,
+		RunId:    &s.cfg.Actor.RunId,
 	})
 	if err != nil {
 		log.Errorf("failed to list queues: %v", code.Format(err))
@@ -70,14 +70,16 @@ func (s *Vector) ListCollections(ctx context.Context, page int64, pageSize int64
 //
 //	ctx: The context for the request.
 //	req: The request object containing collection configuration details.
+//	     Metric is one of "cosine" (default), "euclidean" or "dotproduct".
 func (s *Vector) CreateCollections(ctx context.Context, req *CreateCollectionRequest) (*CreateCollectionResponse, error) {
-	name := req.Name + "-" + env.GetActorEnv().RunId
-	resp, err := storage.ClientInterface.CreateCollections(ctx, &models.CreateCollectionRequest{
-		ActorId:     env.GetActorEnv().ActorId,
-		RunId:       env.GetActorEnv().RunId,
+	name := req.Name + "-" + s.cfg.Actor.RunId
+	resp, err := s.client.CreateCollections(ctx, &models.CreateCollectionRequest{
+		ActorId:     s.cfg.Actor.ActorId,
+		RunId:       s.cfg.Actor.RunId,
 		Name:        name,
 		Description: req.Description,
 		Dimension:   req.Dimension,
+		Metric:      req.Metric,
 	})
 	if err != nil {
 		log.Errorf("failed to create queue: %v", code.Format(err))
@@ -117,7 +119,7 @@ func (s *Vector) UpdateCollection(ctx context.Context, collId string, name strin
 		Name:        name,
 		Description: description,
 	}
-	err := storage.ClientInterface.UpdateCollection(ctx, req)
+	err := s.client.UpdateCollection(ctx, req)
 	if err != nil {
 		log.Errorf("failed to update collection: %v", code.Format(err))
 		return code.Format(err)
@@ -131,7 +133,7 @@ func (s *Vector) UpdateCollection(ctx context.Context, collId string, name strin
 //	ctx: The context for the request.
 //	collId: The ID of the collection to delete.
 func (s *Vector) DelCollection(ctx context.Context, collId string) error {
-	err := storage.ClientInterface.DelCollection(ctx, collId)
+	err := s.client.DelCollection(ctx, collId)
 	if err != nil {
 		log.Errorf("failed to delete collection: %v", code.Format(err))
 		return code.Format(err)
@@ -145,7 +147,7 @@ func (s *Vector) DelCollection(ctx context.Context, collId string) error {
 //	ctx: The context for the request.
 //	collId: The ID of the collection to retrieve.
 func (s *Vector) GetCollection(ctx context.Context, collId string) (*Collection, error) {
-	coll, err := storage.ClientInterface.GetCollection(ctx, collId)
+	coll, err := s.client.GetCollection(ctx, collId)
 	if err != nil {
 		log.Errorf("failed to get collection: %v", code.Format(err))
 		return nil, code.Format(err)
@@ -187,7 +189,7 @@ func (s *Vector) CreateDocs(ctx context.Context, collId string, docs []*BaseDoc)
 		CollId: collId,
 		Docs:   modelDocs,
 	}
-	resp, err := storage.ClientInterface.CreateDocs(ctx, req)
+	resp, err := s.client.CreateDocs(ctx, req)
 	if err != nil {
 		log.Errorf("failed to create docs: %v", code.Format(err))
 		return nil, code.Format(err)
@@ -227,7 +229,7 @@ func (s *Vector) UpdateDocs(ctx context.Context, collId string, docs []*Doc) (*D
 		CollId: collId,
 		Docs:   modelDocs,
 	}
-	resp, err := storage.ClientInterface.UpdateDocs(ctx, req)
+	resp, err := s.client.UpdateDocs(ctx, req)
 	if err != nil {
 		log.Errorf("failed to update docs: %v", code.Format(err))
 		return nil, code.Format(err)
@@ -267,7 +269,7 @@ func (s *Vector) UpsertDocs(ctx context.Context, collId string, docs []*Doc) (*D
 		CollId: collId,
 		Docs:   modelDocs,
 	}
-	resp, err := storage.ClientInterface.UpsertDocs(ctx, req)
+	resp, err := s.client.UpsertDocs(ctx, req)
 	if err != nil {
 		log.Errorf("failed to upsert docs: %v", code.Format(err))
 		return nil, code.Format(err)
@@ -297,7 +299,7 @@ func (s *Vector) DelDocs(ctx context.Context, collId string, ids []string) (*Doc
 		CollId: collId,
 		Ids:    ids,
 	}
-	resp, err := storage.ClientInterface.DelDocs(ctx, req)
+	resp, err := s.client.DelDocs(ctx, req)
 	if err != nil {
 		log.Errorf("failed to delete docs: %v", code.Format(err))
 		return nil, code.Format(err)
@@ -333,7 +335,7 @@ func (s *Vector) QueryDocs(ctx context.Context, collId string, query *QueryVecto
 		IncludeVector:  query.IncludeVector,
 		IncludeContent: query.IncludeContent,
 	}
-	resp, err := storage.ClientInterface.QueryDocs(ctx, req)
+	resp, err := s.client.QueryDocs(ctx, req)
 	if err != nil {
 		log.Errorf("failed to query docs: %v", code.Format(err))
 		return nil, code.Format(err)
@@ -362,7 +364,7 @@ func (s *Vector) QueryDocsByIds(ctx context.Context, collId string, ids []string
 		CollId: collId,
 		Ids:    ids,
 	}
-	resp, err := storage.ClientInterface.QueryDocsByIds(ctx, req)
+	resp, err := s.client.QueryDocsByIds(ctx, req)
 	if err != nil {
 		log.Errorf("failed to query docs by ids: %v", code.Format(err))
 		return nil, code.Format(err)
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/universal"
-	sh "github.com/scrapeless-ai/sdk-go/internal/remote/universal/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/universal/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"github.com/tidwall/gjson"
@@ -13,22 +12,30 @@ import (
 	"time"
 )
 
-type Universal struct{}
+type Universal struct {
+	cfg    *env.Config
+	client universal.Browser
+}
 
-func New(serverMode string) *Universal {
+// New creates a Universal service. The optional cfg selects the credentials,
+// base URL and proxy defaults to use; env.Default is used when it is omitted.
+func New(serverMode string, cfg ...*env.Config) *Universal {
 	log.Info("Internal Universal init")
-	universal.NewClient(serverMode, env.Env.ScrapelessBaseApiUrl)
-	return &Universal{}
+	c := env.Or(cfg...)
+	return &Universal{
+		cfg:    c,
+		client: universal.NewClient(serverMode, c, c.ScrapelessBaseApiUrl),
+	}
 }
 
 func (us *Universal) CreateTask(ctx context.Context, req UniversalTaskRequest) ([]byte, error) {
 	if req.ProxyCountry == "" {
-		req.ProxyCountry = env.Env.ProxyCountry
+		req.ProxyCountry = us.cfg.ProxyCountry
 	}
 	if req.Actor == "" {
 		return nil, errors.New("actor do not be empty")
 	}
-	response, err := sh.Default().CreateTask(ctx, &models.UniversalTaskRequest{
+	response, err := us.client.CreateTask(ctx, &models.UniversalTaskRequest{
 		Actor: string(req.Actor),
 		Input: req.Input,
 		Proxy: models.TaskProxy{Country: strings.ToUpper(req.ProxyCountry)},
@@ -41,11 +48,11 @@ func (us *Universal) CreateTask(ctx context.Context, req UniversalTaskRequest) (
 }
 
 func (us *Universal) Close() error {
-	return sh.Default().Close()
+	return us.client.Close()
 }
 
 func (us *Universal) GetTaskResult(ctx context.Context, taskId string) ([]byte, error) {
-	result, err := sh.Default().GetTaskResult(ctx, taskId)
+	result, err := us.client.GetTaskResult(ctx, taskId)
 	if err != nil {
 		log.Errorf("get task result err:%v", err)
 		return nil, err